@@ -0,0 +1,423 @@
+// Package alerts evaluates spend budgets, MCP server health and user-defined
+// rules against thresholds and notifies when they are crossed. Alert state is kept in the
+// database, so a condition that persists across runs is one incident:
+// notified when it fires, when it escalates and at a repeat interval, and
+// once more when it resolves.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/errclass"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// Severities, in increasing order.
+const (
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// Alert kinds.
+const (
+	KindBudget    = "budget"
+	KindErrorRate = "error_rate"
+	KindLatency   = "latency"
+)
+
+// Store defines the storage operations the alert engine needs.
+type Store interface {
+	analytics.ErrorAnalyzerStore
+	GetCostSummary(ctx context.Context, filter storage.TimeFilter) (*storage.CostSummary, error)
+	GetSessions(ctx context.Context, filter storage.SessionFilter) ([]storage.Session, error)
+	SaveAlert(ctx context.Context, alert *storage.Alert) error
+	GetActiveAlerts(ctx context.Context) ([]storage.Alert, error)
+	GetAlertRuleStates(ctx context.Context) ([]storage.AlertRuleState, error)
+	SaveAlertRuleState(ctx context.Context, state *storage.AlertRuleState) error
+}
+
+// Config sets the thresholds the engine checks. Zero disables a check.
+type Config struct {
+	BudgetDaily   float64
+	BudgetWeekly  float64 // From Monday
+	BudgetMonthly float64
+	WarnPct       float64 // Percentage of a budget that warns; 0 warns only once spent
+
+	ErrorRate float64       // Percentage of a server's calls failing
+	LatencyMs float64       // A server's p90 latency
+	MinCalls  int64         // Calls in Window before a server is checked
+	Window    time.Duration // Window server health is measured over (default: 1 hour)
+
+	Repeat time.Duration // Interval an active alert is notified again; 0 never
+
+	Rules []*Rule
+}
+
+func (c Config) window() time.Duration {
+	if c.Window > 0 {
+		return c.Window
+	}
+	return time.Hour
+}
+
+// Condition is a threshold found crossed by an evaluation. Conditions with
+// the same Key are the same incident.
+type Condition struct {
+	Key       string
+	Kind      string
+	Subject   string
+	Rule      string // Rule that raised it, for rule conditions
+	Severity  string
+	Message   string
+	Value     float64
+	Threshold float64
+}
+
+// Status is what a notification reports about an alert.
+type Status string
+
+const (
+	StatusFiring    Status = "firing"
+	StatusEscalated Status = "escalated"
+	StatusReminder  Status = "reminder"
+	StatusResolved  Status = "resolved"
+)
+
+// Notification is a change in an alert to deliver.
+type Notification struct {
+	Status Status
+	Alert  storage.Alert
+}
+
+// Text renders the notification as one line, such as
+// "[critical] Daily budget: $12.40 of $10.00 spent".
+func (n Notification) Text() string {
+	switch n.Status {
+	case StatusResolved:
+		return fmt.Sprintf("[resolved] %s", n.Alert.Message)
+	case StatusEscalated:
+		return fmt.Sprintf("[%s, escalated] %s", n.Alert.Severity, n.Alert.Message)
+	default:
+		return fmt.Sprintf("[%s] %s", n.Alert.Severity, n.Alert.Message)
+	}
+}
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Result summarizes an engine run.
+type Result struct {
+	Active        []storage.Alert // Alerts active after the run
+	Notifications []Notification  // Notifications delivered or attempted
+	Deduplicated  int             // Active alerts left unnotified as unchanged
+	Errors        []error         // Delivery failures and rules that failed to evaluate
+}
+
+// Engine evaluates conditions and tracks them as alerts.
+type Engine struct {
+	store      Store
+	config     Config
+	notifiers  []Notifier
+	channels   map[string]Notifier
+	rules      map[string]*Rule
+	classifier *errclass.Classifier
+}
+
+// NewEngine creates an alert engine delivering to notifiers.
+func NewEngine(store Store, config Config, notifiers ...Notifier) *Engine {
+	e := &Engine{
+		store:     store,
+		config:    config,
+		notifiers: notifiers,
+		channels:  make(map[string]Notifier),
+		rules:     make(map[string]*Rule, len(config.Rules)),
+	}
+	for _, n := range notifiers {
+		e.channels[n.Name()] = n
+	}
+	for _, r := range config.Rules {
+		e.rules[r.Name] = r
+	}
+	return e
+}
+
+// AddChannel adds a notifier that only rules naming it in their channels
+// deliver to.
+func (e *Engine) AddChannel(n Notifier) {
+	e.channels[n.Name()] = n
+}
+
+// SetClassifier replaces the built-in error classifier rules use for
+// top_error, typically with one that includes user-defined rules.
+func (e *Engine) SetClassifier(c *errclass.Classifier) {
+	e.classifier = c
+}
+
+// Evaluate returns the conditions that hold as of now.
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) ([]Condition, error) {
+	conditions, err := e.evaluateBudgets(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	health, err := e.evaluateServers(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	return append(conditions, health...), nil
+}
+
+// Run evaluates the conditions as of now, records them as alerts and
+// delivers notifications. A new alert is notified as firing and one whose
+// severity rises as escalated; one still active is notified again once
+// Repeat has passed, and one no longer holding is resolved. An alert whose
+// delivery failed is retried on the next run. Delivery failures, and rules
+// that fail to evaluate, are collected in the result rather than returned.
+func (e *Engine) Run(ctx context.Context, now time.Time) (*Result, error) {
+	conditions, err := e.Evaluate(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := e.store.GetActiveAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting active alerts: %w", err)
+	}
+	held, failed, err := e.evaluateRules(ctx, now, active)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, held...)
+	byKey := make(map[string]*storage.Alert, len(active))
+	for i := range active {
+		byKey[active[i].Key] = &active[i]
+	}
+
+	result := &Result{Errors: failed}
+	for _, c := range conditions {
+		alert, ok := byKey[c.Key]
+		delete(byKey, c.Key)
+
+		var status Status
+		switch {
+		case !ok:
+			alert = &storage.Alert{
+				Key:     c.Key,
+				Kind:    c.Kind,
+				Subject: c.Subject,
+				Rule:    c.Rule,
+				FiredAt: now,
+			}
+			status = StatusFiring
+		case severityRank(c.Severity) > severityRank(alert.Severity):
+			status = StatusEscalated
+		case alert.NotifiedAt.IsZero():
+			status = StatusFiring
+		case e.config.Repeat > 0 && now.Sub(alert.NotifiedAt) >= e.config.Repeat:
+			status = StatusReminder
+		default:
+			result.Deduplicated++
+		}
+
+		alert.Severity = c.Severity
+		alert.Message = c.Message
+		alert.Value = c.Value
+		alert.Threshold = c.Threshold
+		alert.UpdatedAt = now
+		if status != "" {
+			e.deliver(ctx, result, status, alert, now)
+		}
+		if err := e.store.SaveAlert(ctx, alert); err != nil {
+			return nil, fmt.Errorf("saving alert %s: %w", alert.Key, err)
+		}
+		result.Active = append(result.Active, *alert)
+	}
+
+	// Whatever is left no longer holds
+	for _, alert := range active {
+		if _, ok := byKey[alert.Key]; !ok {
+			continue
+		}
+		alert.UpdatedAt = now
+		alert.ResolvedAt = now
+		// An alert nobody heard about resolves quietly
+		if !alert.NotifiedAt.IsZero() {
+			e.deliver(ctx, result, StatusResolved, &alert, now)
+		}
+		if err := e.store.SaveAlert(ctx, &alert); err != nil {
+			return nil, fmt.Errorf("saving alert %s: %w", alert.Key, err)
+		}
+	}
+
+	return result, nil
+}
+
+// deliver sends a notification to the alert's notifiers, marking the
+// alert notified if none failed.
+func (e *Engine) deliver(ctx context.Context, result *Result, status Status, alert *storage.Alert, now time.Time) {
+	n := Notification{Status: status, Alert: *alert}
+	result.Notifications = append(result.Notifications, n)
+
+	failed := false
+	for _, notifier := range e.notifiersFor(alert) {
+		if err := notifier.Notify(ctx, n); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", notifier.Name(), err))
+			failed = true
+		}
+	}
+	if !failed {
+		alert.NotifiedAt = now
+		alert.Notifications++
+	}
+}
+
+// notifiersFor returns the notifiers an alert is delivered to: the
+// channels its rule names, if any, and the default notifiers otherwise.
+// Channels that are not set up here, such as the bell outside the TUI, are
+// skipped.
+func (e *Engine) notifiersFor(alert *storage.Alert) []Notifier {
+	r, ok := e.rules[alert.Rule]
+	if !ok || len(r.Channels) == 0 {
+		return e.notifiers
+	}
+	var notifiers []Notifier
+	for _, name := range r.Channels {
+		if n, ok := e.channels[name]; ok {
+			notifiers = append(notifiers, n)
+		}
+	}
+	return notifiers
+}
+
+// evaluateBudgets checks spend in the current day, week and month.
+func (e *Engine) evaluateBudgets(ctx context.Context, now time.Time) ([]Condition, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday := (int(day.Weekday()) + 6) % 7 // Days since Monday
+	periods := []struct {
+		name   string
+		label  string
+		from   time.Time
+		budget float64
+	}{
+		{"daily", "Daily", day, e.config.BudgetDaily},
+		{"weekly", "Weekly", day.AddDate(0, 0, -weekday), e.config.BudgetWeekly},
+		{"monthly", "Monthly", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), e.config.BudgetMonthly},
+	}
+
+	var conditions []Condition
+	for _, p := range periods {
+		if p.budget <= 0 {
+			continue
+		}
+		summary, err := e.store.GetCostSummary(ctx, storage.TimeFilter{From: p.from, To: now})
+		if err != nil {
+			return nil, fmt.Errorf("getting %s spend: %w", p.name, err)
+		}
+
+		spent := summary.TotalCostUSD
+		pct := spent / p.budget * 100
+		severity := ""
+		switch {
+		case pct >= 100:
+			severity = Critical
+		case e.config.WarnPct > 0 && pct >= e.config.WarnPct:
+			severity = Warning
+		default:
+			continue
+		}
+		conditions = append(conditions, Condition{
+			// Keyed by period start, so a new day is a new incident
+			Key:       fmt.Sprintf("budget:%s:%s", p.name, p.from.Format("2006-01-02")),
+			Kind:      KindBudget,
+			Subject:   p.name,
+			Severity:  severity,
+			Message:   fmt.Sprintf("%s budget: $%.2f of $%.2f spent (%.0f%%)", p.label, spent, p.budget, pct),
+			Value:     spent,
+			Threshold: p.budget,
+		})
+	}
+	return conditions, nil
+}
+
+// evaluateServers checks each server's error rate and p90 latency over the
+// window. Crossing a threshold warns; crossing twice it is critical.
+func (e *Engine) evaluateServers(ctx context.Context, now time.Time) ([]Condition, error) {
+	if e.config.ErrorRate <= 0 && e.config.LatencyMs <= 0 {
+		return nil, nil
+	}
+
+	window := e.config.window()
+	stats, err := e.store.GetMCPServerStats(ctx, storage.TimeFilter{From: now.Add(-window), To: now})
+	if err != nil {
+		return nil, fmt.Errorf("getting server stats: %w", err)
+	}
+
+	var conditions []Condition
+	for _, s := range stats {
+		if s.TotalCalls == 0 || s.TotalCalls < e.config.MinCalls {
+			continue
+		}
+		over := analytics.FormatWindow(window)
+
+		if e.config.ErrorRate > 0 {
+			rate := float64(s.ErrorCount) / float64(s.TotalCalls) * 100
+			if severity := thresholdSeverity(rate, e.config.ErrorRate); severity != "" {
+				conditions = append(conditions, Condition{
+					Key:      "error_rate:" + s.ServerName,
+					Kind:     KindErrorRate,
+					Subject:  s.ServerName,
+					Severity: severity,
+					Message: fmt.Sprintf("%s: %.1f%% of %d calls failed over %s (threshold %g%%)",
+						s.ServerName, rate, s.TotalCalls, over, e.config.ErrorRate),
+					Value:     rate,
+					Threshold: e.config.ErrorRate,
+				})
+			}
+		}
+
+		if e.config.LatencyMs > 0 {
+			if severity := thresholdSeverity(s.P90LatencyMs, e.config.LatencyMs); severity != "" {
+				conditions = append(conditions, Condition{
+					Key:      "latency:" + s.ServerName,
+					Kind:     KindLatency,
+					Subject:  s.ServerName,
+					Severity: severity,
+					Message: fmt.Sprintf("%s: p90 latency %.0fms over %s (threshold %gms)",
+						s.ServerName, s.P90LatencyMs, over, e.config.LatencyMs),
+					Value:     s.P90LatencyMs,
+					Threshold: e.config.LatencyMs,
+				})
+			}
+		}
+	}
+	return conditions, nil
+}
+
+// thresholdSeverity returns the severity of a value against a threshold,
+// or "" if it is under it.
+func thresholdSeverity(value, threshold float64) string {
+	switch {
+	case value >= 2*threshold:
+		return Critical
+	case value >= threshold:
+		return Warning
+	default:
+		return ""
+	}
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case Critical:
+		return 2
+	case Warning:
+		return 1
+	default:
+		return 0
+	}
+}
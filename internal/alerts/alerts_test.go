@@ -0,0 +1,229 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// recorder is a notifier that records what it is sent.
+type recorder struct {
+	sent []Notification
+	err  error
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Notify(ctx context.Context, n Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+func (r *recorder) statuses() []Status {
+	var statuses []Status
+	for _, n := range r.sent {
+		statuses = append(statuses, n.Status)
+	}
+	return statuses
+}
+
+func storeSpend(t *testing.T, store *storage.MockStore, at time.Time, cost float64) {
+	t.Helper()
+	err := store.StoreEvent(context.Background(), &storage.Event{
+		SessionID: "s1",
+		EventType: "Stop",
+		CostUSD:   cost,
+		CreatedAt: at,
+	})
+	if err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+}
+
+func TestRun_Budget(t *testing.T) {
+	store := storage.NewMockStore()
+	rec := &recorder{}
+	engine := NewEngine(store, Config{BudgetDaily: 10, WarnPct: 80, Repeat: 6 * time.Hour}, rec)
+	ctx := context.Background()
+
+	// Wednesday
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	steps := []struct {
+		name     string
+		spend    float64
+		advance  time.Duration
+		want     []Status
+		severity string
+	}{
+		{"under the warning", 5, 0, nil, ""},
+		{"warns at 80%", 3.5, time.Minute, []Status{StatusFiring}, Warning},
+		{"deduplicated", 0.1, time.Minute, nil, Warning},
+		{"escalates once spent", 2, time.Minute, []Status{StatusEscalated}, Critical},
+		{"deduplicated when critical", 0, time.Hour, nil, Critical},
+		{"reminds after the repeat", 0, 6 * time.Hour, []Status{StatusReminder}, Critical},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.spend > 0 {
+			storeSpend(t, store, now.Add(-time.Second), step.spend)
+		}
+		rec.sent = nil
+
+		result, err := engine.Run(ctx, now)
+		if err != nil {
+			t.Fatalf("%s: Run failed: %v", step.name, err)
+		}
+		if got := rec.statuses(); !equalStatuses(got, step.want) {
+			t.Errorf("%s: expected notifications %v, got %v", step.name, step.want, got)
+		}
+		if step.severity == "" {
+			if len(result.Active) != 0 {
+				t.Errorf("%s: expected no active alerts, got %+v", step.name, result.Active)
+			}
+			continue
+		}
+		if len(result.Active) != 1 || result.Active[0].Severity != step.severity {
+			t.Errorf("%s: expected one %s alert, got %+v", step.name, step.severity, result.Active)
+		}
+	}
+
+	// The next day the spend starts over and the incident resolves
+	rec.sent = nil
+	result, err := engine.Run(ctx, time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got := rec.statuses(); !equalStatuses(got, []Status{StatusResolved}) {
+		t.Errorf("expected the alert to resolve, got %v", got)
+	}
+	if len(result.Active) != 0 {
+		t.Errorf("expected no active alerts, got %+v", result.Active)
+	}
+
+	history, err := store.GetAlerts(ctx, storage.AlertFilter{})
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Active() || history[0].Notifications != 4 {
+		t.Errorf("expected one resolved alert notified 4 times, got %+v", history)
+	}
+}
+
+func TestRun_ServerHealth(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+
+	// github fails 3 of 10 calls; slack is slow but has too few calls
+	for i := 0; i < 10; i++ {
+		store.StoreEvent(ctx, &storage.Event{
+			SessionID:  "s1",
+			EventType:  "PostToolUse",
+			MCPServer:  "github",
+			Success:    i >= 3,
+			DurationMs: 100,
+			CreatedAt:  now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	for i := 0; i < 3; i++ {
+		store.StoreEvent(ctx, &storage.Event{
+			SessionID:  "s1",
+			EventType:  "PostToolUse",
+			MCPServer:  "slack",
+			Success:    true,
+			DurationMs: 9000,
+			CreatedAt:  now.Add(-time.Minute),
+		})
+	}
+
+	engine := NewEngine(store, Config{ErrorRate: 10, LatencyMs: 1000, MinCalls: 5, Window: time.Hour})
+	conditions, err := engine.Evaluate(ctx, now)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(conditions) != 1 {
+		t.Fatalf("expected one condition, got %+v", conditions)
+	}
+	c := conditions[0]
+	if c.Key != "error_rate:github" || c.Severity != Critical || c.Value != 30 {
+		t.Errorf("expected github's 30%% error rate to be critical, got %+v", c)
+	}
+
+	// Outside the window nothing holds
+	conditions, err = engine.Evaluate(ctx, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(conditions) != 0 {
+		t.Errorf("expected no conditions, got %+v", conditions)
+	}
+}
+
+func TestRun_DeliveryFailure(t *testing.T) {
+	store := storage.NewMockStore()
+	rec := &recorder{err: errors.New("unreachable")}
+	engine := NewEngine(store, Config{BudgetDaily: 1}, rec)
+	ctx := context.Background()
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	storeSpend(t, store, now.Add(-time.Minute), 2)
+
+	result, err := engine.Run(ctx, now)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("expected the delivery error, got %v", result.Errors)
+	}
+
+	// Retried on the next run, and not again once delivered
+	rec.err = nil
+	for i := 0; i < 2; i++ {
+		if _, err := engine.Run(ctx, now.Add(time.Duration(i+1)*time.Minute)); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+	if got := rec.statuses(); !equalStatuses(got, []Status{StatusFiring, StatusFiring}) {
+		t.Errorf("expected the failed notification to be retried once, got %v", got)
+	}
+}
+
+func TestRun_ResolvesUnnotifiedQuietly(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+
+	if err := store.SaveAlert(ctx, &storage.Alert{
+		Key: "latency:github", Kind: KindLatency, Subject: "github", Severity: Warning,
+		FiredAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("SaveAlert failed: %v", err)
+	}
+
+	rec := &recorder{}
+	if _, err := NewEngine(store, Config{LatencyMs: 1000}, rec).Run(ctx, now); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(rec.sent) != 0 {
+		t.Errorf("expected no notifications, got %v", rec.statuses())
+	}
+	active, _ := store.GetActiveAlerts(ctx)
+	if len(active) != 0 {
+		t.Errorf("expected the alert resolved, got %+v", active)
+	}
+}
+
+func equalStatuses(a, b []Status) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,615 @@
+package alerts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled rule expression, such as
+//
+//	server == "github" && error_rate_1h > 10 && calls_1h >= 5
+//
+// Expressions combine variables, numbers, "quoted" strings and true/false
+// with arithmetic (+ - * /), comparisons (== != < <= > >=), regular
+// expression matches (=~), !, && and ||, and parentheses. Values are
+// numbers, strings or booleans; mixing them is a type error, which Check
+// finds before evaluation.
+type Expr struct {
+	source string
+	root   node
+	idents []string
+}
+
+// Env maps variable names to their values: float64, string or bool.
+type Env map[string]interface{}
+
+// ParseExpr compiles an expression.
+func ParseExpr(source string) (*Expr, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", t, t.pos)
+	}
+
+	e := &Expr{source: source, root: root}
+	seen := make(map[string]bool)
+	walk(root, func(n node) {
+		if v, ok := n.(varNode); ok && !seen[string(v)] {
+			seen[string(v)] = true
+			e.idents = append(e.idents, string(v))
+		}
+	})
+	return e, nil
+}
+
+// String returns the expression's source.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Variables returns the variables the expression uses, in order of first use.
+func (e *Expr) Variables() []string {
+	return e.idents
+}
+
+// Check type checks the expression against env, whose values stand for the
+// types of the variables. Unlike Eval, it checks both operands of && and ||,
+// so it finds type errors in branches evaluation might never reach.
+func (e *Expr) Check(env Env) error {
+	t, err := e.root.check(env)
+	if err != nil {
+		return err
+	}
+	if t != "boolean" {
+		return fmt.Errorf("expression is %s, not boolean", t)
+	}
+	return nil
+}
+
+// Eval evaluates the expression, which must be boolean, in env. Operands
+// of && and || are only evaluated when needed.
+func (e *Expr) Eval(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression is %s, not boolean", typeName(v))
+	}
+	return b, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators lists the operators, longest first so that "<=" is not read
+// as "<".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "+", "-", "*", "/"}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(source) {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			kind := tokLParen
+			if c == ')' {
+				kind = tokRParen
+			}
+			tokens = append(tokens, token{kind: kind, text: string(c), pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(source) && rune(source[end]) != c {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			text := source[i+1 : end]
+			if c == '"' {
+				s, err := strconv.Unquote(source[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+				}
+				text = s
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: i})
+			i = end + 1
+		case c >= '0' && c <= '9' || c == '.':
+			end := i
+			for end < len(source) && (source[end] >= '0' && source[end] <= '9' || source[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: source[i:end], pos: i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(source) && (source[end] == '_' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(source)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of ops.
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{or: true, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<=", ">=", "<", ">", "=~")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "=~" {
+		lit, ok := right.(litNode)
+		pattern, isString := lit.value.(string)
+		if !ok || !isString {
+			return nil, fmt.Errorf("=~ needs a string pattern")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return matchNode{left: left, re: re}, nil
+	}
+	return binaryNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return litNode{value: n}, nil
+	case tokString:
+		return litNode{value: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return litNode{value: true}, nil
+		case "false":
+			return litNode{value: false}, nil
+		}
+		return varNode(t.text), nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected \")\" at offset %d, got %s", closing.pos, closing)
+		}
+		return inner, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s at offset %d", t, t.pos)
+	}
+}
+
+type node interface {
+	eval(env Env) (interface{}, error)
+	check(env Env) (string, error) // Type of the node's value, with env's values standing for their types
+}
+
+// walk calls fn on n and every node below it.
+func walk(n node, fn func(node)) {
+	fn(n)
+	switch n := n.(type) {
+	case logicalNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case binaryNode:
+		walk(n.left, fn)
+		walk(n.right, fn)
+	case matchNode:
+		walk(n.left, fn)
+	case unaryNode:
+		walk(n.operand, fn)
+	}
+}
+
+type litNode struct {
+	value interface{}
+}
+
+func (n litNode) eval(env Env) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n litNode) check(env Env) (string, error) {
+	return typeName(n.value), nil
+}
+
+type varNode string
+
+func (n varNode) eval(env Env) (interface{}, error) {
+	v, ok := env[string(n)]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", string(n))
+	}
+	return v, nil
+}
+
+func (n varNode) check(env Env) (string, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return "", err
+	}
+	return typeName(v), nil
+}
+
+type logicalNode struct {
+	or          bool
+	left, right node
+}
+
+func (n logicalNode) eval(env Env) (interface{}, error) {
+	left, err := evalBool(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	if left == n.or {
+		return left, nil
+	}
+	return evalBool(n.right, env)
+}
+
+func (n logicalNode) check(env Env) (string, error) {
+	for _, operand := range []node{n.left, n.right} {
+		if err := checkBool(operand, env); err != nil {
+			return "", err
+		}
+	}
+	return "boolean", nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n unaryNode) eval(env Env) (interface{}, error) {
+	if n.op == "!" {
+		b, err := evalBool(n.operand, env)
+		return !b, err
+	}
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", typeName(v))
+	}
+	return -f, nil
+}
+
+func (n unaryNode) check(env Env) (string, error) {
+	if n.op == "!" {
+		return "boolean", checkBool(n.operand, env)
+	}
+	t, err := n.operand.check(env)
+	if err != nil {
+		return "", err
+	}
+	if t != "number" {
+		return "", fmt.Errorf("cannot negate %s", t)
+	}
+	return "number", nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		if typeName(left) != typeName(right) {
+			return nil, fmt.Errorf("cannot compare %s %s %s", typeName(left), n.op, typeName(right))
+		}
+		return (left == right) == (n.op == "=="), nil
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s needs numbers, got %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	default:
+		return l / r, nil
+	}
+}
+
+func (n binaryNode) check(env Env) (string, error) {
+	left, err := n.left.check(env)
+	if err != nil {
+		return "", err
+	}
+	right, err := n.right.check(env)
+	if err != nil {
+		return "", err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		if left != right {
+			return "", fmt.Errorf("cannot compare %s %s %s", left, n.op, right)
+		}
+		return "boolean", nil
+	}
+	if left != "number" || right != "number" {
+		return "", fmt.Errorf("%s needs numbers, got %s and %s", n.op, left, right)
+	}
+	switch n.op {
+	case "<", "<=", ">", ">=":
+		return "boolean", nil
+	default:
+		return "number", nil
+	}
+}
+
+type matchNode struct {
+	left node
+	re   *regexp.Regexp
+}
+
+func (n matchNode) eval(env Env) (interface{}, error) {
+	v, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("=~ needs a string, got %s", typeName(v))
+	}
+	return n.re.MatchString(s), nil
+}
+
+func (n matchNode) check(env Env) (string, error) {
+	t, err := n.left.check(env)
+	if err != nil {
+		return "", err
+	}
+	if t != "string" {
+		return "", fmt.Errorf("=~ needs a string, got %s", t)
+	}
+	return "boolean", nil
+}
+
+func evalBool(n node, env Env) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected boolean, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+func checkBool(n node, env Env) error {
+	t, err := n.check(env)
+	if err != nil {
+		return err
+	}
+	if t != "boolean" {
+		return fmt.Errorf("expected boolean, got %s", t)
+	}
+	return nil
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,98 @@
+package alerts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	env := Env{
+		"server":        "github",
+		"error_rate_1h": 23.5,
+		"calls_1h":      17.0,
+		"errors_1h":     4.0,
+		"trend_1h":      "up",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`server == "github" && error_rate_1h > 10 && calls_1h >= 5`, true},
+		{`server == 'slack' || calls_1h < 5`, false},
+		{`!(server != "github")`, true},
+		{`errors_1h / calls_1h * 100 > 20`, true},
+		{`calls_1h - errors_1h == 13`, true},
+		{`-calls_1h < 0`, true},
+		{`server =~ "^git"`, true},
+		{`trend_1h == "up" && (calls_1h > 100 || error_rate_1h >= 23.5)`, true},
+		{`true && !false`, true},
+		// The right operand is not evaluated, so a missing variable is fine
+		{`server == "slack" && missing_1h > 0`, false},
+	}
+	for _, tt := range tests {
+		e, err := ParseExpr(tt.expr)
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		got, err := e.Eval(env)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExpr_Variables(t *testing.T) {
+	e, err := ParseExpr(`server == "github" && error_rate_1h > 10 && (calls_1h >= 5 || error_rate_1h > 50)`)
+	if err != nil {
+		t.Fatalf("ParseExpr failed: %v", err)
+	}
+	want := []string{"server", "error_rate_1h", "calls_1h"}
+	if got := e.Variables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+}
+
+func TestExpr_Errors(t *testing.T) {
+	parseErrors := map[string]string{
+		`calls_1h >`:          "unexpected end of expression",
+		`(calls_1h > 5`:       `expected ")"`,
+		`calls_1h > 5 5`:      `unexpected "5"`,
+		`server == "github`:   "unterminated string",
+		`calls_1h # 5`:        `unexpected '#'`,
+		`server =~ calls_1h`:  "=~ needs a string pattern",
+		`server =~ "(github"`: "invalid pattern",
+	}
+	for expr, want := range parseErrors {
+		_, err := ParseExpr(expr)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseExpr(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+
+	env := Env{"server": "github", "calls_1h": 5.0}
+	evalErrors := map[string]string{
+		`server > 5`:       "> needs numbers",
+		`server == 5`:      "cannot compare string == number",
+		`calls_1h + 1`:     "not boolean",
+		`calls_1h && true`: "expected boolean",
+		`missing_1h > 0`:   `unknown variable "missing_1h"`,
+	}
+	for expr, want := range evalErrors {
+		e, err := ParseExpr(expr)
+		if err != nil {
+			t.Errorf("ParseExpr(%q) failed: %v", expr, err)
+			continue
+		}
+		_, err = e.Eval(env)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Eval(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
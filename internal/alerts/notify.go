@@ -0,0 +1,183 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Payload is the JSON body a notification is delivered as. Text suits chat
+// webhooks that post a "text" field as the message.
+type Payload struct {
+	Status        Status     `json:"status"`
+	Text          string     `json:"text"`
+	Key           string     `json:"key"`
+	Kind          string     `json:"kind"`
+	Subject       string     `json:"subject"`
+	Rule          string     `json:"rule,omitempty"`
+	Severity      string     `json:"severity"`
+	Message       string     `json:"message"`
+	Value         float64    `json:"value"`
+	Threshold     float64    `json:"threshold"`
+	FiredAt       time.Time  `json:"fired_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	Notifications int        `json:"notifications"` // Earlier notifications of the alert
+}
+
+// NewPayload builds the payload of a notification.
+func NewPayload(n Notification) Payload {
+	p := Payload{
+		Status:        n.Status,
+		Text:          n.Text(),
+		Key:           n.Alert.Key,
+		Kind:          n.Alert.Kind,
+		Subject:       n.Alert.Subject,
+		Rule:          n.Alert.Rule,
+		Severity:      n.Alert.Severity,
+		Message:       n.Alert.Message,
+		Value:         n.Alert.Value,
+		Threshold:     n.Alert.Threshold,
+		FiredAt:       n.Alert.FiredAt,
+		Notifications: n.Alert.Notifications,
+	}
+	if !n.Alert.ResolvedAt.IsZero() {
+		p.ResolvedAt = &n.Alert.ResolvedAt
+	}
+	return p
+}
+
+// Webhook posts notifications as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client // Default: a client with a 10 second timeout
+}
+
+// Name identifies the notifier in errors.
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify posts the notification, failing on any status other than 2xx.
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(NewPayload(n))
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mcp-lens")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("posting: %s", resp.Status)
+	}
+	return nil
+}
+
+// Command runs a shell command for each notification, with the JSON payload
+// on stdin and the main fields in MCP_LENS_ALERT_* environment variables.
+type Command struct {
+	Command string
+	Timeout time.Duration // Default: 30 seconds
+}
+
+// Name identifies the notifier in errors.
+func (c *Command) Name() string {
+	return "command"
+}
+
+// Notify runs the command, failing if it exits non-zero.
+func (c *Command) Notify(ctx context.Context, n Notification) error {
+	payload := NewPayload(n)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"MCP_LENS_ALERT_STATUS="+string(payload.Status),
+		"MCP_LENS_ALERT_KIND="+payload.Kind,
+		"MCP_LENS_ALERT_SUBJECT="+payload.Subject,
+		"MCP_LENS_ALERT_SEVERITY="+payload.Severity,
+		"MCP_LENS_ALERT_MESSAGE="+payload.Message,
+		"MCP_LENS_ALERT_TEXT="+payload.Text,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("running %q: %w: %s", c.Command, err, msg)
+		}
+		return fmt.Errorf("running %q: %w", c.Command, err)
+	}
+	return nil
+}
+
+// Bell rings the terminal bell when an alert fires or escalates.
+type Bell struct {
+	Out io.Writer
+}
+
+// Name identifies the notifier in errors.
+func (b *Bell) Name() string {
+	return "bell"
+}
+
+// Notify writes the bell character for firing and escalated alerts.
+func (b *Bell) Notify(ctx context.Context, n Notification) error {
+	if n.Status != StatusFiring && n.Status != StatusEscalated {
+		return nil
+	}
+	_, err := io.WriteString(b.Out, "\a")
+	return err
+}
+
+// Named gives a notifier a channel name, so rules can deliver to it by name.
+func Named(name string, n Notifier) Notifier {
+	return &named{name: name, Notifier: n}
+}
+
+type named struct {
+	name string
+	Notifier
+}
+
+// Name returns the channel name.
+func (n *named) Name() string {
+	return n.name
+}
@@ -0,0 +1,115 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func testNotification(status Status) Notification {
+	return Notification{
+		Status: status,
+		Alert: storage.Alert{
+			Key:       "budget:daily:2026-10-14",
+			Kind:      KindBudget,
+			Subject:   "daily",
+			Severity:  Critical,
+			Message:   "Daily budget: $12.00 of $10.00 spent (120%)",
+			Value:     12,
+			Threshold: 10,
+			FiredAt:   time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Payload
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := &Webhook{URL: server.URL}
+	if err := w.Notify(context.Background(), testNotification(StatusFiring)); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected a JSON body, got %q", contentType)
+	}
+	if got.Status != StatusFiring || got.Kind != KindBudget || got.Value != 12 || got.ResolvedAt != nil {
+		t.Errorf("unexpected payload %+v", got)
+	}
+	if got.Text != "[critical] Daily budget: $12.00 of $10.00 spent (120%)" {
+		t.Errorf("unexpected text %q", got.Text)
+	}
+}
+
+func TestWebhook_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	w := &Webhook{URL: server.URL}
+	err := w.Notify(context.Background(), testNotification(StatusFiring))
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected the status in the error, got %v", err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	out := filepath.Join(t.TempDir(), "alert.json")
+
+	c := &Command{Command: `cat > "` + out + `"; [ "$MCP_LENS_ALERT_STATUS" = resolved ]`}
+	if err := c.Notify(context.Background(), testNotification(StatusResolved)); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	var got Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if got.Key != "budget:daily:2026-10-14" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	c = &Command{Command: "echo broken >&2; exit 3"}
+	err = c.Notify(context.Background(), testNotification(StatusFiring))
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the command's output in the error, got %v", err)
+	}
+}
+
+func TestBell(t *testing.T) {
+	var buf bytes.Buffer
+	b := &Bell{Out: &buf}
+	for _, status := range []Status{StatusFiring, StatusReminder, StatusEscalated, StatusResolved} {
+		if err := b.Notify(context.Background(), testNotification(status)); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+	if buf.String() != "\a\a" {
+		t.Errorf("expected two bells, got %q", buf.String())
+	}
+}
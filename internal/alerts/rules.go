@@ -0,0 +1,710 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// KindRule is the kind of alerts raised by rules.
+const KindRule = "rule"
+
+// RuleConfig declares an alert rule. Expr is evaluated for each server when
+// it uses server metrics, and once otherwise. It fires once it has held for
+// For; Resolve, if set, must then hold for the alert to resolve, instead of
+// Expr no longer holding, and must keep holding for ClearFor. Channels names
+// the notifiers to deliver to; empty delivers to the default ones.
+type RuleConfig struct {
+	Name     string
+	Expr     string
+	Resolve  string
+	For      time.Duration
+	ClearFor time.Duration
+	Severity string // Default: warning
+	Channels []string
+}
+
+// Rule is a compiled alert rule.
+type Rule struct {
+	RuleConfig
+	expr      *Expr
+	resolve   *Expr
+	perServer bool
+	needs     map[needKey]bool // Metric sources and windows the expressions use
+}
+
+// metric describes a variable rules can use. Unless global, metrics are per
+// server; all take a window suffix such as _1h, _24h or _7d.
+type metric struct {
+	source metricSource
+	global bool
+	zero   interface{} // Value when a server has no data in the window
+	doc    string
+}
+
+type metricSource int
+
+const (
+	sourceUtilization metricSource = iota
+	sourceErrors
+	sourceLatency
+	sourceSessions
+	sourceCost
+)
+
+var metrics = map[string]metric{
+	"calls":           {source: sourceUtilization, zero: 0.0, doc: "Calls to the server"},
+	"utilization":     {source: sourceUtilization, zero: 0.0, doc: "Percentage of all MCP calls going to the server"},
+	"error_rate":      {source: sourceUtilization, zero: 0.0, doc: "Percentage of the server's calls failing"},
+	"avg_latency_ms":  {source: sourceUtilization, zero: 0.0, doc: "Average latency"},
+	"trend":           {source: sourceUtilization, zero: string(analytics.TrendStable), doc: `Call volume against the previous window: "up", "down" or "stable"`},
+	"trend_pct":       {source: sourceUtilization, zero: 0.0, doc: "Percentage change in call volume; +Inf for a server new in the window"},
+	"errors":          {source: sourceErrors, zero: 0.0, doc: "Failed calls"},
+	"error_trend":     {source: sourceErrors, zero: string(analytics.TrendStable), doc: `Error rate against the previous window: "up", "down" or "stable"`},
+	"error_trend_pct": {source: sourceErrors, zero: 0.0, doc: "Percentage change in error rate; +Inf when new"},
+	"top_error":       {source: sourceErrors, zero: "", doc: `Category most failures fall in, such as "auth" or "timeout"; "" if none`},
+	"top_error_share": {source: sourceErrors, zero: 0.0, doc: "Percentage of failures in the top category"},
+	"p50_ms":          {source: sourceLatency, zero: 0.0, doc: "Median latency"},
+	"p90_ms":          {source: sourceLatency, zero: 0.0, doc: "90th percentile latency"},
+	"p99_ms":          {source: sourceLatency, zero: 0.0, doc: "99th percentile latency"},
+	"sessions":        {source: sourceSessions, global: true, zero: 0.0, doc: "Sessions started"},
+	"session_cost":    {source: sourceSessions, global: true, zero: 0.0, doc: "Cost in USD of the sessions started"},
+	"session_tokens":  {source: sourceSessions, global: true, zero: 0.0, doc: "Tokens used by the sessions started"},
+	"cost":            {source: sourceCost, global: true, zero: 0.0, doc: "Spend in USD"},
+}
+
+// ServerVariable is the variable holding the server name in per-server rules.
+const ServerVariable = "server"
+
+// Variables describes the variables rules can use, sorted by name, such as
+// "error_rate_<window>" with its description.
+func Variables() [][2]string {
+	vars := [][2]string{{ServerVariable, "Server name"}}
+	for name, m := range metrics {
+		vars = append(vars, [2]string{name + "_<window>", m.doc})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i][0] < vars[j][0] })
+	return vars
+}
+
+type needKey struct {
+	source metricSource
+	window time.Duration
+}
+
+// NewRule compiles a rule, checking that its expressions only use known
+// variables.
+func NewRule(config RuleConfig) (*Rule, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("rule has no name")
+	}
+	if config.Severity == "" {
+		config.Severity = Warning
+	}
+	if config.Severity != Warning && config.Severity != Critical {
+		return nil, fmt.Errorf("rule %s: severity must be %q or %q, got %q", config.Name, Warning, Critical, config.Severity)
+	}
+	if config.For < 0 || config.ClearFor < 0 {
+		return nil, fmt.Errorf("rule %s: durations must not be negative", config.Name)
+	}
+
+	r := &Rule{RuleConfig: config, needs: make(map[needKey]bool)}
+	var err error
+	if r.expr, err = r.compile(config.Expr); err != nil {
+		return nil, fmt.Errorf("rule %s: %w", config.Name, err)
+	}
+	if config.Resolve != "" {
+		if r.resolve, err = r.compile(config.Resolve); err != nil {
+			return nil, fmt.Errorf("rule %s: resolve: %w", config.Name, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Rule) compile(source string) (*Expr, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	e, err := ParseExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range e.Variables() {
+		if name == ServerVariable {
+			r.perServer = true
+			continue
+		}
+		m, window, err := lookupVariable(name)
+		if err != nil {
+			return nil, err
+		}
+		if !m.global {
+			r.perServer = true
+		}
+		r.needs[needKey{m.source, window}] = true
+	}
+
+	// Catch type errors now rather than at every evaluation
+	types := Env{}
+	for _, name := range e.Variables() {
+		if name == ServerVariable {
+			types[name] = ""
+			continue
+		}
+		m, _, _ := lookupVariable(name)
+		types[name] = m.zero
+	}
+	if err := e.Check(types); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// lookupVariable splits a variable such as "error_rate_1h" into its metric
+// and window.
+func lookupVariable(name string) (metric, time.Duration, error) {
+	if _, ok := metrics[name]; ok {
+		return metric{}, 0, fmt.Errorf("variable %q needs a window, such as %s_1h", name, name)
+	}
+	i := strings.LastIndex(name, "_")
+	m, ok := metrics[name[:max(i, 0)]]
+	if i < 0 || !ok {
+		return metric{}, 0, fmt.Errorf("unknown variable %q", name)
+	}
+	window, err := parseWindow(name[i+1:])
+	if err != nil {
+		return metric{}, 0, fmt.Errorf("variable %q: %w", name, err)
+	}
+	return m, window, nil
+}
+
+// parseWindow parses a window such as "15m", "6h" or "7d".
+func parseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Minute {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	return d, nil
+}
+
+// key returns the alert key of the rule for a subject, empty for a global
+// rule.
+func (r *Rule) key(subject string) string {
+	if subject == "" {
+		return "rule:" + r.Name
+	}
+	return "rule:" + r.Name + ":" + subject
+}
+
+// step advances the rule's conditions to now. envs holds each subject's
+// variables, active the rule's active alerts by key, and states the saved
+// state by key, which step updates. It returns the conditions that keep or
+// put an alert active: those holding for at least For, and active ones not
+// yet resolved.
+func (r *Rule) step(now time.Time, envs map[string]Env, active map[string]storage.Alert, states map[string]*storage.AlertRuleState) ([]Condition, error) {
+	subjects := make(map[string]bool, len(envs))
+	for subject := range envs {
+		subjects[subject] = true
+	}
+	for _, alert := range active {
+		subjects[alert.Subject] = true
+	}
+	sorted := make([]string, 0, len(subjects))
+	for subject := range subjects {
+		sorted = append(sorted, subject)
+	}
+	sort.Strings(sorted)
+
+	// Pending subjects that no longer appear start over
+	keys := make(map[string]bool, len(sorted))
+	for _, subject := range sorted {
+		keys[r.key(subject)] = true
+	}
+	for key, st := range states {
+		if st.Rule == r.Name && !keys[key] {
+			st.HoldingSince = time.Time{}
+			st.ClearingSince = time.Time{}
+		}
+	}
+
+	var conditions []Condition
+	for _, subject := range sorted {
+		key := r.key(subject)
+		env, hasData := envs[subject]
+		st, ok := states[key]
+		if !ok {
+			st = &storage.AlertRuleState{Key: key, Rule: r.Name}
+			states[key] = st
+		}
+
+		holds := false
+		if hasData {
+			var err error
+			if holds, err = r.expr.Eval(env); err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+		}
+
+		alert, isActive := active[key]
+		if !isActive {
+			st.ClearingSince = time.Time{}
+			if !holds {
+				st.HoldingSince = time.Time{}
+				continue
+			}
+			if st.HoldingSince.IsZero() {
+				st.HoldingSince = now
+			}
+			if now.Sub(st.HoldingSince) < r.For {
+				continue
+			}
+			st.HoldingSince = time.Time{}
+			conditions = append(conditions, r.condition(subject, env))
+			continue
+		}
+
+		st.HoldingSince = time.Time{}
+		if !holds {
+			resolved := true
+			if hasData && r.resolve != nil {
+				var err error
+				if resolved, err = r.resolve.Eval(env); err != nil {
+					return nil, fmt.Errorf("rule %s: resolve: %w", r.Name, err)
+				}
+			}
+			if resolved {
+				if st.ClearingSince.IsZero() {
+					st.ClearingSince = now
+				}
+				if now.Sub(st.ClearingSince) >= r.ClearFor {
+					st.ClearingSince = time.Time{}
+					continue
+				}
+			} else {
+				st.ClearingSince = time.Time{}
+			}
+			// Still active: keep what was last reported
+			c := r.condition(subject, env)
+			if !hasData {
+				c.Message, c.Value = alert.Message, alert.Value
+			}
+			conditions = append(conditions, c)
+			continue
+		}
+
+		st.ClearingSince = time.Time{}
+		conditions = append(conditions, r.condition(subject, env))
+	}
+	return conditions, nil
+}
+
+// condition describes the rule holding for subject, listing the values of
+// the variables it uses, such as "github-errors (github): error_rate_1h=23.50".
+func (r *Rule) condition(subject string, env Env) Condition {
+	c := Condition{
+		Key:      r.key(subject),
+		Kind:     KindRule,
+		Subject:  subject,
+		Rule:     r.Name,
+		Severity: r.Severity,
+	}
+
+	label := r.Name
+	if subject != "" {
+		label += " (" + subject + ")"
+	}
+	var values []string
+	for _, name := range r.expr.Variables() {
+		v, ok := env[name]
+		if name == ServerVariable || !ok {
+			continue
+		}
+		if f, isNumber := v.(float64); isNumber && c.Value == 0 {
+			c.Value = f
+		}
+		values = append(values, name+"="+formatValue(v))
+	}
+	c.Message = label
+	if len(values) > 0 {
+		c.Message += ": " + strings.Join(values, ", ")
+	}
+	return c
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatFloat(v, 'f', 0, 64)
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// metricCache computes the metrics rules use as of one time, once per
+// source and window.
+type metricCache struct {
+	store       Store
+	now         time.Time
+	utilization *analytics.UtilizationAnalyzer
+	errors      *analytics.ErrorAnalyzer
+	loaded      map[needKey]bool
+	servers     map[time.Duration]map[string]Env // Per-server metrics by window, keyed by metric name
+	global      map[time.Duration]Env
+}
+
+func (e *Engine) newMetricCache(now time.Time) *metricCache {
+	errors := analytics.NewErrorAnalyzer(e.store, analytics.DefaultErrorAnalyzerConfig())
+	errors.SetClassifier(e.classifier)
+	return &metricCache{
+		store:       e.store,
+		now:         now,
+		utilization: analytics.NewUtilizationAnalyzer(e.store, analytics.DefaultUtilizationConfig()),
+		errors:      errors,
+		loaded:      make(map[needKey]bool),
+		servers:     make(map[time.Duration]map[string]Env),
+		global:      make(map[time.Duration]Env),
+	}
+}
+
+// envs returns the variables of each subject the rule is evaluated for:
+// every server with data in one of its windows, or "" for a global rule.
+func (c *metricCache) envs(ctx context.Context, r *Rule) (map[string]Env, error) {
+	for need := range r.needs {
+		if err := c.load(ctx, need); err != nil {
+			return nil, err
+		}
+	}
+
+	names := append(r.expr.Variables(), r.resolveVariables()...)
+	build := func(server string) Env {
+		env := Env{}
+		if server != "" {
+			env[ServerVariable] = server
+		}
+		for _, name := range names {
+			if name == ServerVariable {
+				continue
+			}
+			m, window, _ := lookupVariable(name)
+			base := name[:strings.LastIndex(name, "_")]
+			var v interface{}
+			if m.global {
+				v = c.global[window][base]
+			} else {
+				v = c.servers[window][server][base]
+			}
+			if v == nil {
+				v = m.zero
+			}
+			env[name] = v
+		}
+		return env
+	}
+
+	if !r.perServer {
+		return map[string]Env{"": build("")}, nil
+	}
+	envs := make(map[string]Env)
+	for need := range r.needs {
+		for server := range c.servers[need.window] {
+			if _, ok := envs[server]; !ok {
+				envs[server] = build(server)
+			}
+		}
+	}
+	return envs, nil
+}
+
+func (r *Rule) resolveVariables() []string {
+	if r.resolve == nil {
+		return nil
+	}
+	return r.resolve.Variables()
+}
+
+// serverEnv returns the metrics of a server in a window, creating them.
+func (c *metricCache) serverEnv(window time.Duration, server string) Env {
+	if c.servers[window] == nil {
+		c.servers[window] = make(map[string]Env)
+	}
+	env, ok := c.servers[window][server]
+	if !ok {
+		env = Env{}
+		c.servers[window][server] = env
+	}
+	return env
+}
+
+func (c *metricCache) globalEnv(window time.Duration) Env {
+	env, ok := c.global[window]
+	if !ok {
+		env = Env{}
+		c.global[window] = env
+	}
+	return env
+}
+
+// load computes the metrics of one source over a window ending now.
+func (c *metricCache) load(ctx context.Context, need needKey) error {
+	if c.loaded[need] {
+		return nil
+	}
+	c.loaded[need] = true
+	filter := storage.TimeFilter{From: c.now.Add(-need.window), To: c.now}
+
+	switch need.source {
+	case sourceUtilization:
+		servers, err := c.utilization.AnalyzeUtilization(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("analyzing utilization: %w", err)
+		}
+		for _, u := range servers {
+			env := c.serverEnv(need.window, u.ServerName)
+			env["calls"] = float64(u.TotalCalls)
+			env["utilization"] = u.UtilizationPct
+			env["error_rate"] = u.ErrorRate
+			env["avg_latency_ms"] = u.AvgLatencyMs
+			env["trend"] = string(u.Trend)
+			env["trend_pct"] = u.TrendPct
+		}
+
+	case sourceErrors:
+		summaries, err := c.errors.AnalyzeErrors(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("analyzing errors: %w", err)
+		}
+		for _, s := range summaries {
+			env := c.serverEnv(need.window, s.ServerName)
+			env["errors"] = float64(s.ErrorCount)
+			env["error_trend"] = string(s.ErrorTrend)
+			env["error_trend_pct"] = s.ErrorTrendPct
+			if top, ok := s.TopCategory(); ok {
+				env["top_error"] = string(top.Category)
+				env["top_error_share"] = top.Share
+			}
+		}
+
+	case sourceLatency:
+		stats, err := c.store.GetMCPServerStats(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("getting server stats: %w", err)
+		}
+		for _, s := range stats {
+			env := c.serverEnv(need.window, s.ServerName)
+			env["p50_ms"] = s.P50LatencyMs
+			env["p90_ms"] = s.P90LatencyMs
+			env["p99_ms"] = s.P99LatencyMs
+		}
+
+	case sourceSessions:
+		sessions, err := c.store.GetSessions(ctx, storage.SessionFilter{TimeFilter: filter})
+		if err != nil {
+			return fmt.Errorf("getting sessions: %w", err)
+		}
+		var cost float64
+		var tokens int64
+		for _, s := range sessions {
+			cost += s.TotalCostUSD
+			tokens += s.TotalTokens
+		}
+		env := c.globalEnv(need.window)
+		env["sessions"] = float64(len(sessions))
+		env["session_cost"] = cost
+		env["session_tokens"] = float64(tokens)
+
+	case sourceCost:
+		summary, err := c.store.GetCostSummary(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("getting spend: %w", err)
+		}
+		c.globalEnv(need.window)["cost"] = summary.TotalCostUSD
+	}
+	return nil
+}
+
+// evaluateRules steps each rule to now, loading and saving rule state. A
+// rule that fails to evaluate is skipped, its alerts and state left as they
+// were, and its error returned in failed rather than failing the run.
+func (e *Engine) evaluateRules(ctx context.Context, now time.Time, active []storage.Alert) (conditions []Condition, failed []error, err error) {
+	if len(e.config.Rules) == 0 {
+		return nil, nil, nil
+	}
+
+	saved, err := e.store.GetAlertRuleStates(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting alert rule states: %w", err)
+	}
+	before := make(map[string]storage.AlertRuleState, len(saved))
+	states := make(map[string]*storage.AlertRuleState, len(saved))
+	for i := range saved {
+		before[saved[i].Key] = saved[i]
+		states[saved[i].Key] = &saved[i]
+	}
+
+	cache := e.newMetricCache(now)
+	for _, r := range e.config.Rules {
+		ruleActive := make(map[string]storage.Alert)
+		for _, a := range active {
+			if a.Rule == r.Name {
+				ruleActive[a.Key] = a
+			}
+		}
+
+		held, err := e.stepRule(ctx, cache, r, now, ruleActive, states)
+		if err != nil {
+			failed = append(failed, err)
+			// Keep the rule's alerts active as last reported
+			for _, a := range ruleActive {
+				conditions = append(conditions, Condition{
+					Key: a.Key, Kind: a.Kind, Subject: a.Subject, Rule: a.Rule,
+					Severity: a.Severity, Message: a.Message, Value: a.Value, Threshold: a.Threshold,
+				})
+			}
+			continue
+		}
+		conditions = append(conditions, held...)
+	}
+
+	// Save what changed; states of removed rules are dropped
+	rules := make(map[string]bool, len(e.config.Rules))
+	for _, r := range e.config.Rules {
+		rules[r.Name] = true
+	}
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		st := states[key]
+		if !rules[st.Rule] {
+			st.HoldingSince, st.ClearingSince = time.Time{}, time.Time{}
+		}
+		if prev, ok := before[key]; ok && prev == *st {
+			continue
+		}
+		if _, ok := before[key]; !ok && st.HoldingSince.IsZero() && st.ClearingSince.IsZero() {
+			continue
+		}
+		if err := e.store.SaveAlertRuleState(ctx, st); err != nil {
+			return nil, nil, err
+		}
+	}
+	return conditions, failed, nil
+}
+
+// stepRule evaluates one rule, restoring its states if it fails.
+func (e *Engine) stepRule(ctx context.Context, cache *metricCache, r *Rule, now time.Time, active map[string]storage.Alert, states map[string]*storage.AlertRuleState) ([]Condition, error) {
+	envs, err := cache.envs(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+	}
+
+	backup := make(map[string]storage.AlertRuleState)
+	for key, st := range states {
+		if st.Rule == r.Name {
+			backup[key] = *st
+		}
+	}
+	held, err := r.step(now, envs, active, states)
+	if err != nil {
+		for key, st := range states {
+			if st.Rule != r.Name {
+				continue
+			}
+			if prev, ok := backup[key]; ok {
+				*st = prev
+			} else {
+				delete(states, key)
+			}
+		}
+		return nil, err
+	}
+	return held, nil
+}
+
+// Transition is a change in a rule's state found by TestRule.
+type Transition struct {
+	At      time.Time
+	Subject string
+	Status  Status // StatusPending, StatusFiring or StatusResolved
+	Message string
+}
+
+// StatusPending reports a rule holding, but not yet for its For duration.
+const StatusPending Status = "pending"
+
+// TestRule replays a rule over past data, evaluating it every step from
+// from to to as Run would, and returns the changes in its state. Nothing
+// is saved or notified.
+func (e *Engine) TestRule(ctx context.Context, r *Rule, from, to time.Time, step time.Duration) ([]Transition, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	var transitions []Transition
+	active := make(map[string]storage.Alert)
+	states := make(map[string]*storage.AlertRuleState)
+	for at := from; !at.After(to); at = at.Add(step) {
+		envs, err := e.newMetricCache(at).envs(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		pending := make(map[string]bool)
+		for key, st := range states {
+			pending[key] = !st.HoldingSince.IsZero()
+		}
+
+		conditions, err := r.step(at, envs, active, states)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make(map[string]storage.Alert, len(conditions))
+		for _, c := range conditions {
+			next[c.Key] = storage.Alert{Key: c.Key, Subject: c.Subject, Rule: r.Name, Message: c.Message, Value: c.Value}
+			if _, ok := active[c.Key]; !ok {
+				transitions = append(transitions, Transition{At: at, Subject: c.Subject, Status: StatusFiring, Message: c.Message})
+			}
+		}
+		for key, alert := range active {
+			if _, ok := next[key]; !ok {
+				transitions = append(transitions, Transition{At: at, Subject: alert.Subject, Status: StatusResolved, Message: alert.Message})
+			}
+		}
+		for subject, env := range envs {
+			key := r.key(subject)
+			if st := states[key]; st != nil && !st.HoldingSince.IsZero() && !pending[key] {
+				transitions = append(transitions, Transition{At: at, Subject: subject, Status: StatusPending, Message: r.condition(subject, env).Message})
+			}
+		}
+		active = next
+	}
+
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if !transitions[i].At.Equal(transitions[j].At) {
+			return transitions[i].At.Before(transitions[j].At)
+		}
+		return transitions[i].Subject < transitions[j].Subject
+	})
+	return transitions, nil
+}
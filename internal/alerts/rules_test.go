@@ -0,0 +1,256 @@
+package alerts
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// storeCalls records calls to server at, failing the first failures.
+func storeCalls(t *testing.T, store *storage.MockStore, server string, at time.Time, calls, failures int) {
+	t.Helper()
+	for i := 0; i < calls; i++ {
+		err := store.StoreEvent(context.Background(), &storage.Event{
+			SessionID:  "s1",
+			EventType:  "PostToolUse",
+			ToolName:   "mcp__" + server + "__call",
+			MCPServer:  server,
+			Success:    i >= failures,
+			DurationMs: 100,
+			CreatedAt:  at,
+		})
+		if err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+}
+
+func mustRule(t *testing.T, config RuleConfig) *Rule {
+	t.Helper()
+	r, err := NewRule(config)
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	return r
+}
+
+func TestNewRule(t *testing.T) {
+	r := mustRule(t, RuleConfig{Name: "spend", Expr: "cost_1d > 20"})
+	if r.perServer || r.Severity != Warning {
+		t.Errorf("expected a global warning rule, got %+v", r)
+	}
+	if r := mustRule(t, RuleConfig{Name: "github", Expr: `server == "github"`}); !r.perServer {
+		t.Errorf("expected a rule using server to be per server")
+	}
+
+	invalid := map[string]RuleConfig{
+		"unknown variable":   {Name: "a", Expr: "latency_1h > 5"},
+		"needs a window":     {Name: "a", Expr: "calls > 5"},
+		`invalid window "x"`: {Name: "a", Expr: "calls_x > 5"},
+		"empty expression":   {Name: "a", Expr: " "},
+		"severity must be":   {Name: "a", Expr: "calls_1h > 5", Severity: "page"},
+		"resolve: unknown":   {Name: "a", Expr: "calls_1h > 5", Resolve: "foo < 1"},
+		"has no name":        {Expr: "calls_1h > 5"},
+		// Type errors are found when the rule compiles, even in branches
+		// evaluation would skip
+		"expression is number, not boolean": {Name: "a", Expr: "calls_1h + 1"},
+		"cannot compare number == string":   {Name: "a", Expr: `calls_1h == "x"`},
+		"> needs numbers, got string":       {Name: "a", Expr: "top_error_1h > 3"},
+		"expected boolean, got number":      {Name: "a", Expr: `server == "x" && calls_1h`},
+		"resolve: =~ needs a string":        {Name: "a", Expr: "calls_1h > 5", Resolve: `calls_1h =~ "1"`},
+	}
+	for want, config := range invalid {
+		if _, err := NewRule(config); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("NewRule(%+v) error = %v, want %q", config, err, want)
+		}
+	}
+}
+
+func TestRun_RuleForAndHysteresis(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+
+	// github fails 3 of 10 calls in the hour before start; slack is fine
+	storeCalls(t, store, "github", start.Add(-30*time.Minute), 10, 3)
+	storeCalls(t, store, "slack", start.Add(-30*time.Minute), 10, 0)
+
+	rule := mustRule(t, RuleConfig{
+		Name:     "errors",
+		Expr:     "error_rate_1h > 10 && calls_1h >= 5",
+		Resolve:  "error_rate_1h < 5",
+		For:      10 * time.Minute,
+		ClearFor: 10 * time.Minute,
+	})
+	rec := &recorder{}
+	engine := NewEngine(store, Config{Rules: []*Rule{rule}}, rec)
+
+	run := func(at time.Time) *Result {
+		t.Helper()
+		result, err := engine.Run(ctx, at)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result
+	}
+
+	// Holding, but not yet for 10 minutes
+	if result := run(start); len(result.Active) != 0 {
+		t.Fatalf("expected the rule to be pending, got %+v", result.Active)
+	}
+	states, _ := store.GetAlertRuleStates(ctx)
+	if len(states) != 1 || states[0].Key != "rule:errors:github" || !states[0].HoldingSince.Equal(start) {
+		t.Fatalf("expected github pending since start, got %+v", states)
+	}
+
+	result := run(start.Add(10 * time.Minute))
+	if len(result.Active) != 1 || result.Active[0].Subject != "github" || result.Active[0].Rule != "errors" {
+		t.Fatalf("expected github to fire, got %+v", result.Active)
+	}
+	if msg := result.Active[0].Message; msg != "errors (github): error_rate_1h=30, calls_1h=10" {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	// 3 of 70 failing is about 4%: resolving, but only once it has been for 10 minutes
+	storeCalls(t, store, "github", start.Add(15*time.Minute), 60, 0)
+	if result := run(start.Add(20 * time.Minute)); len(result.Active) != 1 {
+		t.Fatalf("expected github to stay active while clearing, got %+v", result.Active)
+	}
+	if result := run(start.Add(30 * time.Minute)); len(result.Active) != 0 {
+		t.Fatalf("expected github to resolve, got %+v", result.Active)
+	}
+	if got := rec.statuses(); len(got) != 2 || got[0] != StatusFiring || got[1] != StatusResolved {
+		t.Errorf("expected firing then resolved, got %v", got)
+	}
+	if states, _ := store.GetAlertRuleStates(ctx); len(states) != 0 {
+		t.Errorf("expected no rule state left, got %+v", states)
+	}
+}
+
+func TestRun_RuleResolveHysteresis(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	storeCalls(t, store, "github", start.Add(-10*time.Minute), 10, 3)
+
+	// Fires over 20%, resolves under 5%
+	rule := mustRule(t, RuleConfig{Name: "errors", Expr: "error_rate_1h > 20", Resolve: "error_rate_1h < 5"})
+	engine := NewEngine(store, Config{Rules: []*Rule{rule}})
+	if result, err := engine.Run(ctx, start); err != nil || len(result.Active) != 1 {
+		t.Fatalf("expected github to fire, got %+v, %v", result, err)
+	}
+
+	// 3 of 30 is 10%: under the firing threshold, over the resolving one
+	storeCalls(t, store, "github", start, 20, 0)
+	result, err := engine.Run(ctx, start.Add(time.Minute))
+	if err != nil || len(result.Active) != 1 {
+		t.Fatalf("expected github to stay active, got %+v, %v", result, err)
+	}
+	if result.Active[0].Value != 10 {
+		t.Errorf("expected the current error rate, got %+v", result.Active[0])
+	}
+}
+
+func TestRun_RuleChannels(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	storeSpend(t, store, now.Add(-time.Hour), 25)
+
+	spend := mustRule(t, RuleConfig{Name: "spend", Expr: "cost_1d > 20", Severity: Critical, Channels: []string{"oncall", "bell"}})
+	defaults, oncall := &recorder{}, &recorder{}
+	engine := NewEngine(store, Config{Rules: []*Rule{spend}}, defaults)
+	engine.AddChannel(Named("oncall", oncall))
+
+	result, err := engine.Run(ctx, now)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Active) != 1 || result.Active[0].Key != "rule:spend" || result.Active[0].Severity != Critical {
+		t.Fatalf("expected a critical global spend alert, got %+v", result.Active)
+	}
+	if len(oncall.sent) != 1 || len(defaults.sent) != 0 {
+		t.Errorf("expected only the oncall channel notified, got %d and %d", len(oncall.sent), len(defaults.sent))
+	}
+	// The missing bell channel is skipped, not a failure
+	if len(result.Errors) != 0 || result.Active[0].Notifications != 1 {
+		t.Errorf("expected the alert notified without errors, got %+v", result)
+	}
+}
+
+func TestTestRule(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+
+	// github fails for a burst from 10:00 to 10:30
+	storeCalls(t, store, "github", start.Add(time.Hour), 10, 8)
+	storeCalls(t, store, "github", start.Add(90*time.Minute), 10, 8)
+	storeCalls(t, store, "slack", start.Add(time.Hour), 10, 0)
+
+	rule := mustRule(t, RuleConfig{Name: "errors", Expr: "error_rate_1h > 50", For: 30 * time.Minute})
+	engine := NewEngine(store, Config{})
+	transitions, err := engine.TestRule(ctx, rule, start, start.Add(4*time.Hour), 30*time.Minute)
+	if err != nil {
+		t.Fatalf("TestRule failed: %v", err)
+	}
+
+	want := []struct {
+		at     time.Duration
+		status Status
+	}{
+		{time.Hour, StatusPending},
+		{90 * time.Minute, StatusFiring},
+		{3 * time.Hour, StatusResolved},
+	}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected %d transitions, got %+v", len(want), transitions)
+	}
+	for i, w := range want {
+		got := transitions[i]
+		if !got.At.Equal(start.Add(w.at)) || got.Status != w.status || got.Subject != "github" {
+			t.Errorf("transition %d: expected %s at +%s, got %+v", i, w.status, w.at, got)
+		}
+	}
+
+	// Nothing is saved
+	if states, _ := store.GetAlertRuleStates(ctx); len(states) != 0 {
+		t.Errorf("expected no saved state, got %+v", states)
+	}
+}
+
+func TestRun_FailingRuleIsSkipped(t *testing.T) {
+	store := storage.NewMockStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	storeCalls(t, store, "github", now.Add(-10*time.Minute), 10, 3)
+
+	broken := mustRule(t, RuleConfig{Name: "broken", Expr: "calls_1h > 5"})
+	working := mustRule(t, RuleConfig{Name: "errors", Expr: "error_rate_1h > 10"})
+	engine := NewEngine(store, Config{Rules: []*Rule{broken, working}})
+	if result, err := engine.Run(ctx, now); err != nil || len(result.Active) != 2 {
+		t.Fatalf("expected both rules to fire, got %+v, %v", result, err)
+	}
+
+	// Break the rule past the compile-time checks, as a bad value would
+	expr, err := ParseExpr("calls_1h + 1")
+	if err != nil {
+		t.Fatalf("ParseExpr failed: %v", err)
+	}
+	broken.expr = expr
+
+	result, err := engine.Run(ctx, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected the run to succeed, got %v", err)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "rule broken") {
+		t.Errorf("expected the broken rule reported, got %v", result.Errors)
+	}
+	// The broken rule's alert is neither resolved nor renotified
+	if len(result.Active) != 2 || len(result.Notifications) != 0 {
+		t.Errorf("expected both alerts left active, got %+v", result)
+	}
+}
@@ -0,0 +1,287 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// AnomalyMetric is the measurement an anomaly was found in.
+type AnomalyMetric string
+
+const (
+	MetricLatency   AnomalyMetric = "latency"    // Average latency in ms
+	MetricErrorRate AnomalyMetric = "error_rate" // Percentage of calls that failed
+)
+
+// Anomaly is an hour in which a server or tool was much slower, or failed
+// much more often, than is normal for it at that hour of day.
+type Anomaly struct {
+	MCPServer string
+	ToolName  string // Empty for the server as a whole
+	Metric    AnomalyMetric
+	Hour      time.Time // Start of the hour, local time
+	Calls     int64
+	Value     float64 // Observed value
+	Expected  float64 // Baseline value
+	ZScore    float64 // Standard deviations above the baseline
+	Seasonal  bool    // Baseline is for this hour of day rather than all hours
+}
+
+// Subject returns the server, or server and tool, the anomaly is about.
+func (a Anomaly) Subject() string {
+	if a.ToolName == "" {
+		return a.MCPServer
+	}
+	return a.MCPServer + " " + a.ToolName
+}
+
+// Describe summarizes the anomaly, such as
+// "latency 1840ms, normally 220ms (4.2σ)".
+func (a Anomaly) Describe() string {
+	switch a.Metric {
+	case MetricLatency:
+		return fmt.Sprintf("latency %.0fms, normally %.0fms (%.1fσ)", a.Value, a.Expected, a.ZScore)
+	default:
+		return fmt.Sprintf("errors %.1f%%, normally %.1f%% (%.1fσ)", a.Value, a.Expected, a.ZScore)
+	}
+}
+
+// AnomalyConfig configures anomaly detection.
+type AnomalyConfig struct {
+	BaselineDays int           // Days of hourly history baselines learn from (default: 14)
+	Alpha        float64       // EWMA smoothing factor; higher adapts faster (default: 0.2)
+	ZThreshold   float64       // Standard deviations above the baseline flagged (default: 3)
+	MinCalls     int64         // Calls an hour needs to be learned from or judged (default: 5)
+	MinSamples   int           // Hours a baseline needs before it is trusted (default: 3)
+	Window       time.Duration // Range checked when the filter has no start (default: 1h)
+}
+
+// DefaultAnomalyConfig returns default configuration.
+func DefaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{
+		BaselineDays: 14,
+		Alpha:        0.2,
+		ZThreshold:   3.0,
+		MinCalls:     5,
+		MinSamples:   3,
+		Window:       time.Hour,
+	}
+}
+
+// Minimum standard deviations, so a perfectly steady baseline does not
+// turn a tiny wobble into a large z-score. Each is also at least 10% of
+// the baseline value.
+const (
+	minLatencyStdMs = 5.0
+	minErrorRateStd = 2.0
+	minStdFraction  = 0.1
+)
+
+// AnomalyStore defines the storage interface needed for anomaly detection.
+type AnomalyStore interface {
+	GetHourlyRollups(ctx context.Context, filter storage.TimeFilter) ([]storage.HourlyRollup, error)
+}
+
+// AnomalyDetector flags hours in which servers or tools depart from their
+// own normal latency and error rate. Baselines are exponentially weighted
+// moving averages over hourly rollups, kept per hour of day so a server
+// that is always slow at night is not flagged every night.
+type AnomalyDetector struct {
+	store  AnomalyStore
+	config AnomalyConfig
+}
+
+// NewAnomalyDetector creates a new anomaly detector.
+func NewAnomalyDetector(store AnomalyStore, config AnomalyConfig) *AnomalyDetector {
+	return &AnomalyDetector{
+		store:  store,
+		config: config,
+	}
+}
+
+// ewma is an exponentially weighted moving mean and variance.
+type ewma struct {
+	mean     float64
+	variance float64
+	samples  int
+}
+
+func (e *ewma) add(x, alpha float64) {
+	if e.samples == 0 {
+		e.mean = x
+	} else {
+		diff := x - e.mean
+		incr := alpha * diff
+		e.mean += incr
+		e.variance = (1 - alpha) * (e.variance + diff*incr)
+	}
+	e.samples++
+}
+
+// baseline holds the overall and hour-of-day EWMAs of one metric.
+type baseline struct {
+	overall ewma
+	hourly  [24]ewma // By hour of day
+}
+
+// seriesKey identifies a server, or a tool of a server.
+type seriesKey struct {
+	server string
+	tool   string
+}
+
+// hourStats are the calls in one hour of a series.
+type hourStats struct {
+	hour      time.Time
+	calls     int64
+	errors    int64
+	latencyMs int64
+}
+
+// Detect returns the anomalies in the filter range, largest first. Each hour
+// in the range is judged against baselines learned from the BaselineDays
+// before it. A range without a start covers the last Window.
+func (d *AnomalyDetector) Detect(ctx context.Context, filter storage.TimeFilter) ([]Anomaly, error) {
+	to := filter.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := filter.From
+	if from.IsZero() {
+		from = to.Add(-d.window())
+	}
+	windowStart := from.Truncate(time.Hour)
+
+	rollups, err := d.store.GetHourlyRollups(ctx, storage.TimeFilter{
+		From: windowStart.AddDate(0, 0, -max(d.config.BaselineDays, 1)),
+		To:   to,
+		Host: filter.Host,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var anomalies []Anomaly
+	for key, hours := range groupSeries(rollups) {
+		latency, errorRate := &baseline{}, &baseline{}
+		for _, h := range hours {
+			if h.calls < d.config.MinCalls {
+				continue
+			}
+			avgLatency := float64(h.latencyMs) / float64(h.calls)
+			rate := float64(h.errors) / float64(h.calls) * 100
+
+			if h.hour.Before(windowStart) {
+				d.learn(latency, h.hour, avgLatency)
+				d.learn(errorRate, h.hour, rate)
+				continue
+			}
+
+			if a, ok := d.judge(latency, h, MetricLatency, avgLatency); ok {
+				a.MCPServer, a.ToolName = key.server, key.tool
+				anomalies = append(anomalies, a)
+			}
+			if a, ok := d.judge(errorRate, h, MetricErrorRate, rate); ok {
+				a.MCPServer, a.ToolName = key.server, key.tool
+				anomalies = append(anomalies, a)
+			}
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].ZScore != anomalies[j].ZScore {
+			return anomalies[i].ZScore > anomalies[j].ZScore
+		}
+		return anomalies[i].Subject() < anomalies[j].Subject()
+	})
+	return anomalies, nil
+}
+
+// learn adds an hour's value to a baseline.
+func (d *AnomalyDetector) learn(b *baseline, hour time.Time, x float64) {
+	b.overall.add(x, d.config.Alpha)
+	b.hourly[hour.Hour()].add(x, d.config.Alpha)
+}
+
+// judge compares an hour's value with its baseline, preferring the baseline
+// for the same hour of day once it has enough samples.
+func (d *AnomalyDetector) judge(b *baseline, h hourStats, metric AnomalyMetric, x float64) (Anomaly, bool) {
+	ref, seasonal := &b.hourly[h.hour.Hour()], true
+	if ref.samples < d.config.MinSamples {
+		ref, seasonal = &b.overall, false
+	}
+	if ref.samples < d.config.MinSamples {
+		return Anomaly{}, false
+	}
+
+	floor := minLatencyStdMs
+	if metric == MetricErrorRate {
+		floor = minErrorRateStd
+	}
+	std := math.Max(math.Sqrt(ref.variance), math.Max(floor, ref.mean*minStdFraction))
+
+	z := (x - ref.mean) / std
+	if z < d.config.ZThreshold {
+		return Anomaly{}, false
+	}
+	return Anomaly{
+		Metric:   metric,
+		Hour:     h.hour,
+		Calls:    h.calls,
+		Value:    x,
+		Expected: ref.mean,
+		ZScore:   z,
+		Seasonal: seasonal,
+	}, true
+}
+
+// window returns the range checked when the filter has no start.
+func (d *AnomalyDetector) window() time.Duration {
+	if d.config.Window > 0 {
+		return d.config.Window
+	}
+	return time.Hour
+}
+
+// groupSeries splits hourly rollups of MCP tools into per-tool series and
+// per-server series summed over tools, each sorted by hour.
+func groupSeries(rollups []storage.HourlyRollup) map[seriesKey][]hourStats {
+	index := make(map[seriesKey]map[time.Time]int)
+	series := make(map[seriesKey][]hourStats)
+
+	add := func(key seriesKey, r storage.HourlyRollup) {
+		if index[key] == nil {
+			index[key] = make(map[time.Time]int)
+		}
+		i, ok := index[key][r.Hour]
+		if !ok {
+			i = len(series[key])
+			index[key][r.Hour] = i
+			series[key] = append(series[key], hourStats{hour: r.Hour})
+		}
+		h := &series[key][i]
+		h.calls += r.Calls
+		h.errors += r.Errors
+		h.latencyMs += r.TotalLatencyMs
+	}
+
+	for _, r := range rollups {
+		if r.MCPServer == "" {
+			continue
+		}
+		add(seriesKey{server: r.MCPServer}, r)
+		add(seriesKey{server: r.MCPServer, tool: r.ToolName}, r)
+	}
+
+	for _, hours := range series {
+		sort.Slice(hours, func(i, j int) bool {
+			return hours[i].hour.Before(hours[j].hour)
+		})
+	}
+	return series
+}
@@ -0,0 +1,139 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// mockAnomalyStore implements AnomalyStore for testing.
+type mockAnomalyStore struct {
+	rollups []storage.HourlyRollup
+	filter  storage.TimeFilter // Last filter queried
+}
+
+func (m *mockAnomalyStore) GetHourlyRollups(ctx context.Context, filter storage.TimeFilter) ([]storage.HourlyRollup, error) {
+	m.filter = filter
+	var result []storage.HourlyRollup
+	for _, r := range m.rollups {
+		if !r.Hour.Before(filter.From) && !r.Hour.After(filter.To) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// steadyHistory returns a week of hourly rollups for one tool with the given
+// average latency, and night hours (0-5) at nightLatency.
+func steadyHistory(end time.Time, tool, server string, latency, nightLatency int64) []storage.HourlyRollup {
+	var rollups []storage.HourlyRollup
+	for h := end.Add(-7 * 24 * time.Hour); h.Before(end); h = h.Add(time.Hour) {
+		avg := latency + int64(h.Hour()%3) // A little noise
+		if h.Hour() < 6 {
+			avg = nightLatency
+		}
+		rollups = append(rollups, storage.HourlyRollup{
+			Hour: h, ToolName: tool, MCPServer: server, Calls: 10, TotalLatencyMs: 10 * avg,
+		})
+	}
+	return rollups
+}
+
+func TestAnomalyDetector_Detect(t *testing.T) {
+	// A fixed afternoon hour, so the window is not at night
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	current := now.Truncate(time.Hour)
+
+	rollups := steadyHistory(current, "mcp__github__search", "github", 200, 900)
+	rollups = append(rollups, steadyHistory(current, "mcp__fs__read", "fs", 20, 20)...)
+	rollups = append(rollups, steadyHistory(current, "Read", "", 5, 5)...)
+	rollups = append(rollups,
+		// github is suddenly slow
+		storage.HourlyRollup{Hour: current, ToolName: "mcp__github__search", MCPServer: "github", Calls: 10, TotalLatencyMs: 10 * 1500},
+		// fs starts failing at its usual speed
+		storage.HourlyRollup{Hour: current, ToolName: "mcp__fs__read", MCPServer: "fs", Calls: 10, Errors: 6, TotalLatencyMs: 10 * 21},
+		// Built-in tools are not MCP servers
+		storage.HourlyRollup{Hour: current, ToolName: "Read", Calls: 10, TotalLatencyMs: 10 * 500},
+	)
+
+	store := &mockAnomalyStore{rollups: rollups}
+	detector := NewAnomalyDetector(store, DefaultAnomalyConfig())
+
+	anomalies, err := detector.Detect(context.Background(), storage.TimeFilter{To: now, Host: "laptop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.filter.Host != "laptop" || !store.filter.From.Equal(current.Add(-time.Hour).AddDate(0, 0, -14)) {
+		t.Errorf("queried %+v, want 14 days before the last hour on the same host", store.filter)
+	}
+
+	found := make(map[string]Anomaly)
+	for _, a := range anomalies {
+		found[a.Subject()+" "+string(a.Metric)] = a
+	}
+	if len(found) != 4 {
+		t.Fatalf("expected 4 anomalies, got %+v", anomalies)
+	}
+
+	slow, ok := found["github latency"]
+	if !ok || slow.Value != 1500 || !slow.Seasonal || slow.ZScore < 3 {
+		t.Errorf("github latency anomaly = %+v, want 1500ms against the hour-of-day baseline", slow)
+	}
+	if _, ok := found["github mcp__github__search latency"]; !ok {
+		t.Error("expected a tool-level latency anomaly for mcp__github__search")
+	}
+	failing, ok := found["fs error_rate"]
+	if !ok || failing.Value != 60 || failing.Expected != 0 {
+		t.Errorf("fs error anomaly = %+v, want 60%% errors against 0%%", failing)
+	}
+	if got := failing.Describe(); got != "errors 60.0%, normally 0.0% (30.0σ)" {
+		t.Errorf("Describe() = %q", got)
+	}
+	if anomalies[0].ZScore < anomalies[len(anomalies)-1].ZScore {
+		t.Error("expected anomalies sorted by z-score, largest first")
+	}
+}
+
+func TestAnomalyDetector_Seasonal(t *testing.T) {
+	// github is always slow at night, so 900ms at 3am is normal
+	now := time.Date(2026, 3, 10, 3, 30, 0, 0, time.Local)
+	current := now.Truncate(time.Hour)
+
+	rollups := steadyHistory(current, "mcp__github__search", "github", 200, 900)
+	rollups = append(rollups, storage.HourlyRollup{
+		Hour: current, ToolName: "mcp__github__search", MCPServer: "github", Calls: 10, TotalLatencyMs: 10 * 900,
+	})
+
+	detector := NewAnomalyDetector(&mockAnomalyStore{rollups: rollups}, DefaultAnomalyConfig())
+	anomalies, err := detector.Detect(context.Background(), storage.TimeFilter{From: current, To: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no anomalies for a normally slow hour, got %+v", anomalies)
+	}
+}
+
+func TestAnomalyDetector_NeedsHistory(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	current := now.Truncate(time.Hour)
+
+	rollups := []storage.HourlyRollup{
+		{Hour: current.Add(-2 * time.Hour), ToolName: "search", MCPServer: "new", Calls: 10, TotalLatencyMs: 1000},
+		{Hour: current.Add(-time.Hour), ToolName: "search", MCPServer: "new", Calls: 10, TotalLatencyMs: 1000},
+		{Hour: current, ToolName: "search", MCPServer: "new", Calls: 10, TotalLatencyMs: 90000},
+		// Too few calls to judge
+		{Hour: current, ToolName: "search", MCPServer: "rare", Calls: 1, TotalLatencyMs: 90000},
+	}
+
+	detector := NewAnomalyDetector(&mockAnomalyStore{rollups: rollups}, DefaultAnomalyConfig())
+	anomalies, err := detector.Detect(context.Background(), storage.TimeFilter{From: current, To: now})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("expected no anomalies without enough history, got %+v", anomalies)
+	}
+}
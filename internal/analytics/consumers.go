@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// LargeResponseTokens is the response size above which Claude Code warns that
+// MCP tool output is filling the context window.
+const LargeResponseTokens = 10000
+
+// ContextConsumer is one tool's share of the context taken up by tool
+// responses over a time range. Token counts are estimates.
+type ContextConsumer struct {
+	ToolName       string
+	ServerName     string // Empty for built-in tools
+	Responses      int64  // Responses with a recorded size
+	TotalBytes     int64
+	TotalTokens    int64
+	AvgTokens      float64
+	P50Tokens      float64
+	P90Tokens      float64
+	P99Tokens      float64
+	LargeResponses int64   // Responses over LargeResponseTokens
+	SharePct       float64 // Percentage of all response tokens in the range
+}
+
+// ContextConsumerStore defines the storage interface needed for context
+// consumer analysis.
+type ContextConsumerStore interface {
+	GetToolResponseSizes(ctx context.Context, filter storage.TimeFilter) ([]storage.ToolResponseSize, error)
+}
+
+// ContextConsumerAnalyzer ranks tools by how much context their responses
+// take up.
+type ContextConsumerAnalyzer struct {
+	store ContextConsumerStore
+}
+
+// NewContextConsumerAnalyzer creates a new context consumer analyzer.
+func NewContextConsumerAnalyzer(store ContextConsumerStore) *ContextConsumerAnalyzer {
+	return &ContextConsumerAnalyzer{store: store}
+}
+
+// Analyze returns the tools whose responses took up the most context in the
+// range, largest total first. A limit of 0 returns every tool; shares are
+// always of the total across all tools.
+func (a *ContextConsumerAnalyzer) Analyze(ctx context.Context, filter storage.TimeFilter, limit int) ([]ContextConsumer, error) {
+	sizes, err := a.store.GetToolResponseSizes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, rs := range sizes {
+		total += rs.TotalTokens
+	}
+
+	if limit > 0 && len(sizes) > limit {
+		sizes = sizes[:limit]
+	}
+
+	result := make([]ContextConsumer, 0, len(sizes))
+	for _, rs := range sizes {
+		c := ContextConsumer{
+			ToolName:       rs.ToolName,
+			ServerName:     rs.ServerName,
+			Responses:      rs.Responses,
+			TotalBytes:     rs.TotalBytes,
+			TotalTokens:    rs.TotalTokens,
+			AvgTokens:      rs.AvgTokens(),
+			P50Tokens:      rs.Histogram.Percentile(0.50),
+			P90Tokens:      rs.Histogram.Percentile(0.90),
+			P99Tokens:      rs.Histogram.Percentile(0.99),
+			LargeResponses: rs.Histogram.CountAbove(LargeResponseTokens),
+		}
+		if total > 0 {
+			c.SharePct = float64(rs.TotalTokens) / float64(total) * 100
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func addResponses(t *testing.T, store *storage.MockStore, at time.Time, server, tool string, n int, tokens int64) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := store.StoreEvent(context.Background(), &storage.Event{
+			SessionID:      "s1",
+			EventType:      "PostToolUse",
+			ToolName:       tool,
+			MCPServer:      server,
+			Success:        true,
+			ResponseBytes:  tokens * 4,
+			ResponseTokens: tokens,
+			CreatedAt:      at,
+		})
+		if err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+}
+
+func TestContextConsumerAnalyzer_Analyze(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+	ctx := context.Background()
+
+	// fetch returns a few huge pages; search many small results; Read is a
+	// built-in tool
+	addResponses(t, store, now.Add(-time.Hour), "web", "mcp__web__fetch", 4, 30000)
+	addResponses(t, store, now.Add(-time.Hour), "github", "mcp__github__search", 90, 200)
+	addResponses(t, store, now.Add(-time.Hour), "github", "mcp__github__search", 10, 2000)
+	addResponses(t, store, now.Add(-time.Hour), "", "Read", 20, 100)
+	// Outside the range
+	addResponses(t, store, now.Add(-48*time.Hour), "", "Read", 1000, 1000)
+
+	analyzer := NewContextConsumerAnalyzer(store)
+	result, err := analyzer.Analyze(ctx, storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now}, 0)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	// 120000 + 38000 + 2000 tokens
+	want := []struct {
+		tool        string
+		responses   int64
+		totalTokens int64
+		large       int64
+		sharePct    float64
+	}{
+		{"mcp__web__fetch", 4, 120000, 4, 75},
+		{"mcp__github__search", 100, 38000, 0, 23.75},
+		{"Read", 20, 2000, 0, 1.25},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("expected %d tools, got %d", len(want), len(result))
+	}
+	for i, w := range want {
+		got := result[i]
+		if got.ToolName != w.tool || got.Responses != w.responses || got.TotalTokens != w.totalTokens ||
+			got.LargeResponses != w.large || math.Abs(got.SharePct-w.sharePct) > 0.01 {
+			t.Errorf("result[%d] = %s %d responses %d tokens %d large %.2f%%, want %s %d responses %d tokens %d large %.2f%%",
+				i, got.ToolName, got.Responses, got.TotalTokens, got.LargeResponses, got.SharePct,
+				w.tool, w.responses, w.totalTokens, w.large, w.sharePct)
+		}
+	}
+
+	search := result[1]
+	if search.AvgTokens != 380 || math.Abs(search.P50Tokens-200)/200 > 0.06 || math.Abs(search.P99Tokens-2000)/2000 > 0.06 {
+		t.Errorf("expected search avg 380, p50 ~200, p99 ~2000, got %.0f, %.0f, %.0f", search.AvgTokens, search.P50Tokens, search.P99Tokens)
+	}
+
+	limited, err := analyzer.Analyze(ctx, storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now}, 1)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(limited) != 1 || limited[0].SharePct != 75 {
+		t.Errorf("expected the top tool with its share of the whole range, got %+v", limited)
+	}
+}
@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// ContextCost is what keeping a server installed costs in context: its tool
+// definitions are loaded into every session, whether or not they are called.
+type ContextCost struct {
+	ServerName         string
+	Tools              int        // Tools in the server's latest tools/list snapshot
+	Tokens             int        // Estimated tokens of its tool definitions, per session
+	Calls              int64      // Calls in the range
+	Sessions           int        // Sessions in the range, each of which loaded the definitions
+	SessionTokens      int64      // Tokens across all sessions in the range
+	TokensPerCall      float64    // SessionTokens per call; 0 when never called
+	UsedTools          int        // Tools called in the range
+	ToolUtilizationPct float64    // Percentage of the server's tools called in the range
+	UnusedTokens       int        // Tokens per session of tools not called in the range
+	ToolCosts          []ToolCost // Each listed tool, unused first, then by tokens
+	CapturedAt         time.Time  // When the server's tools were last listed
+}
+
+// Unused reports whether the server was not called at all in the range.
+func (c ContextCost) Unused() bool {
+	return c.Calls == 0
+}
+
+// UnusedTools returns the tools not called in the range.
+func (c ContextCost) UnusedTools() []ToolCost {
+	var unused []ToolCost
+	for _, t := range c.ToolCosts {
+		if t.Unused() {
+			unused = append(unused, t)
+		}
+	}
+	return unused
+}
+
+// ToolCost is one listed tool's share of its server's context cost.
+type ToolCost struct {
+	ToolName   string // Name as the server lists it
+	Tokens     int    // Estimated tokens of its definition, per session
+	Calls      int64  // Calls in the range
+	TotalCalls int64  // Calls ever recorded
+}
+
+// Unused reports whether the tool was not called in the range.
+func (t ToolCost) Unused() bool {
+	return t.Calls == 0
+}
+
+// NeverCalled reports whether the tool has never been called at all.
+func (t ToolCost) NeverCalled() bool {
+	return t.TotalCalls == 0
+}
+
+// ContextCostStore defines the storage interface needed for context cost
+// analysis.
+type ContextCostStore interface {
+	GetToolDefinitions(ctx context.Context, host string) ([]storage.ToolDefinition, error)
+	GetToolStats(ctx context.Context, filter storage.TimeFilter) ([]storage.ToolStats, error)
+	GetSessions(ctx context.Context, filter storage.SessionFilter) ([]storage.Session, error)
+}
+
+// ContextCostAnalyzer ranks servers by the context their tool definitions
+// take up against how often they are called.
+type ContextCostAnalyzer struct {
+	store ContextCostStore
+}
+
+// NewContextCostAnalyzer creates a new context cost analyzer.
+func NewContextCostAnalyzer(store ContextCostStore) *ContextCostAnalyzer {
+	return &ContextCostAnalyzer{store: store}
+}
+
+// Analyze returns the context cost of every server with recorded tool
+// definitions, most expensive first: servers never called in the range by
+// tokens, then the rest by tokens per call. Each server's listed tools are
+// compared with the tools called, so unused tools within a busy server show
+// up too. When definitions were captured on several hosts, the latest
+// snapshot of each tool is used.
+func (a *ContextCostAnalyzer) Analyze(ctx context.Context, filter storage.TimeFilter) ([]ContextCost, error) {
+	defs, err := a.store.GetToolDefinitions(ctx, filter.Host)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 {
+		return nil, nil
+	}
+
+	stats, err := a.store.GetToolStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	allTime, err := a.store.GetToolStats(ctx, storage.TimeFilter{Host: filter.Host})
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := a.store.GetSessions(ctx, storage.SessionFilter{TimeFilter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[[2]string]storage.ToolDefinition, len(defs))
+	for _, d := range defs {
+		key := [2]string{d.ServerName, d.ToolName}
+		if prev, ok := latest[key]; !ok || d.CapturedAt.After(prev.CapturedAt) {
+			latest[key] = d
+		}
+	}
+
+	rangeCalls := callsByTool(stats)
+	everCalls := callsByTool(allTime)
+
+	costs := make(map[string]*ContextCost)
+	for key, d := range latest {
+		c, ok := costs[d.ServerName]
+		if !ok {
+			c = &ContextCost{ServerName: d.ServerName, Sessions: len(sessions)}
+			costs[d.ServerName] = c
+		}
+		c.Tools++
+		c.Tokens += d.Tokens
+		if d.CapturedAt.After(c.CapturedAt) {
+			c.CapturedAt = d.CapturedAt
+		}
+
+		t := ToolCost{ToolName: d.ToolName, Tokens: d.Tokens, Calls: rangeCalls[key], TotalCalls: everCalls[key]}
+		if t.Unused() {
+			c.UnusedTokens += t.Tokens
+		} else {
+			c.UsedTools++
+		}
+		c.ToolCosts = append(c.ToolCosts, t)
+	}
+	for _, st := range stats {
+		if c, ok := costs[st.MCPServer]; ok {
+			c.Calls += st.TotalCalls
+		}
+	}
+
+	result := make([]ContextCost, 0, len(costs))
+	for _, c := range costs {
+		c.SessionTokens = int64(c.Tokens) * int64(c.Sessions)
+		if c.Calls > 0 {
+			c.TokensPerCall = float64(c.SessionTokens) / float64(c.Calls)
+		}
+		c.ToolUtilizationPct = float64(c.UsedTools) / float64(c.Tools) * 100
+		sortToolCosts(c.ToolCosts)
+		result = append(result, *c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Unused() != result[j].Unused() {
+			return result[i].Unused()
+		}
+		if result[i].Unused() {
+			if result[i].Tokens != result[j].Tokens {
+				return result[i].Tokens > result[j].Tokens
+			}
+		} else if result[i].TokensPerCall != result[j].TokensPerCall {
+			return result[i].TokensPerCall > result[j].TokensPerCall
+		}
+		return result[i].ServerName < result[j].ServerName
+	})
+	return result, nil
+}
+
+// callsByTool sums calls by server and tool name, with the tool name as the
+// server lists it rather than as Claude Code records it ("mcp__github__search").
+func callsByTool(stats []storage.ToolStats) map[[2]string]int64 {
+	calls := make(map[[2]string]int64, len(stats))
+	for _, st := range stats {
+		name := strings.TrimPrefix(st.ToolName, "mcp__"+st.MCPServer+"__")
+		calls[[2]string{st.MCPServer, name}] += st.TotalCalls
+	}
+	return calls
+}
+
+// sortToolCosts orders tools unused first, then by tokens, largest first.
+func sortToolCosts(tools []ToolCost) {
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Unused() != tools[j].Unused() {
+			return tools[i].Unused()
+		}
+		if tools[i].Tokens != tools[j].Tokens {
+			return tools[i].Tokens > tools[j].Tokens
+		}
+		return tools[i].ToolName < tools[j].ToolName
+	})
+}
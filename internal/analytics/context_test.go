@@ -0,0 +1,165 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func TestContextCostAnalyzer_Analyze(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+	ctx := context.Background()
+
+	defs := map[string][]storage.ToolDefinition{
+		"github": {{ToolName: "search", Tokens: 400}, {ToolName: "create_issue", Tokens: 600}},
+		"fs":     {{ToolName: "read", Tokens: 200}},
+		"slack":  {{ToolName: "post", Tokens: 1500}, {ToolName: "history", Tokens: 1500}},
+		"notion": {{ToolName: "query", Tokens: 800}},
+	}
+	for server, tools := range defs {
+		for i := range tools {
+			tools[i].CapturedAt = now.Add(-time.Hour)
+		}
+		if err := store.ReplaceToolDefinitions(ctx, server, tools); err != nil {
+			t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+		}
+	}
+
+	// Four sessions in the range, one of them making the calls
+	addCalls(t, store, now.Add(-time.Hour), "github", "mcp__github__search", 10, 0, 100)
+	addCalls(t, store, now.Add(-time.Hour), "fs", "mcp__fs__read", 100, 0, 10)
+	for i := 2; i <= 4; i++ {
+		err := store.StoreEvent(ctx, &storage.Event{SessionID: fmt.Sprintf("s%d", i), EventType: "SessionStart", CreatedAt: now.Add(-time.Hour)})
+		if err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+
+	analyzer := NewContextCostAnalyzer(store)
+	result, err := analyzer.Analyze(ctx, storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	want := []struct {
+		server        string
+		tools, tokens int
+		calls         int64
+		tokensPerCall float64
+	}{
+		{"slack", 2, 3000, 0, 0},
+		{"notion", 1, 800, 0, 0},
+		{"github", 2, 1000, 10, 400},
+		{"fs", 1, 200, 100, 8},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("expected %d servers, got %d", len(want), len(result))
+	}
+	for i, w := range want {
+		got := result[i]
+		if got.ServerName != w.server || got.Tools != w.tools || got.Tokens != w.tokens ||
+			got.Calls != w.calls || got.TokensPerCall != w.tokensPerCall {
+			t.Errorf("result[%d] = %s %d tools %d tokens %d calls %.0f/call, want %s %d tools %d tokens %d calls %.0f/call",
+				i, got.ServerName, got.Tools, got.Tokens, got.Calls, got.TokensPerCall,
+				w.server, w.tools, w.tokens, w.calls, w.tokensPerCall)
+		}
+		if got.Sessions != 4 || got.SessionTokens != int64(w.tokens)*4 {
+			t.Errorf("%s: expected %d tokens across 4 sessions, got %d across %d",
+				got.ServerName, w.tokens*4, got.SessionTokens, got.Sessions)
+		}
+	}
+	if !result[0].Unused() || result[2].Unused() {
+		t.Error("expected servers without calls to be reported unused")
+	}
+}
+
+func TestContextCostAnalyzer_LatestSnapshotAcrossHosts(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+	ctx := context.Background()
+
+	// The same server listed on two hosts; the newer listing gained a tool
+	if err := store.ReplaceToolDefinitions(ctx, "github", []storage.ToolDefinition{
+		{ToolName: "search", Tokens: 400, Host: "laptop", CapturedAt: now.Add(-48 * time.Hour)},
+		{ToolName: "search", Tokens: 450, Host: "desktop", CapturedAt: now.Add(-time.Hour)},
+		{ToolName: "create_issue", Tokens: 600, Host: "desktop", CapturedAt: now.Add(-time.Hour)},
+	}); err != nil {
+		t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+	}
+
+	result, err := NewContextCostAnalyzer(store).Analyze(ctx, storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result) != 1 || result[0].Tools != 2 || result[0].Tokens != 1050 {
+		t.Fatalf("expected github with 2 tools and 1050 tokens, got %+v", result)
+	}
+	if !result[0].CapturedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("CapturedAt = %v, want the latest listing", result[0].CapturedAt)
+	}
+
+	none, err := NewContextCostAnalyzer(storage.NewMockStore()).Analyze(ctx, storage.TimeFilter{})
+	if err != nil || len(none) != 0 {
+		t.Errorf("expected no costs without tool definitions, got %v, %v", none, err)
+	}
+}
+
+func TestContextCostAnalyzer_ToolUsage(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+	ctx := context.Background()
+
+	if err := store.ReplaceToolDefinitions(ctx, "github", []storage.ToolDefinition{
+		{ToolName: "search", Tokens: 400, CapturedAt: now},
+		{ToolName: "create_issue", Tokens: 600, CapturedAt: now},
+		{ToolName: "list_commits", Tokens: 300, CapturedAt: now},
+		{ToolName: "get_file", Tokens: 200, CapturedAt: now},
+	}); err != nil {
+		t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+	}
+	addCalls(t, store, now.Add(-time.Hour), "github", "mcp__github__search", 10, 0, 100)
+	addCalls(t, store, now.Add(-time.Hour), "github", "mcp__github__get_file", 2, 0, 100)
+	// Called before the range, so unused in it but not never called
+	addCalls(t, store, now.AddDate(0, 0, -30), "github", "mcp__github__list_commits", 1, 0, 100)
+
+	result, err := NewContextCostAnalyzer(store).Analyze(ctx, storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(result))
+	}
+	github := result[0]
+	if github.UsedTools != 2 || github.ToolUtilizationPct != 50 || github.UnusedTokens != 900 {
+		t.Errorf("expected 2 of 4 tools used and 900 unused tokens, got %d (%.0f%%) and %d",
+			github.UsedTools, github.ToolUtilizationPct, github.UnusedTokens)
+	}
+
+	want := []struct {
+		tool        string
+		calls       int64
+		neverCalled bool
+	}{
+		{"create_issue", 0, true},
+		{"list_commits", 0, false},
+		{"search", 10, false},
+		{"get_file", 2, false},
+	}
+	if len(github.ToolCosts) != len(want) {
+		t.Fatalf("expected %d tools, got %d", len(want), len(github.ToolCosts))
+	}
+	for i, w := range want {
+		got := github.ToolCosts[i]
+		if got.ToolName != w.tool || got.Calls != w.calls || got.NeverCalled() != w.neverCalled {
+			t.Errorf("ToolCosts[%d] = %s %d calls never=%v, want %s %d calls never=%v",
+				i, got.ToolName, got.Calls, got.NeverCalled(), w.tool, w.calls, w.neverCalled)
+		}
+	}
+	if unused := github.UnusedTools(); len(unused) != 2 || unused[0].ToolName != "create_issue" {
+		t.Errorf("unexpected unused tools: %+v", unused)
+	}
+}
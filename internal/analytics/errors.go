@@ -2,22 +2,56 @@ package analytics
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"sort"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/errclass"
+	"github.com/anthropics/mcp-lens/internal/errsig"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
 // ErrorSummary provides aggregated error information for an MCP server.
 type ErrorSummary struct {
-	ServerName       string
-	TotalCalls       int64
-	ErrorCount       int64
-	ErrorRate        float64 // Percentage
-	RecentErrors     int64   // Errors in last hour
-	ErrorTrend       Trend   // Increasing, decreasing, or stable
-	MostFailedTools  []ToolErrorInfo
-	SeverityLevel    ErrorSeverity
+	ServerName      string
+	TotalCalls      int64
+	ErrorCount      int64
+	ErrorRate       float64 // Percentage
+	RecentErrors    int64   // Errors in last hour
+	ErrorTrend      Trend   // Error rate against the baseline window
+	ErrorTrendPct   float64 // Percentage change in error rate; +Inf when new
+	MostFailedTools []ToolErrorInfo
+	SeverityLevel   ErrorSeverity
+	Categories      []CategoryCount // Failures by kind, largest first
+}
+
+// CategoryCount is the number of a server's failures in one error category.
+type CategoryCount struct {
+	Category errclass.Category
+	Count    int64
+	Share    float64 // Percentage of the server's classified failures
+}
+
+// TopCategory returns the category most of the server's failures fall in,
+// ignoring failures no rule recognized.
+func (s ErrorSummary) TopCategory() (CategoryCount, bool) {
+	for _, c := range s.Categories {
+		if c.Category != errclass.Other && c.Category != errclass.Unknown {
+			return c, true
+		}
+	}
+	return CategoryCount{}, false
+}
+
+// CategoryLabel describes the server's main kind of failure, such as
+// "80% auth errors", or returns "" when no failure was recognized.
+func (s ErrorSummary) CategoryLabel() string {
+	c, ok := s.TopCategory()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%% %s errors", c.Share, c.Category.Label())
 }
 
 // ToolErrorInfo shows error information for a specific tool.
@@ -39,10 +73,12 @@ const (
 
 // ErrorAnalyzerConfig configures error analysis.
 type ErrorAnalyzerConfig struct {
-	LowThreshold      float64 // Below this is "low" severity
-	MediumThreshold   float64 // Below this is "medium" severity
-	HighThreshold     float64 // Below this is "high" severity
-	TopToolsCount     int     // Number of top failing tools to track
+	LowThreshold    float64 // Below this is "low" severity
+	MediumThreshold float64 // Below this is "medium" severity
+	HighThreshold   float64 // Below this is "high" severity
+	TopToolsCount   int     // Number of top failing tools to track
+	ClusterExamples int     // Example calls kept per error cluster
+	Trend           TrendConfig
 }
 
 // DefaultErrorAnalyzerConfig returns default configuration.
@@ -52,13 +88,16 @@ func DefaultErrorAnalyzerConfig() ErrorAnalyzerConfig {
 		MediumThreshold: 5.0,
 		HighThreshold:   10.0,
 		TopToolsCount:   5,
+		ClusterExamples: 3,
+		Trend:           DefaultTrendConfig(),
 	}
 }
 
 // ErrorAnalyzer provides error aggregation and analysis.
 type ErrorAnalyzer struct {
-	store  ErrorAnalyzerStore
-	config ErrorAnalyzerConfig
+	store      ErrorAnalyzerStore
+	config     ErrorAnalyzerConfig
+	classifier *errclass.Classifier
 }
 
 // ErrorAnalyzerStore defines the storage interface needed for error analysis.
@@ -66,13 +105,24 @@ type ErrorAnalyzerStore interface {
 	GetMCPServerStats(ctx context.Context, filter storage.TimeFilter) ([]storage.MCPServerStats, error)
 	GetToolStats(ctx context.Context, filter storage.TimeFilter) ([]storage.ToolStats, error)
 	GetRecentEvents(ctx context.Context, limit int) ([]storage.RecentEvent, error)
+	GetErrorClusters(ctx context.Context, filter storage.ErrorClusterFilter) ([]storage.ErrorCluster, error)
+	GetErrorMessages(ctx context.Context, filter storage.TimeFilter) ([]storage.ErrorMessageCount, error)
 }
 
 // NewErrorAnalyzer creates a new error analyzer.
 func NewErrorAnalyzer(store ErrorAnalyzerStore, config ErrorAnalyzerConfig) *ErrorAnalyzer {
 	return &ErrorAnalyzer{
-		store:  store,
-		config: config,
+		store:      store,
+		config:     config,
+		classifier: errclass.Default(),
+	}
+}
+
+// SetClassifier replaces the built-in error classifier, typically with one
+// that includes user-defined rules.
+func (a *ErrorAnalyzer) SetClassifier(c *errclass.Classifier) {
+	if c != nil {
+		a.classifier = c
 	}
 }
 
@@ -96,6 +146,16 @@ func (a *ErrorAnalyzer) AnalyzeErrors(ctx context.Context, filter storage.TimeFi
 		}
 	}
 
+	categoriesByServer, err := a.categorizeErrors(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := a.baselineStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get recent events for trend analysis
 	recentEvents, _ := a.store.GetRecentEvents(ctx, 100)
 	recentErrorsByServer := a.countRecentErrors(recentEvents, time.Hour)
@@ -125,8 +185,15 @@ func (a *ErrorAnalyzer) AnalyzeErrors(ctx context.Context, filter storage.TimeFi
 			summary.MostFailedTools = a.getTopFailingTools(tools)
 		}
 
-		// Default trend to stable (would need historical comparison for accurate trend)
+		summary.Categories = categoriesByServer[s.ServerName]
+
+		// Compare the error rate against the baseline, when there is one
 		summary.ErrorTrend = TrendStable
+		if previous, ok := baseline[s.ServerName]; ok && previous.TotalCalls > 0 {
+			previousRate := float64(previous.ErrorCount) / float64(previous.TotalCalls) * 100
+			calls := min(s.TotalCalls, previous.TotalCalls/int64(max(a.config.Trend.BaselineWindows, 1)))
+			summary.ErrorTrend, summary.ErrorTrendPct = a.config.Trend.CompareTrend(summary.ErrorRate, previousRate, calls)
+		}
 
 		result = append(result, summary)
 	}
@@ -163,6 +230,11 @@ func (a *ErrorAnalyzer) GetErrorTotals(ctx context.Context, filter storage.TimeF
 		return nil, err
 	}
 
+	return SummarizeTotals(summaries), nil
+}
+
+// SummarizeTotals combines per-server error summaries into overall totals.
+func SummarizeTotals(summaries []ErrorSummary) *ErrorTotals {
 	totals := &ErrorTotals{
 		ServerCount: len(summaries),
 	}
@@ -185,7 +257,7 @@ func (a *ErrorAnalyzer) GetErrorTotals(ctx context.Context, filter storage.TimeF
 		totals.OverallErrorRate = float64(totals.TotalErrors) / float64(totals.TotalCalls) * 100
 	}
 
-	return totals, nil
+	return totals
 }
 
 // ErrorTotals provides aggregate error statistics.
@@ -199,6 +271,69 @@ type ErrorTotals struct {
 	MediumErrorServers int
 }
 
+// baselineStats returns each server's stats over the baseline range for
+// filter, or nil when the range has no baseline.
+func (a *ErrorAnalyzer) baselineStats(ctx context.Context, filter storage.TimeFilter) (map[string]storage.MCPServerStats, error) {
+	baseFilter, ok := BaselineFilter(filter, a.config.Trend.BaselineWindows)
+	if !ok {
+		return nil, nil
+	}
+
+	stats, err := a.store.GetMCPServerStats(ctx, baseFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]storage.MCPServerStats, len(stats))
+	for _, s := range stats {
+		result[s.ServerName] = s
+	}
+	return result, nil
+}
+
+// categorizeErrors classifies each server's failed calls, returning the
+// categories of each server largest first.
+func (a *ErrorAnalyzer) categorizeErrors(ctx context.Context, filter storage.TimeFilter) (map[string][]CategoryCount, error) {
+	messages, err := a.store.GetErrorMessages(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]map[errclass.Category]int64)
+	totals := make(map[string]int64)
+	for _, m := range messages {
+		if m.MCPServer == "" {
+			continue
+		}
+		if counts[m.MCPServer] == nil {
+			counts[m.MCPServer] = make(map[errclass.Category]int64)
+		}
+		counts[m.MCPServer][a.classifier.Classify(m.Error)] += m.Count
+		totals[m.MCPServer] += m.Count
+	}
+
+	result := make(map[string][]CategoryCount, len(counts))
+	for server, byCategory := range counts {
+		categories := make([]CategoryCount, 0, len(byCategory))
+		for category, n := range byCategory {
+			categories = append(categories, CategoryCount{
+				Category: category,
+				Count:    n,
+				Share:    float64(n) / float64(totals[server]) * 100,
+			})
+		}
+		sort.Slice(categories, func(i, j int) bool {
+			if categories[i].Count != categories[j].Count {
+				return categories[i].Count > categories[j].Count
+			}
+			return categories[i].Category < categories[j].Category
+		})
+		result[server] = categories
+	}
+
+	return result, nil
+}
+
 // countRecentErrors counts errors per server from recent events.
 func (a *ErrorAnalyzer) countRecentErrors(events []storage.RecentEvent, window time.Duration) map[string]int64 {
 	counts := make(map[string]int64)
@@ -258,6 +393,59 @@ func (a *ErrorAnalyzer) calculateSeverity(errorRate float64) ErrorSeverity {
 	}
 }
 
+// ErrorCluster is a group of failed calls to one server whose error messages
+// normalize to the same signature.
+type ErrorCluster struct {
+	storage.ErrorCluster
+	Share    float64           // Percentage of the server's errors in the range
+	Category errclass.Category // Kind of failure, from the newest example
+}
+
+// TopErrorClusters returns the largest error clusters in the range, with
+// first/last seen times and the most recent calls as examples.
+func (a *ErrorAnalyzer) TopErrorClusters(ctx context.Context, filter storage.TimeFilter, limit int) ([]ErrorCluster, error) {
+	clusters, err := a.store.GetErrorClusters(ctx, storage.ErrorClusterFilter{
+		TimeFilter: filter,
+		Limit:      limit,
+		Examples:   a.config.ClusterExamples,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	serverStats, err := a.store.GetMCPServerStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	errorsByServer := make(map[string]int64, len(serverStats))
+	for _, s := range serverStats {
+		errorsByServer[s.ServerName] = s.ErrorCount
+	}
+
+	result := make([]ErrorCluster, 0, len(clusters))
+	for _, c := range clusters {
+		cluster := ErrorCluster{ErrorCluster: c, Category: a.classifyCluster(c)}
+		if total := errorsByServer[c.MCPServer]; total > 0 {
+			cluster.Share = math.Min(float64(c.Count)/float64(total)*100, 100)
+		}
+		result = append(result, cluster)
+	}
+
+	return result, nil
+}
+
+// classifyCluster classifies a cluster by its newest example's full error
+// message, falling back to the signature when it has no examples.
+func (a *ErrorAnalyzer) classifyCluster(c storage.ErrorCluster) errclass.Category {
+	if c.Signature == errsig.NoMessage {
+		return errclass.Unknown
+	}
+	if len(c.Examples) > 0 {
+		return a.classifier.Classify(c.Examples[0].Error)
+	}
+	return a.classifier.Classify(c.Signature)
+}
+
 // ErrorEvent represents a single error occurrence for detailed analysis.
 type ErrorEvent struct {
 	Timestamp  time.Time
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/errclass"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
@@ -13,6 +14,10 @@ type mockErrorAnalyzerStore struct {
 	serverStats  []storage.MCPServerStats
 	toolStats    []storage.ToolStats
 	recentEvents []storage.RecentEvent
+	clusters     []storage.ErrorCluster
+	messages     []storage.ErrorMessageCount
+	previous     []storage.MCPServerStats // Returned for ranges ending at previousTo
+	previousTo   time.Time
 	err          error
 }
 
@@ -20,6 +25,9 @@ func (m *mockErrorAnalyzerStore) GetMCPServerStats(ctx context.Context, filter s
 	if m.err != nil {
 		return nil, m.err
 	}
+	if !m.previousTo.IsZero() && filter.To.Equal(m.previousTo) {
+		return m.previous, nil
+	}
 	return m.serverStats, nil
 }
 
@@ -37,13 +45,27 @@ func (m *mockErrorAnalyzerStore) GetRecentEvents(ctx context.Context, limit int)
 	return m.recentEvents, nil
 }
 
+func (m *mockErrorAnalyzerStore) GetErrorClusters(ctx context.Context, filter storage.ErrorClusterFilter) ([]storage.ErrorCluster, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.clusters, nil
+}
+
+func (m *mockErrorAnalyzerStore) GetErrorMessages(ctx context.Context, filter storage.TimeFilter) ([]storage.ErrorMessageCount, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.messages, nil
+}
+
 func TestErrorAnalyzer_AnalyzeErrors(t *testing.T) {
 	tests := []struct {
 		name              string
 		serverStats       []storage.MCPServerStats
 		toolStats         []storage.ToolStats
 		expectedLen       int
-		expectedFirst     string        // Expected first server (highest error rate)
+		expectedFirst     string // Expected first server (highest error rate)
 		expectedSeverity  ErrorSeverity
 		expectedErrorRate float64
 	}{
@@ -486,4 +508,161 @@ func TestDefaultErrorAnalyzerConfig(t *testing.T) {
 	if config.TopToolsCount != 5 {
 		t.Errorf("expected TopToolsCount 5, got %d", config.TopToolsCount)
 	}
+
+	if config.ClusterExamples != 3 {
+		t.Errorf("expected ClusterExamples 3, got %d", config.ClusterExamples)
+	}
+}
+
+func TestErrorAnalyzer_TopErrorClusters(t *testing.T) {
+	now := time.Now()
+	store := &mockErrorAnalyzerStore{
+		serverStats: []storage.MCPServerStats{
+			{ServerName: "github", TotalCalls: 100, ErrorCount: 8},
+			{ServerName: "fs", TotalCalls: 10, ErrorCount: 0},
+		},
+		clusters: []storage.ErrorCluster{
+			{MCPServer: "github", Signature: "rate limit exceeded: <n>", Count: 6, FirstSeen: now.Add(-time.Hour), LastSeen: now,
+				Examples: []storage.Event{{Error: "rate limit exceeded: 60"}}},
+			{MCPServer: "github", Signature: "repository <str> not found", Count: 2, FirstSeen: now, LastSeen: now},
+			{MCPServer: "", Signature: "(no error message)", Count: 1, FirstSeen: now, LastSeen: now},
+		},
+	}
+
+	analyzer := NewErrorAnalyzer(store, DefaultErrorAnalyzerConfig())
+	clusters, err := analyzer.TopErrorClusters(context.Background(), storage.TimeFilter{}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		signature string
+		share     float64
+		category  errclass.Category
+	}{
+		{"rate limit exceeded: <n>", 75, errclass.RateLimited},
+		{"repository <str> not found", 25, errclass.NotFound},
+		{"(no error message)", 0, errclass.Unknown}, // Built-in tools have no server error count
+	}
+	if len(clusters) != len(tests) {
+		t.Fatalf("expected %d clusters, got %d", len(tests), len(clusters))
+	}
+	for i, tt := range tests {
+		if clusters[i].Signature != tt.signature {
+			t.Errorf("cluster %d signature = %q, want %q", i, clusters[i].Signature, tt.signature)
+		}
+		if clusters[i].Share != tt.share {
+			t.Errorf("cluster %q share = %.1f, want %.1f", tt.signature, clusters[i].Share, tt.share)
+		}
+		if clusters[i].Category != tt.category {
+			t.Errorf("cluster %q category = %s, want %s", tt.signature, clusters[i].Category, tt.category)
+		}
+	}
+}
+
+func TestErrorAnalyzer_Categories(t *testing.T) {
+	store := &mockErrorAnalyzerStore{
+		serverStats: []storage.MCPServerStats{
+			{ServerName: "github", TotalCalls: 100, ErrorCount: 10},
+			{ServerName: "fs", TotalCalls: 50, ErrorCount: 2},
+			{ServerName: "quiet", TotalCalls: 50},
+		},
+		messages: []storage.ErrorMessageCount{
+			{MCPServer: "github", Error: "HTTP 401: Bad credentials", Count: 5},
+			{MCPServer: "github", Error: "The access token has expired", Count: 3},
+			{MCPServer: "github", Error: "API rate limit exceeded", Count: 2},
+			{MCPServer: "fs", Error: "weird failure", Count: 2},
+			{MCPServer: "", Error: "Exit code 1", Count: 4},
+		},
+	}
+
+	analyzer := NewErrorAnalyzer(store, DefaultErrorAnalyzerConfig())
+	result, err := analyzer.AnalyzeErrors(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byServer := make(map[string]ErrorSummary)
+	for _, s := range result {
+		byServer[s.ServerName] = s
+	}
+
+	github := byServer["github"]
+	if len(github.Categories) != 2 {
+		t.Fatalf("expected 2 github categories, got %+v", github.Categories)
+	}
+	if top := github.Categories[0]; top.Category != errclass.Auth || top.Count != 8 || top.Share != 80 {
+		t.Errorf("top github category = %+v, want 8 auth errors at 80%%", top)
+	}
+	if label := github.CategoryLabel(); label != "80% auth errors" {
+		t.Errorf("github label = %q, want %q", label, "80% auth errors")
+	}
+
+	// Failures no rule recognizes are counted but not used as the label
+	if fs := byServer["fs"]; len(fs.Categories) != 1 || fs.CategoryLabel() != "" {
+		t.Errorf("fs summary = %+v, want one unlabeled category", fs)
+	}
+	if quiet := byServer["quiet"]; quiet.Categories != nil {
+		t.Errorf("expected no categories for a server without errors, got %+v", quiet.Categories)
+	}
+
+	// User rules take precedence over the built-in rules
+	rule, err := errclass.NewRule("quota", "rate limit")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	analyzer.SetClassifier(errclass.New([]errclass.Rule{rule}))
+	result, err = analyzer.AnalyzeErrors(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range result {
+		if s.ServerName != "github" {
+			continue
+		}
+		if c := s.Categories[len(s.Categories)-1]; c.Category != "quota" || c.Count != 2 {
+			t.Errorf("smallest github category = %+v, want 2 quota errors", c)
+		}
+	}
+}
+
+func TestErrorAnalyzer_ErrorTrend(t *testing.T) {
+	now := time.Now()
+	filter := storage.TimeFilter{From: now.Add(-time.Hour), To: now}
+
+	store := &mockErrorAnalyzerStore{
+		serverStats: []storage.MCPServerStats{
+			{ServerName: "worse", TotalCalls: 100, ErrorCount: 10},
+			{ServerName: "better", TotalCalls: 100, ErrorCount: 2},
+			{ServerName: "quiet", TotalCalls: 5, ErrorCount: 3},
+		},
+		previous: []storage.MCPServerStats{
+			{ServerName: "worse", TotalCalls: 200, ErrorCount: 10},
+			{ServerName: "better", TotalCalls: 100, ErrorCount: 8},
+			{ServerName: "quiet", TotalCalls: 5, ErrorCount: 0},
+		},
+		previousTo: filter.From,
+	}
+
+	analyzer := NewErrorAnalyzer(store, DefaultErrorAnalyzerConfig())
+	result, err := analyzer.AnalyzeErrors(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		trend     Trend
+		changePct float64
+	}{
+		"worse":  {TrendUp, 100},   // 5% -> 10%
+		"better": {TrendDown, -75}, // 8% -> 2%
+		"quiet":  {TrendStable, 0}, // Too few calls to compare
+	}
+	for _, s := range result {
+		tt := tests[s.ServerName]
+		if s.ErrorTrend != tt.trend || s.ErrorTrendPct != tt.changePct {
+			t.Errorf("%s error trend = %s %.0f%%, want %s %.0f%%",
+				s.ServerName, s.ErrorTrend, s.ErrorTrendPct, tt.trend, tt.changePct)
+		}
+	}
 }
@@ -0,0 +1,201 @@
+package analytics
+
+import (
+	"math"
+	"time"
+)
+
+// Series shorter than these are fit without a trend or without weekday
+// effects, which fewer points cannot tell apart from noise.
+const (
+	MinTrendDays    = 7
+	MinSeasonalDays = 14
+)
+
+// intervalZ is the z-score of a two-sided 80% prediction interval.
+const intervalZ = 1.2816
+
+// Prediction is a forecast value with its 80% prediction interval. Values
+// are never negative.
+type Prediction struct {
+	Value float64
+	Low   float64
+	High  float64
+}
+
+// DailyModel is a fit of a daily series: a linear trend scaled by an index
+// for each day of the week, and the spread of what those leave unexplained.
+// Indexes are multiplicative, since spend follows activity: a day of the
+// week with no activity stays at zero however the trend moves.
+type DailyModel struct {
+	Trend    bool // Whether a trend was fit
+	Seasonal bool // Whether weekday effects were fit
+
+	start     time.Time
+	n         int
+	intercept float64
+	slope     float64
+	weekday   [7]float64 // Index by day of the week, averaging 1
+	sigma     float64    // Standard deviation of the residuals
+	meanT     float64
+	sxx       float64 // Sum of squared deviations of the day indexes
+}
+
+// FitDailyModel fits a model to values, one per consecutive day from start.
+// Each weekday index is the ratio of that day of the week's values to the
+// trend's; the trend is fit by least squares.
+func FitDailyModel(start time.Time, values []float64) *DailyModel {
+	m := &DailyModel{
+		start:    start,
+		n:        len(values),
+		Trend:    len(values) >= MinTrendDays,
+		Seasonal: len(values) >= MinSeasonalDays,
+	}
+	for w := range m.weekday {
+		m.weekday[w] = 1
+	}
+	if m.n == 0 {
+		return m
+	}
+
+	for t := range values {
+		m.meanT += float64(t)
+	}
+	m.meanT /= float64(m.n)
+	for t := range values {
+		d := float64(t) - m.meanT
+		m.sxx += d * d
+	}
+
+	// Fit the trend, take the weekday indexes against it, then fit the
+	// trend again scaled by them
+	m.fitTrend(values)
+	if m.Seasonal {
+		var actual, fitted [7]float64
+		for t, v := range values {
+			w := m.weekdayOf(t)
+			actual[w] += v
+			fitted[w] += m.trendAt(float64(t))
+		}
+		var mean float64
+		for w := range m.weekday {
+			if fitted[w] > 0 {
+				m.weekday[w] = math.Max(actual[w]/fitted[w], 0)
+			}
+			mean += m.weekday[w] / 7
+		}
+		if mean > 0 {
+			for w := range m.weekday {
+				m.weekday[w] /= mean
+			}
+		}
+		m.fitTrend(values)
+	}
+
+	params := 1
+	if m.Trend {
+		params++
+	}
+	if m.Seasonal {
+		params += 6
+	}
+	var ssr, sum float64
+	for t, v := range values {
+		r := v - m.at(t)
+		ssr += r * r
+		sum += v
+	}
+	if dof := m.n - params; dof > 0 {
+		m.sigma = math.Sqrt(ssr / float64(dof))
+	} else {
+		// Too few points to measure the spread; assume it is as large as
+		// the values themselves
+		m.sigma = sum / float64(m.n)
+	}
+	return m
+}
+
+// fitTrend fits the intercept, and the slope when the model has a trend,
+// minimising the squared error of the trend scaled by the weekday indexes.
+func (m *DailyModel) fitTrend(values []float64) {
+	// Normal equations of v = s*a + s*t*b, for index s on day t
+	var ss, sst, sstt, sv, stv float64
+	for t, v := range values {
+		s, ft := m.weekday[m.weekdayOf(t)], float64(t)
+		ss += s * s
+		sst += s * s * ft
+		sstt += s * s * ft * ft
+		sv += s * v
+		stv += s * ft * v
+	}
+
+	m.intercept, m.slope = 0, 0
+	if det := ss*sstt - sst*sst; m.Trend && det > 1e-9 {
+		m.intercept = (sv*sstt - stv*sst) / det
+		m.slope = (ss*stv - sst*sv) / det
+	} else if ss > 0 {
+		m.intercept = sv / ss
+	}
+}
+
+func (m *DailyModel) trendAt(t float64) float64 {
+	return m.intercept + m.slope*t
+}
+
+// at returns the fitted value on day t.
+func (m *DailyModel) at(t int) float64 {
+	return m.trendAt(float64(t)) * m.weekday[m.weekdayOf(t)]
+}
+
+func (m *DailyModel) weekdayOf(t int) int {
+	return int(m.start.AddDate(0, 0, t).Weekday())
+}
+
+// Slope returns the fitted change per day, averaged over the week.
+func (m *DailyModel) Slope() float64 {
+	return m.slope
+}
+
+// Day predicts the value on a day.
+func (m *DailyModel) Day(day time.Time) Prediction {
+	return m.Sum(day, 1)
+}
+
+// Sum predicts the total over days consecutive days from a day. The
+// interval accounts for the daily spread and the uncertainty of the fitted
+// level and trend, treating days as independent.
+func (m *DailyModel) Sum(from time.Time, days int) Prediction {
+	if m.n == 0 || days <= 0 {
+		return Prediction{}
+	}
+
+	first := DaysBetween(m.start, from)
+	var value, meanT float64
+	for i := 0; i < days; i++ {
+		t := first + i
+		value += m.at(t)
+		meanT += float64(t)
+	}
+	meanT /= float64(days)
+
+	h := float64(days)
+	level := 1 / float64(m.n)
+	if m.Trend && m.sxx > 0 {
+		level += (meanT - m.meanT) * (meanT - m.meanT) / m.sxx
+	}
+	margin := intervalZ * m.sigma * math.Sqrt(h+h*h*level)
+
+	return Prediction{
+		Value: math.Max(value, 0),
+		Low:   math.Max(value-margin, 0),
+		High:  math.Max(value+margin, 0),
+	}
+}
+
+// DaysBetween returns the number of calendar days from one day to another,
+// ignoring the time of day and daylight saving changes.
+func DaysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
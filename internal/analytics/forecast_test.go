@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFitDailyModel(t *testing.T) {
+	// Four weeks starting on a Monday: spend rises by $1 a day, with
+	// nothing spent at weekends
+	start := time.Date(2026, 9, 7, 0, 0, 0, 0, time.Local)
+	values := make([]float64, 28)
+	for i := range values {
+		if wd := start.AddDate(0, 0, i).Weekday(); wd != time.Saturday && wd != time.Sunday {
+			values[i] = 20 + float64(i)
+		}
+	}
+
+	m := FitDailyModel(start, values)
+	if !m.Trend || !m.Seasonal {
+		t.Fatalf("expected a trend and weekday effects from 28 days, got %+v", m)
+	}
+	// Averaged over the week, spend rises by 5/7 of $1 a day
+	if math.Abs(m.Slope()-5.0/7) > 0.1 {
+		t.Errorf("expected a slope near $0.71/day, got %.2f", m.Slope())
+	}
+
+	monday := m.Day(start.AddDate(0, 0, 28))
+	saturday := m.Day(start.AddDate(0, 0, 33))
+	if monday.Value < 40 || monday.Value > 56 {
+		t.Errorf("expected the next Monday near $48, got %+v", monday)
+	}
+	if saturday.Value > 1 {
+		t.Errorf("expected little spend on the next Saturday, got %+v", saturday)
+	}
+	if monday.Low > monday.Value || monday.High < monday.Value {
+		t.Errorf("expected the value inside its interval, got %+v", monday)
+	}
+
+	week := m.Sum(start.AddDate(0, 0, 28), 7)
+	var days float64
+	for i := 0; i < 7; i++ {
+		days += m.Day(start.AddDate(0, 0, 28+i)).Value
+	}
+	if math.Abs(week.Value-days) > 1e-6 {
+		t.Errorf("expected the week to sum its days, got %.2f and %.2f", week.Value, days)
+	}
+	if week.High-week.Low <= monday.High-monday.Low {
+		t.Errorf("expected a wider interval for a week than a day, got %+v and %+v", week, monday)
+	}
+}
+
+func TestFitDailyModel_Short(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+
+	m := FitDailyModel(start, []float64{4, 6, 5})
+	if m.Trend || m.Seasonal {
+		t.Errorf("expected a flat fit from 3 days, got %+v", m)
+	}
+	if p := m.Day(start.AddDate(0, 0, 10)); p.Value != 5 {
+		t.Errorf("expected the mean, got %+v", p)
+	}
+
+	if p := FitDailyModel(start, nil).Sum(start, 30); p != (Prediction{}) {
+		t.Errorf("expected no prediction from no data, got %+v", p)
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	// Spans the end of daylight saving time
+	from := time.Date(2026, 10, 31, 23, 0, 0, 0, loc)
+	to := time.Date(2026, 11, 2, 1, 0, 0, 0, loc)
+	if got := DaysBetween(from, to); got != 2 {
+		t.Errorf("expected 2 days, got %d", got)
+	}
+}
@@ -0,0 +1,123 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// UsageStatus describes how much a configured server is used.
+type UsageStatus string
+
+const (
+	UsageNeverUsed UsageStatus = "never_used"  // No calls recorded
+	UsageInactive  UsageStatus = "inactive"    // No calls in the unused threshold period
+	UsageRare      UsageStatus = "rarely_used" // Fewer calls in the range than the rare threshold
+	UsageActive    UsageStatus = "active"
+)
+
+// rank orders statuses from least to most used.
+func (s UsageStatus) rank() int {
+	switch s {
+	case UsageNeverUsed:
+		return 0
+	case UsageInactive:
+		return 1
+	case UsageRare:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ConfiguredServer is a server from the MCP configuration with its usage.
+type ConfiguredServer struct {
+	ServerName   string             // Name the server's tools are recorded under
+	Locations    []mcpconfig.Server // Everywhere the server is configured
+	Calls        int64              // Calls in the range
+	TotalCalls   int64              // Calls ever recorded
+	LastUsedAt   time.Time
+	DaysSinceUse int
+	Usage        UsageStatus
+}
+
+// Disabled reports whether the server is turned off everywhere it is
+// configured.
+func (c ConfiguredServer) Disabled() bool {
+	for _, l := range c.Locations {
+		if !l.Disabled {
+			return false
+		}
+	}
+	return len(c.Locations) > 0
+}
+
+// SetInventory sets the configured servers, so GetUnusedServers also
+// reports servers that have never been called.
+func (a *UtilizationAnalyzer) SetInventory(inv *mcpconfig.Inventory) {
+	a.inventory = inv
+}
+
+// AnalyzeInventory cross-references the configured servers with usage,
+// least used first. Calls are counted in the filter range; whether a server
+// was ever used is judged over all time.
+func (a *UtilizationAnalyzer) AnalyzeInventory(ctx context.Context, inv *mcpconfig.Inventory, filter storage.TimeFilter) ([]ConfiguredServer, error) {
+	allTime, err := a.store.GetMCPServerStats(ctx, storage.TimeFilter{Host: filter.Host})
+	if err != nil {
+		return nil, err
+	}
+	inRange, err := a.store.GetMCPServerStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	everMap := make(map[string]storage.MCPServerStats, len(allTime))
+	for _, s := range allTime {
+		everMap[s.ServerName] = s
+	}
+	rangeMap := make(map[string]int64, len(inRange))
+	for _, s := range inRange {
+		rangeMap[s.ServerName] = s.TotalCalls
+	}
+
+	now := time.Now()
+	threshold := now.AddDate(0, 0, -a.config.UnusedThresholdDays)
+
+	var result []ConfiguredServer
+	for _, id := range inv.IDs() {
+		c := ConfiguredServer{
+			ServerName: id,
+			Locations:  inv.Locations(id),
+			Calls:      rangeMap[id],
+		}
+		ever := everMap[id]
+		c.TotalCalls = ever.TotalCalls
+		c.LastUsedAt = ever.LastUsedAt
+		if !c.LastUsedAt.IsZero() {
+			c.DaysSinceUse = int(now.Sub(c.LastUsedAt).Hours() / 24)
+		}
+
+		switch {
+		case c.TotalCalls == 0:
+			c.Usage = UsageNeverUsed
+		case c.LastUsedAt.Before(threshold):
+			c.Usage = UsageInactive
+		case c.Calls < a.config.RareCallThreshold:
+			c.Usage = UsageRare
+		default:
+			c.Usage = UsageActive
+		}
+		result = append(result, c)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Usage.rank() != result[j].Usage.rank() {
+			return result[i].Usage.rank() < result[j].Usage.rank()
+		}
+		return result[i].Calls < result[j].Calls
+	})
+	return result, nil
+}
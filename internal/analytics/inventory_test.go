@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func testInventory() *mcpconfig.Inventory {
+	return &mcpconfig.Inventory{Servers: []mcpconfig.Server{
+		{Name: "github", Scope: mcpconfig.ScopeUser},
+		{Name: "github", Scope: mcpconfig.ScopeProject, Project: "/src/app"},
+		{Name: "fs", Scope: mcpconfig.ScopeUser},
+		{Name: "slack", Scope: mcpconfig.ScopeUser},
+		{Name: "sentry", Scope: mcpconfig.ScopeProject, Project: "/src/app", Disabled: true},
+		{Name: "browser", Scope: mcpconfig.ScopePlugin, Plugin: "dev"},
+	}}
+}
+
+func TestUtilizationAnalyzer_AnalyzeInventory(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+	addCalls(t, store, now.Add(-time.Hour), "github", "mcp__github__search", 50, 0, 100)
+	addCalls(t, store, now.Add(-2*time.Hour), "fs", "mcp__fs__read", 2, 0, 10)
+	addCalls(t, store, now.AddDate(0, 0, -20), "slack", "mcp__slack__post", 30, 0, 100)
+	addCalls(t, store, now.Add(-time.Hour), "plugin_dev_browser", "mcp__plugin_dev_browser__open", 10, 0, 100)
+	// Called but not configured
+	addCalls(t, store, now.Add(-time.Hour), "other", "mcp__other__x", 10, 0, 100)
+
+	analyzer := NewUtilizationAnalyzer(store, DefaultUtilizationConfig())
+	filter := storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now}
+	result, err := analyzer.AnalyzeInventory(context.Background(), testInventory(), filter)
+	if err != nil {
+		t.Fatalf("AnalyzeInventory failed: %v", err)
+	}
+
+	want := []struct {
+		server string
+		usage  UsageStatus
+		calls  int64
+	}{
+		{"sentry", UsageNeverUsed, 0},
+		{"slack", UsageInactive, 0},
+		{"fs", UsageRare, 2},
+		{"plugin_dev_browser", UsageActive, 10},
+		{"github", UsageActive, 50},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("expected %d servers, got %d", len(want), len(result))
+	}
+	for i, w := range want {
+		got := result[i]
+		if got.ServerName != w.server || got.Usage != w.usage || got.Calls != w.calls {
+			t.Errorf("result[%d] = %s %s %d calls, want %s %s %d calls",
+				i, got.ServerName, got.Usage, got.Calls, w.server, w.usage, w.calls)
+		}
+	}
+
+	if !result[0].Disabled() {
+		t.Error("expected sentry to be disabled")
+	}
+	if github := result[4]; len(github.Locations) != 2 || github.Disabled() {
+		t.Errorf("expected github enabled in 2 places, got %+v", github.Locations)
+	}
+	if slack := result[1]; slack.TotalCalls != 30 || slack.DaysSinceUse != 20 {
+		t.Errorf("expected slack with 30 calls 20 days ago, got %d calls %d days ago", slack.TotalCalls, slack.DaysSinceUse)
+	}
+}
+
+func TestUtilizationAnalyzer_GetUnusedServers_Inventory(t *testing.T) {
+	store := &mockUtilizationStore{stats: []storage.MCPServerStats{
+		{ServerName: "github", TotalCalls: 100, LastUsedAt: time.Now()},
+	}}
+	analyzer := NewUtilizationAnalyzer(store, DefaultUtilizationConfig())
+	analyzer.SetInventory(testInventory())
+
+	result, err := analyzer.GetUnusedServers(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Every configured server but github has never been called
+	if len(result) != 4 {
+		t.Fatalf("expected 4 unused servers, got %d", len(result))
+	}
+	for _, u := range result {
+		if u.ServerName == "github" || u.TotalCalls != 0 || u.HealthStatus != HealthUnused {
+			t.Errorf("unexpected unused server: %+v", u)
+		}
+	}
+}
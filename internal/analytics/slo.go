@@ -0,0 +1,320 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// SLO is a service level objective for an MCP server or one of its tools,
+// such as "github: 99% success, p90 < 2s over 7d". Either objective may be
+// left unset.
+type SLO struct {
+	Name              string
+	Server            string
+	Tool              string        // Empty for the whole server
+	SuccessTarget     float64       // Percentage of calls that must succeed; 0 for none
+	LatencyPercentile float64       // Percentile the latency target applies to (default: 90)
+	LatencyTargetMs   float64       // Latency the percentile must stay under; 0 for none
+	Window            time.Duration // Compliance window (default: 7 days)
+}
+
+// Subject returns the server, or server and tool, the SLO applies to.
+func (s SLO) Subject() string {
+	if s.Tool == "" {
+		return s.Server
+	}
+	return s.Server + " " + s.Tool
+}
+
+// Describe renders the objectives, such as "99% success, p90 < 2000ms over 7d".
+func (s SLO) Describe() string {
+	var parts []string
+	if s.SuccessTarget > 0 {
+		parts = append(parts, fmt.Sprintf("%g%% success", s.SuccessTarget))
+	}
+	if s.LatencyTargetMs > 0 {
+		parts = append(parts, fmt.Sprintf("p%g < %gms", s.percentile(), s.LatencyTargetMs))
+	}
+	return strings.Join(parts, ", ") + " over " + FormatWindow(s.window())
+}
+
+func (s SLO) percentile() float64 {
+	if s.LatencyPercentile > 0 {
+		return s.LatencyPercentile
+	}
+	return 90
+}
+
+func (s SLO) window() time.Duration {
+	if s.Window > 0 {
+		return s.Window
+	}
+	return 7 * 24 * time.Hour
+}
+
+// SLOStatus is the state of an objective.
+type SLOStatus string
+
+const (
+	SLOMet      SLOStatus = "met"      // Within budget
+	SLOAtRisk   SLOStatus = "at_risk"  // Budget nearly spent or burning fast
+	SLOBreached SLOStatus = "breached" // Budget spent
+	SLONoData   SLOStatus = "no_data"  // No calls in the window
+)
+
+// severity orders statuses from best to worst.
+func (s SLOStatus) severity() int {
+	switch s {
+	case SLOBreached:
+		return 3
+	case SLOAtRisk:
+		return 2
+	case SLOMet:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Objective is the evaluation of one objective of an SLO over its window.
+type Objective struct {
+	Target          float64 // Success percentage, or latency in ms
+	Actual          float64 // Observed success percentage, or percentile latency in ms
+	BadCalls        int64   // Failed calls, or calls slower than the latency target
+	AllowedBadCalls float64 // Bad calls the error budget allows in the window
+	BudgetRemaining float64 // Percentage of the error budget left; negative when overspent
+	Status          SLOStatus
+}
+
+// BurnRate is how fast the error budget was spent over a recent window,
+// relative to spending it evenly over the SLO window. A rate of 1 spends
+// exactly the budget; 10 spends it in a tenth of the window.
+type BurnRate struct {
+	Window time.Duration
+	Calls  int64
+	Rate   float64
+}
+
+// SLOResult is the evaluation of an SLO.
+type SLOResult struct {
+	SLO       SLO
+	Calls     int64
+	Success   *Objective // Nil when the SLO has no success objective
+	Latency   *Objective // Nil when the SLO has no latency objective
+	BurnRates []BurnRate // Success budget burn over recent windows, shortest first
+	FastBurn  bool       // Every burn window is above the fast burn rate
+	Status    SLOStatus  // Worst of the objectives
+}
+
+// SLOConfig configures SLO evaluation.
+type SLOConfig struct {
+	BurnWindows  []time.Duration // Recent windows burn rates are computed over (default: 1h, 6h, 24h)
+	FastBurnRate float64         // Burn rate in every window that puts an SLO at risk (default: 10)
+	AtRiskBudget float64         // Remaining budget percentage below which an SLO is at risk (default: 25)
+}
+
+// DefaultSLOConfig returns default configuration.
+func DefaultSLOConfig() SLOConfig {
+	return SLOConfig{
+		BurnWindows:  []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour},
+		FastBurnRate: 10.0,
+		AtRiskBudget: 25.0,
+	}
+}
+
+// SLOStore defines the storage interface needed for SLO evaluation.
+type SLOStore interface {
+	GetMCPServerStats(ctx context.Context, filter storage.TimeFilter) ([]storage.MCPServerStats, error)
+	GetToolStats(ctx context.Context, filter storage.TimeFilter) ([]storage.ToolStats, error)
+	GetToolLatencies(ctx context.Context, filter storage.TimeFilter) ([]storage.ToolLatency, error)
+}
+
+// SLOEngine evaluates SLOs against stored aggregates.
+type SLOEngine struct {
+	store  SLOStore
+	config SLOConfig
+	slos   []SLO
+}
+
+// NewSLOEngine creates an engine for a set of SLOs.
+func NewSLOEngine(store SLOStore, config SLOConfig, slos []SLO) *SLOEngine {
+	return &SLOEngine{
+		store:  store,
+		config: config,
+		slos:   slos,
+	}
+}
+
+// SLOs returns the SLOs the engine evaluates.
+func (e *SLOEngine) SLOs() []SLO {
+	return e.slos
+}
+
+// Evaluate evaluates every SLO over the window ending at now, counting only
+// calls from host unless it is empty.
+func (e *SLOEngine) Evaluate(ctx context.Context, now time.Time, host string) ([]SLOResult, error) {
+	results := make([]SLOResult, 0, len(e.slos))
+	for _, slo := range e.slos {
+		result, err := e.evaluate(ctx, slo, now, host)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating SLO %s: %w", slo.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (e *SLOEngine) evaluate(ctx context.Context, slo SLO, now time.Time, host string) (SLOResult, error) {
+	result := SLOResult{SLO: slo, Status: SLONoData}
+	filter := storage.TimeFilter{From: now.Add(-slo.window()), To: now, Host: host}
+
+	calls, errors, err := e.callCounts(ctx, slo, filter)
+	if err != nil {
+		return result, err
+	}
+	result.Calls = calls
+
+	if slo.SuccessTarget > 0 {
+		result.Success = e.objective(slo.SuccessTarget, 100-slo.SuccessTarget, calls, errors)
+		if calls > 0 {
+			result.Success.Actual = float64(calls-errors) / float64(calls) * 100
+		}
+
+		for _, w := range e.config.BurnWindows {
+			if w >= slo.window() {
+				continue
+			}
+			burnCalls, burnErrors, err := e.callCounts(ctx, slo, storage.TimeFilter{From: now.Add(-w), To: now, Host: host})
+			if err != nil {
+				return result, err
+			}
+			burn := BurnRate{Window: w, Calls: burnCalls}
+			if burnCalls > 0 {
+				burn.Rate = float64(burnErrors) / float64(burnCalls) / ((100 - slo.SuccessTarget) / 100)
+			}
+			result.BurnRates = append(result.BurnRates, burn)
+		}
+		result.FastBurn = len(result.BurnRates) > 0
+		for _, b := range result.BurnRates {
+			if b.Rate < e.config.FastBurnRate {
+				result.FastBurn = false
+			}
+		}
+		if result.FastBurn && result.Success.Status == SLOMet {
+			result.Success.Status = SLOAtRisk
+		}
+	}
+
+	if slo.LatencyTargetMs > 0 {
+		h, err := e.latencyHistogram(ctx, slo, filter)
+		if err != nil {
+			return result, err
+		}
+		result.Latency = e.objective(slo.LatencyTargetMs, 100-slo.percentile(), h.Total, h.CountAbove(slo.LatencyTargetMs))
+		result.Latency.Actual = h.Percentile(slo.percentile() / 100)
+	}
+
+	for _, o := range []*Objective{result.Success, result.Latency} {
+		if o != nil && o.Status.severity() > result.Status.severity() {
+			result.Status = o.Status
+		}
+	}
+	return result, nil
+}
+
+// objective computes the budget of an objective that allows budgetPct
+// percent of calls to be bad.
+func (e *SLOEngine) objective(target, budgetPct float64, calls, bad int64) *Objective {
+	o := &Objective{Target: target, BadCalls: bad, Status: SLONoData}
+	if calls == 0 {
+		return o
+	}
+
+	o.AllowedBadCalls = float64(calls) * budgetPct / 100
+	switch {
+	case o.AllowedBadCalls > 0:
+		o.BudgetRemaining = (1 - float64(bad)/o.AllowedBadCalls) * 100
+	case bad > 0:
+		o.BudgetRemaining = math.Inf(-1) // A 100% target has no budget
+	default:
+		o.BudgetRemaining = 100
+	}
+
+	switch {
+	case o.BudgetRemaining <= 0:
+		o.Status = SLOBreached
+	case o.BudgetRemaining < e.config.AtRiskBudget:
+		o.Status = SLOAtRisk
+	default:
+		o.Status = SLOMet
+	}
+	return o
+}
+
+// callCounts returns the calls and failed calls an SLO covers in a range.
+func (e *SLOEngine) callCounts(ctx context.Context, slo SLO, filter storage.TimeFilter) (int64, int64, error) {
+	if slo.Tool == "" {
+		stats, err := e.store.GetMCPServerStats(ctx, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, s := range stats {
+			if s.ServerName == slo.Server {
+				return s.TotalCalls, s.ErrorCount, nil
+			}
+		}
+		return 0, 0, nil
+	}
+
+	stats, err := e.store.GetToolStats(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	var calls, errors int64
+	for _, s := range stats {
+		if s.MCPServer == slo.Server && matchesTool(s.ToolName, slo) {
+			calls += s.TotalCalls
+			errors += s.ErrorCount
+		}
+	}
+	return calls, errors, nil
+}
+
+// latencyHistogram merges the latency histograms an SLO covers in a range.
+// Histograms are kept per day, so whole days at both ends are included.
+func (e *SLOEngine) latencyHistogram(ctx context.Context, slo SLO, filter storage.TimeFilter) (*storage.LatencyHistogram, error) {
+	latencies, err := e.store.GetToolLatencies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	h := storage.NewLatencyHistogram()
+	for _, tl := range latencies {
+		if tl.ServerName == slo.Server && (slo.Tool == "" || matchesTool(tl.ToolName, slo)) {
+			h.Merge(tl.Histogram)
+		}
+	}
+	return h, nil
+}
+
+// matchesTool reports whether a stored tool name is the SLO's tool, given
+// either in full ("mcp__github__search_code") or without the server prefix.
+func matchesTool(toolName string, slo SLO) bool {
+	return toolName == slo.Tool || toolName == "mcp__"+slo.Server+"__"+slo.Tool
+}
+
+// FormatWindow renders a window, such as "1h" or "7d".
+func FormatWindow(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return d.String()
+}
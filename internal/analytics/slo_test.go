@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// addCalls stores n PostToolUse events for a tool, failing the first errors.
+func addCalls(t *testing.T, store *storage.MockStore, at time.Time, server, tool string, n, errors int, durationMs int64) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := store.StoreEvent(context.Background(), &storage.Event{
+			SessionID:  "s1",
+			EventType:  "PostToolUse",
+			ToolName:   tool,
+			MCPServer:  server,
+			Success:    i >= errors,
+			DurationMs: durationMs,
+			CreatedAt:  at,
+		})
+		if err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+}
+
+func TestSLOEngine_Evaluate(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+
+	// github: 1000 calls over the week with 4 failures, then 6 failures in the last hour
+	addCalls(t, store, now.Add(-3*24*time.Hour), "github", "mcp__github__search", 990, 4, 300)
+	addCalls(t, store, now.Add(-30*time.Minute), "github", "mcp__github__search", 10, 6, 3000)
+	// fs: healthy
+	addCalls(t, store, now.Add(-2*time.Hour), "fs", "mcp__fs__read", 200, 0, 20)
+
+	slos := []SLO{
+		{Name: "github", Server: "github", SuccessTarget: 99, LatencyPercentile: 90, LatencyTargetMs: 2000},
+		{Name: "fs read", Server: "fs", Tool: "read", SuccessTarget: 99.9, LatencyTargetMs: 100},
+		{Name: "unused", Server: "slack", SuccessTarget: 99},
+	}
+	engine := NewSLOEngine(store, DefaultSLOConfig(), slos)
+
+	results, err := engine.Evaluate(context.Background(), now, "")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	github := results[0]
+	if github.Calls != 1000 || github.Success.BadCalls != 10 {
+		t.Fatalf("github counted %d calls with %d failures, want 1000 and 10", github.Calls, github.Success.BadCalls)
+	}
+	// 10 failures spend the whole budget of 1% of 1000 calls
+	if github.Success.BudgetRemaining != 0 || github.Success.Status != SLOBreached {
+		t.Errorf("github success = %+v, want budget spent", github.Success)
+	}
+	if len(github.BurnRates) != 3 || github.BurnRates[0].Window != time.Hour {
+		t.Fatalf("github burn rates = %+v, want 1h, 6h and 24h", github.BurnRates)
+	}
+	// 60% failures in the last hour burn a 1% budget 60x too fast
+	if rate := github.BurnRates[0].Rate; math.Abs(rate-60) > 0.001 || !github.FastBurn {
+		t.Errorf("github 1h burn = %.2f (fast %v), want 60 and fast", rate, github.FastBurn)
+	}
+	// 1% of calls are slower than 2s, well inside the 10% a p90 target allows
+	if github.Latency.BadCalls != 10 || github.Latency.Status != SLOMet || github.Latency.BudgetRemaining != 90 {
+		t.Errorf("github latency = %+v, want 10 slow calls and 90%% budget left", github.Latency)
+	}
+	if github.Status != SLOBreached {
+		t.Errorf("github status = %s, want breached", github.Status)
+	}
+
+	fs := results[1]
+	if fs.Status != SLOMet || fs.Success.BudgetRemaining != 100 || fs.FastBurn {
+		t.Errorf("fs result = %+v, want met with the whole budget left", fs)
+	}
+	if fs.Latency.Actual > 25 {
+		t.Errorf("fs p90 = %.1fms, want ~20ms", fs.Latency.Actual)
+	}
+
+	if results[2].Status != SLONoData {
+		t.Errorf("unused status = %s, want no_data", results[2].Status)
+	}
+}
+
+func TestSLOEngine_AtRisk(t *testing.T) {
+	now := time.Now()
+	store := storage.NewMockStore()
+
+	// 8 of 1000 calls failed days ago: 20% of a 1% budget left
+	addCalls(t, store, now.Add(-4*24*time.Hour), "github", "mcp__github__search", 1000, 8, 100)
+
+	engine := NewSLOEngine(store, DefaultSLOConfig(), []SLO{{Server: "github", SuccessTarget: 99}})
+	results, err := engine.Evaluate(context.Background(), now, "")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	r := results[0]
+	if math.Abs(r.Success.BudgetRemaining-20) > 0.001 || r.Status != SLOAtRisk {
+		t.Errorf("result = %+v (budget %.1f%%), want at risk with 20%% left", r, r.Success.BudgetRemaining)
+	}
+	if r.FastBurn {
+		t.Error("expected no fast burn without recent failures")
+	}
+}
+
+func TestSLO_Describe(t *testing.T) {
+	tests := []struct {
+		slo      SLO
+		expected string
+	}{
+		{SLO{SuccessTarget: 99, LatencyPercentile: 90, LatencyTargetMs: 2000, Window: 7 * 24 * time.Hour}, "99% success, p90 < 2000ms over 7d"},
+		{SLO{SuccessTarget: 99.5}, "99.5% success over 7d"},
+		{SLO{LatencyTargetMs: 500, Window: 24 * time.Hour}, "p90 < 500ms over 1d"},
+	}
+	for _, tt := range tests {
+		if got := tt.slo.Describe(); got != tt.expected {
+			t.Errorf("Describe() = %q, want %q", got, tt.expected)
+		}
+	}
+}
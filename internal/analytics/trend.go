@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// TrendConfig configures trend detection. A window is compared against the
+// average of the BaselineWindows equal-length windows before it.
+type TrendConfig struct {
+	BaselineWindows int     // Previous windows averaged into the baseline (default: 1)
+	MinChangePct    float64 // Smallest change reported as up or down (default: 20)
+	MinCalls        int64   // Calls needed before a trend is reported (default: 20)
+}
+
+// DefaultTrendConfig returns default configuration.
+func DefaultTrendConfig() TrendConfig {
+	return TrendConfig{
+		BaselineWindows: 1,
+		MinChangePct:    20.0,
+		MinCalls:        20,
+	}
+}
+
+// BaselineFilter returns the range the baseline for filter is drawn from:
+// the n windows of the same length immediately before it. Ranges without
+// both bounds have no baseline.
+func BaselineFilter(filter storage.TimeFilter, n int) (storage.TimeFilter, bool) {
+	if filter.From.IsZero() || filter.To.IsZero() || !filter.To.After(filter.From) {
+		return storage.TimeFilter{}, false
+	}
+	if n < 1 {
+		n = 1
+	}
+	span := filter.To.Sub(filter.From)
+	return storage.TimeFilter{
+		From: filter.From.Add(-time.Duration(n) * span),
+		To:   filter.From,
+		Host: filter.Host,
+	}, true
+}
+
+// CompareTrend compares a current value against a baseline value and
+// returns the direction and percentage change. Changes smaller than
+// MinChangePct, or drawn from fewer than MinCalls calls, are stable. A rise
+// from a zero baseline is up with a change of +Inf.
+func (c TrendConfig) CompareTrend(current, baseline float64, calls int64) (Trend, float64) {
+	if calls < c.MinCalls {
+		return TrendStable, 0
+	}
+	if baseline == 0 {
+		if current == 0 {
+			return TrendStable, 0
+		}
+		return TrendUp, math.Inf(1)
+	}
+
+	change := (current - baseline) / baseline * 100
+	switch {
+	case change >= c.MinChangePct:
+		return TrendUp, change
+	case change <= -c.MinChangePct:
+		return TrendDown, change
+	default:
+		return TrendStable, change
+	}
+}
+
+// FormatTrend renders a trend as an arrow with the percentage change, such
+// as "↑35%", "↓12%" or "→". A rise from nothing is "↑new".
+func FormatTrend(trend Trend, changePct float64) string {
+	switch trend {
+	case TrendUp:
+		if math.IsInf(changePct, 1) {
+			return "↑new"
+		}
+		return fmt.Sprintf("↑%.0f%%", changePct)
+	case TrendDown:
+		return fmt.Sprintf("↓%.0f%%", math.Abs(changePct))
+	default:
+		return "→"
+	}
+}
@@ -0,0 +1,80 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func TestBaselineFilter(t *testing.T) {
+	to := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-24 * time.Hour)
+
+	base, ok := BaselineFilter(storage.TimeFilter{From: from, To: to, Host: "laptop"}, 1)
+	if !ok {
+		t.Fatal("expected a baseline for a bounded range")
+	}
+	if !base.From.Equal(from.Add(-24*time.Hour)) || !base.To.Equal(from) || base.Host != "laptop" {
+		t.Errorf("baseline = %+v, want the previous day on the same host", base)
+	}
+
+	base, _ = BaselineFilter(storage.TimeFilter{From: from, To: to}, 7)
+	if !base.From.Equal(from.Add(-7 * 24 * time.Hour)) {
+		t.Errorf("baseline from = %v, want 7 windows back", base.From)
+	}
+
+	if _, ok := BaselineFilter(storage.TimeFilter{To: to}, 1); ok {
+		t.Error("expected no baseline for an unbounded range")
+	}
+}
+
+func TestCompareTrend(t *testing.T) {
+	config := DefaultTrendConfig()
+
+	tests := []struct {
+		name      string
+		current   float64
+		baseline  float64
+		calls     int64
+		trend     Trend
+		changePct float64
+	}{
+		{"rise", 150, 100, 150, TrendUp, 50},
+		{"fall", 60, 100, 100, TrendDown, -40},
+		{"small change", 110, 100, 110, TrendStable, 10},
+		{"too few calls", 10, 2, 10, TrendStable, 0},
+		{"new", 30, 0, 30, TrendUp, math.Inf(1)},
+		{"idle", 0, 0, 50, TrendStable, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend, changePct := config.CompareTrend(tt.current, tt.baseline, tt.calls)
+			if trend != tt.trend || changePct != tt.changePct {
+				t.Errorf("CompareTrend(%v, %v, %d) = %s %v, want %s %v",
+					tt.current, tt.baseline, tt.calls, trend, changePct, tt.trend, tt.changePct)
+			}
+		})
+	}
+}
+
+func TestFormatTrend(t *testing.T) {
+	tests := []struct {
+		trend     Trend
+		changePct float64
+		expected  string
+	}{
+		{TrendUp, 35.4, "↑35%"},
+		{TrendDown, -12, "↓12%"},
+		{TrendStable, 3, "→"},
+		{TrendUp, math.Inf(1), "↑new"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatTrend(tt.trend, tt.changePct); got != tt.expected {
+			t.Errorf("FormatTrend(%s, %v) = %q, want %q", tt.trend, tt.changePct, got, tt.expected)
+		}
+	}
+}
@@ -3,23 +3,26 @@ package analytics
 
 import (
 	"context"
+	"math"
 	"sort"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
 // ServerUtilization represents utilization metrics for an MCP server.
 type ServerUtilization struct {
-	ServerName      string
-	TotalCalls      int64
-	UtilizationPct  float64 // Percentage of total MCP calls
-	ErrorRate       float64 // Percentage of calls that failed
-	AvgLatencyMs    float64
-	LastUsedAt      time.Time
-	DaysSinceUse    int
-	Trend           Trend // Up, Down, or Stable based on recent activity
-	HealthStatus    HealthStatus
+	ServerName     string
+	TotalCalls     int64
+	UtilizationPct float64 // Percentage of total MCP calls
+	ErrorRate      float64 // Percentage of calls that failed
+	AvgLatencyMs   float64
+	LastUsedAt     time.Time
+	DaysSinceUse   int
+	Trend          Trend   // Call volume against the baseline window
+	TrendPct       float64 // Percentage change in call volume; +Inf when new
+	HealthStatus   HealthStatus
 }
 
 // Trend indicates the direction of activity change.
@@ -43,9 +46,11 @@ const (
 
 // UtilizationConfig configures utilization analysis.
 type UtilizationConfig struct {
-	UnusedThresholdDays   int     // Days without activity to consider "unused" (default: 7)
+	UnusedThresholdDays    int     // Days without activity to consider "unused" (default: 7)
 	HighErrorRateThreshold float64 // Error rate % to flag as critical (default: 10.0)
 	HighLatencyThresholdMs float64 // Latency ms to flag as slow (default: 1000)
+	RareCallThreshold      int64   // Calls in the range below which a configured server is rarely used (default: 5)
+	Trend                  TrendConfig
 }
 
 // DefaultUtilizationConfig returns default configuration.
@@ -54,13 +59,16 @@ func DefaultUtilizationConfig() UtilizationConfig {
 		UnusedThresholdDays:    7,
 		HighErrorRateThreshold: 10.0,
 		HighLatencyThresholdMs: 1000.0,
+		RareCallThreshold:      5,
+		Trend:                  DefaultTrendConfig(),
 	}
 }
 
 // UtilizationAnalyzer analyzes MCP server utilization.
 type UtilizationAnalyzer struct {
-	store  UtilizationStore
-	config UtilizationConfig
+	store     UtilizationStore
+	config    UtilizationConfig
+	inventory *mcpconfig.Inventory // Configured servers; nil when unknown
 }
 
 // UtilizationStore defines the storage interface needed for utilization analysis.
@@ -93,6 +101,11 @@ func (a *UtilizationAnalyzer) AnalyzeUtilization(ctx context.Context, filter sto
 		totalCalls += s.TotalCalls
 	}
 
+	baseline, err := a.baselineCalls(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	result := make([]ServerUtilization, len(stats))
 
@@ -122,8 +135,13 @@ func (a *UtilizationAnalyzer) AnalyzeUtilization(ctx context.Context, filter sto
 		// Determine health status
 		util.HealthStatus = a.calculateHealthStatus(util)
 
-		// Trend would require historical data comparison - default to stable
+		// Compare call volume against the baseline, when there is one
 		util.Trend = TrendStable
+		if baseline != nil {
+			previous := baseline[s.ServerName]
+			calls := int64(math.Max(float64(s.TotalCalls), previous))
+			util.Trend, util.TrendPct = a.config.Trend.CompareTrend(float64(s.TotalCalls), previous, calls)
+		}
 
 		result[i] = util
 	}
@@ -136,7 +154,29 @@ func (a *UtilizationAnalyzer) AnalyzeUtilization(ctx context.Context, filter sto
 	return result, nil
 }
 
-// GetUnusedServers returns servers that haven't been used within the threshold.
+// baselineCalls returns each server's average calls per window over the
+// baseline range for filter, or nil when the range has no baseline.
+func (a *UtilizationAnalyzer) baselineCalls(ctx context.Context, filter storage.TimeFilter) (map[string]float64, error) {
+	windows := max(a.config.Trend.BaselineWindows, 1)
+	baseFilter, ok := BaselineFilter(filter, windows)
+	if !ok {
+		return nil, nil
+	}
+
+	stats, err := a.store.GetMCPServerStats(ctx, baseFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make(map[string]float64, len(stats))
+	for _, s := range stats {
+		calls[s.ServerName] = float64(s.TotalCalls) / float64(windows)
+	}
+	return calls, nil
+}
+
+// GetUnusedServers returns servers that haven't been used within the threshold,
+// including configured servers that have never been called.
 func (a *UtilizationAnalyzer) GetUnusedServers(ctx context.Context) ([]ServerUtilization, error) {
 	// Query all time to find servers
 	allTimeFilter := storage.TimeFilter{} // No time restriction
@@ -150,7 +190,9 @@ func (a *UtilizationAnalyzer) GetUnusedServers(ctx context.Context) ([]ServerUti
 	threshold := now.AddDate(0, 0, -a.config.UnusedThresholdDays)
 
 	var unused []ServerUtilization
+	seen := make(map[string]bool, len(stats))
 	for _, s := range stats {
+		seen[s.ServerName] = true
 		// Server is unused if last used before threshold
 		if s.LastUsedAt.Before(threshold) || s.LastUsedAt.IsZero() {
 			daysSince := 0
@@ -168,8 +210,19 @@ func (a *UtilizationAnalyzer) GetUnusedServers(ctx context.Context) ([]ServerUti
 		}
 	}
 
+	if a.inventory != nil {
+		for _, id := range a.inventory.IDs() {
+			if !seen[id] {
+				unused = append(unused, ServerUtilization{
+					ServerName:   id,
+					HealthStatus: HealthUnused,
+				})
+			}
+		}
+	}
+
 	// Sort by days since use (most stale first)
-	sort.Slice(unused, func(i, j int) bool {
+	sort.SliceStable(unused, func(i, j int) bool {
 		return unused[i].DaysSinceUse > unused[j].DaysSinceUse
 	})
 
@@ -244,13 +297,13 @@ func (a *UtilizationAnalyzer) calculateHealthStatus(u ServerUtilization) HealthS
 
 // UtilizationSummary provides an overview of MCP server health.
 type UtilizationSummary struct {
-	TotalServers    int
-	HealthyServers  int
-	WarningServers  int
-	CriticalServers int
-	UnusedServers   int
-	TotalCalls      int64
-	TotalErrors     int64
+	TotalServers     int
+	HealthyServers   int
+	WarningServers   int
+	CriticalServers  int
+	UnusedServers    int
+	TotalCalls       int64
+	TotalErrors      int64
 	OverallErrorRate float64
 }
 
@@ -10,14 +10,19 @@ import (
 
 // mockUtilizationStore implements UtilizationStore for testing.
 type mockUtilizationStore struct {
-	stats []storage.MCPServerStats
-	err   error
+	stats      []storage.MCPServerStats
+	previous   []storage.MCPServerStats // Returned for ranges ending at previousTo
+	previousTo time.Time
+	err        error
 }
 
 func (m *mockUtilizationStore) GetMCPServerStats(ctx context.Context, filter storage.TimeFilter) ([]storage.MCPServerStats, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
+	if !m.previousTo.IsZero() && filter.To.Equal(m.previousTo) {
+		return m.previous, nil
+	}
 	return m.stats, nil
 }
 
@@ -405,3 +410,62 @@ func TestUtilizationPercentageCalculation(t *testing.T) {
 		t.Errorf("expected 25%% utilization, got %.2f%%", result[1].UtilizationPct)
 	}
 }
+
+func TestUtilizationAnalyzer_Trend(t *testing.T) {
+	now := time.Now()
+	filter := storage.TimeFilter{From: now.Add(-24 * time.Hour), To: now}
+
+	store := &mockUtilizationStore{
+		stats: []storage.MCPServerStats{
+			{ServerName: "growing", TotalCalls: 150, LastUsedAt: now},
+			{ServerName: "shrinking", TotalCalls: 40, LastUsedAt: now},
+			{ServerName: "steady", TotalCalls: 105, LastUsedAt: now},
+			{ServerName: "tiny", TotalCalls: 6, LastUsedAt: now},
+			{ServerName: "new", TotalCalls: 25, LastUsedAt: now},
+		},
+		previous: []storage.MCPServerStats{
+			{ServerName: "growing", TotalCalls: 100},
+			{ServerName: "shrinking", TotalCalls: 80},
+			{ServerName: "steady", TotalCalls: 100},
+			{ServerName: "tiny", TotalCalls: 1},
+		},
+		previousTo: filter.From,
+	}
+
+	analyzer := NewUtilizationAnalyzer(store, DefaultUtilizationConfig())
+	result, err := analyzer.AnalyzeUtilization(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		trend     Trend
+		formatted string
+	}{
+		"growing":   {TrendUp, "↑50%"},
+		"shrinking": {TrendDown, "↓50%"},
+		"steady":    {TrendStable, "→"},
+		"tiny":      {TrendStable, "→"}, // Too few calls to call a 6x rise a trend
+		"new":       {TrendUp, "↑new"},
+	}
+	for _, u := range result {
+		tt := tests[u.ServerName]
+		if u.Trend != tt.trend {
+			t.Errorf("%s trend = %s, want %s", u.ServerName, u.Trend, tt.trend)
+		}
+		if got := FormatTrend(u.Trend, u.TrendPct); got != tt.formatted {
+			t.Errorf("%s formatted trend = %q, want %q", u.ServerName, got, tt.formatted)
+		}
+	}
+
+	// Without a bounded range there is nothing to compare against
+	result, err = analyzer.AnalyzeUtilization(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, u := range result {
+		if u.Trend != TrendStable {
+			t.Errorf("%s trend = %s for an unbounded range, want stable", u.ServerName, u.Trend)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Package backup creates and restores snapshots of the MCP Lens database,
+// optionally bundled with the raw JSONL event files.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// Bundle entry names. Session files are stored under sessionsPrefix.
+const (
+	databaseEntry  = "data.db"
+	eventsEntry    = "events.jsonl"
+	sessionsPrefix = "sessions/"
+)
+
+// namePrefix starts the file name of every backup this package creates.
+const namePrefix = "mcp-lens-"
+
+// Snapshotter takes consistent database snapshots.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, path string) error
+}
+
+// Files locates the JSONL files bundled with a backup.
+type Files struct {
+	EventsFile  string
+	SessionsDir string // Per-session <id>.jsonl files
+}
+
+// Result describes a backup that was written.
+type Result struct {
+	Path     string
+	Bundle   bool
+	Snapshot *storage.SnapshotInfo
+	Files    int      // JSONL files included in a bundle
+	Rotated  []string // Older backups removed by a scheduled run
+	Duration time.Duration
+}
+
+// IsBundle reports whether path names a bundle rather than a bare snapshot.
+func IsBundle(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz")
+}
+
+// DefaultName returns the file name for a backup taken at t.
+func DefaultName(t time.Time, bundle bool) string {
+	name := namePrefix + t.Format("20060102-150405")
+	if bundle {
+		return name + ".tar.gz"
+	}
+	return name + ".db"
+}
+
+// Create writes a backup to path. A path ending in .tar.gz produces a bundle
+// holding the snapshot and the JSONL files; any other path gets a bare
+// database snapshot. The snapshot is taken before the JSONL files are read,
+// so a bundle never holds a sync position beyond the end of its events file.
+func Create(ctx context.Context, store Snapshotter, path string, files Files) (*Result, error) {
+	start := time.Now()
+	result := &Result{Path: path, Bundle: IsBundle(path)}
+
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	if !result.Bundle {
+		if err := store.Snapshot(ctx, path); err != nil {
+			return nil, err
+		}
+		info, err := storage.VerifySnapshot(ctx, path)
+		if err != nil {
+			os.Remove(path)
+			return nil, fmt.Errorf("verifying snapshot: %w", err)
+		}
+		result.Snapshot = info
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(path), ".backup-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshot := filepath.Join(tmpDir, databaseEntry)
+	if err := store.Snapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	info, err := storage.VerifySnapshot(ctx, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("verifying snapshot: %w", err)
+	}
+	result.Snapshot = info
+
+	entries := []bundleEntry{{name: databaseEntry, path: snapshot}}
+	if files.EventsFile != "" {
+		if _, err := os.Stat(files.EventsFile); err == nil {
+			entries = append(entries, bundleEntry{name: eventsEntry, path: files.EventsFile})
+		}
+	}
+	if files.SessionsDir != "" {
+		sessions, err := filepath.Glob(filepath.Join(files.SessionsDir, "*.jsonl"))
+		if err != nil {
+			return nil, fmt.Errorf("listing session files: %w", err)
+		}
+		for _, f := range sessions {
+			entries = append(entries, bundleEntry{name: sessionsPrefix + filepath.Base(f), path: f})
+		}
+	}
+	result.Files = len(entries) - 1
+
+	// Write beside the destination and rename, so a partial bundle is never
+	// mistaken for a complete one
+	staged := filepath.Join(tmpDir, "bundle.tar.gz")
+	if err := writeBundle(staged, entries); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(staged, path); err != nil {
+		return nil, fmt.Errorf("moving bundle into place: %w", err)
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// bundleEntry maps a bundle entry name to the file it is read from.
+type bundleEntry struct {
+	name string
+	path string
+}
+
+// writeBundle writes entries to a gzipped tar file.
+func writeBundle(path string, entries []bundleEntry) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		if err := addFile(tw, e); err != nil {
+			return fmt.Errorf("adding %s to bundle: %w", e.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("closing bundle: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("flushing bundle: %w", err)
+	}
+	return nil
+}
+
+// addFile copies one file into the tar stream. Only the bytes present when
+// the file is opened are copied, so a file still being appended to is cut at
+// a consistent length.
+func addFile(tw *tar.Writer, e bundleEntry) error {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name:    e.name,
+		Mode:    0644,
+		Size:    stat.Size(),
+		ModTime: stat.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, stat.Size())
+	return err
+}
+
+// RestoreResult describes a completed restore.
+type RestoreResult struct {
+	storage.RestoreReport
+	Files int // JSONL files restored from a bundle
+}
+
+// Restore replaces the database at dbPath with the backup at path. For a
+// bundle, its JSONL files are restored too unless files is empty. Session
+// files newer than the bundle are left in place; sync deduplicates them.
+func Restore(ctx context.Context, path string, dbPath string, files Files) (*RestoreResult, error) {
+	if !IsBundle(path) {
+		report, err := storage.RestoreSnapshot(ctx, path, dbPath)
+		if err != nil {
+			return nil, err
+		}
+		return &RestoreResult{RestoreReport: *report}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dbPath), ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extracted, err := extractBundle(path, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := extracted[databaseEntry]; !ok {
+		return nil, fmt.Errorf("%s does not contain %s", path, databaseEntry)
+	}
+
+	report, err := storage.RestoreSnapshot(ctx, extracted[databaseEntry], dbPath)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{RestoreReport: *report}
+
+	for name, src := range extracted {
+		var dst string
+		switch {
+		case name == eventsEntry && files.EventsFile != "":
+			dst = files.EventsFile
+		case strings.HasPrefix(name, sessionsPrefix) && files.SessionsDir != "":
+			dst = filepath.Join(files.SessionsDir, strings.TrimPrefix(name, sessionsPrefix))
+		default:
+			continue
+		}
+		if err := moveFile(src, dst); err != nil {
+			return result, fmt.Errorf("restoring %s: %w", name, err)
+		}
+		result.Files++
+	}
+	return result, nil
+}
+
+// extractBundle unpacks the known entries of a bundle into dir and returns
+// their paths by entry name. Unknown entries are ignored.
+func extractBundle(path string, dir string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+	defer gz.Close()
+
+	extracted := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		if !knownEntry(header.Name) {
+			continue
+		}
+
+		dst := filepath.Join(dir, strings.ReplaceAll(header.Name, "/", "_"))
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return nil, fmt.Errorf("extracting %s: %w", header.Name, err)
+		}
+		if err := out.Close(); err != nil {
+			return nil, err
+		}
+		extracted[header.Name] = dst
+	}
+	return extracted, nil
+}
+
+// knownEntry reports whether name is an entry this package writes. Session
+// entries must be a plain file name, so extraction cannot escape its target.
+func knownEntry(name string) bool {
+	if name == databaseEntry || name == eventsEntry {
+		return true
+	}
+	if !strings.HasPrefix(name, sessionsPrefix) {
+		return false
+	}
+	base := strings.TrimPrefix(name, sessionsPrefix)
+	return strings.HasSuffix(base, ".jsonl") && base == filepath.Base(base) && !strings.HasPrefix(base, ".")
+}
+
+// moveFile replaces dst with src, copying when they are on different
+// filesystems.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	staged := dst + ".restore"
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(staged, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(staged)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	return os.Rename(staged, dst)
+}
+
+// List returns the backups in dir, oldest first.
+func List(dir string) ([]string, error) {
+	var backups []string
+	for _, pattern := range []string{namePrefix + "*.db", namePrefix + "*.tar.gz"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("listing backups: %w", err)
+		}
+		backups = append(backups, matches...)
+	}
+
+	// Names embed the creation time, so name order is chronological
+	sort.Slice(backups, func(i, j int) bool {
+		return filepath.Base(backups[i]) < filepath.Base(backups[j])
+	})
+	return backups, nil
+}
+
+// Rotate deletes all but the newest keep backups in dir and returns the
+// removed paths. A keep of zero or less keeps everything.
+func Rotate(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	backups, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, path := range backups[:len(backups)-keep] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("removing %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,207 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// createTestStore creates a store with one session event in dir.
+func createTestStore(t *testing.T, dir string) (*storage.SQLiteStore, string) {
+	t.Helper()
+	dbPath := filepath.Join(dir, "data.db")
+
+	store, err := storage.NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := store.StoreEvent(context.Background(), &storage.Event{
+		SessionID: "s1", EventType: "SessionStart", CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+	return store, dbPath
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestCreateAndRestoreBundle(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, dbPath := createTestStore(t, dir)
+
+	files := Files{
+		EventsFile:  filepath.Join(dir, "events.jsonl"),
+		SessionsDir: filepath.Join(dir, "events"),
+	}
+	writeFile(t, files.EventsFile, "{\"sid\":\"s1\"}\n")
+	writeFile(t, filepath.Join(files.SessionsDir, "s1.jsonl"), "{\"sid\":\"s1\",\"type\":\"Stop\"}\n")
+
+	bundle := filepath.Join(dir, "backups", DefaultName(time.Now(), true))
+	result, err := Create(ctx, store, bundle, files)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if !result.Bundle || result.Files != 2 || result.Snapshot.Events != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	store.Close()
+
+	// Diverge from the backup
+	writeFile(t, files.EventsFile, "{\"sid\":\"s1\"}\n{\"sid\":\"s2\"}\n")
+	os.Remove(filepath.Join(files.SessionsDir, "s1.jsonl"))
+
+	restored, err := Restore(ctx, bundle, dbPath, files)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if restored.Files != 2 {
+		t.Errorf("expected 2 JSONL files restored, got %d", restored.Files)
+	}
+	if got := readFile(t, files.EventsFile); got != "{\"sid\":\"s1\"}\n" {
+		t.Errorf("expected events file from bundle, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(files.SessionsDir, "s1.jsonl")); got != "{\"sid\":\"s1\",\"type\":\"Stop\"}\n" {
+		t.Errorf("expected session file from bundle, got %q", got)
+	}
+	if _, err := storage.VerifySnapshot(ctx, dbPath); err != nil {
+		t.Errorf("restored database failed verification: %v", err)
+	}
+}
+
+func TestRestoreBundle_DBOnly(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, dbPath := createTestStore(t, dir)
+
+	files := Files{EventsFile: filepath.Join(dir, "events.jsonl")}
+	writeFile(t, files.EventsFile, "old\n")
+
+	bundle := filepath.Join(dir, "snapshot.tar.gz")
+	if _, err := Create(ctx, store, bundle, files); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	store.Close()
+
+	writeFile(t, files.EventsFile, "new\n")
+	result, err := Restore(ctx, bundle, dbPath, Files{})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if result.Files != 0 || readFile(t, files.EventsFile) != "new\n" {
+		t.Error("expected JSONL files to be left alone")
+	}
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, _ := createTestStore(t, dir)
+	defer store.Close()
+
+	path := filepath.Join(dir, "copy.db")
+	result, err := Create(ctx, store, path, Files{EventsFile: filepath.Join(dir, "events.jsonl")})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if result.Bundle || result.Snapshot.Events != 1 {
+		t.Errorf("expected a bare snapshot with 1 event, got %+v", result)
+	}
+	if _, err := Create(ctx, store, path, Files{}); err == nil {
+		t.Error("expected an existing backup not to be overwritten")
+	}
+}
+
+func TestKnownEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"data.db", true},
+		{"events.jsonl", true},
+		{"sessions/abc.jsonl", true},
+		{"sessions/../../etc/passwd.jsonl", false},
+		{"sessions/nested/abc.jsonl", false},
+		{"sessions/.hidden.jsonl", false},
+		{"manifest.json", false},
+	}
+
+	for _, tt := range tests {
+		if got := knownEntry(tt.name); got != tt.expected {
+			t.Errorf("knownEntry(%q) = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRunScheduledAndRotate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, _ := createTestStore(t, dir)
+	defer store.Close()
+
+	schedule := Schedule{Dir: filepath.Join(dir, "backups"), Interval: 24 * time.Hour, Keep: 2}
+	start := time.Now()
+
+	result, err := RunScheduled(ctx, store, schedule, Files{}, start)
+	if err != nil {
+		t.Fatalf("scheduled run failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected a backup when none exist")
+	}
+
+	result, err = RunScheduled(ctx, store, schedule, Files{}, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("scheduled run failed: %v", err)
+	}
+	if result != nil {
+		t.Error("expected no backup before the interval elapsed")
+	}
+
+	for i := 1; i <= 3; i++ {
+		now := start.Add(time.Duration(i) * 25 * time.Hour)
+		result, err := RunScheduled(ctx, store, schedule, Files{}, now)
+		if err != nil {
+			t.Fatalf("scheduled run failed: %v", err)
+		}
+		if result == nil {
+			t.Fatalf("expected backup %d once the interval elapsed", i)
+		}
+		// Stamp each backup with the simulated time the schedule compares against
+		if err := os.Chtimes(result.Path, now, now); err != nil {
+			t.Fatalf("failed to set mtime: %v", err)
+		}
+	}
+
+	backups, err := List(schedule.Dir)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected rotation to keep 2 backups, got %d", len(backups))
+	}
+	if filepath.Base(backups[1]) != DefaultName(start.Add(75*time.Hour), false) {
+		t.Errorf("expected the newest backup to be kept, got %v", backups)
+	}
+}
@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Schedule configures periodic local backups.
+type Schedule struct {
+	Dir          string
+	Interval     time.Duration
+	Keep         int  // Newest backups kept after rotation; 0 keeps all
+	IncludeJSONL bool // Write bundles instead of bare snapshots
+}
+
+// Due reports whether a backup is due in dir as of now: when dir holds no
+// backups or the newest is at least one interval old.
+func (s Schedule) Due(now time.Time) (bool, error) {
+	backups, err := List(s.Dir)
+	if err != nil {
+		return false, err
+	}
+	if len(backups) == 0 {
+		return true, nil
+	}
+
+	info, err := os.Stat(backups[len(backups)-1])
+	if err != nil {
+		return false, err
+	}
+	return !now.Before(info.ModTime().Add(s.Interval)), nil
+}
+
+// RunScheduled takes a backup if one is due and rotates old backups. It
+// returns a nil result when no backup was due.
+func RunScheduled(ctx context.Context, store Snapshotter, schedule Schedule, files Files, now time.Time) (*Result, error) {
+	due, err := schedule.Due(now)
+	if err != nil || !due {
+		return nil, err
+	}
+
+	path := filepath.Join(schedule.Dir, DefaultName(now, schedule.IncludeJSONL))
+	result, err := Create(ctx, store, path, files)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Rotated, err = Rotate(schedule.Dir, schedule.Keep)
+	return result, err
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/alerts"
+	"github.com/anthropics/mcp-lens/internal/config"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var (
+	alertsActive bool
+	alertsLimit  int
+	alertsStep   time.Duration
+)
+
+func newAlertsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alerts",
+		Short: "Show alert history",
+		Long: `List alerts raised by budgets, server health thresholds and rules in the
+[alerts] config section, newest first. Alerts are evaluated after each sync,
+in the TUI and by serve.
+
+Use --range to only list alerts fired in a time range.`,
+		RunE: runAlerts,
+	}
+
+	cmd.Flags().BoolVar(&alertsActive, "active", false, "Only show alerts that have not resolved")
+	cmd.Flags().IntVarP(&alertsLimit, "limit", "n", 50, "Maximum number of alerts to show")
+
+	cmd.AddCommand(newAlertsTestCmd())
+
+	return cmd
+}
+
+func newAlertsTestCmd() *cobra.Command {
+	var b strings.Builder
+	for _, v := range alerts.Variables() {
+		fmt.Fprintf(&b, "  %-24s %s\n", v[0], v[1])
+	}
+
+	cmd := &cobra.Command{
+		Use:   "test <rule>",
+		Short: "Replay an alert rule over past data",
+		Long: `Replay an alert rule over the --range, evaluating it every --step as if it
+had been running, and show when it would have gone pending, fired and
+resolved. Nothing is recorded or notified.
+
+The rule is the name of an [[alerts.rules]] entry or an expression, such as
+  mcp-lens alerts test 'server == "github" && error_rate_1h > 10 && calls_1h >= 5' -r 7d
+
+Windows are minutes, hours or days, such as _15m, _1h or _7d. Variables:
+` + b.String(),
+		Args: cobra.ExactArgs(1),
+		RunE: runAlertsTest,
+	}
+
+	cmd.Flags().DurationVar(&alertsStep, "step", 0, "Interval between evaluations (default: 5m up to a day, 1h beyond)")
+
+	return cmd
+}
+
+func runAlerts(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	filter := storage.AlertFilter{ActiveOnly: alertsActive, Limit: alertsLimit}
+	if cmd.Flags().Changed("range") {
+		r := parseTimeRange(timeRange)
+		filter.From, filter.To = r.From, r.To
+	}
+
+	history, err := store.GetAlerts(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("getting alerts: %w", err)
+	}
+	if len(history) == 0 {
+		if !cfg.Alerts.Enabled {
+			fmt.Println("No alerts. Alerts are off; set alerts.enabled in the config file.")
+		} else {
+			fmt.Println("No alerts.")
+		}
+		return nil
+	}
+
+	fmt.Printf("\n%-16s %-10s %-9s %-12s %s\n", "FIRED", "STATUS", "SEVERITY", "DURATION", "MESSAGE")
+	for _, a := range history {
+		status, end := "active", time.Now()
+		if !a.Active() {
+			status, end = "resolved", a.ResolvedAt
+		}
+		fmt.Printf("%-16s %-10s %-9s %-12s %s\n",
+			a.FiredAt.Format("Jan 02 15:04"), status, a.Severity,
+			formatAlertDuration(end.Sub(a.FiredAt)), truncate(a.Message, 80))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runAlertsTest(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	rules, err := newAlertRules(cfg)
+	if err != nil {
+		return err
+	}
+	var rule *alerts.Rule
+	for _, r := range rules {
+		if r.Name == args[0] {
+			rule = r
+		}
+	}
+	if rule == nil {
+		rule, err = alerts.NewRule(alerts.RuleConfig{Name: "test", Expr: args[0]})
+		if err != nil {
+			return err
+		}
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	classifier, err := newErrorClassifier(cfg)
+	if err != nil {
+		return err
+	}
+	engine := alerts.NewEngine(store, alerts.Config{})
+	engine.SetClassifier(classifier)
+
+	r := parseTimeRange(timeRange)
+	step := alertsStep
+	if step <= 0 {
+		step = 5 * time.Minute
+		if r.To.Sub(r.From) > 24*time.Hour {
+			step = time.Hour
+		}
+	}
+
+	transitions, err := engine.TestRule(context.Background(), rule, r.From, r.To, step)
+	if err != nil {
+		return fmt.Errorf("testing rule: %w", err)
+	}
+
+	fmt.Printf("\nRule %s: %s\n", rule.Name, rule.Expr)
+	fmt.Printf("Replayed from %s to %s every %s\n", r.From.Format("Jan 02 15:04"), r.To.Format("Jan 02 15:04"), step)
+	if len(transitions) == 0 {
+		fmt.Println("\nThe rule would not have fired.")
+		return nil
+	}
+
+	fmt.Printf("\n%-16s %-9s %s\n", "TIME", "STATUS", "MESSAGE")
+	fired := 0
+	for _, t := range transitions {
+		if t.Status == alerts.StatusFiring {
+			fired++
+		}
+		fmt.Printf("%-16s %-9s %s\n", t.At.Format("Jan 02 15:04"), t.Status, truncate(t.Message, 90))
+	}
+	fmt.Printf("\nWould have fired: %d\n\n", fired)
+	return nil
+}
+
+// formatAlertDuration renders how long an alert lasted, such as "2h15m".
+func formatAlertDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	d = d.Round(time.Minute)
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd%dh", int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour))
+	}
+	s := d.String()
+	return s[:len(s)-2] // Drop the zero seconds
+}
+
+// newAlertEngine creates an alert engine from the [alerts] config,
+// delivering to its webhook and command and to any extra notifiers, with
+// its rules and their channels. It returns nil if alerts are off.
+func newAlertEngine(cfg *config.Config, store alerts.Store, extra ...alerts.Notifier) (*alerts.Engine, error) {
+	ac := cfg.Alerts
+	if !ac.Enabled {
+		return nil, nil
+	}
+
+	alertConfig := alerts.Config{
+		BudgetDaily:   ac.BudgetDaily,
+		BudgetWeekly:  ac.BudgetWeekly,
+		BudgetMonthly: ac.BudgetMonthly,
+		WarnPct:       ac.WarnPct,
+		ErrorRate:     ac.ErrorRate,
+		LatencyMs:     ac.LatencyMs,
+		MinCalls:      ac.MinCalls,
+	}
+	if ac.Window != "" {
+		window, err := parseWindow(ac.Window)
+		if err != nil {
+			return nil, fmt.Errorf("alerts.window: %w", err)
+		}
+		alertConfig.Window = window
+	}
+	if ac.Repeat != "" && ac.Repeat != "0" {
+		repeat, err := parseWindow(ac.Repeat)
+		if err != nil {
+			return nil, fmt.Errorf("alerts.repeat: %w", err)
+		}
+		alertConfig.Repeat = repeat
+	}
+
+	rules, err := newAlertRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	alertConfig.Rules = rules
+	classifier, err := newErrorClassifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifiers []alerts.Notifier
+	if ac.WebhookURL != "" {
+		notifiers = append(notifiers, &alerts.Webhook{URL: ac.WebhookURL})
+	}
+	if ac.Command != "" {
+		notifiers = append(notifiers, &alerts.Command{Command: ac.Command})
+	}
+	notifiers = append(notifiers, extra...)
+
+	engine := alerts.NewEngine(store, alertConfig, notifiers...)
+	engine.SetClassifier(classifier)
+	for _, c := range ac.Channels {
+		switch {
+		case c.WebhookURL != "":
+			engine.AddChannel(alerts.Named(c.Name, &alerts.Webhook{URL: c.WebhookURL}))
+		case c.Command != "":
+			engine.AddChannel(alerts.Named(c.Name, &alerts.Command{Command: c.Command}))
+		}
+	}
+	return engine, nil
+}
+
+// newAlertRules compiles the [[alerts.rules]] in the config file, checking
+// that the channels they name exist.
+func newAlertRules(cfg *config.Config) ([]*alerts.Rule, error) {
+	channels := map[string]bool{"webhook": true, "command": true, "bell": true}
+	for i, c := range cfg.Alerts.Channels {
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("alert channel %d has no name", i+1)
+		case channels[c.Name]:
+			return nil, fmt.Errorf("alert channel %s is defined twice or shadows a built-in channel", c.Name)
+		case (c.WebhookURL == "") == (c.Command == ""):
+			return nil, fmt.Errorf("alert channel %s needs one of webhook_url or command", c.Name)
+		}
+		channels[c.Name] = true
+	}
+
+	rules := make([]*alerts.Rule, 0, len(cfg.Alerts.Rules))
+	names := make(map[string]bool)
+	for i, c := range cfg.Alerts.Rules {
+		rc := alerts.RuleConfig{
+			Name:     c.Name,
+			Expr:     c.Expr,
+			Resolve:  c.Resolve,
+			Severity: c.Severity,
+			Channels: c.Channels,
+		}
+		if rc.Name == "" {
+			return nil, fmt.Errorf("alert rule %d has no name", i+1)
+		}
+		if names[rc.Name] {
+			return nil, fmt.Errorf("alert rule %s is defined twice", rc.Name)
+		}
+		names[rc.Name] = true
+		for _, ch := range c.Channels {
+			if !channels[ch] {
+				return nil, fmt.Errorf("alert rule %s: unknown channel %q", rc.Name, ch)
+			}
+		}
+		if c.For != "" {
+			d, err := parseWindow(c.For)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: for: %w", rc.Name, err)
+			}
+			rc.For = d
+		}
+		if c.ClearFor != "" {
+			d, err := parseWindow(c.ClearFor)
+			if err != nil {
+				return nil, fmt.Errorf("alert rule %s: clear_for: %w", rc.Name, err)
+			}
+			rc.ClearFor = d
+		}
+
+		rule, err := alerts.NewRule(rc)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// printAlertResult reports the notifications and delivery failures of an
+// alert run.
+func printAlertResult(result *alerts.Result) {
+	for _, n := range result.Notifications {
+		fmt.Printf("  Alert:       %s\n", n.Text())
+	}
+	for _, err := range result.Errors {
+		fmt.Printf("  Alert error: %v\n", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+)
+
+var (
+	anomalyBaselineDays int
+	anomalyZThreshold   float64
+)
+
+func newAnomaliesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "anomalies",
+		Short: "Show servers and tools that are unusually slow or failing",
+		Long: `Compare each hour in the time range with the server's and tool's own normal
+latency and error rate for that hour of day, learned from hourly rollups over
+the preceding days, and list the hours that stand out.`,
+		RunE: runAnomalies,
+	}
+
+	defaults := analytics.DefaultAnomalyConfig()
+	cmd.Flags().IntVar(&anomalyBaselineDays, "days", defaults.BaselineDays, "Days of history baselines learn from")
+	cmd.Flags().Float64Var(&anomalyZThreshold, "z", defaults.ZThreshold, "Standard deviations above normal to flag")
+
+	return cmd
+}
+
+func runAnomalies(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	config := analytics.DefaultAnomalyConfig()
+	config.BaselineDays = anomalyBaselineDays
+	config.ZThreshold = anomalyZThreshold
+
+	detector := analytics.NewAnomalyDetector(store, config)
+	anomalies, err := detector.Detect(context.Background(), parseTimeRange(timeRange))
+	if err != nil {
+		return fmt.Errorf("detecting anomalies: %w", err)
+	}
+
+	if len(anomalies) == 0 {
+		fmt.Printf("No anomalies in the last %s.\n", timeRange)
+		return nil
+	}
+
+	fmt.Printf("\nAnomalies (last %s, against %d-day baselines)\n", timeRange, config.BaselineDays)
+	fmt.Println("─────────────────────────")
+	for _, a := range anomalies {
+		baseline := "all hours"
+		if a.Seasonal {
+			baseline = fmt.Sprintf("%02d:00", a.Hour.Hour())
+		}
+		fmt.Printf("%s  %-40s %s  [%d calls, vs %s]\n",
+			a.Hour.Format("01-02 15:04"), a.Subject(), a.Describe(), a.Calls, baseline)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/backup"
+	"github.com/anthropics/mcp-lens/internal/config"
+)
+
+var (
+	backupWithJSONL bool
+	restoreDBOnly   bool
+	restoreYes      bool
+)
+
+func newBackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [path]",
+		Short: "Snapshot the database",
+		Long: `Take a consistent online snapshot of the database with VACUUM INTO.
+
+Without a path the backup is written to the backup directory (default
+~/.mcp-lens/backups). Use --with-jsonl, or a path ending in .tar.gz, to bundle
+the raw JSONL event files with the snapshot.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runBackup,
+	}
+
+	cmd.Flags().BoolVar(&backupWithJSONL, "with-jsonl", false, "Bundle the JSONL event files with the snapshot (.tar.gz)")
+
+	return cmd
+}
+
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <path>",
+		Short: "Restore the database from a backup",
+		Long: `Verify a backup's integrity and schema version, then atomically replace the
+database with it. The replaced database is kept next to it as a .bak file.
+
+For bundles the JSONL event files are restored too, unless --db-only is set.
+Stop any running mcp-lens processes before restoring.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRestore,
+	}
+
+	cmd.Flags().BoolVar(&restoreDBOnly, "db-only", false, "Restore only the database from a bundle")
+	cmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(expandPath(cfg.BackupDir()), backup.DefaultName(time.Now(), backupWithJSONL))
+	if len(args) == 1 {
+		path = expandPath(args[0])
+		if backupWithJSONL && !backup.IsBundle(path) {
+			return fmt.Errorf("bundle path must end in .tar.gz: %s", path)
+		}
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := backup.Create(context.Background(), store, path, backupFiles(cfg))
+	if err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+
+	fmt.Printf("Backup written to %s\n", result.Path)
+	fmt.Printf("  Schema:   v%d\n", result.Snapshot.SchemaVersion)
+	fmt.Printf("  Events:   %d\n", result.Snapshot.Events)
+	fmt.Printf("  Sessions: %d\n", result.Snapshot.Sessions)
+	if result.Bundle {
+		fmt.Printf("  JSONL:    %d files\n", result.Files)
+	}
+	fmt.Printf("  Took:     %s\n", result.Duration.Round(time.Millisecond))
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	path := expandPath(args[0])
+	dbPath := expandPath(cfg.Storage.DatabasePath)
+
+	if !restoreYes {
+		fmt.Printf("This will replace %s with %s. Continue? [y/N]: ", dbPath, path)
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+
+		if response != "y" && response != "yes" {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	var files backup.Files
+	if !restoreDBOnly {
+		files = backupFiles(cfg)
+	}
+
+	result, err := backup.Restore(context.Background(), path, dbPath, files)
+	if err != nil {
+		return fmt.Errorf("restoring backup: %w", err)
+	}
+
+	fmt.Printf("Restored %s (schema v%d, %d events, %d sessions)\n",
+		path, result.Snapshot.SchemaVersion, result.Snapshot.Events, result.Snapshot.Sessions)
+	if result.Files > 0 {
+		fmt.Printf("  Restored %d JSONL files\n", result.Files)
+	}
+	if result.PreviousBackup != "" {
+		fmt.Printf("  Previous database saved to %s\n", result.PreviousBackup)
+	}
+	return nil
+}
+
+// backupFiles returns the JSONL files bundled with backups.
+func backupFiles(cfg *config.Config) backup.Files {
+	return backup.Files{
+		EventsFile:  expandPath(cfg.Storage.EventsFile),
+		SessionsDir: filepath.Join(expandPath(cfg.Storage.DataDir), "events"),
+	}
+}
+
+// runScheduledBackup takes a backup if scheduled backups are enabled and one
+// is due.
+func runScheduledBackup(ctx context.Context, cfg *config.Config, store backup.Snapshotter) (*backup.Result, error) {
+	if !cfg.Backup.Enabled {
+		return nil, nil
+	}
+
+	schedule := backup.Schedule{
+		Dir:          expandPath(cfg.BackupDir()),
+		Interval:     time.Duration(cfg.Backup.IntervalHours) * time.Hour,
+		Keep:         cfg.Backup.Keep,
+		IncludeJSONL: cfg.Backup.IncludeJSONL,
+	}
+	return backup.RunScheduled(ctx, store, schedule, backupFiles(cfg), time.Now())
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+)
+
+var contextShowTools bool
+
+func newContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "Rank MCP servers by the context their tool definitions cost",
+		Long: `Every installed MCP server's tool definitions are loaded into the context of
+every session, whether or not its tools are called. Estimate the tokens each
+server's definitions take up, from the tools it listed when last probed, and
+rank servers by what keeping them installed costs against how much they are
+used: servers never called in the range first, then by tokens per call.
+
+Each server's listed tools are compared with the tools called, showing how
+many of them are used and how many tokens the rest cost. A busy server using
+few of its tools is a candidate for a slimmer server or tool filtering.
+
+Run 'mcp-lens probe' first to capture tool definitions.`,
+		RunE: runContext,
+	}
+
+	cmd.Flags().BoolVar(&contextShowTools, "tools", false, "List the tools of each server not called in the range")
+
+	return cmd
+}
+
+func runContext(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	costs, err := analytics.NewContextCostAnalyzer(store).Analyze(context.Background(), parseTimeRange(timeRange))
+	if err != nil {
+		return fmt.Errorf("analyzing context cost: %w", err)
+	}
+	if len(costs) == 0 {
+		fmt.Println("No tool definitions recorded. Run 'mcp-lens probe' to capture them.")
+		return nil
+	}
+
+	fmt.Printf("\nContext Cost of MCP Tool Definitions (last %s)\n", timeRange)
+	fmt.Println("─────────────────────────")
+	fmt.Printf("%-24s %11s %8s %13s %8s %9s %14s %12s\n",
+		"SERVER", "TOOLS USED", "TOKENS", "UNUSED TOKENS", "CALLS", "SESSIONS", "TOTAL TOKENS", "TOKENS/CALL")
+	var unused, unusedTokens, unusedTools, unusedToolTokens int
+	for _, c := range costs {
+		perCall := "never called"
+		if !c.Unused() {
+			perCall = fmt.Sprintf("%.0f", c.TokensPerCall)
+			unusedTools += c.Tools - c.UsedTools
+			unusedToolTokens += c.UnusedTokens
+		} else {
+			unused++
+			unusedTokens += c.Tokens
+		}
+		fmt.Printf("%-24s %11s %8d %13d %8d %9d %14d %12s\n",
+			c.ServerName, fmt.Sprintf("%d/%d %3.0f%%", c.UsedTools, c.Tools, c.ToolUtilizationPct),
+			c.Tokens, c.UnusedTokens, c.Calls, c.Sessions, c.SessionTokens, perCall)
+	}
+
+	if unused > 0 {
+		fmt.Printf("\n%d servers were never called but add ~%d tokens to every session.\n", unused, unusedTokens)
+	}
+	if unusedTools > 0 {
+		fmt.Printf("%d uncalled tools of servers in use add ~%d tokens to every session.\n", unusedTools, unusedToolTokens)
+	}
+
+	if contextShowTools {
+		for _, c := range costs {
+			tools := c.UnusedTools()
+			if len(tools) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s: %d of %d tools not called\n", c.ServerName, len(tools), c.Tools)
+			for _, t := range tools {
+				lastCalled := "never called"
+				if !t.NeverCalled() {
+					lastCalled = fmt.Sprintf("%d calls before the range", t.TotalCalls)
+				}
+				fmt.Printf("  %-40s %6d tokens   %s\n", t.ToolName, t.Tokens, lastCalled)
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/metrics"
+)
+
+var (
+	forecastDays   int
+	forecastBudget float64
+)
+
+func newCostsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "costs",
+		Short: "Cost commands",
+	}
+
+	cmd.AddCommand(newCostsForecastCmd())
+
+	return cmd
+}
+
+func newCostsForecastCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Forecast spend and project this month against the budget",
+		Long: `Forecast spend from the daily cost series of the last --days complete days,
+with a trend once there is a week of data and day-of-week effects once there
+are two. Ranges are 80% prediction intervals.
+
+This month's spend is projected to the month end and set against
+alerts.budget_monthly, with the day the budget is projected to be exceeded.`,
+		RunE: runCostsForecast,
+	}
+
+	cmd.Flags().IntVar(&forecastDays, "days", 28, "Days of history to forecast from")
+	cmd.Flags().Float64Var(&forecastBudget, "budget", 0, "Monthly budget in USD (default: alerts.budget_monthly)")
+
+	return cmd
+}
+
+func runCostsForecast(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	budget := cfg.Alerts.BudgetMonthly
+	if cmd.Flags().Changed("budget") {
+		budget = forecastBudget
+	}
+
+	f, err := metrics.NewCalculator(store).GetCostForecast(context.Background(), forecastDays, budget)
+	if err != nil {
+		return fmt.Errorf("forecasting costs: %w", err)
+	}
+	if f.DataDays == 0 && f.MonthToDate == 0 {
+		fmt.Println("No spend recorded yet; costs are read from session transcripts at sync.")
+		return nil
+	}
+
+	basis := fmt.Sprintf("%d days of daily spend", f.DataDays)
+	if f.Seasonal {
+		basis += ", by day of the week"
+	}
+	fmt.Printf("\nCost Forecast (%s)\n", basis)
+	fmt.Println("─────────────────────────")
+	fmt.Printf("  Daily average:  $%.2f", f.DailyAverage)
+	if f.TrendPerDay != 0 {
+		fmt.Printf("  (trend %+.2f/day)", f.TrendPerDay)
+	}
+	fmt.Println()
+	fmt.Printf("  Next 7 days:    $%.2f  ($%.2f – $%.2f)\n", f.WeeklyEstimate, f.WeeklyLow, f.WeeklyHigh)
+	fmt.Printf("  Next 30 days:   $%.2f  ($%.2f – $%.2f)\n", f.MonthlyEstimate, f.MonthlyLow, f.MonthlyHigh)
+	fmt.Printf("  Confidence:     %s\n", f.Confidence)
+
+	fmt.Println("\nThis Month")
+	fmt.Println("─────────────────────────")
+	fmt.Printf("  So far:         $%.2f\n", f.MonthToDate)
+	fmt.Printf("  Projected end:  $%.2f  ($%.2f – $%.2f)\n", f.MonthEnd, f.MonthEndLow, f.MonthEndHigh)
+	switch {
+	case f.BudgetMonthly <= 0:
+		fmt.Println("  Budget:         none (set alerts.budget_monthly)")
+	case f.OverBudget():
+		fmt.Printf("  Budget:         $%.2f, %.0f%% projected; exceeded %s\n",
+			f.BudgetMonthly, f.BudgetPct(), f.BudgetExceedsOn.Format("Mon Jan 02"))
+	default:
+		fmt.Printf("  Budget:         $%.2f, %.0f%% projected; not projected to be exceeded\n",
+			f.BudgetMonthly, f.BudgetPct())
+	}
+
+	if f.DataDays > 0 {
+		fmt.Printf("\n%-12s %10s  %s\n", "DAY", "PROJECTED", "RANGE")
+		for _, d := range f.Days {
+			fmt.Printf("%-12s %10s  $%.2f – $%.2f\n",
+				d.Date.Format("Mon Jan 02"), fmt.Sprintf("$%.2f", d.Value), d.Low, d.High)
+		}
+	}
+	fmt.Println()
+	return nil
+}
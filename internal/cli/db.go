@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var migrateStatus bool
+
+func newDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Database maintenance commands",
+	}
+
+	cmd.AddCommand(newDBMigrateCmd())
+
+	return cmd
+}
+
+func newDBMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending schema migrations",
+		Long: `Bring the database schema up to date. A backup of the database is written
+next to it before any migration runs. Use --status to list migrations without applying them.`,
+		RunE: runDBMigrate,
+	}
+
+	cmd.Flags().BoolVar(&migrateStatus, "status", false, "Show applied and pending migrations without applying them")
+
+	return cmd
+}
+
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	dbPath := expandPath(cfg.Storage.DatabasePath)
+
+	if migrateStatus {
+		states, err := storage.ReadMigrationStatus(context.Background(), dbPath)
+		if err != nil {
+			return fmt.Errorf("reading migration status: %w", err)
+		}
+
+		fmt.Printf("\nSchema migrations (%s)\n", dbPath)
+		fmt.Println("─────────────────────────")
+		for _, st := range states {
+			status := "pending"
+			if st.Applied {
+				status = "applied"
+				if !st.AppliedAt.IsZero() {
+					status += " " + st.AppliedAt.Local().Format("2006-01-02 15:04")
+				}
+			}
+			fmt.Printf("  %3d  %-50s %s\n", st.Version, st.Description, status)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	store, err := storage.NewSQLiteStore(dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report := store.MigrationReport()
+	if len(report.Applied) == 0 {
+		fmt.Printf("Schema is up to date (version %d)\n", report.ToVersion)
+		return nil
+	}
+
+	if report.BackupPath != "" {
+		fmt.Printf("Backup: %s\n", report.BackupPath)
+	}
+	for _, m := range report.Applied {
+		fmt.Printf("Applied %d: %s\n", m.Version, m.Description)
+	}
+	fmt.Printf("Schema migrated from version %d to %d\n", report.FromVersion, report.ToVersion)
+	return nil
+}
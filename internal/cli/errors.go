@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/config"
+	"github.com/anthropics/mcp-lens/internal/errclass"
+)
+
+var errorsLimit int
+
+func newErrorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "errors",
+		Short: "Show the most common tool errors",
+		Long: `Group failed tool calls by server and normalized error message, with paths,
+IDs, and numbers stripped, and list the largest groups with example calls.
+Each group is classified (timeout, auth, rate limited, ...) using the
+built-in rules and any [[errors.rules]] in the config file.`,
+		RunE: runErrors,
+	}
+
+	cmd.Flags().IntVarP(&errorsLimit, "limit", "n", 10, "Maximum number of error clusters")
+
+	return cmd
+}
+
+func runErrors(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	classifier, err := newErrorClassifier(cfg)
+	if err != nil {
+		return err
+	}
+
+	analyzer := analytics.NewErrorAnalyzer(store, analytics.DefaultErrorAnalyzerConfig())
+	analyzer.SetClassifier(classifier)
+	clusters, err := analyzer.TopErrorClusters(context.Background(), parseTimeRange(timeRange), errorsLimit)
+	if err != nil {
+		return fmt.Errorf("clustering errors: %w", err)
+	}
+
+	if len(clusters) == 0 {
+		fmt.Printf("No tool errors in the last %s.\n", timeRange)
+		return nil
+	}
+
+	fmt.Printf("\nTop errors (last %s)\n", timeRange)
+	fmt.Println("─────────────────────────")
+	for _, c := range clusters {
+		server := c.MCPServer
+		if server == "" {
+			server = "built-in"
+		}
+		fmt.Printf("\n%5d×  %s  %s  [%s]\n", c.Count, server, c.Signature, c.Category.Label())
+		if c.Share > 0 {
+			fmt.Printf("        %.0f%% of %s errors\n", c.Share, server)
+		}
+		fmt.Printf("        first %s   last %s\n",
+			c.FirstSeen.Local().Format("2006-01-02 15:04"), c.LastSeen.Local().Format("2006-01-02 15:04"))
+		for _, e := range c.Examples {
+			fmt.Printf("        %s %-30s %s  %s\n", e.CreatedAt.Local().Format("01-02 15:04:05"),
+				e.ToolName, shortSessionID(e.SessionID), firstLine(e.Error, 80))
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// newErrorClassifier builds the error classifier from the configured rules.
+func newErrorClassifier(cfg *config.Config) (*errclass.Classifier, error) {
+	rules := make([]errclass.Rule, 0, len(cfg.Errors.Rules))
+	for _, r := range cfg.Errors.Rules {
+		rule, err := errclass.NewRule(r.Category, r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return errclass.New(rules), nil
+}
+
+// firstLine returns the first line of s, cut to at most n runes.
+func firstLine(s string, n int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if runes := []rune(line); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return line
+}
@@ -33,7 +33,7 @@ func runInit(cmd *cobra.Command, args []string) error {
       "matcher": "*",
       "hooks": [{
         "type": "command",
-        "command": "mkdir -p ~/.mcp-lens && jq -c '{ts: now | todate, sid: .session_id, type: .hook_event_name, tool: .tool_name, ok: (if .tool_response.is_error then false else true end)}' >> ~/.mcp-lens/events.jsonl"
+        "command": "mkdir -p ~/.mcp-lens && jq -c '{ts: now | todate, sid: .session_id, type: .hook_event_name, tool: .tool_name, cwd: .cwd, input: (.tool_input | tojson)[:4096], ok: (if (.tool_response.is_error? // false) then false else true end), err: (if (.tool_response.is_error? // false) then (.tool_response.content // .tool_response.error | tostring)[:4096] else null end), resp_bytes: (.tool_response | if type == \"string\" then . else tojson end | utf8bytelength)}' >> ~/.mcp-lens/events.jsonl"
       }]
     }],
     "SessionStart": [{
@@ -47,7 +47,7 @@ func runInit(cmd *cobra.Command, args []string) error {
       "matcher": "",
       "hooks": [{
         "type": "command",
-        "command": "mkdir -p ~/.mcp-lens && jq -c '{ts: now | todate, sid: .session_id, type: .hook_event_name}' >> ~/.mcp-lens/events.jsonl"
+        "command": "mkdir -p ~/.mcp-lens && jq -c '{ts: now | todate, sid: .session_id, type: .hook_event_name, transcript: .transcript_path}' >> ~/.mcp-lens/events.jsonl"
       }]
     }]
   }
@@ -71,6 +71,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Events file:    %s\n", eventsFile)
 	fmt.Printf("Database:       %s\n", dbPath)
 	fmt.Println()
+	fmt.Println("Note: the events file is not redacted. Tool inputs and errors are written")
+	fmt.Println("as Claude Code sends them (the first 4096 characters with the hook above,")
+	fmt.Println("in full with the alternative), and secrets are only masked when sync stores")
+	fmt.Println("events in the database. The file keeps the raw lines.")
+	fmt.Println()
 
 	return nil
 }
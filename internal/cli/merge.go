@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var mergeAs string
+
+func newMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <other.db|other-data-dir>",
+		Short: "Import data from another machine's database",
+		Long: `Import sessions, tool aggregates, and raw events from another mcp-lens
+database, such as one copied from another machine. Given a data directory, its
+data.db is used. The source is not modified.
+
+Every row keeps the host it was recorded on, so views can be narrowed with
+--host. Events already present are skipped, so merging the same source again
+is safe. Databases from versions before host tagging need --as to name the
+machine they came from.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runMerge,
+	}
+
+	cmd.Flags().StringVar(&mergeAs, "as", "", "Host name to tag the imported rows with")
+
+	return cmd
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	path := expandPath(args[0])
+	if info, err := os.Stat(path); err != nil {
+		return err
+	} else if info.IsDir() {
+		path = filepath.Join(path, "data.db")
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := store.Merge(context.Background(), path, storage.MergeOptions{Host: mergeAs})
+	if err != nil {
+		return fmt.Errorf("merging %s: %w", path, err)
+	}
+
+	fmt.Printf("Merged %s into %s (host %s)\n", path, expandPath(cfg.Storage.DatabasePath), store.Host())
+	fmt.Printf("  Hosts:      %s\n", strings.Join(report.Hosts, ", "))
+	fmt.Printf("  Events:     %d new, %d already present\n", report.Events, report.DuplicateEvents)
+	fmt.Printf("  Sessions:   %d\n", report.Sessions)
+	fmt.Printf("  Aggregates: %d rows\n", report.AggregateRows)
+	fmt.Printf("  Usage:      %d rows\n", report.UsageRows)
+	return nil
+}
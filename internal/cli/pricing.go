@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var pricingModel string
+
+func newPricingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pricing",
+		Short: "Model price commands",
+	}
+
+	cmd.AddCommand(newPricingShowCmd())
+
+	return cmd
+}
+
+func newPricingShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "List the effective model price table",
+		Long: `List the prices usage is charged at, in USD per million tokens: the
+[[cost.models]] entries from config.toml, then the [cost.models.<family>]
+prices, then the built-in prices.
+
+A model ID is charged at the price with the most specific matching pattern
+in effect when the usage was recorded; configured prices win ties with
+built-in ones. Use --model to see which price a model ID gets today.`,
+		RunE: runPricingShow,
+	}
+
+	cmd.Flags().StringVar(&pricingModel, "model", "", "Show the price a model ID is charged at")
+
+	return cmd
+}
+
+func runPricingShow(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	table, err := cfg.Pricing()
+	if err != nil {
+		return err
+	}
+
+	if pricingModel != "" {
+		p, ok := table.Lookup(pricingModel, time.Now())
+		if !ok {
+			fmt.Printf("No price matches %s; its usage is counted at $0.\n", pricingModel)
+			return nil
+		}
+		fmt.Printf("%s is charged at %q (%s): $%.2f input, $%.2f output, $%.2f cache write, $%.2f cache read per 1M tokens\n",
+			pricingModel, p.Match, p.Source, p.Input, p.Output, p.Input*p.CacheWrite, p.Input*p.CacheRead)
+		return nil
+	}
+
+	fmt.Println("\nModel Prices (USD per 1M tokens)")
+	fmt.Println("─────────────────────────")
+	fmt.Printf("%-28s %-10s %8s %8s %12s %11s  %s\n",
+		"MATCH", "SINCE", "INPUT", "OUTPUT", "CACHE WRITE", "CACHE READ", "SOURCE")
+	for _, p := range table.Prices() {
+		since := "-"
+		if !p.Since.IsZero() {
+			since = p.Since.Format("2006-01-02")
+		}
+		fmt.Printf("%-28s %-10s %8.2f %8.2f %12.2f %11.2f  %s\n",
+			truncate(p.Match, 28), since, p.Input, p.Output, p.Input*p.CacheWrite, p.Input*p.CacheRead, p.Source)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+	"github.com/anthropics/mcp-lens/internal/probe"
+)
+
+var probeTimeout time.Duration
+
+func newProbeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "probe [server...]",
+		Short: "Health-check configured MCP servers directly",
+		Long: `Launch each configured stdio MCP server, and connect to http servers at local
+URLs, perform the MCP initialize and tools/list handshake, and record how long
+the server took to start and answer, what it reported about itself, its tool
+definitions, and any failure. Results are stored and shown on the web MCP
+page; 'mcp-lens context' ranks servers by the context their tools cost.
+
+Servers turned off for their project or plugin are skipped unless named.`,
+		RunE: runProbe,
+	}
+
+	cmd.Flags().DurationVar(&probeTimeout, "timeout", probe.DefaultConfig().Timeout, "Time allowed for each server's handshake")
+
+	return cmd
+}
+
+func runProbe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	inv, err := loadInventory(ctx, cfg, store)
+	if err != nil {
+		return err
+	}
+
+	named := make(map[string]bool, len(args))
+	for _, a := range args {
+		named[a] = true
+	}
+
+	var servers []mcpconfig.Server
+	var skipped []string
+	for _, id := range inv.IDs() {
+		if len(named) > 0 && !named[id] {
+			continue
+		}
+		delete(named, id)
+
+		s, ok := probeTarget(inv.Locations(id), len(args) > 0)
+		if !ok {
+			skipped = append(skipped, id)
+			continue
+		}
+		servers = append(servers, s)
+	}
+	for name := range named {
+		return fmt.Errorf("no configured MCP server named %q", name)
+	}
+	if len(servers) == 0 {
+		fmt.Println("No configured MCP servers can be probed (stdio, or http at a local URL).")
+		return nil
+	}
+
+	config := probe.DefaultConfig()
+	config.Timeout = probeTimeout
+	config.ClientVersion = Version
+	fmt.Printf("Probing %d MCP servers...\n\n", len(servers))
+	results := probe.New(config).ProbeAll(ctx, servers)
+
+	failed := 0
+	for i := range results {
+		r := &results[i]
+		if err := store.StoreProbeResult(ctx, r); err != nil {
+			return fmt.Errorf("storing probe result: %w", err)
+		}
+		if r.OK() {
+			if err := store.ReplaceToolDefinitions(ctx, r.ServerName, r.Tools); err != nil {
+				return fmt.Errorf("storing tool definitions: %w", err)
+			}
+		}
+
+		if !r.OK() {
+			failed++
+			fmt.Printf("✗ %-24s %s\n", r.ServerName, r.Error)
+			continue
+		}
+		fmt.Printf("✓ %-24s %-6s started %5dms   tools/list %4dms   %3d tools   %s %s\n",
+			r.ServerName, r.Transport, r.StartupMs, r.HandshakeMs, r.ToolCount, r.InfoName, r.InfoVersion)
+	}
+	for _, id := range skipped {
+		fmt.Printf("- %-24s skipped (disabled or remote)\n", id)
+	}
+
+	fmt.Printf("\n%d ok, %d failed", len(results)-failed, failed)
+	if len(skipped) > 0 {
+		fmt.Printf(", %d skipped", len(skipped))
+	}
+	fmt.Println()
+	return nil
+}
+
+// probeTarget picks the configuration of a server to probe: the first one
+// that is probeable and enabled, or disabled too when includeDisabled.
+func probeTarget(locations []mcpconfig.Server, includeDisabled bool) (mcpconfig.Server, bool) {
+	for _, s := range locations {
+		if probe.Probeable(s) && (!s.Disabled || includeDisabled) {
+			return s, true
+		}
+	}
+	return mcpconfig.Server{}, false
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/proxy"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var (
+	proxyServerName string
+	proxyUpstream   string
+	proxyListen     string
+)
+
+func newProxyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy --name <server> {-- <command> [args...] | --http <url>}",
+		Short: "Run an MCP server behind a recording proxy",
+		Long: `Start a stdio MCP server and relay its messages to and from Claude Code
+unchanged, recording every JSON-RPC request with its exact latency, request
+and response sizes and error code, every notification, and each line the
+server writes to stderr, under the server's name.
+
+Put it in front of a server in the MCP configuration, with --name set to the
+name the server is configured under:
+
+  "github": {
+    "command": "mcp-lens",
+    "args": ["proxy", "--name", "github", "--", "npx", "-y", "@modelcontextprotocol/server-github"]
+  }
+
+The proxy exits with the server's exit status.
+
+For a remote server, --http forwards to its Streamable HTTP (or HTTP+SSE)
+URL from a local address instead, passing session headers and event streams
+through. Requests that fail upstream are recorded by cause: the HTTP status
+of an error response, or a TLS, connection or timeout failure. Point the MCP
+configuration at the local address:
+
+  mcp-lens proxy --name linear --http https://mcp.linear.app/mcp --listen 127.0.0.1:9878
+
+  "linear": {"type": "http", "url": "http://127.0.0.1:9878/"}
+
+If the database cannot be opened, messages are still relayed but nothing is
+recorded.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if proxyUpstream != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		SilenceUsage: true,
+		RunE:         runProxy,
+	}
+
+	cmd.Flags().StringVar(&proxyServerName, "name", "", "Name the server is configured under (required)")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVar(&proxyUpstream, "http", "", "Forward to a remote MCP server at this URL instead of running a command")
+	cmd.Flags().StringVar(&proxyListen, "listen", "127.0.0.1:9878", "Address the HTTP proxy listens on")
+	// Flags after the command belong to the server
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+func runProxy(cmd *cobra.Command, args []string) error {
+	var recorder proxy.Recorder = discardRecorder{}
+	var store *storage.SQLiteStore
+	if cfg, err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp-lens proxy: not recording: %v\n", err)
+	} else if store, err = openStorage(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "mcp-lens proxy: not recording: %v\n", err)
+	} else {
+		recorder = store
+	}
+
+	// Stopping the proxy stops the server, giving it time to exit cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if proxyUpstream != "" {
+		err := runHTTPProxy(ctx, recorder)
+		if store != nil {
+			store.Close()
+		}
+		return err
+	}
+
+	server := exec.CommandContext(ctx, args[0], args[1:]...)
+	server.Cancel = func() error {
+		return server.Process.Signal(os.Interrupt)
+	}
+	server.WaitDelay = 5 * time.Second
+
+	config := proxy.DefaultConfig()
+	config.ServerName = proxyServerName
+	err := proxy.RunStdio(config, recorder, server, os.Stdin, os.Stdout, os.Stderr)
+	if store != nil {
+		store.Close()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		stop()
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
+
+// runHTTPProxy serves the HTTP proxy until ctx is done, then lets open
+// requests finish.
+func runHTTPProxy(ctx context.Context, recorder proxy.Recorder) error {
+	config := proxy.DefaultConfig()
+	config.ServerName = proxyServerName
+	handler, err := proxy.NewHTTP(config, recorder, proxyUpstream)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", proxyListen)
+	if err != nil {
+		handler.Close()
+		return fmt.Errorf("listening on %s: %w", proxyListen, err)
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(os.Stderr, "mcp-lens proxy: forwarding http://%s/ to %s\n", listener.Addr(), proxyUpstream)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		// Event streams stay open until the client leaves, so only wait a
+		// little for them
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if server.Shutdown(shutdownCtx) != nil {
+			server.Close()
+		}
+		err = nil
+	}
+	if closeErr := handler.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "mcp-lens proxy: %v\n", closeErr)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving: %w", err)
+	}
+	return nil
+}
+
+// discardRecorder records nothing, for relaying without a database.
+type discardRecorder struct{}
+
+func (discardRecorder) StoreProxyCalls(ctx context.Context, calls []storage.ProxyCall) error {
+	return nil
+}
+
+func (discardRecorder) ReplaceToolDefinitions(ctx context.Context, serverName string, tools []storage.ToolDefinition) error {
+	return nil
+}
@@ -84,6 +84,9 @@ func runPurge(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("cleaning up: %w", err)
 		}
+		if _, err := store.CleanupUsage(ctx, olderThan); err != nil {
+			return fmt.Errorf("cleaning up usage: %w", err)
+		}
 
 		fmt.Printf("Deleted %d events older than %d days\n", deleted, purgeDays)
 		return nil
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+)
+
+var responsesLimit int
+
+func newResponsesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "responses",
+		Short: "Rank tools by the context their responses take up",
+		Long: `Every tool response is added to the session's context. Rank tools by the
+estimated tokens their responses took up in the range, with each tool's share
+of the total and the spread of its response sizes.
+
+Tools with responses over 10,000 tokens, the size Claude Code warns about,
+are candidates for pagination, narrower queries, or a lower output limit.
+
+Sizes are recorded from the tool_response of each PostToolUse event; events
+collected before sizes were recorded are not counted.`,
+		RunE: runResponses,
+	}
+
+	cmd.Flags().IntVarP(&responsesLimit, "limit", "n", 20, "Number of tools to show (0 for all)")
+
+	return cmd
+}
+
+func runResponses(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	consumers, err := analytics.NewContextConsumerAnalyzer(store).Analyze(context.Background(), parseTimeRange(timeRange), responsesLimit)
+	if err != nil {
+		return fmt.Errorf("analyzing response sizes: %w", err)
+	}
+	if len(consumers) == 0 {
+		fmt.Println("No tool response sizes recorded in this range.")
+		return nil
+	}
+
+	fmt.Printf("\nBiggest Context Consumers (last %s)\n", timeRange)
+	fmt.Println("─────────────────────────")
+	fmt.Printf("%-40s %-16s %8s %13s %7s %8s %8s %8s %8s %6s\n",
+		"TOOL", "SERVER", "CALLS", "TOTAL TOKENS", "SHARE", "AVG", "P50", "P90", "P99", ">10K")
+	var large int
+	for _, c := range consumers {
+		server := c.ServerName
+		if server == "" {
+			server = "-"
+		}
+		fmt.Printf("%-40s %-16s %8d %13d %6.1f%% %8.0f %8.0f %8.0f %8.0f %6d\n",
+			truncate(c.ToolName, 40), truncate(server, 16), c.Responses, c.TotalTokens, c.SharePct,
+			c.AvgTokens, c.P50Tokens, c.P90Tokens, c.P99Tokens, c.LargeResponses)
+		if c.LargeResponses > 0 {
+			large++
+		}
+	}
+
+	if large > 0 {
+		fmt.Printf("\n%d tools returned responses over %d tokens.\n", large, analytics.LargeResponseTokens)
+	}
+	fmt.Println()
+	return nil
+}
@@ -12,9 +12,12 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/anthropics/mcp-lens/internal/alerts"
 	"github.com/anthropics/mcp-lens/internal/collector"
 	"github.com/anthropics/mcp-lens/internal/config"
+	"github.com/anthropics/mcp-lens/internal/retention"
 	"github.com/anthropics/mcp-lens/internal/storage"
+	"github.com/anthropics/mcp-lens/internal/transcript"
 	"github.com/anthropics/mcp-lens/internal/tui"
 )
 
@@ -23,6 +26,7 @@ var (
 	cfgFile   string
 	dataDir   string
 	timeRange string
+	host      string
 	noColor   bool
 	refresh   int
 
@@ -46,15 +50,34 @@ Use subcommands for specific operations.`,
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Data directory (default: ~/.mcp-lens)")
 	rootCmd.PersistentFlags().StringVarP(&timeRange, "range", "r", "24h", "Time range: 1h, 24h, 7d, 30d")
+	rootCmd.PersistentFlags().StringVar(&host, "host", "", "Only show data recorded on this host (default: all hosts)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colors")
 	rootCmd.PersistentFlags().IntVar(&refresh, "refresh", 5, "TUI refresh interval in seconds")
 
 	// Add subcommands
 	rootCmd.AddCommand(newStatsCmd())
 	rootCmd.AddCommand(newTailCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newErrorsCmd())
+	rootCmd.AddCommand(newServersCmd())
+	rootCmd.AddCommand(newProbeCmd())
+	rootCmd.AddCommand(newContextCmd())
+	rootCmd.AddCommand(newResponsesCmd())
+	rootCmd.AddCommand(newCostsCmd())
+	rootCmd.AddCommand(newPricingCmd())
+	rootCmd.AddCommand(newProxyCmd())
+	rootCmd.AddCommand(newRPCCmd())
+	rootCmd.AddCommand(newAnomaliesCmd())
+	rootCmd.AddCommand(newSLOCmd())
+	rootCmd.AddCommand(newAlertsCmd())
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newSyncCmd())
+	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newPurgeCmd())
+	rootCmd.AddCommand(newBackupCmd())
+	rootCmd.AddCommand(newRestoreCmd())
+	rootCmd.AddCommand(newMergeCmd())
+	rootCmd.AddCommand(newDBCmd())
 	rootCmd.AddCommand(newVersionCmd())
 
 	return rootCmd
@@ -75,28 +98,72 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	}
 	defer store.Close()
 
+	prices, err := cfg.Pricing()
+	if err != nil {
+		return err
+	}
+
 	// Create sync engine
 	eventsFile := expandPath(cfg.Storage.EventsFile)
 	syncConfig := collector.SyncConfig{
-		EventsFile: eventsFile,
-		BatchSize:  1000,
-		DataDir:    expandPath(cfg.Storage.DataDir),
+		EventsFile:  eventsFile,
+		BatchSize:   1000,
+		DataDir:     expandPath(cfg.Storage.DataDir),
+		ProjectsDir: claudeProjectsDir(cfg),
+		Pricer:      usagePricer(prices),
 	}
 
 	// Create sync engine with store adapter
 	syncStore := &sqliteSyncAdapter{store: store}
 	syncEngine := collector.NewSyncEngine(syncConfig, syncStore)
 
+	classifier, err := newErrorClassifier(cfg)
+	if err != nil {
+		return err
+	}
+	slos, err := newSLOs(cfg)
+	if err != nil {
+		return err
+	}
+	inventory, err := loadInventory(context.Background(), cfg, store)
+	if err != nil {
+		// The dashboard still works without the configured servers
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	var bell []alerts.Notifier
+	if cfg.Alerts.Bell {
+		bell = append(bell, &alerts.Bell{Out: os.Stdout})
+	}
+	alertEngine, err := newAlertEngine(cfg, store, bell...)
+	if err != nil {
+		return err
+	}
+
 	// Create TUI app
 	tuiConfig := tui.AppConfig{
 		RefreshInterval: cfg.TUI.RefreshInterval,
 		TimeRange:       timeRange,
+		Host:            host,
 		NoColor:         noColor,
+		Classifier:      classifier,
+		SLOs:            slos,
+		Inventory:       inventory,
+		Alerts:          alertEngine,
 	}
 	if refresh > 0 {
 		tuiConfig.RefreshInterval = cfg.TUI.RefreshInterval
 	}
 
+	// Enforce retention quietly; the TUI owns the terminal
+	retentionEngine := newRetentionEngine(cfg, store)
+	retentionEngine.SetLogger(nil)
+	if _, err := retentionEngine.Run(context.Background(), time.Now()); err != nil {
+		return fmt.Errorf("applying retention: %w", err)
+	}
+	if _, err := runScheduledBackup(context.Background(), cfg, store); err != nil {
+		return fmt.Errorf("running scheduled backup: %w", err)
+	}
+
 	app := tui.NewApp(tuiConfig, store, syncEngine)
 
 	// Handle signals
@@ -118,7 +185,10 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 
 // loadConfig loads the configuration.
 func loadConfig() (*config.Config, error) {
-	cfg := config.DefaultConfig()
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
 
 	// Override data dir if specified
 	if dataDir != "" {
@@ -133,7 +203,38 @@ func loadConfig() (*config.Config, error) {
 // openStorage opens the SQLite storage.
 func openStorage(cfg *config.Config) (*storage.SQLiteStore, error) {
 	dbPath := expandPath(cfg.Storage.DatabasePath)
-	return storage.NewSQLiteStore(dbPath)
+	store, err := storage.NewSQLiteStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	store.SetRetentionPolicy(storage.RetentionPolicy{
+		RawDays:    cfg.Storage.RawRetentionDays(),
+		MinuteDays: cfg.Storage.Retention.MinuteDays,
+		HourlyDays: cfg.Storage.Retention.HourlyDays,
+	})
+	store.SetRecentEventsCapacity(cfg.Storage.Retention.RecentEvents)
+
+	if err := store.SetHost(context.Background(), cfg.Storage.Host); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("setting host: %w", err)
+	}
+	return store, nil
+}
+
+// newRetentionEngine creates a retention engine from the storage config.
+func newRetentionEngine(cfg *config.Config, store *storage.SQLiteStore) *retention.Engine {
+	policy := retention.Policy{
+		Tiers:        store.RetentionPolicy(),
+		FileDays:     cfg.Storage.FileRetentionDays(),
+		RecentEvents: cfg.Storage.Retention.RecentEvents,
+		Vacuum:       cfg.Storage.Retention.Vacuum,
+	}
+	files := retention.Files{
+		EventsFile:  expandPath(cfg.Storage.EventsFile),
+		SessionsDir: filepath.Join(expandPath(cfg.Storage.DataDir), "events"),
+	}
+	return retention.NewEngine(store, policy, files)
 }
 
 // expandPath expands ~ to home directory.
@@ -161,8 +262,16 @@ func (a *sqliteSyncAdapter) SetSyncPosition(ctx context.Context, pos int64) erro
 	return a.store.SetSyncPosition(ctx, pos)
 }
 
-func (a *sqliteSyncAdapter) UpsertToolStats(ctx context.Context, date string, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
-	return a.store.UpsertToolStats(ctx, date, toolName, serverName, calls, errors, latencyMs)
+func (a *sqliteSyncAdapter) UpsertCallRollups(ctx context.Context, timestamp time.Time, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
+	return a.store.UpsertCallRollups(ctx, timestamp, toolName, serverName, calls, errors, latencyMs)
+}
+
+func (a *sqliteSyncAdapter) UpsertLatencyHistogram(ctx context.Context, timestamp time.Time, toolName string, serverName string, durationMs int64) error {
+	return a.store.UpsertLatencyHistogram(ctx, timestamp, toolName, serverName, durationMs)
+}
+
+func (a *sqliteSyncAdapter) UpsertResponseSize(ctx context.Context, date string, toolName string, serverName string, bytes int64, tokens int64) error {
+	return a.store.UpsertResponseSize(ctx, date, toolName, serverName, bytes, tokens)
 }
 
 func (a *sqliteSyncAdapter) UpsertSession(ctx context.Context, id string, cwd string, startedAt time.Time) error {
@@ -177,6 +286,57 @@ func (a *sqliteSyncAdapter) IncrementSessionStats(ctx context.Context, id string
 	return a.store.IncrementSessionStats(ctx, id, toolCalls, errors)
 }
 
+func (a *sqliteSyncAdapter) InsertEvent(ctx context.Context, event *collector.Event, serverName string) error {
+	return a.store.InsertEvent(ctx, &storage.Event{
+		SessionID:      event.SessionID,
+		EventType:      event.EventType,
+		ToolName:       event.ToolName,
+		MCPServer:      serverName,
+		Success:        event.Success,
+		DurationMs:     event.DurationMs,
+		ToolInput:      event.Input,
+		Error:          event.Error,
+		ErrorSignature: event.ErrorSignature,
+		ResponseBytes:  event.ResponseBytes,
+		ResponseTokens: event.ResponseTokens,
+		Cwd:            event.Cwd,
+		CreatedAt:      event.Timestamp,
+	})
+}
+
+func (a *sqliteSyncAdapter) StoreTranscript(ctx context.Context, t *transcript.Transcript) error {
+	turns := make([]storage.TurnUsage, 0, len(t.Turns))
+	for _, turn := range t.Turns {
+		turns = append(turns, storage.TurnUsage{
+			MessageID:           turn.MessageID,
+			SessionID:           t.SessionID,
+			Model:               turn.Model,
+			InputTokens:         turn.Usage.InputTokens,
+			OutputTokens:        turn.Usage.OutputTokens,
+			CacheCreationTokens: turn.Usage.CacheCreationTokens,
+			CacheReadTokens:     turn.Usage.CacheReadTokens,
+			CostUSD:             turn.CostUSD,
+			CreatedAt:           turn.Timestamp,
+		})
+	}
+	calls := make([]storage.ToolCallCost, 0, len(t.ToolCalls))
+	for _, c := range t.ToolCalls {
+		calls = append(calls, storage.ToolCallCost{
+			ToolUseID:       c.ToolUseID,
+			SessionID:       t.SessionID,
+			ToolName:        c.ToolName,
+			ServerName:      collector.ExtractMCPServer(c.ToolName),
+			Model:           c.Model,
+			ResultBytes:     c.ResultBytes,
+			InputTokens:     c.InputTokens,
+			InputCostUSD:    c.InputCostUSD,
+			FollowUpCostUSD: c.FollowUpCostUSD,
+			CreatedAt:       c.Timestamp,
+		})
+	}
+	return a.store.StoreUsage(ctx, turns, calls)
+}
+
 func (a *sqliteSyncAdapter) InsertRecentEvent(ctx context.Context, timestamp time.Time, sessionID string, eventType string, toolName string, serverName string, durationMs int64, success bool) error {
 	return a.store.InsertRecentEvent(ctx, timestamp, sessionID, eventType, toolName, serverName, durationMs, success)
 }
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func newRPCCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rpc",
+		Short: "Show JSON-RPC traffic recorded by the MCP proxy",
+		Long: `Summarize the requests, notifications and stderr output recorded by
+'mcp-lens proxy' for each server, by method and tool: call counts, errors,
+exact latency and message sizes, followed by failed requests by cause.`,
+		RunE: runRPC,
+	}
+}
+
+func runRPC(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	stats, err := store.GetProxyStats(context.Background(), parseTimeRange(timeRange))
+	if err != nil {
+		return fmt.Errorf("getting proxy stats: %w", err)
+	}
+	failures, err := store.GetProxyFailures(context.Background(), parseTimeRange(timeRange))
+	if err != nil {
+		return fmt.Errorf("getting proxy failures: %w", err)
+	}
+	if len(stats) == 0 {
+		fmt.Printf("No proxied MCP traffic in the last %s. See 'mcp-lens proxy --help'.\n", timeRange)
+		return nil
+	}
+
+	fmt.Printf("\nProxied MCP Requests (last %s)\n", timeRange)
+	fmt.Println("─────────────────────────")
+	fmt.Printf("%-20s %-32s %7s %7s %10s %10s %10s %10s\n",
+		"SERVER", "METHOD", "CALLS", "ERRORS", "AVG MS", "MAX MS", "AVG REQ", "AVG RESP")
+	var notifications, stderr []storage.ProxyMethodStats
+	for _, st := range stats {
+		switch st.Kind {
+		case storage.ProxyNotification:
+			notifications = append(notifications, st)
+			continue
+		case storage.ProxyStderr:
+			stderr = append(stderr, st)
+			continue
+		}
+
+		method := st.Method
+		if st.ToolName != "" {
+			method += " " + st.ToolName
+		}
+		if st.Direction == "server" {
+			method += " (from server)"
+		}
+		fmt.Printf("%-20s %-32s %7d %7d %10.1f %10.1f %10s %10s\n",
+			st.ServerName, method, st.Calls, st.Errors, st.AvgLatencyMs, st.MaxLatencyMs,
+			formatBytes(int64(st.AvgRequestBytes)), formatBytes(int64(st.AvgResponseBytes)))
+	}
+
+	if len(failures) > 0 {
+		fmt.Println("\nFailures")
+		for _, f := range failures {
+			cause := f.Failure
+			if f.HTTPStatus != 0 {
+				cause = fmt.Sprintf("%s %d", cause, f.HTTPStatus)
+			}
+			fmt.Printf("%-20s %-16s %7d  %s\n", f.ServerName, cause, f.Count, truncate(f.LastError, 60))
+		}
+	}
+	if len(notifications) > 0 {
+		fmt.Println("\nNotifications")
+		for _, st := range notifications {
+			fmt.Printf("%-20s %-40s %7d from %s\n", st.ServerName, st.Method, st.Calls, st.Direction)
+		}
+	}
+	if len(stderr) > 0 {
+		fmt.Println("\nServer stderr")
+		for _, st := range stderr {
+			fmt.Printf("%-20s %7d lines, last at %s\n", st.ServerName, st.Calls, st.LastSeenAt.Format("Jan 02 15:04"))
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// formatBytes renders a byte count, such as "512B", "4.2KB" or "1.3MB".
+func formatBytes(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var (
+	searchServer  string
+	searchSession string
+	searchLimit   int
+)
+
+func newSearchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search tool inputs and error messages",
+		Long: `Full-text search over redacted tool inputs, error messages, and working
+directories of recorded tool calls.
+
+Every word must match. Use "quoted phrases" for exact sequences and a
+trailing * for prefix matches, e.g. mcp-lens search 'migrat* "no such table"'.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runSearch,
+	}
+
+	cmd.Flags().StringVar(&searchServer, "server", "", "Only search calls to this MCP server")
+	cmd.Flags().StringVar(&searchSession, "session", "", "Only search this session")
+	cmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results")
+
+	return cmd
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	query := storage.SearchQuery{
+		EventFilter: storage.EventFilter{
+			TimeFilter: parseTimeRange(timeRange),
+			SessionID:  searchSession,
+			Limit:      searchLimit,
+		},
+		Query: strings.Join(args, " "),
+	}
+	if searchServer != "" {
+		query.MCPServers = []string{searchServer}
+	}
+
+	results, err := store.SearchEvents(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("searching events: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No matches in the last %s.\n", timeRange)
+		return nil
+	}
+
+	for _, r := range results {
+		status := "✓"
+		if !r.Success {
+			status = "✗"
+		}
+		fmt.Printf("%s %s %-40s %s\n", r.CreatedAt.Local().Format("2006-01-02 15:04:05"), status, r.ToolName, shortSessionID(r.SessionID))
+		fmt.Printf("    %s\n", r.Snippet)
+	}
+	fmt.Printf("\n%d result(s)\n", len(results))
+	return nil
+}
+
+// shortSessionID returns the first 8 characters of a session ID.
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/collector"
+	"github.com/anthropics/mcp-lens/internal/web"
+)
+
+var (
+	servePort     int
+	serveInterval time.Duration
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the web dashboard",
+		Long: `Serve the web dashboard, syncing events and evaluating alerts at an interval
+until interrupted.`,
+		RunE: runServe,
+	}
+
+	cmd.Flags().IntVarP(&servePort, "port", "p", 0, "Port to listen on (default: server.dashboard_port)")
+	cmd.Flags().DurationVar(&serveInterval, "interval", time.Minute, "Interval between syncs")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	prices, err := cfg.Pricing()
+	if err != nil {
+		return err
+	}
+	classifier, err := newErrorClassifier(cfg)
+	if err != nil {
+		return err
+	}
+	slos, err := newSLOs(cfg)
+	if err != nil {
+		return err
+	}
+	alertEngine, err := newAlertEngine(cfg, store)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	inventory, err := loadInventory(ctx, cfg, store)
+	if err != nil {
+		// The dashboard still works without the configured servers
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	serverConfig := web.DefaultServerConfig()
+	serverConfig.Port = cfg.Server.DashboardPort
+	if servePort > 0 {
+		serverConfig.Port = servePort
+	}
+	serverConfig.BindAddress = cfg.Server.BindAddress
+	serverConfig.RefreshInterval = cfg.Dashboard.RefreshInterval
+	serverConfig.Classifier = classifier
+	serverConfig.SLOs = slos
+	serverConfig.Inventory = inventory
+	serverConfig.BudgetMonthly = cfg.Alerts.BudgetMonthly
+
+	server, err := web.NewServer(serverConfig, store)
+	if err != nil {
+		return fmt.Errorf("creating web server: %w", err)
+	}
+	if err := server.Start(ctx); err != nil {
+		return fmt.Errorf("starting web server: %w", err)
+	}
+	fmt.Printf("Serving dashboard on http://%s\n", server.Address())
+
+	syncEngine := collector.NewSyncEngine(collector.SyncConfig{
+		EventsFile:  expandPath(cfg.Storage.EventsFile),
+		BatchSize:   1000,
+		DataDir:     expandPath(cfg.Storage.DataDir),
+		ProjectsDir: claudeProjectsDir(cfg),
+		Pricer:      usagePricer(prices),
+	}, &sqliteSyncAdapter{store: store})
+
+	interval := serveInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// A failed sync or alert run is retried on the next tick
+		if _, err := syncEngine.Sync(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: syncing: %v\n", err)
+		}
+		if alertEngine != nil {
+			result, err := alertEngine.Run(ctx, time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: evaluating alerts: %v\n", err)
+			} else {
+				printAlertResult(result)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Stop(shutdownCtx)
+		case <-ticker.C:
+		}
+	}
+}
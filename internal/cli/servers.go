@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/config"
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+var serversUnusedOnly bool
+
+func newServersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "servers",
+		Short: "List configured MCP servers and how much each is used",
+		Long: `Read Claude Code's MCP configuration (~/.claude.json, each project's
+.mcp.json and installed plugins) and compare it with recorded usage, so servers
+that are configured but never or rarely called stand out, along with where
+each is configured.`,
+		RunE: runServers,
+	}
+
+	cmd.Flags().BoolVar(&serversUnusedOnly, "unused", false, "Only show never, rarely and no longer used servers")
+
+	return cmd
+}
+
+func runServers(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	inv, err := loadInventory(ctx, cfg, store)
+	if err != nil {
+		return err
+	}
+	if len(inv.Servers) == 0 {
+		fmt.Println("No MCP servers configured for Claude Code.")
+		return nil
+	}
+
+	analyzer := analytics.NewUtilizationAnalyzer(store, analytics.DefaultUtilizationConfig())
+	servers, err := analyzer.AnalyzeInventory(ctx, inv, parseTimeRange(timeRange))
+	if err != nil {
+		return fmt.Errorf("analyzing configured servers: %w", err)
+	}
+
+	fmt.Printf("\nConfigured MCP Servers (calls in the last %s)\n", timeRange)
+	fmt.Println("─────────────────────────")
+	shown := 0
+	for _, s := range servers {
+		if serversUnusedOnly && s.Usage == analytics.UsageActive {
+			continue
+		}
+		shown++
+
+		lastUsed := "never"
+		if !s.LastUsedAt.IsZero() {
+			lastUsed = fmt.Sprintf("%dd ago", s.DaysSinceUse)
+		}
+		fmt.Printf("%-12s %-24s %6d calls   last used %-9s %s\n",
+			usageLabel(s.Usage), s.ServerName, s.Calls, lastUsed, formatLocations(s.Locations))
+	}
+	if shown == 0 {
+		fmt.Println("Every configured server is in use.")
+	}
+	fmt.Println()
+	return nil
+}
+
+// loadInventory reads Claude Code's MCP configuration, checking the projects
+// in the config file, those of recorded sessions and the current directory
+// for .mcp.json files.
+func loadInventory(ctx context.Context, cfg *config.Config, store storage.Store) (*mcpconfig.Inventory, error) {
+	opts := mcpconfig.Options{
+		ConfigDir: expandPath(cfg.Claude.ConfigDir),
+		UserFile:  expandPath(cfg.Claude.UserFile),
+	}
+	for _, p := range cfg.Claude.Projects {
+		opts.Projects = append(opts.Projects, expandPath(p))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		opts.Projects = append(opts.Projects, wd)
+	}
+
+	sessions, err := store.GetSessions(ctx, storage.SessionFilter{Limit: 10000})
+	if err != nil {
+		return nil, fmt.Errorf("getting sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Cwd != "" {
+			opts.Projects = append(opts.Projects, s.Cwd)
+		}
+	}
+
+	inv, err := mcpconfig.Load(opts)
+	if err != nil {
+		return nil, fmt.Errorf("reading MCP configuration: %w", err)
+	}
+	return inv, nil
+}
+
+// usageLabel renders a usage status for the terminal.
+func usageLabel(usage analytics.UsageStatus) string {
+	switch usage {
+	case analytics.UsageNeverUsed:
+		return "never used"
+	case analytics.UsageInactive:
+		return "inactive"
+	case analytics.UsageRare:
+		return "rarely used"
+	default:
+		return "active"
+	}
+}
+
+// formatLocations lists where a server is configured, such as
+// "user, project ~/src/app (disabled)".
+func formatLocations(servers []mcpconfig.Server) string {
+	parts := make([]string, 0, len(servers))
+	for _, s := range servers {
+		loc := s.Location()
+		if s.Disabled {
+			loc += " (disabled)"
+		}
+		parts = append(parts, loc)
+	}
+	return strings.Join(parts, ", ")
+}
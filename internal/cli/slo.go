@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/config"
+)
+
+func newSLOCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "slo",
+		Short: "Show SLO compliance, error budgets and burn rates",
+		Long: `Evaluate the [[slo]] objectives in the config file over their windows and
+show each one's remaining error budget and how fast it has been burning over
+the last 1h, 6h and 24h.`,
+		RunE: runSLO,
+	}
+}
+
+func runSLO(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	slos, err := newSLOs(cfg)
+	if err != nil {
+		return err
+	}
+	if len(slos) == 0 {
+		fmt.Println("No SLOs configured. Add [[slo]] entries to the config file.")
+		return nil
+	}
+
+	store, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	engine := analytics.NewSLOEngine(store, analytics.DefaultSLOConfig(), slos)
+	results, err := engine.Evaluate(context.Background(), time.Now(), host)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nService Level Objectives")
+	fmt.Println("────────────────────────")
+	for _, r := range results {
+		fmt.Printf("\n%-10s %s  (%s)\n", sloStatusLabel(r.Status), r.SLO.Name, r.SLO.Describe())
+		if r.Status == analytics.SLONoData {
+			continue
+		}
+		if o := r.Success; o != nil {
+			fmt.Printf("           success  %.2f%% of %d calls   budget %s   %d failed of %.0f allowed\n",
+				o.Actual, r.Calls, formatBudget(o.BudgetRemaining), o.BadCalls, o.AllowedBadCalls)
+		}
+		if o := r.Latency; o != nil {
+			fmt.Printf("           latency  p%g %.0fms   budget %s   %d slower than %.0fms of %.0f allowed\n",
+				r.SLO.LatencyPercentile, o.Actual, formatBudget(o.BudgetRemaining), o.BadCalls, o.Target, o.AllowedBadCalls)
+		}
+		if len(r.BurnRates) > 0 {
+			var burns []string
+			for _, b := range r.BurnRates {
+				burns = append(burns, fmt.Sprintf("%s %.1fx", analytics.FormatWindow(b.Window), b.Rate))
+			}
+			line := strings.Join(burns, "  ")
+			if r.FastBurn {
+				line += "   FAST BURN"
+			}
+			fmt.Printf("           burn     %s\n", line)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// newSLOs builds the SLOs declared in the config file.
+func newSLOs(cfg *config.Config) ([]analytics.SLO, error) {
+	slos := make([]analytics.SLO, 0, len(cfg.SLOs))
+	for i, c := range cfg.SLOs {
+		slo := analytics.SLO{
+			Name:              c.Name,
+			Server:            c.Server,
+			Tool:              c.Tool,
+			SuccessTarget:     c.Success,
+			LatencyPercentile: c.LatencyPercentile,
+			LatencyTargetMs:   c.LatencyMs,
+		}
+		if slo.LatencyPercentile == 0 {
+			slo.LatencyPercentile = 90
+		}
+		if slo.Name == "" {
+			slo.Name = slo.Subject()
+		}
+
+		switch {
+		case c.Server == "":
+			return nil, fmt.Errorf("slo %d has no server", i+1)
+		case c.Success == 0 && c.LatencyMs == 0:
+			return nil, fmt.Errorf("slo %s has no success or latency objective", slo.Name)
+		case c.Success < 0 || c.Success >= 100:
+			return nil, fmt.Errorf("slo %s: success must be below 100%%, got %g", slo.Name, c.Success)
+		case slo.LatencyPercentile <= 0 || slo.LatencyPercentile >= 100:
+			return nil, fmt.Errorf("slo %s: latency_percentile must be between 0 and 100, got %g", slo.Name, slo.LatencyPercentile)
+		}
+
+		if c.Window != "" {
+			window, err := parseWindow(c.Window)
+			if err != nil {
+				return nil, fmt.Errorf("slo %s: %w", slo.Name, err)
+			}
+			slo.Window = window
+		}
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// parseWindow parses a duration such as "6h" or "7d".
+func parseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	return d, nil
+}
+
+// sloStatusLabel renders an SLO status for the terminal.
+func sloStatusLabel(status analytics.SLOStatus) string {
+	switch status {
+	case analytics.SLOMet:
+		return "✓ met"
+	case analytics.SLOAtRisk:
+		return "! at risk"
+	case analytics.SLOBreached:
+		return "✗ breached"
+	default:
+		return "- no data"
+	}
+}
+
+// formatBudget renders the remaining error budget, such as "42% left".
+func formatBudget(remaining float64) string {
+	if math.IsInf(remaining, -1) || remaining <= 0 {
+		return "spent"
+	}
+	return fmt.Sprintf("%.0f%% left", remaining)
+}
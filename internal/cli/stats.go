@@ -73,7 +73,9 @@ func runStats(cmd *cobra.Command, args []string) error {
 			if i >= 5 {
 				break
 			}
-			fmt.Printf("  %-12s %5d calls   avg %dms\n", s.ServerName, s.TotalCalls, int(s.AvgLatencyMs))
+			fmt.Printf("  %-12s %5d calls   avg %dms   p50 %dms   p90 %dms   p99 %dms\n",
+				s.ServerName, s.TotalCalls, int(s.AvgLatencyMs),
+				int(s.P50LatencyMs), int(s.P90LatencyMs), int(s.P99LatencyMs))
 		}
 	} else {
 		fmt.Println("\n(No MCP server data)")
@@ -103,5 +105,6 @@ func parseTimeRange(r string) storage.TimeFilter {
 	return storage.TimeFilter{
 		From: from,
 		To:   now,
+		Host: host,
 	}
 }
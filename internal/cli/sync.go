@@ -3,10 +3,16 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/anthropics/mcp-lens/internal/collector"
+	"github.com/anthropics/mcp-lens/internal/config"
+	"github.com/anthropics/mcp-lens/internal/pricing"
+	"github.com/anthropics/mcp-lens/internal/transcript"
 )
 
 var resetSync bool
@@ -36,11 +42,18 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 	defer store.Close()
 
+	prices, err := cfg.Pricing()
+	if err != nil {
+		return err
+	}
+
 	eventsFile := expandPath(cfg.Storage.EventsFile)
 	syncConfig := collector.SyncConfig{
-		EventsFile: eventsFile,
-		BatchSize:  1000,
-		DataDir:    expandPath(cfg.Storage.DataDir),
+		EventsFile:  eventsFile,
+		BatchSize:   1000,
+		DataDir:     expandPath(cfg.Storage.DataDir),
+		ProjectsDir: claudeProjectsDir(cfg),
+		Pricer:      usagePricer(prices),
 	}
 
 	syncStore := &sqliteSyncAdapter{store: store}
@@ -62,6 +75,9 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Processed %d events in %s\n", result.EventsProcessed, result.Duration)
+	if result.TranscriptsRead > 0 {
+		fmt.Printf("  Transcripts: %d sessions' token usage read\n", result.TranscriptsRead)
+	}
 
 	// Show validation and deduplication stats
 	if result.EventsSkipped > 0 {
@@ -78,6 +94,40 @@ func runSync(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Errors:      %d\n", len(result.Errors))
 	}
 
+	// Enforce retention across tables and files
+	retentionEngine := newRetentionEngine(cfg, store)
+	retentionEngine.SetLogger(func(format string, args ...interface{}) {
+		fmt.Printf("  "+format+"\n", args...)
+	})
+	if _, err := retentionEngine.Run(ctx, time.Now()); err != nil {
+		return fmt.Errorf("applying retention: %w", err)
+	}
+
+	// Take a scheduled backup once the data is synced and trimmed
+	backupResult, err := runScheduledBackup(ctx, cfg, store)
+	if err != nil {
+		return fmt.Errorf("running scheduled backup: %w", err)
+	}
+	if backupResult != nil {
+		fmt.Printf("  Backup:      %s\n", backupResult.Path)
+		for _, path := range backupResult.Rotated {
+			fmt.Printf("  Rotated:     %s\n", path)
+		}
+	}
+
+	// Evaluate alerts against the synced data
+	alertEngine, err := newAlertEngine(cfg, store)
+	if err != nil {
+		return err
+	}
+	if alertEngine != nil {
+		alertResult, err := alertEngine.Run(ctx, time.Now())
+		if err != nil {
+			return fmt.Errorf("evaluating alerts: %w", err)
+		}
+		printAlertResult(alertResult)
+	}
+
 	// Show warnings if verbose (but limit to first 5)
 	if len(result.Warnings) > 0 {
 		fmt.Printf("\nValidation warnings:\n")
@@ -92,3 +142,32 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// claudeProjectsDir returns the directory Claude Code keeps session
+// transcripts in, or "" if it cannot be found.
+func claudeProjectsDir(cfg *config.Config) string {
+	dir := expandPath(cfg.Claude.ConfigDir)
+	if dir == "" {
+		dir = os.Getenv("CLAUDE_CONFIG_DIR")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".claude")
+	}
+	return filepath.Join(dir, "projects")
+}
+
+// usagePricer prices transcript usage with the configured price table.
+func usagePricer(table *pricing.Table) transcript.Pricer {
+	return func(model string, at time.Time, u transcript.Usage) float64 {
+		return table.Cost(model, at, pricing.Usage{
+			InputTokens:         u.InputTokens,
+			OutputTokens:        u.OutputTokens,
+			CacheCreationTokens: u.CacheCreationTokens,
+			CacheReadTokens:     u.CacheReadTokens,
+		})
+	}
+}
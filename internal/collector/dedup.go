@@ -83,14 +83,14 @@ func (v *EventValidator) Validate(event *Event) bool {
 // isValidEventType checks if the event type is recognized.
 func isValidEventType(eventType string) bool {
 	validTypes := map[string]bool{
-		"SessionStart":  true,
-		"SessionEnd":    true,
-		"PreToolUse":    true,
-		"PostToolUse":   true,
-		"Stop":          true,
-		"SubagentStop":  true,
-		"Notification":  true,
-		"PreCompact":    true,
+		"SessionStart": true,
+		"SessionEnd":   true,
+		"PreToolUse":   true,
+		"PostToolUse":  true,
+		"Stop":         true,
+		"SubagentStop": true,
+		"Notification": true,
+		"PreCompact":   true,
 	}
 	return validTypes[eventType]
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"strings"
 	"time"
+
+	"github.com/anthropics/mcp-lens/internal/tokens"
 )
 
 // Event represents a Claude Code hook event in minimal JSONL format.
@@ -16,6 +18,21 @@ type Event struct {
 	DurationMs int64     `json:"dur_ms,omitempty"`
 	Success    bool      `json:"ok,omitempty"`
 	Cwd        string    `json:"cwd,omitempty"`
+	Input      string    `json:"input,omitempty"` // Tool input as JSON text
+	Error      string    `json:"err,omitempty"`   // Error text of a failed tool call
+
+	// TranscriptPath is the session transcript, logged on Stop and
+	// SessionEnd so sync can read the turn's token usage
+	TranscriptPath string `json:"transcript,omitempty"`
+
+	// Size of the tool response. Hooks that log only the length leave the
+	// token estimate to sync.
+	ResponseBytes  int64 `json:"resp_bytes,omitempty"`
+	ResponseTokens int64 `json:"resp_tokens,omitempty"`
+
+	// ErrorSignature is the normalized error of a failed call, derived
+	// during sync rather than logged
+	ErrorSignature string `json:"-"`
 }
 
 // FullEvent represents a complete Claude Code hook event payload.
@@ -36,6 +53,9 @@ func ParseEvent(line []byte) (*Event, error) {
 	// Try minimal format first
 	var event Event
 	if err := json.Unmarshal(line, &event); err == nil && event.SessionID != "" && event.EventType != "" {
+		if event.ResponseTokens == 0 {
+			event.ResponseTokens = tokens.EstimateBytes(event.ResponseBytes)
+		}
 		return &event, nil
 	}
 
@@ -53,19 +73,53 @@ func ParseEvent(line []byte) (*Event, error) {
 		ToolName:  full.ToolName,
 		Cwd:       full.Cwd,
 		Success:   true,
+
+		TranscriptPath: full.TranscriptPath,
+	}
+
+	if len(full.ToolInput) > 0 {
+		if data, err := json.Marshal(full.ToolInput); err == nil {
+			event.Input = string(data)
+		}
 	}
 
 	// Check for errors in tool response (can be map or string)
 	if respMap, ok := full.ToolResponse.(map[string]interface{}); ok {
 		if isError, ok := respMap["is_error"].(bool); ok && isError {
 			event.Success = false
+			event.Error = responseErrorText(respMap)
 		}
 	}
 	// If tool_response is a string, assume success (MCP tools return JSON string)
 
+	var raw struct {
+		ToolResponse json.RawMessage `json:"tool_response"`
+	}
+	if json.Unmarshal(line, &raw) == nil {
+		event.ResponseBytes, event.ResponseTokens = tokens.Response(raw.ToolResponse)
+	}
+
 	return &event, nil
 }
 
+// responseErrorText returns the error message of a failed tool response,
+// taken from its error or content field.
+func responseErrorText(resp map[string]interface{}) string {
+	for _, key := range []string{"error", "content"} {
+		switch v := resp[key].(type) {
+		case nil:
+			continue
+		case string:
+			return v
+		default:
+			if data, err := json.Marshal(v); err == nil {
+				return string(data)
+			}
+		}
+	}
+	return ""
+}
+
 // ExtractMCPServer extracts the MCP server name from a tool name.
 // Tool names follow the pattern: mcp__SERVER__tool_name
 // Returns empty string for built-in tools (Read, Write, Bash, etc.)
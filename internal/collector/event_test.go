@@ -54,6 +54,48 @@ func TestParseEvent_FullFormat(t *testing.T) {
 	}
 }
 
+func TestParseEvent_ResponseSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantBytes  int64
+		wantTokens int64
+	}{
+		{
+			name:      "content blocks",
+			input:     `{"session_id":"s","hook_event_name":"PostToolUse","tool_name":"mcp__github__get_issue","tool_response":[{"type":"text","text":"issue body"}]}`,
+			wantBytes: int64(len(`[{"type":"text","text":"issue body"}]`)),
+		},
+		{
+			name:      "string",
+			input:     `{"session_id":"s","hook_event_name":"PostToolUse","tool_name":"mcp__github__get_issue","tool_response":"issue body"}`,
+			wantBytes: 10, wantTokens: 2,
+		},
+		{
+			name:  "no response",
+			input: `{"session_id":"s","hook_event_name":"SessionStart"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseEvent([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if event.ResponseBytes != tt.wantBytes {
+				t.Errorf("ResponseBytes = %d, want %d", event.ResponseBytes, tt.wantBytes)
+			}
+			if tt.wantTokens != 0 && event.ResponseTokens != tt.wantTokens {
+				t.Errorf("ResponseTokens = %d, want %d", event.ResponseTokens, tt.wantTokens)
+			}
+			if (event.ResponseBytes > 0) != (event.ResponseTokens > 0) {
+				t.Errorf("expected a token estimate with every size, got %d bytes %d tokens", event.ResponseBytes, event.ResponseTokens)
+			}
+		})
+	}
+}
+
 func TestParseEvent_FullFormatWithError(t *testing.T) {
 	input := []byte(`{
 		"session_id": "sess-789",
@@ -72,6 +114,28 @@ func TestParseEvent_FullFormatWithError(t *testing.T) {
 	}
 }
 
+func TestParseEvent_FullFormatInputAndError(t *testing.T) {
+	input := []byte(`{
+		"session_id": "sess-789",
+		"hook_event_name": "PostToolUse",
+		"tool_name": "mcp__postgres__query",
+		"tool_input": {"sql": "SELECT 1"},
+		"tool_response": {"is_error": true, "content": "relation \"users\" does not exist"}
+	}`)
+
+	event, err := ParseEvent(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if event.Input != `{"sql":"SELECT 1"}` {
+		t.Errorf("expected tool input as JSON, got %q", event.Input)
+	}
+	if event.Error != `relation "users" does not exist` {
+		t.Errorf("expected error text from content, got %q", event.Error)
+	}
+}
+
 func TestParseEvent_InvalidJSON(t *testing.T) {
 	input := []byte(`{invalid json}`)
 
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/anthropics/mcp-lens/internal/errsig"
+	"github.com/anthropics/mcp-lens/internal/redact"
+	"github.com/anthropics/mcp-lens/internal/transcript"
 )
 
 // SyncEngine processes JSONL events into SQLite aggregations.
@@ -12,13 +16,22 @@ type SyncEngine struct {
 	store     SyncStore
 	config    SyncConfig
 	validator *EventValidator
+
+	// Transcripts of sessions whose turns ended in this sync, by session ID
+	transcripts map[string]string
 }
 
 // SyncConfig configures the sync engine.
 type SyncConfig struct {
-	EventsFile   string
-	BatchSize    int
-	DataDir      string
+	EventsFile string
+	BatchSize  int
+	DataDir    string
+
+	// ProjectsDir is Claude Code's projects directory, searched for the
+	// transcripts of sessions whose events did not name one
+	ProjectsDir string
+	// Pricer prices the usage read from transcripts; nil records no cost
+	Pricer transcript.Pricer
 }
 
 // DefaultSyncConfig returns default sync configuration.
@@ -37,12 +50,16 @@ type SyncStore interface {
 	SetSyncPosition(ctx context.Context, pos int64) error
 
 	// Aggregation
-	UpsertToolStats(ctx context.Context, date string, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error
+	StoreTranscript(ctx context.Context, t *transcript.Transcript) error
+	UpsertCallRollups(ctx context.Context, timestamp time.Time, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error
+	UpsertLatencyHistogram(ctx context.Context, timestamp time.Time, toolName string, serverName string, durationMs int64) error
+	UpsertResponseSize(ctx context.Context, date string, toolName string, serverName string, bytes int64, tokens int64) error
 	UpsertSession(ctx context.Context, id string, cwd string, startedAt time.Time) error
 	UpdateSessionEnd(ctx context.Context, id string, endedAt time.Time) error
 	IncrementSessionStats(ctx context.Context, id string, toolCalls int64, errors int64) error
 
-	// Recent events
+	// Raw events and recent events
+	InsertEvent(ctx context.Context, event *Event, serverName string) error
 	InsertRecentEvent(ctx context.Context, timestamp time.Time, sessionID string, eventType string, toolName string, serverName string, durationMs int64, success bool) error
 
 	// Deduplication
@@ -66,6 +83,7 @@ type SyncResult struct {
 	EventsSkipped   int64 // Invalid or duplicate events
 	DuplicatesFound int64
 	InvalidEvents   int64
+	TranscriptsRead int64 // Session transcripts whose usage was stored
 	NewPosition     int64
 	Duration        time.Duration
 	Errors          []error
@@ -109,6 +127,9 @@ func (s *SyncEngine) Sync(ctx context.Context) (*SyncResult, error) {
 		result.EventsProcessed += int64(len(batch))
 	}
 
+	// Read the usage of the turns that ended
+	s.syncTranscripts(ctx, result)
+
 	// Collect validation warnings
 	result.Warnings = append(result.Warnings, s.validator.Warnings...)
 
@@ -162,27 +183,58 @@ func (s *SyncEngine) processBatch(ctx context.Context, events []*Event, result *
 
 // processEvent processes a single event.
 func (s *SyncEngine) processEvent(ctx context.Context, event *Event) error {
+	// Every event except PreToolUse goes to the raw events table, matching
+	// what the hook processor stores. PostToolUse carries the full call.
+	// Inputs and errors are redacted before they reach the search index.
+	if event.EventType != "PreToolUse" {
+		serverName := ExtractMCPServer(event.ToolName)
+		redacted := *event
+		redacted.Input = redact.String(event.Input)
+		redacted.Error = redact.String(event.Error)
+		if event.EventType == "PostToolUse" && !event.Success {
+			redacted.ErrorSignature = errsig.Signature(redacted.Error)
+		}
+		if err := s.store.InsertEvent(ctx, &redacted, serverName); err != nil {
+			return err
+		}
+	}
+
 	switch event.EventType {
 	case "SessionStart":
 		return s.store.UpsertSession(ctx, event.SessionID, event.Cwd, event.Timestamp)
 
 	case "SessionEnd", "Stop":
+		s.noteTranscript(event)
 		return s.store.UpdateSessionEnd(ctx, event.SessionID, event.Timestamp)
 
 	case "PostToolUse":
 		// Extract MCP server
 		serverName := ExtractMCPServer(event.ToolName)
 
-		// Update tool stats
-		date := event.Timestamp.Format("2006-01-02")
+		// Update minute, hour, and day rollups
 		var errors int64
 		if !event.Success {
 			errors = 1
 		}
-		if err := s.store.UpsertToolStats(ctx, date, event.ToolName, serverName, 1, errors, event.DurationMs); err != nil {
+		if err := s.store.UpsertCallRollups(ctx, event.Timestamp, event.ToolName, serverName, 1, errors, event.DurationMs); err != nil {
 			return err
 		}
 
+		// Update latency histogram (only when the hook reported a duration)
+		if event.DurationMs > 0 {
+			if err := s.store.UpsertLatencyHistogram(ctx, event.Timestamp, event.ToolName, serverName, event.DurationMs); err != nil {
+				return err
+			}
+		}
+
+		// Update response size histogram (only when the hook logged the response)
+		if event.ResponseBytes > 0 {
+			date := event.Timestamp.Local().Format("2006-01-02")
+			if err := s.store.UpsertResponseSize(ctx, date, event.ToolName, serverName, event.ResponseBytes, event.ResponseTokens); err != nil {
+				return err
+			}
+		}
+
 		// Update session stats
 		if err := s.store.IncrementSessionStats(ctx, event.SessionID, 1, errors); err != nil {
 			return err
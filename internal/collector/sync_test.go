@@ -2,17 +2,26 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/anthropics/mcp-lens/internal/errsig"
+	"github.com/anthropics/mcp-lens/internal/transcript"
 )
 
 // MockSyncStore implements SyncStore for testing.
 type MockSyncStore struct {
 	syncPosition     int64
 	toolStats        map[string]*mockToolStat
+	latencies        map[string][]int64
+	responseSizes    map[string][][2]int64 // Bytes and tokens of each response
+	transcripts      []*transcript.Transcript
 	sessions         map[string]*mockSession
+	events           []*Event
 	recentEvents     []*Event
 	fingerprints     map[string]time.Time
 	upsertCalls      int
@@ -26,20 +35,22 @@ type mockToolStat struct {
 }
 
 type mockSession struct {
-	id         string
-	cwd        string
-	startedAt  time.Time
-	endedAt    *time.Time
-	toolCalls  int64
-	errors     int64
+	id        string
+	cwd       string
+	startedAt time.Time
+	endedAt   *time.Time
+	toolCalls int64
+	errors    int64
 }
 
 func NewMockSyncStore() *MockSyncStore {
 	return &MockSyncStore{
-		toolStats:    make(map[string]*mockToolStat),
-		sessions:     make(map[string]*mockSession),
-		recentEvents: make([]*Event, 0),
-		fingerprints: make(map[string]time.Time),
+		toolStats:     make(map[string]*mockToolStat),
+		latencies:     make(map[string][]int64),
+		responseSizes: make(map[string][][2]int64),
+		sessions:      make(map[string]*mockSession),
+		recentEvents:  make([]*Event, 0),
+		fingerprints:  make(map[string]time.Time),
 	}
 }
 
@@ -52,9 +63,9 @@ func (m *MockSyncStore) SetSyncPosition(ctx context.Context, pos int64) error {
 	return nil
 }
 
-func (m *MockSyncStore) UpsertToolStats(ctx context.Context, date string, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
+func (m *MockSyncStore) UpsertCallRollups(ctx context.Context, timestamp time.Time, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
 	m.upsertCalls++
-	key := date + "|" + toolName
+	key := timestamp.Format("2006-01-02") + "|" + toolName
 	if m.toolStats[key] == nil {
 		m.toolStats[key] = &mockToolStat{}
 	}
@@ -64,6 +75,23 @@ func (m *MockSyncStore) UpsertToolStats(ctx context.Context, date string, toolNa
 	return nil
 }
 
+func (m *MockSyncStore) UpsertLatencyHistogram(ctx context.Context, timestamp time.Time, toolName string, serverName string, durationMs int64) error {
+	key := timestamp.Local().Format("2006-01-02") + "|" + toolName
+	m.latencies[key] = append(m.latencies[key], durationMs)
+	return nil
+}
+
+func (m *MockSyncStore) UpsertResponseSize(ctx context.Context, date string, toolName string, serverName string, bytes int64, tokens int64) error {
+	key := date + "|" + toolName
+	m.responseSizes[key] = append(m.responseSizes[key], [2]int64{bytes, tokens})
+	return nil
+}
+
+func (m *MockSyncStore) StoreTranscript(ctx context.Context, t *transcript.Transcript) error {
+	m.transcripts = append(m.transcripts, t)
+	return nil
+}
+
 func (m *MockSyncStore) UpsertSession(ctx context.Context, id string, cwd string, startedAt time.Time) error {
 	m.sessions[id] = &mockSession{
 		id:        id,
@@ -88,6 +116,12 @@ func (m *MockSyncStore) IncrementSessionStats(ctx context.Context, id string, to
 	return nil
 }
 
+func (m *MockSyncStore) InsertEvent(ctx context.Context, event *Event, serverName string) error {
+	stored := *event
+	m.events = append(m.events, &stored)
+	return nil
+}
+
 func (m *MockSyncStore) InsertRecentEvent(ctx context.Context, timestamp time.Time, sessionID string, eventType string, toolName string, serverName string, durationMs int64, success bool) error {
 	m.insertEventCalls++
 	event := &Event{
@@ -163,6 +197,177 @@ func TestSyncEngine_Sync_NewEvents(t *testing.T) {
 	if store.insertEventCalls != 2 {
 		t.Errorf("expected 2 recent event inserts, got %d", store.insertEventCalls)
 	}
+
+	// Check every event reached the raw events table
+	if len(store.events) != 4 {
+		t.Errorf("expected 4 raw events, got %d", len(store.events))
+	}
+
+	// Check latencies recorded in histograms
+	if got := store.latencies["2026-01-10|mcp__github__create_issue"]; len(got) != 1 || got[0] != 200 {
+		t.Errorf("expected one 200ms latency for create_issue, got %v", got)
+	}
+}
+
+func TestSyncEngine_LatencyHistogramSkipsUnknownDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+
+	content := `{"ts":"2026-01-10T10:01:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__github__get_issue","ok":true}
+{"ts":"2026-01-10T10:02:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__github__get_issue","ok":true,"dur_ms":120}
+`
+	if err := os.WriteFile(eventsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := NewMockSyncStore()
+	engine := NewSyncEngine(SyncConfig{EventsFile: eventsFile, BatchSize: 1000}, store)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := store.latencies["2026-01-10|mcp__github__get_issue"]
+	if len(got) != 1 || got[0] != 120 {
+		t.Errorf("expected only the 120ms call in the histogram, got %v", got)
+	}
+}
+
+func TestSyncEngine_ResponseSizes(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+
+	content := `{"ts":"2026-01-10T10:01:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__github__get_issue","ok":true}
+{"ts":"2026-01-10T10:02:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__github__get_issue","ok":true,"resp_bytes":4000}
+{"ts":"2026-01-10T10:03:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__github__get_issue","ok":true,"resp_bytes":800,"resp_tokens":250}
+`
+	if err := os.WriteFile(eventsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := NewMockSyncStore()
+	engine := NewSyncEngine(SyncConfig{EventsFile: eventsFile, BatchSize: 1000}, store)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Sizes logged without a token estimate are estimated from their length
+	got := store.responseSizes["2026-01-10|mcp__github__get_issue"]
+	if len(got) != 2 || got[0] != [2]int64{4000, 1000} || got[1] != [2]int64{800, 250} {
+		t.Errorf("expected the two logged responses, got %v", got)
+	}
+	if len(store.events) != 3 || store.events[1].ResponseBytes != 4000 || store.events[1].ResponseTokens != 1000 {
+		t.Errorf("expected response sizes on the raw events, got %+v", store.events)
+	}
+}
+
+func TestSyncEngine_Transcripts(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+	projectsDir := filepath.Join(tmpDir, "projects")
+
+	turn := `{"type":"assistant","sessionId":"%s","timestamp":"2026-01-10T10:00:00Z","message":{"id":"msg_%s","model":"claude-sonnet-4-5","content":[],"usage":{"input_tokens":10,"output_tokens":100}}}` + "\n"
+	logged := filepath.Join(tmpDir, "logged.jsonl")
+	if err := os.WriteFile(logged, []byte(fmt.Sprintf(turn, "sess-1", "1")), 0644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectsDir, "-work-app"), 0755); err != nil {
+		t.Fatalf("failed to create projects dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectsDir, "-work-app", "sess-2.jsonl"), []byte(fmt.Sprintf(turn, "sess-2", "2")), 0644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+
+	// sess-1 logs its transcript on each Stop; sess-2's is found by session
+	// ID; sess-3 has none
+	content := fmt.Sprintf(`{"ts":"2026-01-10T10:01:00Z","sid":"sess-1","type":"Stop","transcript":%q}
+{"ts":"2026-01-10T10:02:00Z","sid":"sess-1","type":"Stop","transcript":%q}
+{"ts":"2026-01-10T10:03:00Z","sid":"sess-2","type":"Stop"}
+{"ts":"2026-01-10T10:04:00Z","sid":"sess-3","type":"SessionEnd"}
+{"ts":"2026-01-10T10:05:00Z","sid":"sess-4","type":"PostToolUse","tool":"Read","ok":true}
+`, logged, logged)
+	if err := os.WriteFile(eventsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := NewMockSyncStore()
+	engine := NewSyncEngine(SyncConfig{EventsFile: eventsFile, BatchSize: 1000, ProjectsDir: projectsDir}, store)
+
+	result, err := engine.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.TranscriptsRead != 2 || len(result.Errors) != 0 {
+		t.Errorf("expected 2 transcripts read without errors, got %d and %v", result.TranscriptsRead, result.Errors)
+	}
+
+	read := make(map[string]int)
+	for _, tr := range store.transcripts {
+		read[tr.SessionID] = len(tr.Turns)
+	}
+	if len(read) != 2 || read["sess-1"] != 1 || read["sess-2"] != 1 {
+		t.Errorf("expected each transcript read once, got %v", read)
+	}
+}
+
+func TestSyncEngine_RedactsInputsAndErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+
+	content := `{"ts":"2026-01-10T10:01:00Z","sid":"sess-1","type":"PostToolUse","tool":"Bash","ok":false,"input":"{\"command\":\"curl -H 'Authorization: Bearer abcdef123456' https://api\"}","err":"login failed for postgres://app:hunter2@db/app"}
+`
+	if err := os.WriteFile(eventsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := NewMockSyncStore()
+	engine := NewSyncEngine(SyncConfig{EventsFile: eventsFile, BatchSize: 1000}, store)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(store.events) != 1 {
+		t.Fatalf("expected 1 raw event, got %d", len(store.events))
+	}
+	got := store.events[0]
+	if strings.Contains(got.Input, "abcdef123456") || !strings.Contains(got.Input, "curl") {
+		t.Errorf("expected bearer token redacted from input, got %q", got.Input)
+	}
+	if strings.Contains(got.Error, "hunter2") || !strings.Contains(got.Error, "login failed") {
+		t.Errorf("expected URL password redacted from error, got %q", got.Error)
+	}
+}
+
+func TestSyncEngine_ErrorSignatures(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+
+	content := `{"ts":"2026-01-10T10:01:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__fs__read","ok":false,"err":"open /home/a/x.txt: no such file"}
+{"ts":"2026-01-10T10:02:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__fs__read","ok":false}
+{"ts":"2026-01-10T10:03:00Z","sid":"sess-1","type":"PostToolUse","tool":"mcp__fs__read","ok":true}
+`
+	if err := os.WriteFile(eventsFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	store := NewMockSyncStore()
+	engine := NewSyncEngine(SyncConfig{EventsFile: eventsFile, BatchSize: 1000}, store)
+
+	if _, err := engine.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"open <path>: no such file", errsig.NoMessage, ""}
+	if len(store.events) != len(want) {
+		t.Fatalf("expected %d raw events, got %d", len(want), len(store.events))
+	}
+	for i, e := range store.events {
+		if e.ErrorSignature != want[i] {
+			t.Errorf("event %d signature = %q, want %q", i, e.ErrorSignature, want[i])
+		}
+	}
 }
 
 func TestSyncEngine_Sync_IncrementalSync(t *testing.T) {
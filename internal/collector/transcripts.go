@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/anthropics/mcp-lens/internal/transcript"
+)
+
+// noteTranscript remembers the transcript of a session whose turn ended, to
+// be read once the batch is synced.
+func (s *SyncEngine) noteTranscript(event *Event) {
+	if s.transcripts == nil {
+		s.transcripts = make(map[string]string)
+	}
+	path := event.TranscriptPath
+	if path == "" {
+		path = s.transcripts[event.SessionID]
+	}
+	s.transcripts[event.SessionID] = path
+}
+
+// syncTranscripts reads the transcripts of sessions whose turns ended and
+// stores their usage. Transcripts are read whole each time, since the usage
+// of a turn depends on the turns before it; stored rows are replaced.
+func (s *SyncEngine) syncTranscripts(ctx context.Context, result *SyncResult) {
+	pending := s.transcripts
+	s.transcripts = nil
+
+	for sessionID, path := range pending {
+		if path == "" {
+			path = s.findTranscript(sessionID)
+			if path == "" {
+				continue
+			}
+		}
+
+		t, err := transcript.ReadFile(path, s.config.Pricer)
+		if err != nil {
+			// Claude Code removes old transcripts; there is nothing to read
+			if !errors.Is(err, fs.ErrNotExist) {
+				result.Errors = append(result.Errors, fmt.Errorf("reading transcript of session %s: %w", sessionID, err))
+			}
+			continue
+		}
+		if t.SessionID == "" {
+			t.SessionID = sessionID
+		}
+		if err := s.store.StoreTranscript(ctx, t); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("storing usage of session %s: %w", sessionID, err))
+			continue
+		}
+		result.TranscriptsRead++
+	}
+}
+
+// findTranscript returns the transcript of a session in the Claude Code
+// projects directory, where each project's transcripts are named by session
+// ID, or "" if there is none.
+func (s *SyncEngine) findTranscript(sessionID string) string {
+	if s.config.ProjectsDir == "" || sessionID == "" || strings.ContainsAny(sessionID, `/\*?[`) {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(s.config.ProjectsDir, "*", sessionID+".jsonl"))
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
@@ -189,6 +189,10 @@ type jsonlEvent struct {
 	Success    bool   `json:"ok,omitempty"`
 	DurationMs int64  `json:"dur_ms,omitempty"`
 	Cwd        string `json:"cwd,omitempty"`
+	Input      string `json:"input,omitempty"`
+	Error      string `json:"err,omitempty"`
+	RespBytes  int64  `json:"resp_bytes,omitempty"`
+	RespTokens int64  `json:"resp_tokens,omitempty"`
 }
 
 // ToJSONL converts an Event to the JSONL format.
@@ -201,5 +205,9 @@ func (e *Event) ToJSONL() jsonlEvent {
 		Success:    e.Success,
 		DurationMs: e.DurationMs,
 		Cwd:        e.Cwd,
+		Input:      e.Input,
+		Error:      e.Error,
+		RespBytes:  e.ResponseBytes,
+		RespTokens: e.ResponseTokens,
 	}
 }
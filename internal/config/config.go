@@ -2,13 +2,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/pelletier/go-toml/v2"
+
+	"github.com/anthropics/mcp-lens/internal/pricing"
 )
 
 // Config represents the complete MCP Lens configuration.
@@ -19,6 +21,10 @@ type Config struct {
 	TUI       TUIConfig       `toml:"tui"`
 	Cost      CostConfig      `toml:"cost"`
 	Alerts    AlertsConfig    `toml:"alerts"`
+	Backup    BackupConfig    `toml:"backup"`
+	Errors    ErrorsConfig    `toml:"errors"`
+	SLOs      []SLOConfig     `toml:"slo"`
+	Claude    ClaudeConfig    `toml:"claude"`
 }
 
 // ServerConfig configures the HTTP servers.
@@ -34,6 +40,72 @@ type StorageConfig struct {
 	DatabasePath  string `toml:"database_path"`
 	EventsFile    string `toml:"events_file"`
 	RetentionDays int    `toml:"retention_days"`
+	Host          string `toml:"host"` // Name rows are tagged with; defaults to the hostname
+
+	Retention RetentionConfig `toml:"retention"`
+}
+
+// RetentionConfig configures tiered retention. Raw events are kept for
+// RawDays, minute rollups for MinuteDays, and hourly rollups for HourlyDays;
+// daily rollups are kept forever. Zero keeps a tier forever, except RawDays,
+// which falls back to retention_days when unset. Transcript usage, which
+// costs are computed from, is kept as long as hourly rollups but no shorter
+// than raw events. Rotated JSONL and session files are kept for FileDays,
+// also falling back to the raw retention.
+type RetentionConfig struct {
+	RawDays      int  `toml:"raw_days"`
+	MinuteDays   int  `toml:"minute_days"`
+	HourlyDays   int  `toml:"hourly_days"`
+	FileDays     int  `toml:"file_days"`
+	RecentEvents int  `toml:"recent_events"`
+	Vacuum       bool `toml:"vacuum"`
+}
+
+// BackupConfig configures scheduled local backups. When enabled, a backup is
+// taken after sync once the newest backup in Dir is IntervalHours old, and
+// only the newest Keep backups are kept. Dir defaults to <data_dir>/backups.
+type BackupConfig struct {
+	Enabled       bool   `toml:"enabled"`
+	Dir           string `toml:"dir"`
+	IntervalHours int    `toml:"interval_hours"`
+	Keep          int    `toml:"keep"`
+	IncludeJSONL  bool   `toml:"include_jsonl"`
+}
+
+// ErrorsConfig configures error classification. Rules are tried in order
+// before the built-in rules, so they can override them.
+type ErrorsConfig struct {
+	Rules []ErrorRule `toml:"rules"`
+}
+
+// ErrorRule assigns Category to error messages matching Pattern, a regular
+// expression matched case-insensitively.
+type ErrorRule struct {
+	Category string `toml:"category"`
+	Pattern  string `toml:"pattern"`
+}
+
+// SLOConfig declares a service level objective for an MCP server or one of
+// its tools, such as 99% success and p90 under 2s over 7 days. Window is a
+// duration such as "24h" or "7d"; it defaults to 7 days.
+type SLOConfig struct {
+	Name              string  `toml:"name"`
+	Server            string  `toml:"server"`
+	Tool              string  `toml:"tool"`
+	Success           float64 `toml:"success"`            // Percentage of calls that must succeed
+	LatencyPercentile float64 `toml:"latency_percentile"` // Defaults to 90
+	LatencyMs         float64 `toml:"latency_ms"`
+	Window            string  `toml:"window"`
+}
+
+// ClaudeConfig locates Claude Code's MCP configuration. ConfigDir defaults
+// to $CLAUDE_CONFIG_DIR or ~/.claude and UserFile to ~/.claude.json.
+// Projects lists directories checked for .mcp.json besides those Claude Code
+// and recorded sessions already know about.
+type ClaudeConfig struct {
+	ConfigDir string   `toml:"config_dir"`
+	UserFile  string   `toml:"user_file"`
+	Projects  []string `toml:"projects"`
 }
 
 // DashboardConfig configures the web dashboard.
@@ -48,16 +120,35 @@ type TUIConfig struct {
 	DefaultRange    string        `toml:"default_range"`
 }
 
-// CostConfig configures cost calculation.
+// CostConfig configures cost calculation. Models is decoded by
+// decodeModels, since it takes two forms: a table of per-family prices
+// ([cost.models.opus]), or an array of prices matched against model IDs
+// ([[cost.models]]).
 type CostConfig struct {
-	Models ModelPricing `toml:"models"`
+	Models ModelPricing `toml:"-"`
 }
 
-// ModelPricing defines per-model pricing.
+// ModelPricing defines per-model pricing. The family prices apply to model
+// IDs naming the family that no more specific price matches, such as the
+// "opus" alias or a model newer than the built-in prices.
 type ModelPricing struct {
 	Opus   TokenPricing `toml:"opus"`
 	Sonnet TokenPricing `toml:"sonnet"`
 	Haiku  TokenPricing `toml:"haiku"`
+	Prices []ModelPrice `toml:"-"` // From [[cost.models]]
+}
+
+// ModelPrice prices the models whose IDs match Match, a glob pattern such as
+// "claude-sonnet-4-5*", in USD per 1M tokens. Since dates a price change, so
+// usage before it keeps the earlier price. Cache writes and reads are
+// charged as multiples of Input, 1.25 and 0.1 when unset.
+type ModelPrice struct {
+	Match      string    `toml:"match"`
+	Since      time.Time `toml:"since"`
+	Input      float64   `toml:"input"`
+	Output     float64   `toml:"output"`
+	CacheWrite float64   `toml:"cache_write"`
+	CacheRead  float64   `toml:"cache_read"`
 }
 
 // TokenPricing defines input/output token costs per 1M tokens.
@@ -66,13 +157,52 @@ type TokenPricing struct {
 	Output float64 `toml:"output"`
 }
 
-// AlertsConfig configures budget alerts.
+// AlertsConfig configures budget and health alerts. Budgets cover the
+// calendar day, the week from Monday and the month; they warn at WarnPct
+// and are critical once spent. A server's error rate and p90 latency over
+// Window warn at their threshold and are critical at twice it. Zero
+// disables a check. An alert still active is notified again every Repeat.
 type AlertsConfig struct {
 	Enabled       bool    `toml:"enabled"`
 	BudgetDaily   float64 `toml:"budget_daily"`
 	BudgetWeekly  float64 `toml:"budget_weekly"`
 	BudgetMonthly float64 `toml:"budget_monthly"`
+	WarnPct       float64 `toml:"warn_pct"`
+	ErrorRate     float64 `toml:"error_rate"` // Percentage of a server's calls failing
+	LatencyMs     float64 `toml:"latency_ms"` // A server's p90 latency
+	MinCalls      int64   `toml:"min_calls"`  // Calls in Window before a server is checked
+	Window        string  `toml:"window"`     // "1h" or "1d"
+	Repeat        string  `toml:"repeat"`     // "0" notifies only on changes
 	WebhookURL    string  `toml:"webhook_url"`
+	Command       string  `toml:"command"` // Run by the shell with the alert as JSON on stdin
+	Bell          bool    `toml:"bell"`    // Ring the terminal bell in the TUI
+
+	Rules    []AlertRule    `toml:"rules"`
+	Channels []AlertChannel `toml:"channels"`
+}
+
+// AlertRule declares an alert on an expression over server metrics, such as
+// `server == "github" && error_rate_1h > 10 && calls_1h >= 5`. It fires once
+// Expr has held for For and resolves once Resolve (by default, Expr no
+// longer holding) has held for ClearFor. Durations are such as "10m" or
+// "1d". Channels names the notifiers it goes to: "webhook", "command",
+// "bell" or an [[alerts.channels]] entry; by default the [alerts] ones.
+type AlertRule struct {
+	Name     string   `toml:"name"`
+	Expr     string   `toml:"expr"`
+	Resolve  string   `toml:"resolve"`
+	For      string   `toml:"for"`
+	ClearFor string   `toml:"clear_for"`
+	Severity string   `toml:"severity"` // "warning" (default) or "critical"
+	Channels []string `toml:"channels"`
+}
+
+// AlertChannel is a named notifier rules can deliver to, posting to a
+// webhook or running a command.
+type AlertChannel struct {
+	Name       string `toml:"name"`
+	WebhookURL string `toml:"webhook_url"`
+	Command    string `toml:"command"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -93,6 +223,12 @@ func DefaultConfig() *Config {
 			DatabasePath:  defaultDBPath,
 			EventsFile:    eventsFile,
 			RetentionDays: 30,
+			Retention: RetentionConfig{
+				MinuteDays:   90,
+				HourlyDays:   400,
+				RecentEvents: 100,
+				Vacuum:       true,
+			},
 		},
 		Dashboard: DashboardConfig{
 			RefreshInterval: 30,
@@ -119,7 +255,17 @@ func DefaultConfig() *Config {
 			},
 		},
 		Alerts: AlertsConfig{
-			Enabled: false,
+			Enabled:  false,
+			WarnPct:  80,
+			MinCalls: 10,
+			Window:   "1h",
+			Repeat:   "24h",
+			Bell:     true,
+		},
+		Backup: BackupConfig{
+			Enabled:       false,
+			IntervalHours: 24,
+			Keep:          7,
 		},
 	}
 }
@@ -138,10 +284,50 @@ func LoadFromFile(path string) (*Config, error) {
 	if err := toml.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	if err := decodeModels(data, &cfg.Cost.Models); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
+// decodeModels decodes cost.models, either per-family prices over the
+// defaults or an array of model prices.
+func decodeModels(data []byte, models *ModelPricing) error {
+	var probe struct {
+		Cost struct {
+			Models interface{} `toml:"models"`
+		} `toml:"cost"`
+	}
+	if err := toml.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+
+	switch probe.Cost.Models.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var doc struct {
+			Cost struct {
+				Models []ModelPrice `toml:"models"`
+			} `toml:"cost"`
+		}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		models.Prices = doc.Cost.Models
+		return nil
+	default:
+		var doc struct {
+			Cost struct {
+				Models *ModelPricing `toml:"models"`
+			} `toml:"cost"`
+		}
+		doc.Cost.Models = models
+		return toml.Unmarshal(data, &doc)
+	}
+}
+
 // Load loads configuration from the default location or environment.
 func Load() (*Config, error) {
 	// Check for config file path in environment
@@ -192,6 +378,9 @@ func (c *Config) ApplyEnvOverrides() {
 	if v := os.Getenv("MCP_LENS_DATABASE_PATH"); v != "" {
 		c.Storage.DatabasePath = v
 	}
+	if v := os.Getenv("MCP_LENS_HOST"); v != "" {
+		c.Storage.Host = v
+	}
 	if v := os.Getenv("MCP_LENS_RETENTION_DAYS"); v != "" {
 		if days, err := strconv.Atoi(v); err == nil {
 			c.Storage.RetentionDays = days
@@ -209,26 +398,82 @@ func (c *Config) ApplyEnvOverrides() {
 	}
 }
 
-// CalculateCost calculates the cost for a given model and token counts.
-// Returns the cost in USD.
+// Pricing builds the price table: the configured model prices, then the
+// family prices, then the built-in prices.
+func (c *Config) Pricing() (*pricing.Table, error) {
+	models := c.Cost.Models
+	prices := make([]pricing.Price, 0, len(models.Prices)+3)
+	for i, m := range models.Prices {
+		prices = append(prices, pricing.Price{
+			Match:      m.Match,
+			Since:      m.Since,
+			Input:      m.Input,
+			Output:     m.Output,
+			CacheWrite: m.CacheWrite,
+			CacheRead:  m.CacheRead,
+			Source:     fmt.Sprintf("config #%d", i+1),
+		})
+	}
+	families := []struct {
+		name    string
+		pricing TokenPricing
+	}{
+		{"opus", models.Opus},
+		{"sonnet", models.Sonnet},
+		{"haiku", models.Haiku},
+	}
+	for _, f := range families {
+		if f.pricing.Input == 0 && f.pricing.Output == 0 {
+			continue
+		}
+		prices = append(prices, pricing.Price{
+			Match:  "*" + f.name + "*",
+			Input:  f.pricing.Input,
+			Output: f.pricing.Output,
+			Source: "family",
+		})
+	}
+
+	table, err := pricing.New(prices)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model price: %w", err)
+	}
+	return table, nil
+}
+
+// CalculateCost calculates the cost for a given model and token counts at
+// current prices. Returns the cost in USD, or 0 if the model has no price.
 func (c *Config) CalculateCost(model string, inputTokens, outputTokens int64) float64 {
-	var pricing TokenPricing
-
-	switch strings.ToLower(model) {
-	case "opus", "claude-opus-4", "claude-opus-4-20250514":
-		pricing = c.Cost.Models.Opus
-	case "sonnet", "claude-sonnet-4", "claude-sonnet-4-20250514":
-		pricing = c.Cost.Models.Sonnet
-	case "haiku", "claude-haiku-3-5", "claude-3-5-haiku-20241022":
-		pricing = c.Cost.Models.Haiku
-	default:
+	table, err := c.Pricing()
+	if err != nil {
 		return 0
 	}
+	return table.Cost(model, time.Now(), pricing.Usage{InputTokens: inputTokens, OutputTokens: outputTokens})
+}
 
-	inputCost := float64(inputTokens) / 1_000_000 * pricing.Input
-	outputCost := float64(outputTokens) / 1_000_000 * pricing.Output
+// RawRetentionDays returns how many days of raw events to keep.
+func (s StorageConfig) RawRetentionDays() int {
+	if s.Retention.RawDays > 0 {
+		return s.Retention.RawDays
+	}
+	return s.RetentionDays
+}
+
+// FileRetentionDays returns how many days to keep rotated JSONL and session
+// files.
+func (s StorageConfig) FileRetentionDays() int {
+	if s.Retention.FileDays > 0 {
+		return s.Retention.FileDays
+	}
+	return s.RawRetentionDays()
+}
 
-	return inputCost + outputCost
+// BackupDir returns the directory scheduled and default backups are written to.
+func (c *Config) BackupDir() string {
+	if c.Backup.Dir != "" {
+		return c.Backup.Dir
+	}
+	return filepath.Join(c.Storage.DataDir, "backups")
 }
 
 // HookAddress returns the full hook receiver address.
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -25,6 +26,13 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("expected RetentionDays 30, got %d", cfg.Storage.RetentionDays)
 	}
 
+	if cfg.Storage.RawRetentionDays() != 30 {
+		t.Errorf("expected raw retention to follow RetentionDays, got %d", cfg.Storage.RawRetentionDays())
+	}
+	if cfg.Storage.Retention.MinuteDays != 90 || cfg.Storage.Retention.HourlyDays != 400 {
+		t.Errorf("expected 90/400 day rollup retention, got %+v", cfg.Storage.Retention)
+	}
+
 	// Test dashboard defaults
 	if cfg.Dashboard.RefreshInterval != 30 {
 		t.Errorf("expected RefreshInterval 30, got %d", cfg.Dashboard.RefreshInterval)
@@ -104,6 +112,182 @@ output = 80.0
 	}
 }
 
+func TestLoadTieredRetention(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[storage]
+retention_days = 60
+
+[storage.retention]
+raw_days = 14
+hourly_days = 0
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.Storage.RawRetentionDays() != 14 {
+		t.Errorf("expected raw_days to override retention_days, got %d", cfg.Storage.RawRetentionDays())
+	}
+	if cfg.Storage.Retention.MinuteDays != 90 {
+		t.Errorf("expected default minute retention, got %d", cfg.Storage.Retention.MinuteDays)
+	}
+	if cfg.Storage.Retention.HourlyDays != 0 {
+		t.Errorf("expected hourly rollups kept forever, got %d", cfg.Storage.Retention.HourlyDays)
+	}
+}
+
+func TestLoadBackupConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[storage]
+data_dir = "/var/lib/mcp-lens"
+
+[backup]
+enabled = true
+keep = 3
+include_jsonl = true
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if !cfg.Backup.Enabled || cfg.Backup.Keep != 3 || !cfg.Backup.IncludeJSONL {
+		t.Errorf("expected backup settings from file, got %+v", cfg.Backup)
+	}
+	if cfg.Backup.IntervalHours != 24 {
+		t.Errorf("expected default 24h interval, got %d", cfg.Backup.IntervalHours)
+	}
+	if cfg.BackupDir() != "/var/lib/mcp-lens/backups" {
+		t.Errorf("expected backups under the data dir, got %s", cfg.BackupDir())
+	}
+
+	cfg.Backup.Dir = "/mnt/backups"
+	if cfg.BackupDir() != "/mnt/backups" {
+		t.Errorf("expected configured backup dir, got %s", cfg.BackupDir())
+	}
+}
+
+func TestLoadErrorRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[[errors.rules]]
+category = "auth"
+pattern = "sso session (expired|timed out)"
+
+[[errors.rules]]
+category = "quota"
+pattern = "monthly quota"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(cfg.Errors.Rules) != 2 {
+		t.Fatalf("expected 2 error rules, got %d", len(cfg.Errors.Rules))
+	}
+	if cfg.Errors.Rules[0].Category != "auth" || cfg.Errors.Rules[1].Pattern != "monthly quota" {
+		t.Errorf("expected rules in file order, got %+v", cfg.Errors.Rules)
+	}
+}
+
+func TestLoadSLOs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[[slo]]
+server = "github"
+success = 99.0
+latency_percentile = 90
+latency_ms = 2000
+window = "7d"
+
+[[slo]]
+name = "fs reads"
+server = "fs"
+tool = "read_file"
+success = 99.9
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(cfg.SLOs) != 2 {
+		t.Fatalf("expected 2 SLOs, got %d", len(cfg.SLOs))
+	}
+	github := cfg.SLOs[0]
+	if github.Server != "github" || github.Success != 99 || github.LatencyMs != 2000 || github.Window != "7d" {
+		t.Errorf("unexpected github SLO: %+v", github)
+	}
+	if cfg.SLOs[1].Tool != "read_file" || cfg.SLOs[1].Name != "fs reads" {
+		t.Errorf("unexpected fs SLO: %+v", cfg.SLOs[1])
+	}
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[alerts]
+enabled = true
+
+[[alerts.rules]]
+name = "github-errors"
+expr = 'server == "github" && error_rate_1h > 10 && calls_1h >= 5'
+resolve = "error_rate_1h < 5"
+for = "10m"
+clear_for = "30m"
+severity = "critical"
+channels = ["oncall"]
+
+[[alerts.channels]]
+name = "oncall"
+command = "page-me"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if len(cfg.Alerts.Rules) != 1 {
+		t.Fatalf("expected 1 alert rule, got %d", len(cfg.Alerts.Rules))
+	}
+	rule := cfg.Alerts.Rules[0]
+	if rule.Expr != `server == "github" && error_rate_1h > 10 && calls_1h >= 5` || rule.For != "10m" ||
+		rule.ClearFor != "30m" || rule.Severity != "critical" || len(rule.Channels) != 1 {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	if len(cfg.Alerts.Channels) != 1 || cfg.Alerts.Channels[0].Command != "page-me" {
+		t.Errorf("unexpected channels: %+v", cfg.Alerts.Channels)
+	}
+	// Defaults survive a partial [alerts] section
+	if cfg.Alerts.WarnPct != 80 {
+		t.Errorf("expected default warn_pct 80, got %g", cfg.Alerts.WarnPct)
+	}
+}
+
 func TestLoadConfigFromEnv(t *testing.T) {
 	// Set environment variables
 	os.Setenv("MCP_LENS_HOOK_PORT", "7777")
@@ -173,3 +357,58 @@ func TestCalculateCostUnknownModel(t *testing.T) {
 		t.Errorf("expected 0 for unknown model, got %f", cost)
 	}
 }
+
+func TestLoadModelPrices(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	configContent := `
+[[cost.models]]
+match = "claude-sonnet-4-5*"
+input = 2.0
+output = 10.0
+
+[[cost.models]]
+match = "claude-sonnet-4-5*"
+since = 2026-06-01
+input = 4.0
+output = 20.0
+cache_read = 0.5
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(cfg.Cost.Models.Prices) != 2 {
+		t.Fatalf("expected 2 model prices, got %+v", cfg.Cost.Models.Prices)
+	}
+	if cfg.Cost.Models.Opus.Input != 15.0 {
+		t.Errorf("expected default Opus family price, got %f", cfg.Cost.Models.Opus.Input)
+	}
+
+	table, err := cfg.Pricing()
+	if err != nil {
+		t.Fatalf("Pricing failed: %v", err)
+	}
+	change := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	if p, _ := table.Lookup("claude-sonnet-4-5-20250929", change.Add(-time.Hour)); p.Input != 2.0 {
+		t.Errorf("expected the earlier price before the change, got %+v", p)
+	}
+	if p, _ := table.Lookup("claude-sonnet-4-5-20250929", change); p.Input != 4.0 || p.CacheRead != 0.5 || p.CacheWrite != 1.25 {
+		t.Errorf("expected the later price from the change, got %+v", p)
+	}
+}
+
+func TestCalculateCostNewerModel(t *testing.T) {
+	cfg := DefaultConfig()
+
+	// Dated and newer model IDs are priced, not just the family aliases
+	if cost := cfg.CalculateCost("claude-sonnet-4-5-20250929", 1_000_000, 0); cost != 3.0 {
+		t.Errorf("expected $3.00 for 1M Sonnet 4.5 input tokens, got %f", cost)
+	}
+	if cost := cfg.CalculateCost("claude-opus-5", 0, 1_000_000); cost != 75.0 {
+		t.Errorf("expected the Opus family price for an unlisted Opus model, got %f", cost)
+	}
+}
@@ -0,0 +1,118 @@
+// Package errclass classifies tool error messages into categories such as
+// timeouts, authentication failures, and rate limiting.
+package errclass
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Category is the kind of failure an error message describes.
+type Category string
+
+// Built-in categories. User rules may name others.
+const (
+	Timeout     Category = "timeout"
+	Auth        Category = "auth"
+	RateLimited Category = "rate_limited"
+	NotFound    Category = "not_found"
+	InvalidArgs Category = "invalid_args"
+	Crashed     Category = "crashed"
+	Permission  Category = "permission"
+	Other       Category = "other"   // Has a message no rule matched
+	Unknown     Category = "unknown" // No error message was recorded
+)
+
+// labels are the display names of the built-in categories.
+var labels = map[Category]string{
+	Timeout:     "timeout",
+	Auth:        "auth",
+	RateLimited: "rate limited",
+	NotFound:    "not found",
+	InvalidArgs: "invalid arguments",
+	Crashed:     "crashed/disconnected",
+	Permission:  "permission denied",
+	Other:       "other",
+	Unknown:     "no message",
+}
+
+// Label returns the display name of a category.
+func (c Category) Label() string {
+	if label, ok := labels[c]; ok {
+		return label
+	}
+	return strings.ReplaceAll(string(c), "_", " ")
+}
+
+// Rule assigns a category to messages matching a pattern.
+type Rule struct {
+	Category Category
+	Pattern  *regexp.Regexp
+}
+
+// NewRule compiles a rule. Patterns are matched case-insensitively.
+func NewRule(category string, pattern string) (Rule, error) {
+	if category == "" {
+		return Rule{}, fmt.Errorf("rule for %q has no category", pattern)
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("compiling rule for %s: %w", category, err)
+	}
+	return Rule{Category: Category(category), Pattern: re}, nil
+}
+
+// builtinRules are tried in order, so more specific categories come first:
+// a 401 is an auth failure before it is a permission failure, and an
+// expired token is auth rather than a timeout.
+var builtinRules = []Rule{
+	{RateLimited, regexp.MustCompile(`(?i)rate.?limit|too many requests|\b429\b|quota (?:exceeded|exhausted)|throttl`)},
+	{Auth, regexp.MustCompile(`(?i)unauthori[sz]ed|unauthenticated|not authenticated|authenticat\w* (?:failed|required|error)|` +
+		`(?:status|code|http|error)\D{0,12}401\b|invalid (?:api[ _-]?key|token|credentials?)|bad credentials|` +
+		`(?:token|credentials?|session|key) (?:has |is )?expired|expired (?:token|credentials?)|login required`)},
+	{Permission, regexp.MustCompile(`(?i)permission denied|\beacces\b|\beperm\b|forbidden|(?:status|code|http|error)\D{0,12}403\b|` +
+		`access (?:is )?denied|not (?:allowed|permitted)|insufficient (?:permissions?|scopes?|privileges)`)},
+	{Timeout, regexp.MustCompile(`(?i)time[sd]? ?out|deadline exceeded|\betimedout\b|-32001\b`)},
+	{NotFound, regexp.MustCompile(`(?i)not found|no such (?:file|directory|resource|tool)|does not exist|\benoent\b|` +
+		`(?:status|code|http|error)\D{0,12}404\b|unknown tool`)},
+	{InvalidArgs, regexp.MustCompile(`(?i)invalid (?:argument|param|input|request|value|type)|validation (?:error|failed)|` +
+		`(?:is |field |property |parameter )required|missing (?:required )?(?:argument|param|field|property)|` +
+		`bad request|(?:status|code|http|error)\D{0,12}400\b|-32602\b|unexpected (?:argument|property|field)|` +
+		`must be (?:a|an|one of)`)},
+	{Crashed, regexp.MustCompile(`(?i)connection (?:refused|reset|closed)|\beconn(?:refused|reset)\b|broken pipe|\bepipe\b|` +
+		`disconnected|not connected|crash|exited|terminated|process (?:died|killed)|server (?:closed|stopped|unavailable)|` +
+		`-32000\b|(?:status|code|http|error)\D{0,12}5\d\d\b|internal server error|bad gateway|service unavailable`)},
+}
+
+// Classifier assigns categories to error messages. User rules are tried
+// before the built-in rules, so they can override them.
+type Classifier struct {
+	rules []Rule
+}
+
+// New creates a classifier that tries rules before the built-in rules.
+func New(rules []Rule) *Classifier {
+	all := make([]Rule, 0, len(rules)+len(builtinRules))
+	all = append(all, rules...)
+	all = append(all, builtinRules...)
+	return &Classifier{rules: all}
+}
+
+// Default returns a classifier with only the built-in rules.
+func Default() *Classifier {
+	return New(nil)
+}
+
+// Classify returns the category of an error message.
+func (c *Classifier) Classify(message string) Category {
+	if strings.TrimSpace(message) == "" {
+		return Unknown
+	}
+	for _, r := range c.rules {
+		if r.Pattern.MatchString(message) {
+			return r.Category
+		}
+	}
+	return Other
+}
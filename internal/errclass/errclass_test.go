@@ -0,0 +1,94 @@
+package errclass
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Category
+	}{
+		{"", Unknown},
+		{"  \n", Unknown},
+		{"MCP error -32001: Request timed out", Timeout},
+		{"context deadline exceeded", Timeout},
+		{"connect ETIMEDOUT 10.0.0.1:443", Timeout},
+		{"Bad credentials", Auth},
+		{"HTTP 401: Unauthorized", Auth},
+		{"The access token has expired", Auth},
+		{"invalid api key provided", Auth},
+		{"API rate limit exceeded for user", RateLimited},
+		{"status 429 Too Many Requests", RateLimited},
+		{"Not Found", NotFound},
+		{"ENOENT: no such file or directory, open '<path>'", NotFound},
+		{"Repository does not exist", NotFound},
+		{"MCP error -32602: Invalid arguments for tool search", InvalidArgs},
+		{"validation failed: field \"query\" is required", InvalidArgs},
+		{"MCP error -32000: Connection closed", Crashed},
+		{"connect ECONNREFUSED 127.0.0.1:3000", Crashed},
+		{"server process exited with code 1", Crashed},
+		{"EACCES: permission denied, open '<path>'", Permission},
+		{"Resource not accessible by integration: 403 Forbidden", Permission},
+		{"something odd happened", Other},
+	}
+
+	c := Default()
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := c.Classify(tt.message); got != tt.want {
+				t.Errorf("Classify(%q) = %s, want %s", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify_UserRulesFirst(t *testing.T) {
+	quota, err := NewRule("quota", `monthly quota`)
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	timeout, err := NewRule("auth", `sso session timed out`)
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	c := New([]Rule{quota, timeout})
+
+	tests := []struct {
+		message string
+		want    Category
+	}{
+		{"Monthly quota exceeded", "quota"},
+		{"SSO session timed out, log in again", Auth},
+		{"request timed out", Timeout},
+	}
+	for _, tt := range tests {
+		if got := c.Classify(tt.message); got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestNewRule_Errors(t *testing.T) {
+	if _, err := NewRule("", "x"); err == nil {
+		t.Error("expected an error for a rule without a category")
+	}
+	if _, err := NewRule("bad", "("); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestCategoryLabel(t *testing.T) {
+	tests := []struct {
+		category Category
+		want     string
+	}{
+		{Auth, "auth"},
+		{RateLimited, "rate limited"},
+		{Crashed, "crashed/disconnected"},
+		{"quota_exhausted", "quota exhausted"},
+	}
+	for _, tt := range tests {
+		if got := tt.category.Label(); got != tt.want {
+			t.Errorf("%s.Label() = %q, want %q", tt.category, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package errsig normalizes error messages into signatures, so failures
+// that differ only in paths, IDs, and numbers group together.
+package errsig
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/anthropics/mcp-lens/internal/redact"
+)
+
+// NoMessage is the signature of a failure that reported no error text.
+const NoMessage = "(no error message)"
+
+// MaxLength is the maximum length of a signature, in bytes.
+const MaxLength = 200
+
+// replacement maps a variable part of a message to its placeholder.
+type replacement struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// replacements are applied in order; more specific shapes come first so,
+// for example, a UUID is not read as a run of numbers.
+var replacements = []replacement{
+	{regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s"'<>]+`), "<url>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`), "<email>"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?:\b[A-Za-z]:\\|~/|\.{1,2}/|/)[^\s"'():,;]*[\w/]`), "<path>"},
+	{regexp.MustCompile(`"[^"\n]*"|'[^'\n]*'|` + "`[^`\n]*`"), "<str>"},
+	{regexp.MustCompile(`(?i)\b(?:0x)?[0-9a-f]*\d[0-9a-f]*[a-f][0-9a-f]*\b|\b(?:0x)?[0-9a-f]*[a-f][0-9a-f]*\d[0-9a-f]*\b`), "<hex>"},
+	{regexp.MustCompile(`[+-]?\b\d+(?:\.\d+)?`), "<n>"},
+}
+
+// hexMinLength is the shortest run of hex digits read as an ID. Shorter
+// runs are usually words ("bad", "face") rather than hashes.
+const hexMinLength = 8
+
+var (
+	whitespace         = regexp.MustCompile(`\s+`)
+	placeholderPattern = regexp.MustCompile(`^<[a-z]+>$`)
+)
+
+// Signature normalizes an error message. Only the first non-empty line is
+// used, since stack traces and details below it vary between occurrences.
+func Signature(message string) string {
+	line := firstLine(message)
+	if line == "" {
+		return NoMessage
+	}
+
+	for _, r := range replacements {
+		placeholder := r.placeholder
+		line = r.pattern.ReplaceAllStringFunc(line, func(m string) string {
+			switch placeholder {
+			case "<str>":
+				// Keep the quotes around a value already replaced, such
+				// as '<path>'
+				if placeholderPattern.MatchString(m[1 : len(m)-1]) {
+					return m
+				}
+			case "<hex>":
+				if len(strings.TrimPrefix(strings.ToLower(m), "0x")) < hexMinLength {
+					return m
+				}
+			}
+			return placeholder
+		})
+	}
+
+	line = strings.TrimSpace(whitespace.ReplaceAllString(line, " "))
+	return redact.Truncate(line, MaxLength)
+}
+
+// firstLine returns the first line of s that is not blank.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package errsig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"empty", "", NoMessage},
+		{"blank lines", "\n  \n", NoMessage},
+		{"plain", "permission denied", "permission denied"},
+		{"path", "ENOENT: no such file or directory, open '/home/alice/project/src/main.go'",
+			"ENOENT: no such file or directory, open '<path>'"},
+		{"unquoted path", "cannot read /var/lib/data/file.json: permission denied",
+			"cannot read <path>: permission denied"},
+		{"relative path", "stat ./build/out.bin failed", "stat <path> failed"},
+		{"windows path", `cannot open C:\Users\bob\notes.txt`, "cannot open <path>"},
+		{"numbers", "rate limit exceeded: 429 after 3 retries in 1.5s",
+			"rate limit exceeded: <n> after <n> retries in <n>s"},
+		{"uuid", "issue 3f2b8c1e-9d4a-4f6b-8e2a-1c5d7e9f0a3b not found", "issue <uuid> not found"},
+		{"hex id", "commit deadbeef1234 does not exist", "commit <hex> does not exist"},
+		{"short hex words kept", "bad face", "bad face"},
+		{"url", "GET https://api.example.com/v1/items?id=7 returned 500", "GET <url> returned <n>"},
+		{"ip", "dial tcp 10.0.0.12:5432: connection refused", "dial tcp <ip>: connection refused"},
+		{"timestamp", "token expired at 2026-01-10T10:15:00Z", "token expired at <time>"},
+		{"quoted", `unknown column "user_name" in table`, "unknown column <str> in table"},
+		{"email", "user alice@example.com not found", "user <email> not found"},
+		{"first line only", "panic: boom\n\tat foo.go:12\n\tat bar.go:40", "panic: boom"},
+		{"whitespace", "  too   many\tspaces ", "too many spaces"},
+		{"redaction kept", "auth failed for token [REDACTED]", "auth failed for token [REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Signature(tt.message); got != tt.want {
+				t.Errorf("Signature(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignature_GroupsVariants(t *testing.T) {
+	a := Signature("file /tmp/run-1234/out.log not found (attempt 2)")
+	b := Signature("file /tmp/run-98/out.log not found (attempt 17)")
+	if a != b {
+		t.Errorf("signatures differ: %q vs %q", a, b)
+	}
+}
+
+func TestSignature_Truncates(t *testing.T) {
+	got := Signature(strings.Repeat("é", MaxLength))
+	if len(got) > MaxLength {
+		t.Errorf("len = %d, want <= %d", len(got), MaxLength)
+	}
+	if !strings.HasPrefix(strings.Repeat("é", MaxLength), got) {
+		t.Error("truncation split a character")
+	}
+}
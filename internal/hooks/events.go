@@ -4,6 +4,8 @@ package hooks
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/anthropics/mcp-lens/internal/tokens"
 )
 
 // HookEvent represents the base structure of all Claude Code hook events.
@@ -21,7 +23,11 @@ type ToolUseEvent struct {
 	HookEvent
 	ToolName     string                 `json:"tool_name"`
 	ToolInput    map[string]interface{} `json:"tool_input"`
-	ToolResponse map[string]interface{} `json:"tool_response,omitempty"`
+	ToolResponse map[string]interface{} `json:"tool_response,omitempty"` // Nil unless the response is an object
+
+	// Size of the tool response, whatever its shape
+	ResponseBytes  int64 `json:"-"`
+	ResponseTokens int64 `json:"-"`
 }
 
 // ParsedEvent wraps a hook event with metadata.
@@ -52,11 +58,22 @@ func ParseEvent(data []byte) (*ParsedEvent, error) {
 
 	// Parse tool-specific fields if this is a tool event
 	if base.HookEventName == "PreToolUse" || base.HookEventName == "PostToolUse" {
-		var toolEvent ToolUseEvent
+		// MCP tools respond with content blocks or text as often as with
+		// an object, so the response is decoded separately
+		var toolEvent struct {
+			ToolUseEvent
+			ToolResponse json.RawMessage `json:"tool_response"`
+		}
 		if err := json.Unmarshal(data, &toolEvent); err != nil {
 			return nil, err
 		}
-		parsed.Tool = &toolEvent
+		if len(toolEvent.ToolResponse) > 0 && toolEvent.ToolResponse[0] == '{' {
+			if err := json.Unmarshal(toolEvent.ToolResponse, &toolEvent.ToolUseEvent.ToolResponse); err != nil {
+				return nil, err
+			}
+		}
+		toolEvent.ResponseBytes, toolEvent.ResponseTokens = tokens.Response(toolEvent.ToolResponse)
+		parsed.Tool = &toolEvent.ToolUseEvent
 	}
 
 	return parsed, nil
@@ -100,6 +117,28 @@ func (p *ParsedEvent) IsSuccess() bool {
 	return true // Default to success if no indicators
 }
 
+// ErrorText returns the error message of a failed PostToolUse event, taken
+// from the error or content field of tool_response.
+func (p *ParsedEvent) ErrorText() string {
+	if p.IsSuccess() || p.Tool.ToolResponse == nil {
+		return ""
+	}
+
+	for _, key := range []string{"error", "content"} {
+		switch v := p.Tool.ToolResponse[key].(type) {
+		case nil:
+			continue
+		case string:
+			return v
+		default:
+			if data, err := json.Marshal(v); err == nil {
+				return string(data)
+			}
+		}
+	}
+	return ""
+}
+
 // SupportedEventTypes lists all supported Claude Code hook event types.
 var SupportedEventTypes = []string{
 	"PreToolUse",
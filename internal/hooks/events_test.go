@@ -101,6 +101,35 @@ func TestParseEvent_ToolWithError(t *testing.T) {
 	if parsed.IsSuccess() {
 		t.Error("expected IsSuccess to return false when error field present")
 	}
+
+	if got := parsed.ErrorText(); got != "command not found" {
+		t.Errorf("expected error text 'command not found', got %q", got)
+	}
+}
+
+func TestParseEvent_ResponseSize(t *testing.T) {
+	data := []byte(`{
+		"session_id": "abc123",
+		"hook_event_name": "PostToolUse",
+		"tool_name": "mcp__github__get_issue",
+		"tool_input": {"number": 7},
+		"tool_response": [{"type": "text", "text": "Issue #7: crash on startup"}]
+	}`)
+
+	parsed, err := ParseEvent(data)
+	if err != nil {
+		t.Fatalf("failed to parse event with content blocks: %v", err)
+	}
+
+	if !parsed.IsSuccess() {
+		t.Error("expected IsSuccess to return true for content blocks")
+	}
+	if parsed.Tool.ResponseBytes != int64(len(`[{"type": "text", "text": "Issue #7: crash on startup"}]`)) {
+		t.Errorf("expected the response length, got %d", parsed.Tool.ResponseBytes)
+	}
+	if parsed.Tool.ResponseTokens == 0 {
+		t.Error("expected a token estimate")
+	}
 }
 
 func TestGetToolName(t *testing.T) {
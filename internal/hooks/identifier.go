@@ -45,23 +45,23 @@ func NewRuleBasedIdentifier() *RuleBasedIdentifier {
 		},
 		exactRules: map[string]string{
 			// Built-in Claude Code tools (not MCP)
-			"Read":          "",
-			"Write":         "",
-			"Edit":          "",
-			"Bash":          "",
-			"Glob":          "",
-			"Grep":          "",
-			"LS":            "",
-			"MultiEdit":     "",
-			"NotebookEdit":  "",
-			"WebFetch":      "",
-			"WebSearch":     "",
-			"TodoRead":      "",
-			"TodoWrite":     "",
-			"Task":          "",
-			"Skill":         "",
-			"KillShell":     "",
-			"TaskOutput":    "",
+			"Read":         "",
+			"Write":        "",
+			"Edit":         "",
+			"Bash":         "",
+			"Glob":         "",
+			"Grep":         "",
+			"LS":           "",
+			"MultiEdit":    "",
+			"NotebookEdit": "",
+			"WebFetch":     "",
+			"WebSearch":    "",
+			"TodoRead":     "",
+			"TodoWrite":    "",
+			"Task":         "",
+			"Skill":        "",
+			"KillShell":    "",
+			"TaskOutput":   "",
 			// Common MCP tools
 			"create_issue":       "github",
 			"list_issues":        "github",
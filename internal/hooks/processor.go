@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/errsig"
+	"github.com/anthropics/mcp-lens/internal/redact"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
@@ -68,6 +70,7 @@ func (p *Processor) processEvent(ctx context.Context, parsed *ParsedEvent) error
 		SessionID:  parsed.Event.SessionID,
 		EventType:  parsed.Event.HookEventName,
 		RawPayload: parsed.Raw,
+		Cwd:        parsed.Event.Cwd,
 		CreatedAt:  parsed.ReceivedAt,
 	}
 
@@ -76,6 +79,15 @@ func (p *Processor) processEvent(ctx context.Context, parsed *ParsedEvent) error
 		event.ToolName = parsed.Tool.ToolName
 		event.MCPServer = p.identifier.Identify(parsed.Tool.ToolName, parsed.Tool.ToolInput)
 		event.Success = parsed.IsSuccess()
+		if len(parsed.Tool.ToolInput) > 0 {
+			event.ToolInput = redact.JSON(parsed.Tool.ToolInput)
+		}
+		event.Error = redact.String(parsed.ErrorText())
+		event.ResponseBytes = parsed.Tool.ResponseBytes
+		event.ResponseTokens = parsed.Tool.ResponseTokens
+		if parsed.Event.HookEventName == "PostToolUse" && !event.Success {
+			event.ErrorSignature = errsig.Signature(event.Error)
+		}
 
 		// Calculate duration for PostToolUse events
 		if parsed.Event.HookEventName == "PostToolUse" {
@@ -0,0 +1,270 @@
+// Package mcpconfig reads the MCP servers configured for Claude Code, so
+// usage can be compared with what is installed.
+package mcpconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Scope is where a server is configured.
+type Scope string
+
+const (
+	ScopeUser    Scope = "user"    // mcpServers in ~/.claude.json, available in every project
+	ScopeLocal   Scope = "local"   // mcpServers of a project entry in ~/.claude.json
+	ScopeProject Scope = "project" // .mcp.json in a project directory
+	ScopePlugin  Scope = "plugin"  // Provided by an installed plugin
+)
+
+// Server is one configured MCP server. The same server may be configured in
+// several places, giving one Server for each.
+type Server struct {
+	Name      string // Name as configured
+	Scope     Scope
+	Project   string // Project directory, for local and project scope
+	Plugin    string // Plugin providing the server, for plugin scope
+	Root      string // Plugin install directory, for plugin scope
+	Source    string // File the server is declared in
+	Transport string // stdio, http or sse
+	Command   string // Executable, for stdio servers
+	Args      []string
+	Env       map[string]string
+	URL       string // Endpoint, for http and sse servers
+	Disabled  bool   // Turned off for the project or plugin
+}
+
+// CommandLine returns the command and its arguments.
+func (s Server) CommandLine() string {
+	return strings.TrimSpace(strings.Join(append([]string{s.Command}, s.Args...), " "))
+}
+
+// ID returns the name the server's tools are recorded under, the "github"
+// in "mcp__github__search_code". Plugin servers are namespaced by plugin.
+func (s Server) ID() string {
+	if s.Scope == ScopePlugin {
+		return "plugin_" + normalizeName(s.Plugin) + "_" + normalizeName(s.Name)
+	}
+	return normalizeName(s.Name)
+}
+
+// Location describes where the server is configured, such as "user",
+// "project ~/src/app" or "plugin github-tools".
+func (s Server) Location() string {
+	switch s.Scope {
+	case ScopeLocal, ScopeProject:
+		return string(s.Scope) + " " + s.Project
+	case ScopePlugin:
+		return "plugin " + s.Plugin
+	default:
+		return string(s.Scope)
+	}
+}
+
+// normalizeName replaces the characters Claude Code does not allow in tool
+// names with underscores.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// Inventory is every MCP server configured for Claude Code.
+type Inventory struct {
+	Servers []Server
+}
+
+// IDs returns the IDs of the configured servers, sorted, each once.
+func (inv *Inventory) IDs() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, s := range inv.Servers {
+		if id := s.ID(); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Locations returns every configuration of the server with the given ID.
+func (inv *Inventory) Locations(id string) []Server {
+	var servers []Server
+	for _, s := range inv.Servers {
+		if s.ID() == id {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// Options configures where configuration is read from.
+type Options struct {
+	ConfigDir string   // Claude Code directory (default: $CLAUDE_CONFIG_DIR, then ~/.claude)
+	UserFile  string   // User config file (default: ~/.claude.json, or .claude.json in $CLAUDE_CONFIG_DIR)
+	Projects  []string // Project directories checked for .mcp.json besides those in the user file
+}
+
+// serverEntry is a server declaration, shared by every config file format.
+type serverEntry struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+}
+
+// userFile is the part of ~/.claude.json that declares servers.
+type userFile struct {
+	MCPServers map[string]serverEntry `json:"mcpServers"`
+	Projects   map[string]struct {
+		MCPServers             map[string]serverEntry `json:"mcpServers"`
+		DisabledMCPJSONServers []string               `json:"disabledMcpjsonServers"`
+	} `json:"projects"`
+}
+
+// mcpFile is a .mcp.json file.
+type mcpFile struct {
+	MCPServers map[string]serverEntry `json:"mcpServers"`
+}
+
+// Load builds the inventory from the user file, the .mcp.json of every known
+// project and installed plugins. Missing files are skipped.
+func Load(opts Options) (*Inventory, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{}
+	var user userFile
+	found, err := readJSON(opts.UserFile, &user)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		inv.add(user.MCPServers, Server{Scope: ScopeUser, Source: opts.UserFile}, nil)
+	}
+
+	projects := append([]string(nil), opts.Projects...)
+	disabled := make(map[string][]string)
+	for dir, p := range user.Projects {
+		projects = append(projects, dir)
+		disabled[dir] = p.DisabledMCPJSONServers
+		inv.add(p.MCPServers, Server{Scope: ScopeLocal, Project: dir, Source: opts.UserFile}, nil)
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range projects {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		path := filepath.Join(dir, ".mcp.json")
+		var f mcpFile
+		found, err := readJSON(path, &f)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			inv.add(f.MCPServers, Server{Scope: ScopeProject, Project: dir, Source: path}, disabled[dir])
+		}
+	}
+
+	if err := inv.loadPlugins(opts.ConfigDir); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(inv.Servers, func(i, j int) bool {
+		return inv.Servers[i].ID() < inv.Servers[j].ID()
+	})
+	return inv, nil
+}
+
+// withDefaults fills in the default locations.
+func (o Options) withDefaults() (Options, error) {
+	envDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	if o.ConfigDir != "" && o.UserFile != "" {
+		return o, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return o, fmt.Errorf("finding home directory: %w", err)
+	}
+	if o.ConfigDir == "" {
+		o.ConfigDir = envDir
+		if o.ConfigDir == "" {
+			o.ConfigDir = filepath.Join(home, ".claude")
+		}
+	}
+	if o.UserFile == "" {
+		o.UserFile = filepath.Join(home, ".claude.json")
+		if envDir != "" {
+			o.UserFile = filepath.Join(envDir, ".claude.json")
+		}
+	}
+	return o, nil
+}
+
+// add adds the servers declared in one place, marking those named in
+// disabled as turned off.
+func (inv *Inventory) add(entries map[string]serverEntry, base Server, disabled []string) {
+	off := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		off[name] = true
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		e := entries[name]
+		s := base
+		s.Name = name
+		s.Transport = e.Type
+		if s.Transport == "" {
+			s.Transport = "stdio"
+			if e.URL != "" {
+				s.Transport = "http"
+			}
+		}
+		s.Command = e.Command
+		s.Args = e.Args
+		s.Env = e.Env
+		s.URL = e.URL
+		s.Disabled = base.Disabled || off[name]
+		inv.Servers = append(inv.Servers, s)
+	}
+}
+
+// readJSON decodes a JSON file into v, reporting false if it does not exist.
+func readJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,138 @@
+package mcpconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("creating directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".claude")
+	userFile := filepath.Join(dir, ".claude.json")
+	project := filepath.Join(dir, "src", "app")
+	other := filepath.Join(dir, "src", "other")
+	pluginDir := filepath.Join(configDir, "plugins", "cache", "tools")
+
+	writeFile(t, userFile, `{
+		"numStartups": 12,
+		"mcpServers": {
+			"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"},
+			"filesystem": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]}
+		},
+		"projects": {
+			"`+project+`": {
+				"mcpServers": {"postgres": {"command": "pg-mcp"}},
+				"disabledMcpjsonServers": ["sentry"]
+			}
+		}
+	}`)
+	writeFile(t, filepath.Join(project, ".mcp.json"), `{
+		"mcpServers": {
+			"sentry": {"type": "sse", "url": "https://mcp.sentry.dev/sse"},
+			"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/"}
+		}
+	}`)
+	writeFile(t, filepath.Join(other, ".mcp.json"), `{"mcpServers": {"linear.app": {"command": "linear-mcp"}}}`)
+	writeFile(t, filepath.Join(configDir, "plugins", "installed_plugins.json"), `{
+		"version": 2,
+		"plugins": {
+			"dev-tools@market": [{"scope": "user", "installPath": "`+pluginDir+`"}],
+			"missing@market": [{"scope": "user", "installPath": "`+filepath.Join(dir, "gone")+`"}]
+		}
+	}`)
+	writeFile(t, filepath.Join(pluginDir, ".mcp.json"), `{"browser": {"command": "browser-mcp"}}`)
+	writeFile(t, filepath.Join(configDir, "settings.json"), `{"enabledPlugins": {"dev-tools@market": false}}`)
+
+	inv, err := Load(Options{ConfigDir: configDir, UserFile: userFile, Projects: []string{other}})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	wantIDs := []string{"filesystem", "github", "linear_app", "plugin_dev-tools_browser", "postgres", "sentry"}
+	ids := inv.IDs()
+	if len(ids) != len(wantIDs) {
+		t.Fatalf("IDs() = %v, want %v", ids, wantIDs)
+	}
+	for i := range wantIDs {
+		if ids[i] != wantIDs[i] {
+			t.Errorf("IDs()[%d] = %s, want %s", i, ids[i], wantIDs[i])
+		}
+	}
+
+	github := inv.Locations("github")
+	if len(github) != 2 {
+		t.Fatalf("expected github in 2 places, got %d", len(github))
+	}
+	if github[0].Location() != "user" || github[1].Location() != "project "+project {
+		t.Errorf("unexpected github locations: %s, %s", github[0].Location(), github[1].Location())
+	}
+	if github[0].Transport != "http" {
+		t.Errorf("expected http transport, got %s", github[0].Transport)
+	}
+
+	fs := inv.Locations("filesystem")[0]
+	if fs.Transport != "stdio" || fs.CommandLine() != "npx -y @modelcontextprotocol/server-filesystem /tmp" {
+		t.Errorf("unexpected filesystem server: %+v", fs)
+	}
+
+	pg := inv.Locations("postgres")[0]
+	if pg.Scope != ScopeLocal || pg.Project != project {
+		t.Errorf("expected postgres in local scope of %s, got %s", project, pg.Location())
+	}
+
+	if sentry := inv.Locations("sentry")[0]; !sentry.Disabled {
+		t.Error("expected sentry to be disabled for the project")
+	}
+
+	browser := inv.Locations("plugin_dev-tools_browser")[0]
+	if browser.Plugin != "dev-tools" || !browser.Disabled {
+		t.Errorf("expected disabled dev-tools plugin server, got %+v", browser)
+	}
+}
+
+func TestLoad_PluginManifest(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "plugins", "db")
+
+	writeFile(t, filepath.Join(dir, "plugins", "installed_plugins.json"), `{
+		"version": 1,
+		"plugins": {"db@market": {"installPath": "`+pluginDir+`"}}
+	}`)
+	writeFile(t, filepath.Join(pluginDir, ".claude-plugin", "plugin.json"), `{
+		"name": "db-tools",
+		"mcpServers": {"query": {"command": "${CLAUDE_PLUGIN_ROOT}/bin/query"}}
+	}`)
+
+	inv, err := Load(Options{ConfigDir: dir, UserFile: filepath.Join(dir, "missing.json")})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(inv.Servers) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(inv.Servers))
+	}
+	s := inv.Servers[0]
+	if s.ID() != "plugin_db-tools_query" || s.Location() != "plugin db-tools" || s.Disabled || s.Root != pluginDir {
+		t.Errorf("unexpected plugin server: %+v", s)
+	}
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, ".claude.json")
+	writeFile(t, userFile, `{"mcpServers": `)
+
+	if _, err := Load(Options{ConfigDir: dir, UserFile: userFile}); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,156 @@
+package mcpconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// installedPlugins is plugins/installed_plugins.json. Version 1 maps each
+// plugin to one install; version 2 maps it to a list of installs.
+type installedPlugins struct {
+	Plugins map[string]json.RawMessage `json:"plugins"`
+}
+
+// pluginInstall is one install of a plugin.
+type pluginInstall struct {
+	InstallPath string `json:"installPath"`
+}
+
+// pluginManifest is the part of .claude-plugin/plugin.json that declares
+// servers, either inline or as the path of a JSON file.
+type pluginManifest struct {
+	Name       string          `json:"name"`
+	MCPServers json.RawMessage `json:"mcpServers"`
+}
+
+// settings is the part of settings.json that turns plugins on and off.
+type settings struct {
+	EnabledPlugins map[string]bool `json:"enabledPlugins"`
+}
+
+// loadPlugins adds the servers of every installed plugin.
+func (inv *Inventory) loadPlugins(configDir string) error {
+	path := filepath.Join(configDir, "plugins", "installed_plugins.json")
+	var installed installedPlugins
+	found, err := readJSON(path, &installed)
+	if err != nil || !found {
+		return err
+	}
+
+	var s settings
+	if _, err := readJSON(filepath.Join(configDir, "settings.json"), &s); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(installed.Plugins))
+	for id := range installed.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		installs, err := decodeInstalls(installed.Plugins[id])
+		if err != nil {
+			return fmt.Errorf("parsing %s: plugin %s: %w", path, id, err)
+		}
+		enabled, listed := s.EnabledPlugins[id]
+		for _, install := range installs {
+			if install.InstallPath == "" {
+				continue
+			}
+			base := Server{
+				Scope:    ScopePlugin,
+				Plugin:   pluginName(id),
+				Root:     install.InstallPath,
+				Disabled: listed && !enabled,
+			}
+			if err := inv.loadPlugin(install.InstallPath, base); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadPlugin adds the servers of the plugin installed in dir, declared in
+// its manifest or in a .mcp.json at its root.
+func (inv *Inventory) loadPlugin(dir string, base Server) error {
+	manifestPath := filepath.Join(dir, ".claude-plugin", "plugin.json")
+	var manifest pluginManifest
+	if _, err := readJSON(manifestPath, &manifest); err != nil {
+		return err
+	}
+	if manifest.Name != "" {
+		base.Plugin = manifest.Name
+	}
+
+	if len(manifest.MCPServers) > 0 {
+		var rel string
+		if err := json.Unmarshal(manifest.MCPServers, &rel); err != nil {
+			var entries map[string]serverEntry
+			if err := json.Unmarshal(manifest.MCPServers, &entries); err != nil {
+				return fmt.Errorf("parsing %s: %w", manifestPath, err)
+			}
+			base.Source = manifestPath
+			inv.add(entries, base, nil)
+			return nil
+		}
+		return inv.loadPluginServers(filepath.Join(dir, rel), base)
+	}
+	return inv.loadPluginServers(filepath.Join(dir, ".mcp.json"), base)
+}
+
+// loadPluginServers adds the servers in a plugin's server file, which may
+// wrap them in "mcpServers" or list them at the top level.
+func (inv *Inventory) loadPluginServers(path string, base Server) error {
+	var raw map[string]json.RawMessage
+	found, err := readJSON(path, &raw)
+	if err != nil || !found {
+		return err
+	}
+
+	var entries map[string]serverEntry
+	if wrapped, ok := raw["mcpServers"]; ok {
+		err = json.Unmarshal(wrapped, &entries)
+	} else {
+		entries = make(map[string]serverEntry, len(raw))
+		for name, v := range raw {
+			var e serverEntry
+			if err = json.Unmarshal(v, &e); err != nil {
+				break
+			}
+			entries[name] = e
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	base.Source = path
+	inv.add(entries, base, nil)
+	return nil
+}
+
+// decodeInstalls reads a plugin's installs in either file version.
+func decodeInstalls(raw json.RawMessage) ([]pluginInstall, error) {
+	var installs []pluginInstall
+	if err := json.Unmarshal(raw, &installs); err == nil {
+		return installs, nil
+	}
+	var install pluginInstall
+	if err := json.Unmarshal(raw, &install); err != nil {
+		return nil, err
+	}
+	return []pluginInstall{install}, nil
+}
+
+// pluginName strips the marketplace from a plugin ID ("name@marketplace").
+func pluginName(id string) string {
+	if i := strings.LastIndex(id, "@"); i > 0 {
+		return id[:i]
+	}
+	return id
+}
@@ -3,8 +3,10 @@ package metrics
 
 import (
 	"context"
+	"math"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/analytics"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
@@ -36,9 +38,13 @@ type MCPUtilization struct {
 	ServerName     string
 	CallCount      int64
 	Percentage     float64
-	TrendDirection string // "up", "down", "stable"
+	TrendDirection string  // "up", "down", "stable"
+	TrendPct       float64 // Percentage change in calls against the previous window
 	SuccessRate    float64
 	AvgLatencyMs   float64
+	P50LatencyMs   float64
+	P90LatencyMs   float64
+	P99LatencyMs   float64
 }
 
 // ToolSuccessRate tracks success/failure rates per tool.
@@ -49,24 +55,64 @@ type ToolSuccessRate struct {
 	SuccessRate  float64
 	ErrorRate    float64
 	AvgLatencyMs float64
+	P50LatencyMs float64
+	P90LatencyMs float64
+	P99LatencyMs float64
 	CommonErrors []string
 }
 
 // UnusedServer represents an MCP server with no recent activity.
 type UnusedServer struct {
-	ServerName  string
-	LastUsedAt  time.Time
+	ServerName   string
+	LastUsedAt   time.Time
 	DaysSinceUse int
-	Status      string // "never_used" or "inactive"
+	Status       string // "never_used" or "inactive"
 }
 
-// CostForecast provides cost projections.
+// CostForecast provides cost projections. Estimates come with 80%
+// prediction intervals.
 type CostForecast struct {
 	DailyAverage    float64
-	WeeklyEstimate  float64
-	MonthlyEstimate float64
-	Confidence      string // "low", "medium", "high"
-	DataDays        int    // Number of days of data used
+	WeeklyEstimate  float64 // Next 7 days
+	MonthlyEstimate float64 // Next 30 days
+	WeeklyLow       float64
+	WeeklyHigh      float64
+	MonthlyLow      float64
+	MonthlyHigh     float64
+	TrendPerDay     float64 // Change in daily spend per day; 0 without a trend
+	Seasonal        bool    // Whether the forecast follows the day of the week
+	Confidence      string  // "low", "medium", "high"
+	DataDays        int     // Number of days of data used
+	Days            []DayForecast
+
+	// Projected spend this calendar month against BudgetMonthly
+	MonthToDate     float64
+	MonthEnd        float64
+	MonthEndLow     float64
+	MonthEndHigh    float64
+	BudgetMonthly   float64   // 0 when no budget is set
+	BudgetExceedsOn time.Time // Day spend passes the budget; zero if it is not projected to
+}
+
+// DayForecast is the projected spend on one day.
+type DayForecast struct {
+	Date time.Time
+	analytics.Prediction
+}
+
+// OverBudget reports whether month-end spend is projected to pass the
+// monthly budget.
+func (f *CostForecast) OverBudget() bool {
+	return !f.BudgetExceedsOn.IsZero()
+}
+
+// BudgetPct returns projected month-end spend as a percentage of the
+// monthly budget, or 0 without one.
+func (f *CostForecast) BudgetPct() float64 {
+	if f.BudgetMonthly <= 0 {
+		return 0
+	}
+	return f.MonthEnd / f.BudgetMonthly * 100
 }
 
 // GetDashboardSummary returns high-level metrics for the dashboard.
@@ -149,6 +195,20 @@ func (c *Calculator) GetMCPUtilization(ctx context.Context, filter storage.TimeF
 		totalCalls += s.TotalCalls
 	}
 
+	// Calls in the previous window of the same length, for trends
+	trendConfig := analytics.DefaultTrendConfig()
+	var previousCalls map[string]int64
+	if baseFilter, ok := analytics.BaselineFilter(filter, trendConfig.BaselineWindows); ok {
+		baseStats, err := c.store.GetMCPServerStats(ctx, baseFilter)
+		if err != nil {
+			return nil, err
+		}
+		previousCalls = make(map[string]int64, len(baseStats))
+		for _, s := range baseStats {
+			previousCalls[s.ServerName] = s.TotalCalls
+		}
+	}
+
 	result := make([]MCPUtilization, len(stats))
 	for i, s := range stats {
 		percentage := float64(0)
@@ -161,13 +221,23 @@ func (c *Calculator) GetMCPUtilization(ctx context.Context, filter storage.TimeF
 			successRate = float64(s.SuccessCount) / float64(s.TotalCalls) * 100
 		}
 
+		trend, trendPct := analytics.TrendStable, 0.0
+		if previousCalls != nil {
+			previous := previousCalls[s.ServerName]
+			trend, trendPct = trendConfig.CompareTrend(float64(s.TotalCalls), float64(previous), max(s.TotalCalls, previous))
+		}
+
 		result[i] = MCPUtilization{
 			ServerName:     s.ServerName,
 			CallCount:      s.TotalCalls,
 			Percentage:     percentage,
-			TrendDirection: "stable", // TODO: Calculate trend from historical data
+			TrendDirection: string(trend),
+			TrendPct:       trendPct,
 			SuccessRate:    successRate,
 			AvgLatencyMs:   s.AvgLatencyMs,
+			P50LatencyMs:   s.P50LatencyMs,
+			P90LatencyMs:   s.P90LatencyMs,
+			P99LatencyMs:   s.P99LatencyMs,
 		}
 	}
 
@@ -197,6 +267,9 @@ func (c *Calculator) GetToolSuccessRates(ctx context.Context, filter storage.Tim
 			SuccessRate:  successRate,
 			ErrorRate:    errorRate,
 			AvgLatencyMs: s.AvgLatencyMs,
+			P50LatencyMs: s.P50LatencyMs,
+			P90LatencyMs: s.P90LatencyMs,
+			P99LatencyMs: s.P99LatencyMs,
 		}
 	}
 
@@ -234,39 +307,139 @@ func (c *Calculator) GetUnusedServers(ctx context.Context, unusedSince time.Dura
 	return unused, nil
 }
 
-// GetCostForecast returns cost projections based on recent usage.
-func (c *Calculator) GetCostForecast(ctx context.Context, lookbackDays int) (*CostForecast, error) {
-	// Get costs for the lookback period
-	from := time.Now().AddDate(0, 0, -lookbackDays)
-	filter := storage.TimeFilter{From: from}
-
-	costSummary, err := c.store.GetCostSummary(ctx, filter)
+// GetCostForecast returns cost projections from the daily spend of the
+// last lookbackDays complete days, and projects this month's spend against
+// budgetMonthly (0 for none).
+//
+// The daily series starts on the first day with any spend, so days before
+// usage was recorded do not pull the forecast down. It is fit with a
+// linear trend once it spans a week and with day-of-week indexes once it
+// spans two.
+func (c *Calculator) GetCostForecast(ctx context.Context, lookbackDays int, budgetMonthly float64) (*CostForecast, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+
+	from := today.AddDate(0, 0, -lookbackDays)
+	if monthStart.Before(from) {
+		from = monthStart
+	}
+	daily, err := c.store.GetDailyCosts(ctx, storage.TimeFilter{From: from})
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate daily average
-	dailyAverage := costSummary.TotalCostUSD / float64(lookbackDays)
-	if dailyAverage < 0 {
-		dailyAverage = 0
+	byDay := make(map[int]float64, len(daily))
+	first := 0
+	var monthToDate, spentToday float64
+	for _, d := range daily {
+		t := analytics.DaysBetween(today, d.Date)
+		byDay[t] = d.CostUSD
+		if t >= -lookbackDays && t < 0 && (first == 0 || t < first) {
+			first = t
+		}
+		if !d.Date.Before(monthStart) {
+			monthToDate += d.CostUSD
+		}
+		if t == 0 {
+			spentToday = d.CostUSD
+		}
+	}
+
+	// Complete days from the first with spend through yesterday
+	var values []float64
+	for t := first; t < 0; t++ {
+		values = append(values, byDay[t])
 	}
+	start := today.AddDate(0, 0, first)
+	model := analytics.FitDailyModel(start, values)
 
-	// Determine confidence based on data availability
-	confidence := "low"
-	if lookbackDays >= 7 && costSummary.TotalCostUSD > 0 {
-		confidence = "medium"
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	forecast := &CostForecast{
+		Seasonal:      model.Seasonal,
+		DataDays:      len(values),
+		MonthToDate:   monthToDate,
+		BudgetMonthly: budgetMonthly,
+	}
+	if len(values) > 0 {
+		forecast.DailyAverage = total / float64(len(values))
 	}
-	if lookbackDays >= 14 && costSummary.TotalCostUSD > 0 {
-		confidence = "high"
+	if model.Trend {
+		forecast.TrendPerDay = model.Slope()
 	}
 
-	return &CostForecast{
-		DailyAverage:    dailyAverage,
-		WeeklyEstimate:  dailyAverage * 7,
-		MonthlyEstimate: dailyAverage * 30,
-		Confidence:      confidence,
-		DataDays:        lookbackDays,
-	}, nil
+	// Today is partly spent, so forecasts start tomorrow
+	tomorrow := today.AddDate(0, 0, 1)
+	week := model.Sum(tomorrow, 7)
+	month := model.Sum(tomorrow, 30)
+	forecast.WeeklyEstimate, forecast.WeeklyLow, forecast.WeeklyHigh = week.Value, week.Low, week.High
+	forecast.MonthlyEstimate, forecast.MonthlyLow, forecast.MonthlyHigh = month.Value, month.Low, month.High
+	for i := 0; i < 7; i++ {
+		day := tomorrow.AddDate(0, 0, i)
+		forecast.Days = append(forecast.Days, DayForecast{Date: day, Prediction: model.Day(day)})
+	}
+
+	// Month end: spend so far, what is left of today's forecast, and the
+	// remaining days
+	todayLeft := math.Max(model.Day(today).Value-spentToday, 0)
+	daysLeft := analytics.DaysBetween(today, monthStart.AddDate(0, 1, 0)) - 1
+	rest := model.Sum(tomorrow, daysLeft)
+	forecast.MonthEnd = monthToDate + todayLeft + rest.Value
+	forecast.MonthEndLow = monthToDate + todayLeft + rest.Low
+	forecast.MonthEndHigh = monthToDate + todayLeft + rest.High
+
+	if budgetMonthly > 0 {
+		forecast.BudgetExceedsOn = budgetExceedsOn(daily, monthStart, today, todayLeft, model, budgetMonthly)
+	}
+
+	forecast.Confidence = forecastConfidence(forecast)
+	return forecast, nil
+}
+
+// budgetExceedsOn returns the day this month's spend passed or is projected
+// to pass budget, or zero if it is not projected to by the month end.
+func budgetExceedsOn(daily []storage.DailyCost, monthStart, today time.Time, todayLeft float64, model *analytics.DailyModel, budget float64) time.Time {
+	var spent float64
+	for _, d := range daily {
+		if d.Date.Before(monthStart) {
+			continue
+		}
+		spent += d.CostUSD
+		if spent > budget {
+			return d.Date
+		}
+	}
+	if spent+todayLeft > budget {
+		return today
+	}
+	spent += todayLeft
+	for day := today.AddDate(0, 0, 1); day.Month() == monthStart.Month(); day = day.AddDate(0, 0, 1) {
+		spent += model.Day(day).Value
+		if spent > budget {
+			return day
+		}
+	}
+	return time.Time{}
+}
+
+// forecastConfidence rates a forecast by how much data it rests on and how
+// wide its monthly interval is against the estimate.
+func forecastConfidence(f *CostForecast) string {
+	if f.DataDays < analytics.MinTrendDays || f.MonthlyEstimate <= 0 {
+		return "low"
+	}
+	spread := (f.MonthlyHigh - f.MonthlyLow) / 2 / f.MonthlyEstimate
+	switch {
+	case f.DataDays >= 3*7 && spread <= 0.25:
+		return "high"
+	case spread <= 0.5:
+		return "medium"
+	default:
+		return "low"
+	}
 }
 
 // TimeFilter is a convenience re-export.
@@ -0,0 +1,186 @@
+// Package pricing prices model usage from a table of per-model prices.
+//
+// Each price names the model IDs it applies to with a glob pattern, such as
+// "*claude-sonnet-4-5*", which also matches dated and provider-prefixed IDs
+// like "us.anthropic.claude-sonnet-4-5-20250929-v1:0". A price may take
+// effect on a date, so usage recorded before a price change keeps the price
+// it was charged at.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Default cache multipliers, as multiples of the input price: writing to
+// the prompt cache costs a quarter more than plain input, and reading from
+// it a tenth.
+const (
+	DefaultCacheWrite = 1.25
+	DefaultCacheRead  = 0.1
+)
+
+// Builtin is the source of the built-in prices.
+const Builtin = "built-in"
+
+// Usage is the token usage of one model response.
+type Usage struct {
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+}
+
+// Price is what a model charges, in USD per million tokens.
+type Price struct {
+	Match      string    // Glob pattern matched against lowercased model IDs, see matches
+	Since      time.Time // When the price took effect; zero for always
+	Input      float64
+	Output     float64
+	CacheWrite float64 // Multiple of Input charged for cache writes
+	CacheRead  float64 // Multiple of Input charged for cache reads
+	Source     string  // Where the price came from, such as "config"
+}
+
+// Cost returns the cost in USD of usage at this price.
+func (p Price) Cost(u Usage) float64 {
+	input := p.Input / 1_000_000
+	return float64(u.InputTokens)*input +
+		float64(u.CacheCreationTokens)*input*p.CacheWrite +
+		float64(u.CacheReadTokens)*input*p.CacheRead +
+		float64(u.OutputTokens)*p.Output/1_000_000
+}
+
+// specificity is the number of literal characters in the pattern, so
+// "*claude-opus-4-5*" is more specific than "*claude-opus-4*".
+func (p Price) specificity() int {
+	n := 0
+	for _, r := range p.Match {
+		if r != '*' && r != '?' {
+			n++
+		}
+	}
+	return n
+}
+
+// builtinPrices are Anthropic's published list prices.
+var builtinPrices = []Price{
+	{Match: "*claude-opus-4-5*", Input: 5, Output: 25},
+	{Match: "*claude-opus-4-1*", Input: 15, Output: 75},
+	{Match: "*claude-opus-4*", Input: 15, Output: 75},
+	{Match: "*claude-3-opus*", Input: 15, Output: 75},
+	{Match: "*claude-sonnet-4-5*", Input: 3, Output: 15},
+	{Match: "*claude-sonnet-4*", Input: 3, Output: 15},
+	{Match: "*claude-3-7-sonnet*", Input: 3, Output: 15},
+	{Match: "*claude-3-5-sonnet*", Input: 3, Output: 15},
+	{Match: "*claude-haiku-4-5*", Input: 1, Output: 5},
+	{Match: "*claude-3-5-haiku*", Input: 0.8, Output: 4},
+	{Match: "*claude-3-haiku*", Input: 0.25, Output: 1.25},
+}
+
+// Table looks up the price of a model.
+type Table struct {
+	prices []Price
+}
+
+// New builds a table from prices, which take precedence over the built-in
+// prices for patterns that are equally specific. Unset cache multipliers
+// default to DefaultCacheWrite and DefaultCacheRead.
+func New(prices []Price) (*Table, error) {
+	t := &Table{prices: make([]Price, 0, len(prices)+len(builtinPrices))}
+	for _, p := range prices {
+		if p.Match == "" {
+			return nil, errors.New("price has no model pattern")
+		}
+		p.Match = strings.ToLower(p.Match)
+		if _, err := path.Match(p.Match, ""); err != nil {
+			return nil, fmt.Errorf("price for %q: %w", p.Match, err)
+		}
+		if p.Input < 0 || p.Output < 0 || p.CacheWrite < 0 || p.CacheRead < 0 {
+			return nil, fmt.Errorf("price for %q is negative", p.Match)
+		}
+		if p.CacheWrite == 0 {
+			p.CacheWrite = DefaultCacheWrite
+		}
+		if p.CacheRead == 0 {
+			p.CacheRead = DefaultCacheRead
+		}
+		t.prices = append(t.prices, p)
+	}
+	for _, p := range builtinPrices {
+		p.CacheWrite = DefaultCacheWrite
+		p.CacheRead = DefaultCacheRead
+		p.Source = Builtin
+		t.prices = append(t.prices, p)
+	}
+	return t, nil
+}
+
+// Lookup returns the price of model in effect at a time, or false if no
+// price matches. The most specific matching pattern wins; among prices with
+// the same specificity, configured prices win over built-in ones, and later
+// prices over earlier ones. A zero time means now.
+func (t *Table) Lookup(model string, at time.Time) (Price, bool) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	model = strings.ToLower(model)
+
+	var best Price
+	found := false
+	for _, p := range t.prices {
+		if !p.Since.IsZero() && at.Before(p.Since) {
+			continue
+		}
+		if !p.matches(model) {
+			continue
+		}
+		if !found || p.better(best) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// matches reports whether the pattern matches model, or the part of model
+// after its last "/". Glob stars do not cross "/", so this lets provider IDs
+// such as Bedrock inference profile ARNs match the bare model patterns.
+func (p Price) matches(model string) bool {
+	if ok, _ := path.Match(p.Match, model); ok {
+		return true
+	}
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		ok, _ := path.Match(p.Match, model[i+1:])
+		return ok
+	}
+	return false
+}
+
+// better reports whether p takes precedence over q when both match.
+func (p Price) better(q Price) bool {
+	if ps, qs := p.specificity(), q.specificity(); ps != qs {
+		return ps > qs
+	}
+	if (p.Source == Builtin) != (q.Source == Builtin) {
+		return q.Source == Builtin
+	}
+	return p.Since.After(q.Since)
+}
+
+// Cost returns the cost in USD of usage on model at a time, or 0 if the
+// model has no price.
+func (t *Table) Cost(model string, at time.Time, u Usage) float64 {
+	p, ok := t.Lookup(model, at)
+	if !ok {
+		return 0
+	}
+	return p.Cost(u)
+}
+
+// Prices returns every price in the table, configured prices first.
+func (t *Table) Prices() []Price {
+	return append([]Price(nil), t.prices...)
+}
@@ -0,0 +1,88 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	change := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	table, err := New([]Price{
+		{Match: "*claude-sonnet-4-5*", Input: 2, Output: 10, Source: "config"},
+		{Match: "*claude-sonnet-4-5*", Since: change, Input: 4, Output: 20, Source: "config"},
+		{Match: "Internal-*", Input: 1, Output: 1, CacheRead: 0.5, Source: "config"},
+		{Match: "*opus*", Input: 15, Output: 75, Source: "family"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	before := change.Add(-time.Hour)
+	tests := []struct {
+		model     string
+		at        time.Time
+		wantMatch string
+		wantInput float64
+		wantFound bool
+	}{
+		{"claude-opus-4-5-20251101", before, "*claude-opus-4-5*", 5, true},
+		{"claude-opus-4-20250514", before, "*claude-opus-4*", 15, true},
+		{"us.anthropic.claude-haiku-4-5-20251001-v1:0", before, "*claude-haiku-4-5*", 1, true},
+		{"opus", before, "*opus*", 15, true},
+		{"arn:aws:bedrock:us-east-1:123456789012:inference-profile/us.anthropic.claude-haiku-4-5-20251001-v1:0", before, "*claude-haiku-4-5*", 1, true},
+		// Configured prices win over built-in ones for the same pattern,
+		// and a dated price only from its date
+		{"claude-sonnet-4-5-20250929", before, "*claude-sonnet-4-5*", 2, true},
+		{"claude-sonnet-4-5-20250929", change, "*claude-sonnet-4-5*", 4, true},
+		{"claude-sonnet-4-20250514", change, "*claude-sonnet-4*", 3, true},
+		{"INTERNAL-model", before, "internal-*", 1, true},
+		{"gpt-5", before, "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			p, ok := table.Lookup(tt.model, tt.at)
+			if ok != tt.wantFound || p.Match != tt.wantMatch || p.Input != tt.wantInput {
+				t.Errorf("Lookup(%q) = %q $%.2f %v, want %q $%.2f %v", tt.model, p.Match, p.Input, ok, tt.wantMatch, tt.wantInput, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestCost(t *testing.T) {
+	table, err := New([]Price{{Match: "custom", Input: 10, Output: 20, CacheRead: 0.5}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	u := Usage{InputTokens: 1_000_000, OutputTokens: 500_000, CacheCreationTokens: 1_000_000, CacheReadTokens: 2_000_000}
+	// 10 input + 10 output + 12.50 cache writes + 10 cache reads
+	if got := table.Cost("custom", time.Time{}, u); math.Abs(got-42.5) > 1e-9 {
+		t.Errorf("expected $42.50, got $%.4f", got)
+	}
+	// 3 input + 7.50 output + 3.75 cache writes + 0.60 cache reads
+	if got := table.Cost("claude-sonnet-4-5", time.Time{}, u); math.Abs(got-14.85) > 1e-9 {
+		t.Errorf("expected $14.85, got $%.4f", got)
+	}
+	if got := table.Cost("unknown", time.Time{}, u); got != 0 {
+		t.Errorf("expected no cost for an unknown model, got $%.4f", got)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		price Price
+	}{
+		{"no pattern", Price{Input: 1}},
+		{"bad pattern", Price{Match: "claude-[", Input: 1}},
+		{"negative", Price{Match: "claude-*", Input: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]Price{tt.price}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpConn talks to a server over the streamable HTTP transport: each
+// message is POSTed, and the response is either JSON or an event stream.
+type httpConn struct {
+	url       string
+	client    *http.Client
+	sessionID string // Mcp-Session-Id assigned by the server, if any
+	protocol  string // Negotiated protocol version, sent once initialized
+	nextID    int64
+}
+
+func newHTTPConn(url string) *httpConn {
+	return &httpConn{url: url, client: &http.Client{}}
+}
+
+func (c *httpConn) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.nextID++
+	id := c.nextID
+	resp, err := c.post(ctx, request{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if method == "initialize" {
+		c.sessionID = resp.Header.Get("Mcp-Session-Id")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var m message
+	if mediaType == "text/event-stream" {
+		m, err = readEventStream(resp.Body, id)
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(&m)
+	}
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if !m.isResponseTo(id) {
+		return fmt.Errorf("response does not match request %d", id)
+	}
+
+	if method == "initialize" {
+		var init initializeResult
+		if err := m.decode(&init); err == nil {
+			c.protocol = init.ProtocolVersion
+		}
+	}
+	return m.decode(result)
+}
+
+func (c *httpConn) notify(ctx context.Context, method string) error {
+	resp, err := c.post(ctx, request{JSONRPC: "2.0", Method: method})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a message, failing on any status other than 200 and 202.
+func (c *httpConn) post(ctx context.Context, r request) (*http.Response, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.setSessionHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if text := strings.TrimSpace(string(msg)); text != "" {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, text)
+		}
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (c *httpConn) setSessionHeaders(req *http.Request) {
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	if c.protocol != "" {
+		req.Header.Set("MCP-Protocol-Version", c.protocol)
+	}
+}
+
+// close ends the server's session, if it assigned one.
+func (c *httpConn) close() error {
+	if c.sessionID == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.url, nil)
+	if err != nil {
+		return err
+	}
+	c.setSessionHeaders(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// readEventStream reads server-sent events until the response to the
+// request with id arrives. Other messages on the stream are skipped.
+func readEventStream(r io.Reader, id int64) (message, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			data.WriteByte('\n')
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		// A blank line ends the event
+		var m message
+		if err := json.Unmarshal([]byte(data.String()), &m); err == nil && m.isResponseTo(id) {
+			return m, nil
+		}
+		data.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return message{}, err
+	}
+	return message{}, io.ErrUnexpectedEOF
+}
@@ -0,0 +1,280 @@
+// Package probe health-checks configured MCP servers by launching or
+// connecting to them directly and performing the MCP handshake, so servers
+// Claude never called can still be seen to start, or fail to.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+	"github.com/anthropics/mcp-lens/internal/storage"
+	"github.com/anthropics/mcp-lens/internal/tokens"
+)
+
+// ProtocolVersion is the MCP protocol version offered in initialize.
+const ProtocolVersion = "2025-06-18"
+
+// maxToolPages bounds tools/list pagination against a server that never
+// stops returning cursors.
+const maxToolPages = 100
+
+// Config configures probing.
+type Config struct {
+	Timeout       time.Duration // Time allowed for each server's whole handshake (default: 10s)
+	Concurrency   int           // Servers probed at once by ProbeAll (default: 4)
+	ClientVersion string        // Version reported in clientInfo
+}
+
+// DefaultConfig returns default configuration.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       10 * time.Second,
+		Concurrency:   4,
+		ClientVersion: "dev",
+	}
+}
+
+// Prober performs MCP handshakes with configured servers.
+type Prober struct {
+	config Config
+}
+
+// New creates a new prober.
+func New(config Config) *Prober {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return &Prober{config: config}
+}
+
+// Probeable reports whether a server can be probed: stdio servers with a
+// command, and http servers at a local URL. Remote servers are left alone,
+// since probing them would need their credentials and reach the network.
+func Probeable(s mcpconfig.Server) bool {
+	switch s.Transport {
+	case "stdio":
+		return s.Command != ""
+	case "http":
+		return isLocalURL(s.URL)
+	default:
+		return false
+	}
+}
+
+// isLocalURL reports whether rawURL points at this machine.
+func isLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ProbeAll probes servers concurrently, returning results in the same order.
+func (p *Prober) ProbeAll(ctx context.Context, servers []mcpconfig.Server) []storage.ProbeResult {
+	results := make([]storage.ProbeResult, len(servers))
+	sem := make(chan struct{}, p.config.Concurrency)
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s mcpconfig.Server) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = p.Probe(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+	return results
+}
+
+// Probe launches or connects to a server, initializes a session and lists
+// its tools, keeping their definitions with estimated token costs. Failures
+// are reported in the result's Error.
+func (p *Prober) Probe(ctx context.Context, s mcpconfig.Server) storage.ProbeResult {
+	result := storage.ProbeResult{
+		ServerName: s.ID(),
+		Transport:  s.Transport,
+		ProbedAt:   time.Now(),
+	}
+	if err := p.probe(ctx, s, &result); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (p *Prober) probe(ctx context.Context, s mcpconfig.Server, result *storage.ProbeResult) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	var c conn
+	var err error
+	switch {
+	case s.Transport == "stdio" && s.Command != "":
+		c, err = startStdio(ctx, s)
+	case s.Transport == "http":
+		c = newHTTPConn(s.URL)
+	default:
+		return fmt.Errorf("cannot probe %s servers", s.Transport)
+	}
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	var init initializeResult
+	if err := c.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "mcp-lens", "version": p.config.ClientVersion},
+	}, &init); err != nil {
+		return wrapTimeout(ctx, "initialize", err)
+	}
+	result.StartupMs = time.Since(start).Milliseconds()
+	result.InfoName = init.ServerInfo.Name
+	result.InfoVersion = init.ServerInfo.Version
+	result.ProtocolVersion = init.ProtocolVersion
+
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		return wrapTimeout(ctx, "initialized", err)
+	}
+
+	listStart := time.Now()
+	cursor := ""
+	for page := 0; page < maxToolPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var tools ToolsListResult
+		if err := c.call(ctx, "tools/list", params, &tools); err != nil {
+			return wrapTimeout(ctx, "tools/list", err)
+		}
+		for _, t := range tools.Tools {
+			result.Tools = append(result.Tools, t.Definition(result.ProbedAt))
+		}
+		result.ToolCount += len(tools.Tools)
+		if cursor = tools.NextCursor; cursor == "" {
+			break
+		}
+	}
+	result.HandshakeMs = time.Since(listStart).Milliseconds()
+	return nil
+}
+
+// wrapTimeout names the step a probe failed at, reporting a timeout as such
+// rather than as whatever error the interrupted read returned.
+func wrapTimeout(ctx context.Context, step string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s: timed out", step)
+	}
+	return fmt.Errorf("%s: %w", step, err)
+}
+
+// conn is a JSON-RPC connection to an MCP server.
+type conn interface {
+	call(ctx context.Context, method string, params interface{}, result interface{}) error
+	notify(ctx context.Context, method string) error
+	close() error
+}
+
+// request is an outgoing JSON-RPC request, or a notification when ID is nil.
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// message is an incoming JSON-RPC response, request or notification.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+// isResponseTo reports whether the message answers the request with id.
+func (m message) isResponseTo(id int64) bool {
+	return m.Method == "" && strings.TrimSpace(string(m.ID)) == fmt.Sprint(id)
+}
+
+// decode returns the message's error, or decodes its result into v.
+func (m message) decode(v interface{}) error {
+	if m.Error != nil {
+		return m.Error
+	}
+	if v == nil || len(m.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(m.Result, v); err != nil {
+		return fmt.Errorf("decoding result: %w", err)
+	}
+	return nil
+}
+
+// rpcError is a JSON-RPC error object.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("MCP error %d: %s", e.Code, e.Message)
+}
+
+type initializeResult struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	ServerInfo      struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"serverInfo"`
+}
+
+// ToolsListResult is the result of a tools/list request.
+type ToolsListResult struct {
+	Tools      []ListedTool `json:"tools"`
+	NextCursor string       `json:"nextCursor"`
+}
+
+// ListedTool is a tool as listed by tools/list.
+type ListedTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// Definition converts a listed tool to the form stored, estimating the
+// tokens its definition takes up in the model's context.
+func (t ListedTool) Definition(capturedAt time.Time) storage.ToolDefinition {
+	schema := string(t.InputSchema)
+	var compact bytes.Buffer
+	if json.Compact(&compact, t.InputSchema) == nil {
+		schema = compact.String()
+	}
+	return storage.ToolDefinition{
+		ToolName:    t.Name,
+		Description: t.Description,
+		InputSchema: schema,
+		Tokens:      tokens.EstimateTool(t.Name, t.Description, schema),
+		CapturedAt:  capturedAt,
+	}
+}
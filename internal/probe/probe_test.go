@@ -0,0 +1,264 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+)
+
+// stubEnv selects the stub server mode when the test binary is launched as
+// a stdio MCP server.
+const stubEnv = "MCP_LENS_PROBE_STUB"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(stubEnv); mode != "" {
+		runStub(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runStub serves MCP over stdio. "ok" answers the handshake with two pages
+// of tools, pinging the client and logging first; "crash" fails on start;
+// "hang" never answers.
+func runStub(mode string) {
+	switch mode {
+	case "crash":
+		fmt.Fprintln(os.Stderr, "starting stub")
+		fmt.Fprintln(os.Stderr, "error: GITHUB_TOKEN is not set")
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		return
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "initialize":
+			fmt.Fprintln(os.Stdout, "not json")
+			out.Encode(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/message", "params": map[string]string{"level": "info"}})
+			out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": "srv-1", "method": "ping"})
+			result = map[string]interface{}{
+				"protocolVersion": ProtocolVersion,
+				"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+				"serverInfo":      map[string]string{"name": "stub-server", "version": os.Getenv("STUB_VERSION")},
+			}
+		case "tools/list":
+			if strings.Contains(string(req.Params), "page2") {
+				result = map[string]interface{}{"tools": []map[string]string{{"name": "c"}}}
+			} else {
+				result = map[string]interface{}{"tools": []map[string]interface{}{
+					{"name": "a", "description": "Search the index", "inputSchema": map[string]interface{}{
+						"type": "object", "properties": map[string]interface{}{"query": map[string]string{"type": "string"}},
+					}},
+					{"name": "b"},
+				}, "nextCursor": "page2"}
+			}
+		default:
+			out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"}})
+			continue
+		}
+		out.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+	}
+}
+
+// stubServer returns a stdio server that runs this test binary as the stub.
+func stubServer(mode string) mcpconfig.Server {
+	return mcpconfig.Server{
+		Name:      "stub",
+		Scope:     mcpconfig.ScopeUser,
+		Transport: "stdio",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=^$"},
+		Env:       map[string]string{stubEnv: mode, "STUB_VERSION": "${STUB_MISSING:-1.2.3}"},
+	}
+}
+
+func TestProbe_Stdio(t *testing.T) {
+	prober := New(DefaultConfig())
+
+	result := prober.Probe(context.Background(), stubServer("ok"))
+	if !result.OK() {
+		t.Fatalf("probe failed: %s", result.Error)
+	}
+	if result.ServerName != "stub" || result.Transport != "stdio" {
+		t.Errorf("unexpected server %s over %s", result.ServerName, result.Transport)
+	}
+	if result.InfoName != "stub-server" || result.InfoVersion != "1.2.3" || result.ProtocolVersion != ProtocolVersion {
+		t.Errorf("unexpected server info %s %s %s", result.InfoName, result.InfoVersion, result.ProtocolVersion)
+	}
+	if result.ToolCount != 3 {
+		t.Errorf("expected 3 tools across 2 pages, got %d", result.ToolCount)
+	}
+	if result.StartupMs <= 0 {
+		t.Errorf("expected the startup time to be recorded, got %dms", result.StartupMs)
+	}
+
+	if len(result.Tools) != 3 {
+		t.Fatalf("expected 3 tool definitions, got %d", len(result.Tools))
+	}
+	search := result.Tools[0]
+	if search.ToolName != "a" || search.Description != "Search the index" {
+		t.Errorf("unexpected tool definition: %+v", search)
+	}
+	if search.InputSchema != `{"properties":{"query":{"type":"string"}},"type":"object"}` {
+		t.Errorf("expected the compacted input schema, got %s", search.InputSchema)
+	}
+	if search.Tokens <= result.Tools[1].Tokens {
+		t.Errorf("expected a described tool with a schema to cost more than a bare one, got %d and %d",
+			search.Tokens, result.Tools[1].Tokens)
+	}
+}
+
+func TestProbe_StdioFailures(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = 500 * time.Millisecond
+	prober := New(config)
+
+	missing := stubServer("ok")
+	missing.Command = "/nonexistent/mcp-server"
+
+	tests := []struct {
+		name   string
+		server mcpconfig.Server
+		want   string
+	}{
+		{"crash", stubServer("crash"), "GITHUB_TOKEN is not set"},
+		{"hang", stubServer("hang"), "initialize: timed out"},
+		{"missing command", missing, "starting /nonexistent/mcp-server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := prober.Probe(context.Background(), tt.server)
+			if result.OK() {
+				t.Fatal("expected the probe to fail")
+			}
+			if !strings.Contains(result.Error, tt.want) {
+				t.Errorf("error %q does not mention %q", result.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbe_HTTP(t *testing.T) {
+	var deleted bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = r.Header.Get("Mcp-Session-Id") == "sess-1"
+			return
+		}
+		var req struct {
+			ID     *int64 `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch req.Method {
+		case "initialize":
+			w.Header().Set("Mcp-Session-Id", "sess-1")
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": *req.ID, "result": map[string]interface{}{
+				"protocolVersion": ProtocolVersion,
+				"serverInfo":      map[string]string{"name": "http-stub", "version": "0.1.0"},
+			}})
+		case "notifications/initialized":
+			w.WriteHeader(http.StatusAccepted)
+		case "tools/list":
+			if r.Header.Get("Mcp-Session-Id") != "sess-1" {
+				http.Error(w, "missing session", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":{\"tools\":[{\"name\":\"a\"},{\"name\":\"b\"}]}}\n\n", *req.ID)
+		}
+	}))
+	defer srv.Close()
+
+	server := mcpconfig.Server{Name: "local", Transport: "http", URL: srv.URL + "/mcp"}
+	if !Probeable(server) {
+		t.Fatal("expected a local http server to be probeable")
+	}
+
+	result := New(DefaultConfig()).Probe(context.Background(), server)
+	if !result.OK() {
+		t.Fatalf("probe failed: %s", result.Error)
+	}
+	if result.InfoName != "http-stub" || result.ToolCount != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !deleted {
+		t.Error("expected the session to be deleted")
+	}
+}
+
+func TestProbeable(t *testing.T) {
+	tests := []struct {
+		server mcpconfig.Server
+		want   bool
+	}{
+		{mcpconfig.Server{Transport: "stdio", Command: "npx"}, true},
+		{mcpconfig.Server{Transport: "stdio"}, false},
+		{mcpconfig.Server{Transport: "http", URL: "http://localhost:3000/mcp"}, true},
+		{mcpconfig.Server{Transport: "http", URL: "http://127.0.0.1:8080/mcp"}, true},
+		{mcpconfig.Server{Transport: "http", URL: "http://[::1]:8080/mcp"}, true},
+		{mcpconfig.Server{Transport: "http", URL: "https://api.githubcopilot.com/mcp/"}, false},
+		{mcpconfig.Server{Transport: "sse", URL: "http://localhost:3000/sse"}, false},
+	}
+	for _, tt := range tests {
+		if got := Probeable(tt.server); got != tt.want {
+			t.Errorf("Probeable(%s %s%s) = %v, want %v", tt.server.Transport, tt.server.Command, tt.server.URL, got, tt.want)
+		}
+	}
+}
+
+func TestProbeAll(t *testing.T) {
+	servers := []mcpconfig.Server{stubServer("ok"), stubServer("crash"), stubServer("ok")}
+	results := New(DefaultConfig()).ProbeAll(context.Background(), servers)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].OK() || results[1].OK() || !results[2].OK() {
+		t.Errorf("results out of order: %q, %q, %q", results[0].Error, results[1].Error, results[2].Error)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	lookup := envLookup(mcpconfig.Server{Root: "/plugins/db"})
+	t.Setenv("PROBE_TEST_VAR", "set")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"${CLAUDE_PLUGIN_ROOT}/bin/server", "/plugins/db/bin/server"},
+		{"--token=${PROBE_TEST_VAR}", "--token=set"},
+		{"${PROBE_TEST_UNSET:-fallback}", "fallback"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := expand(tt.in, lookup); got != tt.want {
+			t.Errorf("expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
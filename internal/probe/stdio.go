@@ -0,0 +1,249 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
+)
+
+// maxStderr is how much of a server's stderr is kept for error messages.
+const maxStderr = 4096
+
+// stdioConn talks to a server process over newline-delimited JSON on its
+// stdin and stdout.
+type stdioConn struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	messages chan message // Closed when stdout closes
+	stderr   *tailBuffer
+	nextID   int64
+	mu       sync.Mutex // Serializes writes
+	waitOnce sync.Once
+	exited   chan struct{} // Closed once the process has exited
+	done     chan struct{} // Closed when the probe is finished with the server
+}
+
+// startStdio launches a stdio server. The process is killed when ctx ends.
+func startStdio(ctx context.Context, s mcpconfig.Server) (*stdioConn, error) {
+	lookup := envLookup(s)
+	args := make([]string, len(s.Args))
+	for i, a := range s.Args {
+		args[i] = expand(a, lookup)
+	}
+
+	cmd := exec.CommandContext(ctx, expand(s.Command, lookup), args...)
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		cmd.Env = append(cmd.Env, k+"="+expand(v, lookup))
+	}
+	switch {
+	case s.Scope == mcpconfig.ScopePlugin && s.Root != "":
+		cmd.Dir = s.Root
+	case s.Project != "":
+		cmd.Dir = s.Project
+	}
+	cmd.WaitDelay = time.Second
+
+	c := &stdioConn{
+		cmd:      cmd,
+		messages: make(chan message, 16),
+		stderr:   &tailBuffer{max: maxStderr},
+		exited:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	cmd.Stderr = c.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", s.Command, err)
+	}
+	c.stdin = stdin
+
+	go c.read(stdout)
+	return c, nil
+}
+
+// read decodes messages from stdout until it closes.
+func (c *stdioConn) read(stdout io.Reader) {
+	defer close(c.messages)
+	r := bufio.NewReader(stdout)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var m message
+			if jsonErr := json.Unmarshal(line, &m); jsonErr == nil {
+				select {
+				case c.messages <- m:
+				case <-c.done:
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *stdioConn) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (c *stdioConn) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.nextID++
+	id := c.nextID
+	if err := c.write(request{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return c.exitError(fmt.Errorf("writing request: %w", err))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-c.messages:
+			if !ok {
+				// Stdout is drained, so waiting for the exit is safe
+				c.wait()
+				select {
+				case <-c.exited:
+				case <-time.After(time.Second):
+				}
+				return c.exitError(fmt.Errorf("server closed its output"))
+			}
+			if m.isResponseTo(id) {
+				return m.decode(result)
+			}
+			if m.Method != "" && len(m.ID) > 0 {
+				c.answer(m)
+			}
+		}
+	}
+}
+
+// answer replies to a request from the server: ping succeeds and anything
+// else is not supported by the probe.
+func (c *stdioConn) answer(m message) {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": m.ID}
+	if m.Method == "ping" {
+		reply["result"] = map[string]interface{}{}
+	} else {
+		reply["error"] = rpcError{Code: -32601, Message: "method not found"}
+	}
+	c.write(reply)
+}
+
+func (c *stdioConn) notify(ctx context.Context, method string) error {
+	if err := c.write(request{JSONRPC: "2.0", Method: method}); err != nil {
+		return c.exitError(fmt.Errorf("writing notification: %w", err))
+	}
+	return nil
+}
+
+// exitError adds the end of the server's stderr to an error, since a server
+// that fails to start usually says why there.
+func (c *stdioConn) exitError(err error) error {
+	if tail := c.stderr.lastLine(); tail != "" {
+		return fmt.Errorf("%w: %s", err, tail)
+	}
+	return err
+}
+
+// close ends the session by closing stdin, as the stdio transport
+// specifies, and kills the server if it does not exit promptly.
+func (c *stdioConn) close() error {
+	close(c.done)
+	c.stdin.Close()
+	c.wait()
+	select {
+	case <-c.exited:
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+		<-c.exited
+	}
+	return nil
+}
+
+// wait reaps the process in the background, closing exited once it exits.
+func (c *stdioConn) wait() {
+	c.waitOnce.Do(func() {
+		go func() {
+			c.cmd.Wait()
+			close(c.exited)
+		}()
+	})
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last non-empty line written.
+func (b *tailBuffer) lastLine() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(string(b.buf)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// envLookup resolves variables in a server's command, arguments and
+// environment the way Claude Code does: from the process environment, with
+// CLAUDE_PLUGIN_ROOT set to a plugin's install directory.
+func envLookup(s mcpconfig.Server) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if name == "CLAUDE_PLUGIN_ROOT" && s.Root != "" {
+			return s.Root, true
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// expand replaces ${VAR} and ${VAR:-default} in s.
+func expand(s string, lookup func(string) (string, bool)) string {
+	return os.Expand(s, func(name string) string {
+		def := ""
+		if i := strings.Index(name, ":-"); i >= 0 {
+			name, def = name[:i], name[i+2:]
+		}
+		if v, ok := lookup(name); ok && v != "" {
+			return v
+		}
+		return def
+	})
+}
@@ -0,0 +1,285 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// hopHeaders apply to a single connection and are not forwarded.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// HTTP relays a remote MCP server's Streamable HTTP transport, or the older
+// HTTP+SSE one, recording the same calls as RunStdio. Requests to "/" or to
+// the upstream URL's path go to the upstream URL; any other path goes to the
+// same path on the upstream host, so endpoints a server advertises keep
+// working. Headers, including Mcp-Session-Id, pass through in both
+// directions, and event streams are relayed event by event as they arrive.
+//
+// Requests that fail upstream are recorded with a failure class: the HTTP
+// status of an error response, or whether the TLS handshake, the connection
+// or a timeout failed.
+type HTTP struct {
+	upstream *url.URL
+	client   *http.Client
+	writer   *writer
+	tracker  *tracker
+}
+
+// NewHTTP creates an HTTP proxy for the MCP server at upstream. Call Close
+// when it is no longer serving.
+func NewHTTP(config Config, recorder Recorder, upstream string) (*HTTP, error) {
+	u, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("parsing upstream URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("upstream URL must be http or https: %q", upstream)
+	}
+
+	w := newWriter(config, recorder)
+	return &HTTP{
+		upstream: u,
+		client: &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+			// Redirects are the client's to follow
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		writer:  w,
+		tracker: newTracker(config.ServerName, w),
+	}, nil
+}
+
+// Close records requests still awaiting a response and writes everything
+// recorded, reporting the first storage error or how many calls were
+// dropped.
+func (h *HTTP) Close() error {
+	h.client.CloseIdleConnections()
+	h.tracker.abandon(time.Now(), "no response before the proxy stopped")
+	return h.writer.close()
+}
+
+// ServeHTTP forwards a request upstream and relays the response.
+func (h *HTTP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "mcp-lens proxy: reading request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out, err := http.NewRequestWithContext(r.Context(), r.Method, h.target(r.URL), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, "mcp-lens proxy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	copyHeaders(out.Header, r.Header)
+	// The transport negotiates compression itself and decompresses, so
+	// messages can be read
+	out.Header.Del("Accept-Encoding")
+
+	var keys []string
+	if r.Method == http.MethodPost {
+		keys = h.tracker.observe(FromClient, body, time.Now())
+	}
+
+	resp, err := h.client.Do(out)
+	if err != nil {
+		if r.Context().Err() != nil {
+			h.tracker.fail(keys, time.Now(), storage.FailureCancelled, 0, "client disconnected")
+			return
+		}
+		failure := classify(err)
+		h.tracker.fail(keys, time.Now(), failure, 0, err.Error())
+		http.Error(w, fmt.Sprintf("mcp-lens proxy: %s: %v", failure, err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	copyHeaders(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		w.Write(data)
+		msg := fmt.Sprintf("HTTP %d", resp.StatusCode)
+		if snippet := strings.TrimSpace(string(data)); snippet != "" {
+			msg += ": " + truncate(snippet, 200)
+		}
+		h.tracker.fail(keys, time.Now(), storage.FailureHTTPStatus, resp.StatusCode, msg)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		h.relayEvents(w, resp.Body)
+	case "application/json":
+		data, err := io.ReadAll(resp.Body)
+		w.Write(data)
+		if err != nil {
+			h.tracker.fail(keys, time.Now(), classify(err), 0, err.Error())
+			return
+		}
+		h.tracker.observe(FromServer, data, time.Now())
+	default:
+		// Anything else, such as 202 Accepted for notifications or the older
+		// transport's POSTs, whose responses arrive on the event stream
+		io.Copy(w, resp.Body)
+		return
+	}
+
+	// A POST's responses come in its own reply, so any still missing
+	// never will
+	if r.Context().Err() != nil {
+		h.tracker.fail(keys, time.Now(), storage.FailureCancelled, 0, "client disconnected")
+	} else {
+		h.tracker.fail(keys, time.Now(), storage.FailureNoResponse, 0, "no response before the reply ended")
+	}
+}
+
+// target returns the upstream URL for a request to the proxy.
+func (h *HTTP) target(u *url.URL) string {
+	t := *h.upstream
+	if u.Path != "/" && u.Path != "" && u.Path != h.upstream.Path {
+		t.Path, t.RawPath, t.RawQuery = u.Path, u.RawPath, u.RawQuery
+		return t.String()
+	}
+	if u.RawQuery != "" {
+		if t.RawQuery != "" {
+			t.RawQuery += "&" + u.RawQuery
+		} else {
+			t.RawQuery = u.RawQuery
+		}
+	}
+	return t.String()
+}
+
+// relayEvents copies a server-sent event stream to w one event at a time,
+// flushing each so it reaches the client as soon as it arrives, and records
+// the messages the events carry. The older transport's endpoint event is
+// rewritten to point at the proxy when it names the upstream host.
+func (h *HTTP) relayEvents(w http.ResponseWriter, body io.Reader) {
+	flusher := http.NewResponseController(w)
+	reader := bufio.NewReaderSize(body, 64*1024)
+	origin := h.upstream.Scheme + "://" + h.upstream.Host
+
+	var event bytes.Buffer
+	var eventType string
+	var data []string
+	dispatch := func() {
+		if event.Len() == 0 {
+			return
+		}
+		w.Write(event.Bytes())
+		flusher.Flush()
+		if len(data) > 0 && (eventType == "" || eventType == "message") {
+			h.tracker.observe(FromServer, []byte(strings.Join(data, "\n")), time.Now())
+		}
+		event.Reset()
+		eventType, data = "", nil
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			field, value := parseEventLine(line)
+			switch field {
+			case "event":
+				eventType = value
+			case "data":
+				if eventType == "endpoint" && strings.HasPrefix(value, origin+"/") {
+					line = []byte("data: " + strings.TrimPrefix(value, origin) + "\n")
+				}
+				data = append(data, value)
+			}
+			event.Write(line)
+			if len(bytes.TrimRight(line, "\r\n")) == 0 {
+				dispatch()
+			}
+		}
+		if err != nil {
+			dispatch()
+			return
+		}
+	}
+}
+
+// parseEventLine splits a server-sent event line into its field and value.
+func parseEventLine(line []byte) (field, value string) {
+	s := strings.TrimRight(string(line), "\r\n")
+	field, value, found := strings.Cut(s, ":")
+	if !found {
+		return field, ""
+	}
+	return field, strings.TrimPrefix(value, " ")
+}
+
+// copyHeaders adds the end-to-end headers in src to dst.
+func copyHeaders(dst, src http.Header) {
+	for name, values := range src {
+		for _, v := range values {
+			dst.Add(name, v)
+		}
+	}
+	for _, name := range hopHeaders {
+		dst.Del(name)
+	}
+}
+
+// classify returns the failure class of an error reaching the upstream.
+func classify(err error) string {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return storage.FailureTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return storage.FailureTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return storage.FailureTimeout
+	}
+	return storage.FailureConnect
+}
+
+// truncate shortens s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,255 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// streamableStub is a stand-in Streamable HTTP MCP server. initialize starts
+// a session, which every later request must carry; tools/list answers over
+// an event stream after a progress notification, and tools/call of "broken"
+// fails with isError.
+func streamableStub() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if req.Method != "initialize" && r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "initialize":
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			reply["result"] = map[string]interface{}{"protocolVersion": "2025-06-18"}
+		case "tools/list":
+			reply["result"] = map[string]interface{}{"tools": []map[string]interface{}{
+				{"name": "search", "description": "Search", "inputSchema": map[string]string{"type": "object"}},
+			}}
+			data, _ := json.Marshal(reply)
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\",\"params\":{}}\n\n")
+			w.(http.Flusher).Flush()
+			fmt.Fprintf(w, "id: 2\ndata: %s\n\n", data)
+			return
+		case "tools/call":
+			reply["result"] = map[string]interface{}{"content": []interface{}{}, "isError": req.Params.Name == "broken"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reply)
+	}))
+}
+
+func newTestHTTP(t *testing.T, upstream string) (*HTTP, *httptest.Server, *storage.MockStore) {
+	t.Helper()
+	store := storage.NewMockStore()
+	config := DefaultConfig()
+	config.ServerName = "remote"
+	h, err := NewHTTP(config, store, upstream)
+	if err != nil {
+		t.Fatalf("NewHTTP failed: %v", err)
+	}
+	front := httptest.NewServer(h)
+	t.Cleanup(front.Close)
+	return h, front, store
+}
+
+func post(t *testing.T, url, session, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if session != "" {
+		req.Header.Set("Mcp-Session-Id", session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	return resp
+}
+
+func TestHTTP(t *testing.T) {
+	upstream := streamableStub()
+	defer upstream.Close()
+	h, front, store := newTestHTTP(t, upstream.URL+"/mcp")
+
+	resp := post(t, front.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	resp.Body.Close()
+	session := resp.Header.Get("Mcp-Session-Id")
+	if session != "session-1" {
+		t.Fatalf("expected the session header to be passed back, got %q", session)
+	}
+
+	post(t, front.URL, session, `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+
+	resp = post(t, front.URL, session, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	stream, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") || strings.Count(string(stream), "data: ") != 2 {
+		t.Errorf("expected the event stream to be relayed, got %q", stream)
+	}
+
+	post(t, front.URL, session, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search"}}`).Body.Close()
+	post(t, front.URL, session, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"broken"}}`).Body.Close()
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	stats, err := store.GetProxyStats(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetProxyStats failed: %v", err)
+	}
+	byKey := make(map[string]storage.ProxyMethodStats)
+	for _, st := range stats {
+		byKey[st.Kind+" "+st.Direction+" "+st.Method+" "+st.ToolName] = st
+	}
+	tests := []struct {
+		key    string
+		calls  int64
+		errors int64
+	}{
+		{"request client initialize ", 1, 0},
+		{"notification client notifications/initialized ", 1, 0},
+		{"request client tools/list ", 1, 0},
+		{"notification server notifications/progress ", 1, 0},
+		{"request client tools/call search", 1, 0},
+		{"request client tools/call broken", 1, 1},
+	}
+	for _, tt := range tests {
+		st, ok := byKey[tt.key]
+		if !ok {
+			t.Errorf("no calls recorded for %q", tt.key)
+			continue
+		}
+		if st.Calls != tt.calls || st.Errors != tt.errors {
+			t.Errorf("%q: %d calls %d errors, want %d and %d", tt.key, st.Calls, st.Errors, tt.calls, tt.errors)
+		}
+	}
+	if len(stats) != len(tests) {
+		t.Errorf("expected %d groups, got %d: %+v", len(tests), len(stats), stats)
+	}
+
+	tools, _ := store.GetToolDefinitions(context.Background(), "")
+	if len(tools) != 1 || tools[0].ServerName != "remote" {
+		t.Errorf("expected tools/list over the event stream to record tool definitions, got %+v", tools)
+	}
+}
+
+func TestHTTP_Failures(t *testing.T) {
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer unauthorized.Close()
+
+	untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer untrusted.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	tests := []struct {
+		name       string
+		upstream   string
+		wantStatus int
+		failure    string
+		httpStatus int
+	}{
+		{"error status", unauthorized.URL, http.StatusUnauthorized, storage.FailureHTTPStatus, http.StatusUnauthorized},
+		{"untrusted certificate", untrusted.URL, http.StatusBadGateway, storage.FailureTLS, 0},
+		{"connection refused", closed.URL, http.StatusBadGateway, storage.FailureConnect, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, front, store := newTestHTTP(t, tt.upstream)
+
+			resp := post(t, front.URL, "", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}}`)
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if err := h.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			failures, err := store.GetProxyFailures(context.Background(), storage.TimeFilter{})
+			if err != nil {
+				t.Fatalf("GetProxyFailures failed: %v", err)
+			}
+			if len(failures) != 1 {
+				t.Fatalf("expected 1 failure class, got %+v", failures)
+			}
+			f := failures[0]
+			if f.Failure != tt.failure || f.HTTPStatus != tt.httpStatus || f.Count != 1 || f.ServerName != "remote" {
+				t.Errorf("expected one %s failure with status %d, got %+v", tt.failure, tt.httpStatus, f)
+			}
+		})
+	}
+}
+
+func TestHTTP_Target(t *testing.T) {
+	upstream, _ := url.Parse("https://mcp.example.com/v1/mcp?tenant=a")
+	h := &HTTP{upstream: upstream}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "https://mcp.example.com/v1/mcp?tenant=a"},
+		{"/v1/mcp", "https://mcp.example.com/v1/mcp?tenant=a"},
+		{"/?x=1", "https://mcp.example.com/v1/mcp?tenant=a&x=1"},
+		{"/messages?sessionId=7", "https://mcp.example.com/messages?sessionId=7"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse("http://127.0.0.1" + tt.path)
+		if got := h.target(u); got != tt.want {
+			t.Errorf("target(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHTTP_EndpointRewrite(t *testing.T) {
+	var upstream *httptest.Server
+	upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: endpoint\ndata: %s/messages?sessionId=7\n\n", upstream.URL)
+	}))
+	defer upstream.Close()
+	h, front, _ := newTestHTTP(t, upstream.URL+"/sse")
+	defer h.Close()
+
+	resp, err := http.Get(front.URL + "/sse")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if want := "event: endpoint\ndata: /messages?sessionId=7\n\n"; string(body) != want {
+		t.Errorf("expected the endpoint to point at the proxy, got %q", body)
+	}
+}
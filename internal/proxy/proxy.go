@@ -0,0 +1,379 @@
+// Package proxy sits between Claude Code and an MCP server, over stdio or
+// HTTP, forwarding every message unchanged while recording each exchange:
+// exact latency, message sizes, JSON-RPC errors, notifications, the server's
+// stderr and, over HTTP, why requests failed to reach it.
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/probe"
+	"github.com/anthropics/mcp-lens/internal/redact"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// Message directions, by sender.
+const (
+	FromClient = "client" // Sent by Claude Code
+	FromServer = "server" // Sent by the MCP server
+)
+
+// Config configures a proxy.
+type Config struct {
+	ServerName    string        // Name the server's calls are recorded under
+	FlushInterval time.Duration // How often recorded calls are written (default: 1s)
+	QueueSize     int           // Calls buffered for writing before new ones are dropped (default: 1000)
+}
+
+// DefaultConfig returns default configuration.
+func DefaultConfig() Config {
+	return Config{
+		FlushInterval: time.Second,
+		QueueSize:     1000,
+	}
+}
+
+// Recorder stores what a proxy sees.
+type Recorder interface {
+	StoreProxyCalls(ctx context.Context, calls []storage.ProxyCall) error
+	ReplaceToolDefinitions(ctx context.Context, serverName string, tools []storage.ToolDefinition) error
+}
+
+// record is a unit of work for the writer: a call, or a tools/list snapshot.
+type record struct {
+	call  *storage.ProxyCall
+	tools []storage.ToolDefinition
+}
+
+// writer stores records in the background so recording never holds up the
+// messages being forwarded. When storage falls behind, records are dropped.
+type writer struct {
+	config   Config
+	recorder Recorder
+	queue    chan record
+	done     chan struct{}
+	dropped  atomic.Int64
+	errMu    sync.Mutex
+	err      error // First storage error
+}
+
+func newWriter(config Config, recorder Recorder) *writer {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	w := &writer{
+		config:   config,
+		recorder: recorder,
+		queue:    make(chan record, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *writer) add(r record) {
+	select {
+	case w.queue <- r:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+func (w *writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []storage.ProxyCall
+	flush := func() {
+		if len(batch) > 0 {
+			w.setErr(w.recorder.StoreProxyCalls(context.Background(), batch))
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case r, ok := <-w.queue:
+			if !ok {
+				flush()
+				return
+			}
+			if r.call != nil {
+				batch = append(batch, *r.call)
+				if len(batch) >= 100 {
+					flush()
+				}
+			} else {
+				w.setErr(w.recorder.ReplaceToolDefinitions(context.Background(), w.config.ServerName, r.tools))
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (w *writer) setErr(err error) {
+	if err == nil {
+		return
+	}
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// close writes what is queued and reports the first storage error, or how
+// many records were dropped.
+func (w *writer) close() error {
+	close(w.queue)
+	<-w.done
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	if w.err != nil {
+		return fmt.Errorf("recording calls: %w", w.err)
+	}
+	if n := w.dropped.Load(); n > 0 {
+		return fmt.Errorf("recording calls: %d dropped while storage was busy", n)
+	}
+	return nil
+}
+
+// pending is a request awaiting its response.
+type pending struct {
+	call  storage.ProxyCall
+	start time.Time
+}
+
+// tracker pairs requests with their responses in both directions and turns
+// what it sees into proxy calls.
+type tracker struct {
+	server  string
+	writer  *writer
+	mu      sync.Mutex
+	pending map[string]*pending      // By sender and request ID
+	tools   []storage.ToolDefinition // tools/list pages collected so far
+}
+
+func newTracker(server string, w *writer) *tracker {
+	return &tracker{server: server, writer: w, pending: make(map[string]*pending)}
+}
+
+// message is any JSON-RPC message.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// observe records a message sent by from, read at the given time, and
+// returns the keys of any requests it opened. Batches are split into their
+// messages; anything that is not JSON-RPC is ignored.
+func (t *tracker) observe(from string, data []byte, at time.Time) []string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] == '[' {
+		var batch []json.RawMessage
+		if json.Unmarshal(data, &batch) != nil {
+			return nil
+		}
+		var keys []string
+		for _, m := range batch {
+			keys = append(keys, t.observe(from, m, at)...)
+		}
+		return keys
+	}
+
+	var m message
+	if json.Unmarshal(data, &m) != nil {
+		return nil
+	}
+	size := int64(len(data))
+	hasID := len(m.ID) > 0 && string(m.ID) != "null"
+
+	switch {
+	case m.Method != "" && hasID:
+		return []string{t.request(from, m, size, at)}
+	case m.Method != "":
+		t.notification(from, m, size, at)
+	case hasID:
+		t.response(from, m, size, at)
+	}
+	return nil
+}
+
+// request opens a pending request, returning its key.
+func (t *tracker) request(from string, m message, size int64, at time.Time) string {
+	call := storage.ProxyCall{
+		ServerName:   t.server,
+		Kind:         storage.ProxyRequest,
+		Direction:    from,
+		Method:       m.Method,
+		RequestBytes: size,
+		CreatedAt:    at,
+	}
+	var params struct {
+		Name   string `json:"name"`
+		Cursor string `json:"cursor"`
+	}
+	json.Unmarshal(m.Params, &params)
+	switch m.Method {
+	case "tools/call":
+		call.ToolName = params.Name
+	case "tools/list":
+		if params.Cursor == "" {
+			t.mu.Lock()
+			t.tools = nil
+			t.mu.Unlock()
+		}
+	}
+
+	key := from + ":" + string(m.ID)
+	t.mu.Lock()
+	t.pending[key] = &pending{call: call, start: at}
+	t.mu.Unlock()
+	return key
+}
+
+func (t *tracker) notification(from string, m message, size int64, at time.Time) {
+	t.writer.add(record{call: &storage.ProxyCall{
+		ServerName:   t.server,
+		Kind:         storage.ProxyNotification,
+		Direction:    from,
+		Method:       m.Method,
+		RequestBytes: size,
+		CreatedAt:    at,
+	}})
+
+	// A cancelled request gets no response, so finish it now
+	if m.Method == "notifications/cancelled" {
+		var params struct {
+			RequestID json.RawMessage `json:"requestId"`
+		}
+		if json.Unmarshal(m.Params, &params) == nil && len(params.RequestID) > 0 {
+			t.fail([]string{from + ":" + string(params.RequestID)}, at, storage.FailureCancelled, 0, "cancelled")
+		}
+	}
+}
+
+// response completes the request it answers, which came from the other side.
+func (t *tracker) response(from string, m message, size int64, at time.Time) {
+	sender := FromClient
+	if from == FromClient {
+		sender = FromServer
+	}
+	t.finish(sender+":"+string(m.ID), at, func(c *storage.ProxyCall) {
+		c.ResponseBytes = size
+		if m.Error != nil {
+			c.ErrorCode = m.Error.Code
+			c.Failure = storage.FailureRPC
+			c.Error = redact.String(m.Error.Message)
+			return
+		}
+
+		switch c.Method {
+		case "tools/call":
+			var result struct {
+				IsError bool `json:"isError"`
+			}
+			if json.Unmarshal(m.Result, &result) == nil && result.IsError {
+				c.ToolError = true
+				c.Failure = storage.FailureTool
+			}
+		case "tools/list":
+			t.collectTools(m.Result, at)
+		}
+	})
+}
+
+// fail completes pending requests as failed with the given class, HTTP
+// status and message. Keys no longer pending are skipped.
+func (t *tracker) fail(keys []string, at time.Time, failure string, status int, msg string) {
+	for _, key := range keys {
+		t.finish(key, at, func(c *storage.ProxyCall) {
+			c.Failure = failure
+			c.HTTPStatus = status
+			c.Error = redact.String(msg)
+		})
+	}
+}
+
+// finish completes a pending request and records it.
+func (t *tracker) finish(key string, at time.Time, complete func(*storage.ProxyCall)) {
+	t.mu.Lock()
+	p, ok := t.pending[key]
+	delete(t.pending, key)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	p.call.DurationUs = at.Sub(p.start).Microseconds()
+	complete(&p.call)
+	t.writer.add(record{call: &p.call})
+}
+
+// collectTools adds a page of tools/list results, recording the server's
+// tool definitions once the last page arrives.
+func (t *tracker) collectTools(result json.RawMessage, at time.Time) {
+	var list probe.ToolsListResult
+	if json.Unmarshal(result, &list) != nil {
+		return
+	}
+
+	t.mu.Lock()
+	for _, tool := range list.Tools {
+		t.tools = append(t.tools, tool.Definition(at))
+	}
+	tools := t.tools
+	t.mu.Unlock()
+
+	if list.NextCursor == "" && len(tools) > 0 {
+		t.writer.add(record{tools: tools})
+	}
+}
+
+// stderr records a line the server wrote to stderr.
+func (t *tracker) stderr(line string, at time.Time) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	t.writer.add(record{call: &storage.ProxyCall{
+		ServerName: t.server,
+		Kind:       storage.ProxyStderr,
+		Direction:  FromServer,
+		Error:      redact.String(line),
+		CreatedAt:  at,
+	}})
+}
+
+// abandon records requests still awaiting a response when the connection
+// ends.
+func (t *tracker) abandon(at time.Time, reason string) {
+	t.mu.Lock()
+	keys := make([]string, 0, len(t.pending))
+	for key := range t.pending {
+		keys = append(keys, key)
+	}
+	t.mu.Unlock()
+
+	t.fail(keys, at, storage.FailureNoResponse, 0, reason)
+}
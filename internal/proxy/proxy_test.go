@@ -0,0 +1,237 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// stubEnv selects the stub server mode when the test binary is launched as
+// a stdio MCP server.
+const stubEnv = "MCP_LENS_PROXY_STUB"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(stubEnv); mode != "" {
+		os.Exit(runStub(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runStub serves MCP over stdio until stdin closes. tools/call fails for the
+// tools "broken" (isError) and "invalid" (a JSON-RPC error), "slow" is never
+// answered, and progress is reported before each tools/call result. "exit3"
+// exits with status 3 without reading anything.
+func runStub(mode string) int {
+	if mode == "exit3" {
+		fmt.Fprintln(os.Stderr, "fatal: missing config")
+		return 3
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || req.ID == nil {
+			continue
+		}
+
+		reply := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "initialize":
+			fmt.Fprintln(os.Stderr, "stub starting with token=abc123")
+			reply["result"] = map[string]interface{}{"protocolVersion": "2025-06-18", "serverInfo": map[string]string{"name": "stub"}}
+		case "tools/list":
+			reply["result"] = map[string]interface{}{"tools": []map[string]interface{}{
+				{"name": "search", "description": "Search", "inputSchema": map[string]string{"type": "object"}},
+				{"name": "broken"},
+			}}
+		case "tools/call":
+			out.Encode(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/progress", "params": map[string]int{"progress": 1}})
+			switch req.Params.Name {
+			case "slow":
+				continue
+			case "invalid":
+				reply["error"] = map[string]interface{}{"code": -32602, "message": "unknown tool: invalid"}
+			default:
+				reply["result"] = map[string]interface{}{
+					"content": []map[string]string{{"type": "text", "text": strings.Repeat("x", 1000)}},
+					"isError": req.Params.Name == "broken",
+				}
+			}
+		default:
+			reply["result"] = map[string]interface{}{}
+		}
+		out.Encode(reply)
+	}
+	return 0
+}
+
+func stubCommand(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), stubEnv+"="+mode)
+	return cmd
+}
+
+func TestRunStdio(t *testing.T) {
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search","arguments":{"q":"x"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"broken"}}`,
+		`{"jsonrpc":"2.0","id":"five","method":"tools/call","params":{"name":"invalid"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"slow"}}`,
+		`not json`,
+	}
+	stdin := strings.NewReader(strings.Join(requests, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	store := storage.NewMockStore()
+
+	config := DefaultConfig()
+	config.ServerName = "stub"
+	if err := RunStdio(config, store, stubCommand("ok"), stdin, &stdout, &stderr); err != nil {
+		t.Fatalf("RunStdio failed: %v", err)
+	}
+
+	// Everything the server wrote reaches the client unchanged
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("expected 9 lines from the server (5 responses, 4 progress notifications), got %d:\n%s", len(lines), stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "stub starting") {
+		t.Errorf("expected the server's stderr to be passed through, got %q", stderr.String())
+	}
+
+	stats, err := store.GetProxyStats(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetProxyStats failed: %v", err)
+	}
+	byKey := make(map[string]storage.ProxyMethodStats)
+	for _, st := range stats {
+		if st.ServerName != "stub" {
+			t.Errorf("expected calls recorded under stub, got %q", st.ServerName)
+		}
+		byKey[st.Kind+" "+st.Direction+" "+st.Method+" "+st.ToolName] = st
+	}
+
+	tests := []struct {
+		key    string
+		calls  int64
+		errors int64
+	}{
+		{"request client initialize ", 1, 0},
+		{"notification client notifications/initialized ", 1, 0},
+		{"request client tools/list ", 1, 0},
+		{"request client tools/call search", 1, 0},
+		{"request client tools/call broken", 1, 1},
+		{"request client tools/call invalid", 1, 1},
+		{"request client tools/call slow", 1, 1},
+		{"notification server notifications/progress ", 4, 0},
+		{"stderr server  ", 1, 1},
+	}
+	for _, tt := range tests {
+		st, ok := byKey[tt.key]
+		if !ok {
+			t.Errorf("no calls recorded for %q", tt.key)
+			continue
+		}
+		if st.Calls != tt.calls || st.Errors != tt.errors {
+			t.Errorf("%q: %d calls %d errors, want %d and %d", tt.key, st.Calls, st.Errors, tt.calls, tt.errors)
+		}
+	}
+	if search := byKey["request client tools/call search"]; search.AvgResponseBytes < 1000 || search.AvgRequestBytes == 0 {
+		t.Errorf("expected request and response sizes, got %+v", search)
+	}
+	if len(stats) != len(tests) {
+		t.Errorf("expected %d groups, got %d", len(tests), len(stats))
+	}
+
+	failures, err := store.GetProxyFailures(context.Background(), storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetProxyFailures failed: %v", err)
+	}
+	classes := make(map[string]int64)
+	for _, f := range failures {
+		classes[f.Failure] += f.Count
+	}
+	wantClasses := map[string]int64{storage.FailureTool: 1, storage.FailureRPC: 1, storage.FailureNoResponse: 1}
+	if len(classes) != len(wantClasses) {
+		t.Errorf("expected failure classes %v, got %v", wantClasses, classes)
+	}
+	for class, n := range wantClasses {
+		if classes[class] != n {
+			t.Errorf("expected %d %s failures, got %d", n, class, classes[class])
+		}
+	}
+
+	tools, err := store.GetToolDefinitions(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetToolDefinitions failed: %v", err)
+	}
+	if len(tools) != 2 || tools[0].ServerName != "stub" || tools[1].ToolName != "search" || tools[1].Tokens == 0 {
+		t.Errorf("expected tools/list to record tool definitions, got %+v", tools)
+	}
+}
+
+func TestRunStdio_ExitStatus(t *testing.T) {
+	config := DefaultConfig()
+	config.ServerName = "stub"
+	store := storage.NewMockStore()
+	var stderr bytes.Buffer
+
+	err := RunStdio(config, store, stubCommand("exit3"), strings.NewReader(""), io.Discard, &stderr)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+
+	stats, _ := store.GetProxyStats(context.Background(), storage.TimeFilter{})
+	if len(stats) != 1 || stats[0].Kind != storage.ProxyStderr {
+		t.Errorf("expected the server's stderr to be recorded, got %+v", stats)
+	}
+}
+
+func TestTracker_Cancelled(t *testing.T) {
+	store := storage.NewMockStore()
+	config := DefaultConfig()
+	config.ServerName = "stub"
+	w := newWriter(config, store)
+	tr := newTracker("stub", w)
+
+	start := time.Now()
+	tr.observe(FromClient, []byte(`[{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"search"}}]`), start)
+	tr.observe(FromClient, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`), start.Add(250*time.Millisecond))
+	// A late response to a cancelled request is not counted again
+	tr.observe(FromServer, []byte(`{"jsonrpc":"2.0","id":7,"result":{}}`), start.Add(time.Second))
+	if err := w.close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	stats, _ := store.GetProxyStats(context.Background(), storage.TimeFilter{})
+	var call *storage.ProxyMethodStats
+	for i := range stats {
+		if stats[i].Method == "tools/call" {
+			call = &stats[i]
+		}
+	}
+	if call == nil || call.Calls != 1 || call.Errors != 1 || call.MaxLatencyMs != 250 {
+		t.Errorf("expected one cancelled call after 250ms, got %+v", call)
+	}
+}
@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// RunStdio starts a stdio MCP server with cmd and relays newline-delimited
+// messages between it and the client on stdin and stdout, passing the
+// server's stderr through to stderr. Each line is forwarded exactly as read
+// before it is recorded. It returns once the server exits, with the
+// server's *exec.ExitError when it exits unsuccessfully.
+func RunStdio(config Config, recorder Recorder, cmd *exec.Cmd, stdin io.Reader, stdout, stderr io.Writer) error {
+	serverIn, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("creating stdin pipe: %w", err)
+	}
+	serverOut, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	serverErr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("creating stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s: %w", cmd.Path, err)
+	}
+
+	w := newWriter(config, recorder)
+	t := newTracker(config.ServerName, w)
+
+	// Client to server. Closing the server's stdin when the client closes
+	// ours is how the stdio transport ends a session.
+	go func() {
+		relayLines(stdin, serverIn, func(line []byte, at time.Time) {
+			t.observe(FromClient, line, at)
+		})
+		serverIn.Close()
+	}()
+
+	// The server's output pipes close when it exits; both must be drained
+	// before waiting for it.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		relayLines(serverOut, stdout, func(line []byte, at time.Time) {
+			t.observe(FromServer, line, at)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		relayLines(serverErr, stderr, func(line []byte, at time.Time) {
+			t.stderr(string(line), at)
+		})
+	}()
+	wg.Wait()
+	waitErr := cmd.Wait()
+
+	t.abandon(time.Now(), "no response before the server exited")
+	if err := w.close(); err != nil {
+		fmt.Fprintf(stderr, "mcp-lens proxy: %v\n", err)
+	}
+	return waitErr
+}
+
+// relayLines copies lines from r to w, calling observe with each line and
+// the time it was read once it has been written. Write errors stop the
+// writes but not the reads, so the other side is never blocked on a full
+// pipe.
+func relayLines(r io.Reader, w io.Writer, observe func(line []byte, at time.Time)) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	writable := true
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			at := time.Now()
+			if writable {
+				if _, werr := w.Write(line); werr != nil {
+					writable = false
+				}
+			}
+			observe(line, at)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package redact masks secrets in text before it is stored or indexed.
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// MaxLength is the maximum stored length of redacted text, in bytes.
+const MaxLength = 4096
+
+// secretPatterns match credentials that are unsafe to store verbatim.
+var secretPatterns = []*regexp.Regexp{
+	// PEM private keys
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	// Provider key formats
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	// Authorization headers
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{8,}`),
+}
+
+// keyValuePattern matches values assigned to secret-looking keys, in JSON
+// ("token": "x"), env (TOKEN=x), and flag (--password x) forms.
+var keyValuePattern = regexp.MustCompile(
+	`(?i)((?:"|\b)[a-z0-9_-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)[a-z0-9_]*"?\s*[:=]\s*|--(?:password|token|secret|api-key)[ =])("[^"]*"|'[^']*'|[^\s,}&"']+)`)
+
+// urlCredentialPattern matches user:password@ in URLs.
+var urlCredentialPattern = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+
+// String masks secrets in s and truncates the result to MaxLength.
+func String(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, Mask)
+	}
+
+	s = keyValuePattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := keyValuePattern.FindStringSubmatch(match)
+		value := parts[2]
+		if strings.HasPrefix(value, `"`) {
+			return parts[1] + `"` + Mask + `"`
+		}
+		if strings.HasPrefix(value, "'") {
+			return parts[1] + "'" + Mask + "'"
+		}
+		return parts[1] + Mask
+	})
+
+	s = urlCredentialPattern.ReplaceAllString(s, "${1}"+Mask+"@")
+
+	return Truncate(s, MaxLength)
+}
+
+// JSON marshals v and masks secrets in the result. Returns "" for nil or
+// unmarshalable values.
+func JSON(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return String(string(data))
+}
+
+// Truncate cuts s to at most n bytes without splitting a UTF-8 sequence. It
+// backs up only to the start of the rune at the cut, so invalid bytes earlier
+// in s are kept as they are.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
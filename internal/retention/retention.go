@@ -0,0 +1,229 @@
+// Package retention enforces data retention limits across the database and
+// the JSONL files MCP Lens writes.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// Store defines the storage operations the retention engine needs.
+type Store interface {
+	Cleanup(ctx context.Context, olderThan time.Time) (int64, error)
+	PruneRollups(ctx context.Context, tier storage.Tier, olderThan time.Time) (int64, error)
+	PruneLatencyHistograms(ctx context.Context, olderThan time.Time) (int64, error)
+	TrimRecentEvents(ctx context.Context, keep int, olderThan time.Time) (int64, error)
+	CleanupUsage(ctx context.Context, olderThan time.Time) (int64, error)
+	CleanupFingerprints(ctx context.Context, olderThan time.Time) (int64, error)
+	CleanupSessions(ctx context.Context, olderThan time.Time) (int64, error)
+	Vacuum(ctx context.Context) (*storage.VacuumResult, error)
+}
+
+// Policy defines what the engine keeps. Zero day counts keep data forever.
+type Policy struct {
+	Tiers        storage.RetentionPolicy
+	FileDays     int  // Rotated JSONL files and per-session files
+	RecentEvents int  // Rows kept in the recent events buffer
+	Vacuum       bool // Reclaim free pages after deleting rows
+}
+
+// Files locates the JSONL files subject to retention.
+type Files struct {
+	EventsFile  string // Rotated copies are named <EventsFile>.<timestamp>
+	SessionsDir string // Per-session <id>.jsonl files
+}
+
+// Removal records what one retention step deleted.
+type Removal struct {
+	Target string
+	Count  int64
+}
+
+// Report summarizes a retention run.
+type Report struct {
+	Removed  []Removal
+	Vacuum   *storage.VacuumResult
+	Duration time.Duration
+}
+
+// Total returns the number of rows and files removed.
+func (r *Report) Total() int64 {
+	var total int64
+	for _, rm := range r.Removed {
+		total += rm.Count
+	}
+	return total
+}
+
+// Engine applies a retention policy.
+type Engine struct {
+	store  Store
+	policy Policy
+	files  Files
+	logf   func(format string, args ...interface{})
+}
+
+// NewEngine creates a retention engine. Removals are logged with log.Printf
+// unless SetLogger is called.
+func NewEngine(store Store, policy Policy, files Files) *Engine {
+	return &Engine{
+		store:  store,
+		policy: policy,
+		files:  files,
+		logf:   log.Printf,
+	}
+}
+
+// SetLogger replaces the function used to log removals. Pass nil to
+// disable logging.
+func (e *Engine) SetLogger(logf func(format string, args ...interface{})) {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	e.logf = logf
+}
+
+// Run enforces the policy as of now.
+func (e *Engine) Run(ctx context.Context, now time.Time) (*Report, error) {
+	start := time.Now()
+	report := &Report{}
+
+	record := func(target string, count int64) {
+		report.Removed = append(report.Removed, Removal{Target: target, Count: count})
+		if count > 0 {
+			e.logf("retention: removed %d %s", count, target)
+		}
+	}
+
+	tiers := e.policy.Tiers
+
+	// Raw data shares the raw retention window
+	if days := tiers.RawDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+
+		deleted, err := e.store.Cleanup(ctx, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		record("events", deleted)
+
+		deleted, err = e.store.CleanupFingerprints(ctx, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		record("event fingerprints", deleted)
+
+		deleted, err = e.store.CleanupSessions(ctx, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		record("sessions", deleted)
+	}
+
+	// Costs have no rollup, so they outlive the raw events
+	if days := tiers.UsageDays(); days > 0 {
+		deleted, err := e.store.CleanupUsage(ctx, now.AddDate(0, 0, -days))
+		if err != nil {
+			return nil, err
+		}
+		record("transcript usage", deleted)
+	}
+
+	var recentCutoff time.Time
+	if tiers.RawDays > 0 {
+		recentCutoff = now.AddDate(0, 0, -tiers.RawDays)
+	}
+	keep := e.policy.RecentEvents
+	if keep <= 0 {
+		keep = storage.RecentEventsCapacity
+	}
+	deleted, err := e.store.TrimRecentEvents(ctx, keep, recentCutoff)
+	if err != nil {
+		return nil, err
+	}
+	record("recent events", deleted)
+
+	for _, tier := range []storage.Tier{storage.TierMinute, storage.TierHour} {
+		days := tiers.Days(tier)
+		if days <= 0 {
+			continue
+		}
+		deleted, err := e.store.PruneRollups(ctx, tier, now.AddDate(0, 0, -days))
+		if err != nil {
+			return nil, err
+		}
+		record(tier.String()+" rollups", deleted)
+	}
+
+	// Hourly latency histograms back the percentiles of the hourly tier
+	if days := tiers.Days(storage.TierHour); days > 0 {
+		deleted, err := e.store.PruneLatencyHistograms(ctx, now.AddDate(0, 0, -days))
+		if err != nil {
+			return nil, err
+		}
+		record("hourly latency histograms", deleted)
+	}
+
+	if days := e.policy.FileDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+
+		if e.files.EventsFile != "" {
+			removed, err := removeFilesBefore(e.files.EventsFile+".????-??-??-??????", cutoff)
+			if err != nil {
+				return nil, err
+			}
+			record("rotated JSONL files", removed)
+		}
+
+		if e.files.SessionsDir != "" {
+			removed, err := removeFilesBefore(filepath.Join(e.files.SessionsDir, "*.jsonl"), cutoff)
+			if err != nil {
+				return nil, err
+			}
+			record("session files", removed)
+		}
+	}
+
+	if e.policy.Vacuum && report.Total() > 0 {
+		result, err := e.store.Vacuum(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.Vacuum = result
+		if result.FreedPages > 0 {
+			e.logf("retention: %s vacuum freed %d pages", result.Mode, result.FreedPages)
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// removeFilesBefore deletes files matching pattern last modified before cutoff.
+func removeFilesBefore(pattern string, cutoff time.Time) (int64, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("listing %s: %w", pattern, err)
+	}
+
+	var removed int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(file); err != nil {
+				return removed, fmt.Errorf("removing %s: %w", file, err)
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
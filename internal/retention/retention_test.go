@@ -0,0 +1,215 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+func TestEngine_Run(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := storage.NewSQLiteStore(filepath.Join(tmpDir, "data.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	old := now.AddDate(0, 0, -60)
+
+	// One old and one fresh call, through the full ingest path
+	for _, ts := range []time.Time{old, now} {
+		if err := store.StoreEvent(ctx, &storage.Event{
+			SessionID: "s-" + ts.Format("0102"), EventType: "PostToolUse", ToolName: "mcp__github__get_issue",
+			MCPServer: "github", Success: true, DurationMs: 40, CreatedAt: ts,
+		}); err != nil {
+			t.Fatalf("failed to store event: %v", err)
+		}
+		if err := store.StoreEventFingerprint(ctx, "fp-"+ts.Format("0102"), ts); err != nil {
+			t.Fatalf("failed to store fingerprint: %v", err)
+		}
+	}
+
+	// Usage from the old session, past both the raw and hourly retention
+	if err := store.StoreUsage(ctx, []storage.TurnUsage{
+		{MessageID: "msg_old", SessionID: "s-old", Model: "claude-sonnet-4-5", CostUSD: 1, CreatedAt: old},
+	}, nil); err != nil {
+		t.Fatalf("failed to store usage: %v", err)
+	}
+
+	// Rotated JSONL and session files, one old and one fresh of each
+	eventsFile := filepath.Join(tmpDir, "events.jsonl")
+	sessionsDir := filepath.Join(tmpDir, "events")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatalf("failed to create sessions dir: %v", err)
+	}
+	oldFiles := []string{
+		eventsFile + ".2026-01-01-000000",
+		filepath.Join(sessionsDir, "sess-old.jsonl"),
+	}
+	freshFiles := []string{
+		eventsFile,
+		eventsFile + ".2026-02-01-000000",
+		filepath.Join(sessionsDir, "sess-new.jsonl"),
+	}
+	for _, f := range append(oldFiles, freshFiles...) {
+		if err := os.WriteFile(f, []byte("{}\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", f, err)
+		}
+	}
+	for _, f := range append(oldFiles, eventsFile) {
+		if err := os.Chtimes(f, old, old); err != nil {
+			t.Fatalf("failed to age %s: %v", f, err)
+		}
+	}
+
+	policy := Policy{
+		Tiers:        storage.RetentionPolicy{RawDays: 30, MinuteDays: 7, HourlyDays: 30},
+		FileDays:     30,
+		RecentEvents: 100,
+		Vacuum:       true,
+	}
+	engine := NewEngine(store, policy, Files{EventsFile: eventsFile, SessionsDir: sessionsDir})
+
+	var logged []string
+	engine.SetLogger(func(format string, args ...interface{}) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	})
+
+	report, err := engine.Run(ctx, now)
+	if err != nil {
+		t.Fatalf("retention failed: %v", err)
+	}
+
+	expected := map[string]int64{
+		"events":                    1,
+		"event fingerprints":        1,
+		"sessions":                  1,
+		"transcript usage":          1,
+		"recent events":             1,
+		"minute rollups":            1,
+		"hour rollups":              1,
+		"hourly latency histograms": 1,
+		"rotated JSONL files":       1,
+		"session files":             1,
+	}
+	for _, rm := range report.Removed {
+		if want, ok := expected[rm.Target]; ok && rm.Count != want {
+			t.Errorf("%s: expected %d removed, got %d", rm.Target, want, rm.Count)
+		}
+		delete(expected, rm.Target)
+	}
+	for target := range expected {
+		t.Errorf("%s: missing from report", target)
+	}
+	if len(logged) == 0 {
+		t.Error("expected removals to be logged")
+	}
+	if report.Vacuum == nil {
+		t.Error("expected vacuum after removing rows")
+	}
+
+	for _, f := range oldFiles {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", f)
+		}
+	}
+	for _, f := range freshFiles {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("expected %s to be kept: %v", f, err)
+		}
+	}
+
+	// Daily rollups still hold both calls
+	stats, err := store.GetMCPServerStats(ctx, storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 2 {
+		t.Errorf("expected 2 calls in daily rollups, got %+v", stats)
+	}
+
+	// A second run has nothing left to do
+	report, err = engine.Run(ctx, now)
+	if err != nil {
+		t.Fatalf("retention failed: %v", err)
+	}
+	if report.Total() != 0 || report.Vacuum != nil {
+		t.Errorf("expected an idle second run, got %+v", report)
+	}
+}
+
+func TestEngine_KeepsCostsPastRawEvents(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	old := now.AddDate(0, 0, -60)
+	if err := store.StoreUsage(ctx, []storage.TurnUsage{
+		{MessageID: "msg_1", SessionID: "s1", Model: "claude-sonnet-4-5", CostUSD: 4, CreatedAt: old},
+	}, []storage.ToolCallCost{
+		{ToolUseID: "toolu_1", SessionID: "s1", ToolName: "mcp__github__search", ServerName: "github",
+			InputCostUSD: 1, FollowUpCostUSD: 1, CreatedAt: old},
+	}); err != nil {
+		t.Fatalf("failed to store usage: %v", err)
+	}
+
+	engine := NewEngine(store, Policy{Tiers: storage.DefaultRetentionPolicy()}, Files{})
+	engine.SetLogger(nil)
+	if _, err := engine.Run(ctx, now); err != nil {
+		t.Fatalf("retention failed: %v", err)
+	}
+
+	// Spend past the raw retention still counts
+	summary, err := store.GetCostSummary(ctx, storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get cost summary: %v", err)
+	}
+	if summary.TotalCostUSD != 4 {
+		t.Errorf("expected the old spend kept, got $%.2f", summary.TotalCostUSD)
+	}
+	byServer, err := store.GetCostByServer(ctx, storage.TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get cost by server: %v", err)
+	}
+	if len(byServer) != 1 || byServer[0].TotalCostUSD != 2 {
+		t.Errorf("expected the old tool call costs kept, got %+v", byServer)
+	}
+}
+
+func TestEngine_KeepForever(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	old := time.Now().AddDate(-2, 0, 0)
+	if err := store.StoreEvent(ctx, &storage.Event{
+		SessionID: "s1", EventType: "PostToolUse", ToolName: "Read", CreatedAt: old,
+	}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+
+	engine := NewEngine(store, Policy{}, Files{})
+	engine.SetLogger(nil)
+
+	report, err := engine.Run(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("retention failed: %v", err)
+	}
+	if report.Total() != 0 {
+		t.Errorf("expected nothing removed with a keep-forever policy, got %+v", report.Removed)
+	}
+}
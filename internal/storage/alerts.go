@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SaveAlert records a new alert, setting its ID, or updates an existing one.
+func (s *SQLiteStore) SaveAlert(ctx context.Context, alert *Alert) error {
+	var notifiedAt, resolvedAt interface{}
+	if !alert.NotifiedAt.IsZero() {
+		notifiedAt = alert.NotifiedAt.Local()
+	}
+	if !alert.ResolvedAt.IsZero() {
+		resolvedAt = alert.ResolvedAt.Local()
+	}
+
+	if alert.ID == 0 {
+		res, err := s.db.ExecContext(ctx, `
+			INSERT INTO alerts (alert_key, kind, subject, rule, severity, message, value, threshold,
+				fired_at, updated_at, notified_at, notifications, resolved_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			alert.Key, alert.Kind, alert.Subject, alert.Rule, alert.Severity, alert.Message, alert.Value, alert.Threshold,
+			alert.FiredAt.Local(), alert.UpdatedAt.Local(), notifiedAt, alert.Notifications, resolvedAt)
+		if err != nil {
+			return fmt.Errorf("inserting alert: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("getting alert id: %w", err)
+		}
+		alert.ID = id
+		return nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE alerts SET severity = ?, message = ?, value = ?, threshold = ?, updated_at = ?,
+			notified_at = ?, notifications = ?, resolved_at = ?
+		WHERE id = ?`,
+		alert.Severity, alert.Message, alert.Value, alert.Threshold, alert.UpdatedAt.Local(),
+		notifiedAt, alert.Notifications, resolvedAt, alert.ID); err != nil {
+		return fmt.Errorf("updating alert: %w", err)
+	}
+	return nil
+}
+
+// GetActiveAlerts returns the alerts that have not resolved, oldest first.
+func (s *SQLiteStore) GetActiveAlerts(ctx context.Context) ([]Alert, error) {
+	return s.queryAlerts(ctx, alertColumns+" WHERE resolved_at IS NULL ORDER BY fired_at, id")
+}
+
+// GetAlerts returns the alerts fired in the range, newest first.
+func (s *SQLiteStore) GetAlerts(ctx context.Context, filter AlertFilter) ([]Alert, error) {
+	query := alertColumns + " WHERE 1=1"
+	var args []interface{}
+	if !filter.From.IsZero() {
+		query += " AND fired_at >= ?"
+		args = append(args, filter.From.Local())
+	}
+	if !filter.To.IsZero() {
+		query += " AND fired_at <= ?"
+		args = append(args, filter.To.Local())
+	}
+	if filter.ActiveOnly {
+		query += " AND resolved_at IS NULL"
+	}
+	query += " ORDER BY fired_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	return s.queryAlerts(ctx, query, args...)
+}
+
+const alertColumns = `
+	SELECT id, alert_key, kind, subject, rule, severity, message, value, threshold,
+		fired_at, updated_at, notified_at, notifications, resolved_at
+	FROM alerts`
+
+func (s *SQLiteStore) queryAlerts(ctx context.Context, query string, args ...interface{}) ([]Alert, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		var firedAt, updatedAt string
+		var notifiedAt, resolvedAt sql.NullString
+		if err := rows.Scan(&a.ID, &a.Key, &a.Kind, &a.Subject, &a.Rule, &a.Severity, &a.Message, &a.Value, &a.Threshold,
+			&firedAt, &updatedAt, &notifiedAt, &a.Notifications, &resolvedAt); err != nil {
+			return nil, fmt.Errorf("scanning alert: %w", err)
+		}
+		a.FiredAt = parseStoredTime(firedAt)
+		a.UpdatedAt = parseStoredTime(updatedAt)
+		if notifiedAt.Valid {
+			a.NotifiedAt = parseStoredTime(notifiedAt.String)
+		}
+		if resolvedAt.Valid {
+			a.ResolvedAt = parseStoredTime(resolvedAt.String)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// GetAlertRuleStates returns the saved state of alert rule conditions.
+func (s *SQLiteStore) GetAlertRuleStates(ctx context.Context) ([]AlertRuleState, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT alert_key, rule, holding_since, clearing_since FROM alert_rule_states ORDER BY alert_key")
+	if err != nil {
+		return nil, fmt.Errorf("querying alert rule states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []AlertRuleState
+	for rows.Next() {
+		var st AlertRuleState
+		var holding, clearing sql.NullString
+		if err := rows.Scan(&st.Key, &st.Rule, &holding, &clearing); err != nil {
+			return nil, fmt.Errorf("scanning alert rule state: %w", err)
+		}
+		if holding.Valid {
+			st.HoldingSince = parseStoredTime(holding.String)
+		}
+		if clearing.Valid {
+			st.ClearingSince = parseStoredTime(clearing.String)
+		}
+		states = append(states, st)
+	}
+	return states, rows.Err()
+}
+
+// SaveAlertRuleState records the state of an alert rule condition,
+// removing it once neither holding nor clearing.
+func (s *SQLiteStore) SaveAlertRuleState(ctx context.Context, state *AlertRuleState) error {
+	if state.HoldingSince.IsZero() && state.ClearingSince.IsZero() {
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM alert_rule_states WHERE alert_key = ?", state.Key); err != nil {
+			return fmt.Errorf("deleting alert rule state: %w", err)
+		}
+		return nil
+	}
+
+	var holding, clearing interface{}
+	if !state.HoldingSince.IsZero() {
+		holding = state.HoldingSince.Local()
+	}
+	if !state.ClearingSince.IsZero() {
+		clearing = state.ClearingSince.Local()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO alert_rule_states (alert_key, rule, holding_since, clearing_since)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(alert_key) DO UPDATE SET
+			rule = excluded.rule,
+			holding_since = excluded.holding_since,
+			clearing_since = excluded.clearing_since`,
+		state.Key, state.Rule, holding, clearing); err != nil {
+		return fmt.Errorf("saving alert rule state: %w", err)
+	}
+	return nil
+}
+
+// deleteResolvedAlerts removes alerts resolved before a time.
+func (s *SQLiteStore) deleteResolvedAlerts(ctx context.Context, before time.Time) error {
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM alerts WHERE resolved_at IS NOT NULL AND resolved_at < ?", before.Local()); err != nil {
+		return fmt.Errorf("deleting old alerts: %w", err)
+	}
+	return nil
+}
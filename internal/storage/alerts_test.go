@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAlerts(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	budget := &Alert{
+		Key: "budget:daily:2026-10-14", Kind: "budget", Subject: "daily", Severity: "warning",
+		Message: "Daily budget", Value: 8, Threshold: 10, FiredAt: base, UpdatedAt: base,
+	}
+	latency := &Alert{
+		Key: "latency:github", Kind: "latency", Subject: "github", Severity: "critical",
+		Message: "github slow", Value: 2500, Threshold: 1000,
+		FiredAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour), NotifiedAt: base.Add(time.Hour), Notifications: 1,
+	}
+	for _, a := range []*Alert{budget, latency} {
+		if err := store.SaveAlert(ctx, a); err != nil {
+			t.Fatalf("SaveAlert failed: %v", err)
+		}
+		if a.ID == 0 {
+			t.Fatalf("expected an ID for %s", a.Key)
+		}
+	}
+
+	active, err := store.GetActiveAlerts(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(active) != 2 || active[0].Key != budget.Key {
+		t.Fatalf("expected both alerts, oldest first, got %+v", active)
+	}
+	if !active[0].NotifiedAt.IsZero() || !active[1].NotifiedAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("expected notification times to round-trip, got %+v", active)
+	}
+
+	// Escalate and resolve the budget alert
+	budget.Severity = "critical"
+	budget.Value = 11
+	budget.UpdatedAt = base.Add(2 * time.Hour)
+	budget.ResolvedAt = base.Add(2 * time.Hour)
+	if err := store.SaveAlert(ctx, budget); err != nil {
+		t.Fatalf("SaveAlert failed: %v", err)
+	}
+
+	active, err = store.GetActiveAlerts(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(active) != 1 || active[0].Key != latency.Key {
+		t.Errorf("expected only the latency alert active, got %+v", active)
+	}
+
+	history, err := store.GetAlerts(ctx, AlertFilter{})
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Key != latency.Key {
+		t.Fatalf("expected both alerts, newest first, got %+v", history)
+	}
+	if got := history[1]; got.Active() || got.Severity != "critical" || got.Value != 11 {
+		t.Errorf("expected the updated budget alert, got %+v", got)
+	}
+
+	limited, err := store.GetAlerts(ctx, AlertFilter{TimeFilter: TimeFilter{To: base.Add(30 * time.Minute)}, Limit: 5})
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(limited) != 1 || limited[0].Key != budget.Key {
+		t.Errorf("expected the alert fired in range, got %+v", limited)
+	}
+
+	// Cleanup drops resolved alerts but keeps active ones
+	if _, err := store.Cleanup(ctx, base.Add(3*time.Hour)); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	history, err = store.GetAlerts(ctx, AlertFilter{})
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Key != latency.Key {
+		t.Errorf("expected only the active alert kept, got %+v", history)
+	}
+}
+
+func TestAlertRuleStates(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	base := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	alert := &Alert{
+		Key: "rule:errors:github", Kind: "rule", Subject: "github", Rule: "errors", Severity: "warning",
+		FiredAt: base, UpdatedAt: base,
+	}
+	if err := store.SaveAlert(ctx, alert); err != nil {
+		t.Fatalf("SaveAlert failed: %v", err)
+	}
+	active, err := store.GetActiveAlerts(ctx)
+	if err != nil {
+		t.Fatalf("GetActiveAlerts failed: %v", err)
+	}
+	if len(active) != 1 || active[0].Rule != "errors" {
+		t.Errorf("expected the rule to round-trip, got %+v", active)
+	}
+
+	pending := &AlertRuleState{Key: "rule:errors:slack", Rule: "errors", HoldingSince: base}
+	clearing := &AlertRuleState{Key: "rule:errors:github", Rule: "errors", ClearingSince: base.Add(time.Minute)}
+	for _, st := range []*AlertRuleState{pending, clearing} {
+		if err := store.SaveAlertRuleState(ctx, st); err != nil {
+			t.Fatalf("SaveAlertRuleState failed: %v", err)
+		}
+	}
+
+	// Updating replaces the state; clearing both times removes it
+	pending.HoldingSince = base.Add(2 * time.Minute)
+	if err := store.SaveAlertRuleState(ctx, pending); err != nil {
+		t.Fatalf("SaveAlertRuleState failed: %v", err)
+	}
+	clearing.ClearingSince = time.Time{}
+	if err := store.SaveAlertRuleState(ctx, clearing); err != nil {
+		t.Fatalf("SaveAlertRuleState failed: %v", err)
+	}
+
+	states, err := store.GetAlertRuleStates(ctx)
+	if err != nil {
+		t.Fatalf("GetAlertRuleStates failed: %v", err)
+	}
+	if len(states) != 1 || states[0].Key != pending.Key || !states[0].HoldingSince.Equal(pending.HoldingSince) ||
+		!states[0].ClearingSince.IsZero() {
+		t.Errorf("expected only the updated pending state, got %+v", states)
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// GetErrorClusters groups failed tool calls by server and error signature,
+// largest cluster first, with the most recent calls of each as examples.
+func (s *SQLiteStore) GetErrorClusters(ctx context.Context, filter ErrorClusterFilter) ([]ErrorCluster, error) {
+	query := `
+		SELECT COALESCE(mcp_server, ''), error_signature, COUNT(*), MIN(created_at), MAX(created_at)
+		FROM events
+		WHERE error_signature != ''`
+
+	var args []interface{}
+
+	if filter.MCPServer != "" {
+		query += " AND mcp_server = ?"
+		args = append(args, filter.MCPServer)
+	}
+	query, args = appendEventFilter(query, args, EventFilter{TimeFilter: filter.TimeFilter})
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	query += fmt.Sprintf(" GROUP BY COALESCE(mcp_server, ''), error_signature ORDER BY COUNT(*) DESC, MAX(created_at) DESC LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying error clusters: %w", err)
+	}
+
+	var clusters []ErrorCluster
+	for rows.Next() {
+		var c ErrorCluster
+		var firstSeen, lastSeen string
+		if err := rows.Scan(&c.MCPServer, &c.Signature, &c.Count, &firstSeen, &lastSeen); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning error cluster: %w", err)
+		}
+		c.FirstSeen = parseStoredTime(firstSeen)
+		c.LastSeen = parseStoredTime(lastSeen)
+		clusters = append(clusters, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	examples := filter.Examples
+	if examples <= 0 {
+		examples = 3
+	}
+	for i := range clusters {
+		if err := s.fillClusterExamples(ctx, &clusters[i], filter.TimeFilter, examples); err != nil {
+			return nil, err
+		}
+	}
+	return clusters, nil
+}
+
+// fillClusterExamples loads the most recent calls in a cluster.
+func (s *SQLiteStore) fillClusterExamples(ctx context.Context, c *ErrorCluster, filter TimeFilter, limit int) error {
+	query := `SELECT id, session_id, tool_name, duration_ms, tool_input, error, cwd, host, created_at
+		FROM events
+		WHERE error_signature = ? AND COALESCE(mcp_server, '') = ?`
+	args := []interface{}{c.Signature, c.MCPServer}
+
+	query, args = appendEventFilter(query, args, EventFilter{TimeFilter: filter})
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying error examples: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := Event{EventType: "PostToolUse", MCPServer: c.MCPServer, ErrorSignature: c.Signature}
+		var toolName sql.NullString
+		var durationMs sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.SessionID, &toolName, &durationMs,
+			&e.ToolInput, &e.Error, &e.Cwd, &e.Host, &e.CreatedAt); err != nil {
+			return fmt.Errorf("scanning error example: %w", err)
+		}
+		e.ToolName = toolName.String
+		e.DurationMs = durationMs.Int64
+		c.Examples = append(c.Examples, e)
+	}
+	return rows.Err()
+}
+
+// GetErrorMessages counts failed tool calls by server and full error
+// message. Unlike signatures, messages keep the status codes and names that
+// tell one kind of failure from another.
+func (s *SQLiteStore) GetErrorMessages(ctx context.Context, filter TimeFilter) ([]ErrorMessageCount, error) {
+	query := `
+		SELECT COALESCE(mcp_server, ''), COALESCE(error, ''), COUNT(*)
+		FROM events
+		WHERE error_signature != ''`
+
+	var args []interface{}
+	query, args = appendEventFilter(query, args, EventFilter{TimeFilter: filter})
+	query += " GROUP BY COALESCE(mcp_server, ''), COALESCE(error, '')"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying error messages: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ErrorMessageCount
+	for rows.Next() {
+		var m ErrorMessageCount
+		if err := rows.Scan(&m.MCPServer, &m.Error, &m.Count); err != nil {
+			return nil, fmt.Errorf("scanning error message: %w", err)
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
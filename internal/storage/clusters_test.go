@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetErrorClusters(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	failures := []struct {
+		server    string
+		signature string
+		err       string
+	}{
+		{"github", "rate limit exceeded: <n>", "rate limit exceeded: 30"},
+		{"github", "rate limit exceeded: <n>", "rate limit exceeded: 60"},
+		{"github", "rate limit exceeded: <n>", "rate limit exceeded: 90"},
+		{"github", "not found", "not found"},
+		{"fs", "not found", "not found"},
+	}
+	for i, f := range failures {
+		err := store.StoreEvent(ctx, &Event{
+			SessionID:      "s1",
+			EventType:      "PostToolUse",
+			ToolName:       "call",
+			MCPServer:      f.server,
+			Error:          f.err,
+			ErrorSignature: f.signature,
+			CreatedAt:      base.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "PostToolUse", ToolName: "call",
+		MCPServer: "github", Success: true, CreatedAt: base}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+
+	clusters, err := store.GetErrorClusters(ctx, ErrorClusterFilter{Examples: 2})
+	if err != nil {
+		t.Fatalf("GetErrorClusters failed: %v", err)
+	}
+	if len(clusters) != 3 {
+		t.Fatalf("expected 3 clusters, got %d", len(clusters))
+	}
+
+	top := clusters[0]
+	if top.MCPServer != "github" || top.Signature != "rate limit exceeded: <n>" || top.Count != 3 {
+		t.Errorf("top cluster = %s %q x%d, want github rate limit x3", top.MCPServer, top.Signature, top.Count)
+	}
+	if !top.FirstSeen.Before(top.LastSeen) {
+		t.Errorf("first seen %v should be before last seen %v", top.FirstSeen, top.LastSeen)
+	}
+	if len(top.Examples) != 2 || top.Examples[0].Error != "rate limit exceeded: 90" {
+		t.Errorf("examples = %+v, want the 2 newest calls", top.Examples)
+	}
+
+	// The same signature on different servers forms separate clusters
+	byServer, err := store.GetErrorClusters(ctx, ErrorClusterFilter{MCPServer: "fs"})
+	if err != nil {
+		t.Fatalf("GetErrorClusters failed: %v", err)
+	}
+	if len(byServer) != 1 || byServer[0].Signature != "not found" || byServer[0].Count != 1 {
+		t.Errorf("fs clusters = %+v, want one not found cluster", byServer)
+	}
+
+	// Messages keep what signatures strip, so each rate limit is counted apart
+	messages, err := store.GetErrorMessages(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetErrorMessages failed: %v", err)
+	}
+	counts := make(map[string]int64)
+	for _, m := range messages {
+		counts[m.MCPServer+"|"+m.Error] += m.Count
+	}
+	if len(counts) != 5 || counts["github|not found"] != 1 || counts["github|rate limit exceeded: 30"] != 1 {
+		t.Errorf("message counts = %v, want one per server and message", counts)
+	}
+}
+
+func TestMigrations_BackfillErrorSignatures(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	createLegacyDatabase(t, dbPath)
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open legacy db: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO events (session_id, event_type, tool_name, mcp_server, success, created_at)
+		VALUES ('s1', 'PostToolUse', 'search', 'github', 0, '2026-01-10 10:20:00 +0000 UTC')`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("failed to insert failed call: %v", err)
+	}
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	clusters, err := store.GetErrorClusters(ctx, ErrorClusterFilter{})
+	if err != nil {
+		t.Fatalf("GetErrorClusters failed: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Signature != "(no error message)" || clusters[0].Count != 1 {
+		t.Errorf("clusters = %+v, want the legacy failure without a message", clusters)
+	}
+}
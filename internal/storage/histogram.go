@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"math"
+	"sort"
+)
+
+// histogramGrowth is the ratio between consecutive bucket boundaries.
+// With a growth factor of 1.1, any value reported from a bucket is within
+// ~5% of the true latency, no matter how many histograms are merged.
+const histogramGrowth = 1.1
+
+// LatencyHistogram is a log-bucketed distribution of call latencies.
+// Bucket 0 holds latencies of at most 1ms; bucket i (i > 0) holds latencies
+// in (growth^(i-1), growth^i]. Histograms with the same bucket layout can be
+// merged by adding counts, which lets per-day histograms be combined into
+// percentiles for any time range.
+type LatencyHistogram struct {
+	Counts map[int]int64
+	Total  int64
+}
+
+// NewLatencyHistogram creates an empty histogram.
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{Counts: make(map[int]int64)}
+}
+
+// LatencyBucket returns the bucket index for a latency in milliseconds.
+func LatencyBucket(ms int64) int {
+	if ms <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log(float64(ms)) / math.Log(histogramGrowth)))
+}
+
+// BucketValue returns the representative latency of a bucket, which is the
+// geometric midpoint of its boundaries.
+func BucketValue(bucket int) float64 {
+	if bucket <= 0 {
+		return 1
+	}
+	return math.Pow(histogramGrowth, float64(bucket)-0.5)
+}
+
+// Add records a single latency observation.
+func (h *LatencyHistogram) Add(ms int64) {
+	h.AddBucket(LatencyBucket(ms), 1)
+}
+
+// AddBucket adds count observations to a bucket.
+func (h *LatencyHistogram) AddBucket(bucket int, count int64) {
+	if count <= 0 {
+		return
+	}
+	h.Counts[bucket] += count
+	h.Total += count
+}
+
+// Merge adds all observations from other into h.
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other == nil {
+		return
+	}
+	for bucket, count := range other.Counts {
+		h.AddBucket(bucket, count)
+	}
+}
+
+// Percentile returns the latency at quantile q (0 < q <= 1) in milliseconds.
+// Returns 0 for an empty histogram.
+func (h *LatencyHistogram) Percentile(q float64) float64 {
+	if h == nil || h.Total == 0 {
+		return 0
+	}
+
+	buckets := make([]int, 0, len(h.Counts))
+	for bucket := range h.Counts {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	rank := int64(math.Ceil(q * float64(h.Total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for _, bucket := range buckets {
+		seen += h.Counts[bucket]
+		if seen >= rank {
+			return BucketValue(bucket)
+		}
+	}
+	return BucketValue(buckets[len(buckets)-1])
+}
+
+// CountAbove returns the number of observations slower than ms. Latencies
+// in the same bucket as ms are not counted, so the result may be off by up
+// to one bucket's width (~10%).
+func (h *LatencyHistogram) CountAbove(ms float64) int64 {
+	if h == nil {
+		return 0
+	}
+	limit := LatencyBucket(int64(math.Ceil(ms)))
+	var count int64
+	for bucket, n := range h.Counts {
+		if bucket > limit {
+			count += n
+		}
+	}
+	return count
+}
+
+// ToolLatency pairs a tool with its merged latency histogram.
+type ToolLatency struct {
+	ToolName   string
+	ServerName string
+	Histogram  *LatencyHistogram
+}
+
+// mergeByServer combines tool histograms into one histogram per server.
+func mergeByServer(latencies []ToolLatency) map[string]*LatencyHistogram {
+	servers := make(map[string]*LatencyHistogram)
+	for _, tl := range latencies {
+		h, ok := servers[tl.ServerName]
+		if !ok {
+			h = NewLatencyHistogram()
+			servers[tl.ServerName] = h
+		}
+		h.Merge(tl.Histogram)
+	}
+	return servers
+}
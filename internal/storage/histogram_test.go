@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	tests := []struct {
+		ms       int64
+		expected int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 8},
+		{100, 49},
+		{1000, 73},
+	}
+
+	for _, tt := range tests {
+		if got := LatencyBucket(tt.ms); got != tt.expected {
+			t.Errorf("LatencyBucket(%d) = %d, expected %d", tt.ms, got, tt.expected)
+		}
+	}
+}
+
+func TestBucketValueRelativeError(t *testing.T) {
+	for _, ms := range []int64{2, 7, 50, 123, 999, 4500, 60000} {
+		got := BucketValue(LatencyBucket(ms))
+		relErr := math.Abs(got-float64(ms)) / float64(ms)
+		if relErr > 0.05 {
+			t.Errorf("bucket value for %dms is %.1f (%.1f%% error), expected within 5%%", ms, got, relErr*100)
+		}
+	}
+}
+
+func TestLatencyHistogram_Percentile(t *testing.T) {
+	h := NewLatencyHistogram()
+	for ms := int64(1); ms <= 1000; ms++ {
+		h.Add(ms)
+	}
+
+	tests := []struct {
+		q        float64
+		expected float64
+	}{
+		{0.50, 500},
+		{0.90, 900},
+		{0.99, 990},
+	}
+
+	for _, tt := range tests {
+		got := h.Percentile(tt.q)
+		if math.Abs(got-tt.expected)/tt.expected > 0.05 {
+			t.Errorf("p%.0f = %.1f, expected ~%.0f", tt.q*100, got, tt.expected)
+		}
+	}
+}
+
+func TestLatencyHistogram_Empty(t *testing.T) {
+	h := NewLatencyHistogram()
+	if got := h.Percentile(0.5); got != 0 {
+		t.Errorf("expected 0 for empty histogram, got %f", got)
+	}
+
+	var nilHist *LatencyHistogram
+	if got := nilHist.Percentile(0.5); got != 0 {
+		t.Errorf("expected 0 for nil histogram, got %f", got)
+	}
+}
+
+func TestLatencyHistogram_CountAbove(t *testing.T) {
+	h := NewLatencyHistogram()
+	for i := 0; i < 90; i++ {
+		h.Add(100)
+	}
+	for i := 0; i < 10; i++ {
+		h.Add(5000)
+	}
+
+	tests := []struct {
+		ms       float64
+		expected int64
+	}{
+		{50, 100},
+		{2000, 10},
+		{5000, 0}, // Same bucket as the slowest calls
+		{10000, 0},
+	}
+	for _, tt := range tests {
+		if got := h.CountAbove(tt.ms); got != tt.expected {
+			t.Errorf("CountAbove(%v) = %d, want %d", tt.ms, got, tt.expected)
+		}
+	}
+
+	var nilHist *LatencyHistogram
+	if got := nilHist.CountAbove(1); got != 0 {
+		t.Errorf("expected 0 for nil histogram, got %d", got)
+	}
+}
+
+func TestLatencyHistogram_Merge(t *testing.T) {
+	day1 := NewLatencyHistogram()
+	day2 := NewLatencyHistogram()
+	for i := 0; i < 90; i++ {
+		day1.Add(100)
+	}
+	for i := 0; i < 10; i++ {
+		day2.Add(5000)
+	}
+
+	merged := NewLatencyHistogram()
+	merged.Merge(day1)
+	merged.Merge(day2)
+
+	if merged.Total != 100 {
+		t.Fatalf("expected 100 observations, got %d", merged.Total)
+	}
+	if p50 := merged.Percentile(0.50); math.Abs(p50-100) > 5 {
+		t.Errorf("expected p50 ~100ms, got %.1f", p50)
+	}
+	if p99 := merged.Percentile(0.99); math.Abs(p99-5000) > 250 {
+		t.Errorf("expected p99 ~5000ms, got %.1f", p99)
+	}
+}
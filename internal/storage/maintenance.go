@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TrimRecentEvents removes recent events beyond the newest keep rows and any
+// older than olderThan. A zero olderThan trims by count only.
+func (s *SQLiteStore) TrimRecentEvents(ctx context.Context, keep int, olderThan time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM recent_events WHERE id <= (
+			SELECT id FROM recent_events ORDER BY id DESC LIMIT 1 OFFSET ?
+		)`, keep)
+	if err != nil {
+		return 0, fmt.Errorf("trimming recent events: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	if !olderThan.IsZero() {
+		result, err = s.db.ExecContext(ctx,
+			"DELETE FROM recent_events WHERE timestamp < ?",
+			olderThan.UTC().Format(time.RFC3339))
+		if err != nil {
+			return 0, fmt.Errorf("deleting old recent events: %w", err)
+		}
+		aged, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("getting rows affected: %w", err)
+		}
+		deleted += aged
+	}
+
+	return deleted, nil
+}
+
+// CleanupSessions removes sessions that started before olderThan.
+func (s *SQLiteStore) CleanupSessions(ctx context.Context, olderThan time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		"DELETE FROM sessions WHERE started_at < ?", olderThan.Local())
+	if err != nil {
+		return 0, fmt.Errorf("deleting old sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
+// VacuumResult reports the outcome of Vacuum.
+type VacuumResult struct {
+	Mode       string // "incremental", "full", or "" when nothing was done
+	FreedPages int64
+}
+
+// Vacuum returns free pages to the filesystem. Databases are switched to
+// incremental auto-vacuum with a one-time full VACUUM; after that only
+// PRAGMA incremental_vacuum runs, which is cheap. Nothing happens when the
+// database has no free pages.
+func (s *SQLiteStore) Vacuum(ctx context.Context) (*VacuumResult, error) {
+	// PRAGMAs apply per connection, so pin one for the whole operation
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring connection: %w", err)
+	}
+	defer conn.Close()
+
+	var before int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&before); err != nil {
+		return nil, fmt.Errorf("reading freelist count: %w", err)
+	}
+
+	result := &VacuumResult{}
+	if before == 0 {
+		return result, nil
+	}
+
+	var mode int
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return nil, fmt.Errorf("reading auto_vacuum mode: %w", err)
+	}
+
+	const autoVacuumIncremental = 2
+	if mode == autoVacuumIncremental {
+		result.Mode = "incremental"
+		if _, err := conn.ExecContext(ctx, "PRAGMA incremental_vacuum"); err != nil {
+			return nil, fmt.Errorf("running incremental vacuum: %w", err)
+		}
+	} else {
+		result.Mode = "full"
+		if _, err := conn.ExecContext(ctx, "PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+			return nil, fmt.Errorf("enabling incremental auto_vacuum: %w", err)
+		}
+		if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+			return nil, fmt.Errorf("running vacuum: %w", err)
+		}
+	}
+
+	var after int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&after); err != nil {
+		return nil, fmt.Errorf("reading freelist count: %w", err)
+	}
+	result.FreedPages = before - after
+
+	return result, nil
+}
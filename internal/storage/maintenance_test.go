@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTrimRecentEvents(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		ts := now.Add(-time.Duration(10-i) * 24 * time.Hour)
+		if err := store.InsertRecentEvent(ctx, ts, "s1", "PostToolUse", "Read", "", 10, true); err != nil {
+			t.Fatalf("failed to insert recent event: %v", err)
+		}
+	}
+
+	// Keep at most 8, and nothing older than 5 days
+	deleted, err := store.TrimRecentEvents(ctx, 8, now.AddDate(0, 0, -5))
+	if err != nil {
+		t.Fatalf("failed to trim: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("expected 5 rows trimmed, got %d", deleted)
+	}
+
+	events, err := store.GetRecentEvents(ctx, 100)
+	if err != nil {
+		t.Fatalf("failed to get recent events: %v", err)
+	}
+	if len(events) != 5 {
+		t.Errorf("expected 5 recent events left, got %d", len(events))
+	}
+}
+
+func TestInsertRecentEvent_ConfiguredCapacity(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	store.SetRecentEventsCapacity(150)
+
+	now := time.Now()
+	for i := 0; i < 200; i++ {
+		if err := store.InsertRecentEvent(ctx, now, "s1", "PostToolUse", "Read", "", 10, true); err != nil {
+			t.Fatalf("failed to insert recent event: %v", err)
+		}
+	}
+
+	events, err := store.GetRecentEvents(ctx, 500)
+	if err != nil {
+		t.Fatalf("failed to get recent events: %v", err)
+	}
+	if len(events) != 150 {
+		t.Errorf("expected 150 recent events kept, got %d", len(events))
+	}
+}
+
+func TestCleanupSessions(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := store.UpsertSession(ctx, "old", "/tmp", now.AddDate(0, 0, -40)); err != nil {
+		t.Fatalf("failed to upsert session: %v", err)
+	}
+	if err := store.UpsertSession(ctx, "new", "/tmp", now); err != nil {
+		t.Fatalf("failed to upsert session: %v", err)
+	}
+
+	deleted, err := store.CleanupSessions(ctx, now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("failed to clean up sessions: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 session removed, got %d", deleted)
+	}
+	if sess, _ := store.GetSession(ctx, "new"); sess == nil {
+		t.Error("expected recent session to remain")
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	fillAndClear := func() {
+		for i := 0; i < 500; i++ {
+			if err := store.StoreEvent(ctx, &Event{
+				SessionID: "s1", EventType: "PostToolUse", ToolName: "Read",
+				RawPayload: make([]byte, 512),
+			}); err != nil {
+				t.Fatalf("failed to store event: %v", err)
+			}
+		}
+		if _, err := store.Cleanup(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("failed to clean up: %v", err)
+		}
+	}
+
+	// First vacuum converts the database to incremental auto-vacuum
+	fillAndClear()
+	result, err := store.Vacuum(ctx)
+	if err != nil {
+		t.Fatalf("failed to vacuum: %v", err)
+	}
+	if result.Mode != "full" || result.FreedPages == 0 {
+		t.Errorf("expected a full vacuum freeing pages, got %+v", result)
+	}
+	var mode int
+	if err := store.db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		t.Fatalf("failed to read auto_vacuum: %v", err)
+	}
+	if mode != 2 {
+		t.Errorf("expected incremental auto_vacuum (2), got %d", mode)
+	}
+
+	// Nothing to reclaim right after a vacuum
+	result, err = store.Vacuum(ctx)
+	if err != nil {
+		t.Fatalf("failed to vacuum: %v", err)
+	}
+	if result.Mode != "" {
+		t.Errorf("expected no vacuum on an empty freelist, got %q", result.Mode)
+	}
+
+	// Later runs are incremental
+	fillAndClear()
+	result, err = store.Vacuum(ctx)
+	if err != nil {
+		t.Fatalf("failed to vacuum: %v", err)
+	}
+	if result.Mode != "incremental" || result.FreedPages == 0 {
+		t.Errorf("expected an incremental vacuum freeing pages, got %+v", result)
+	}
+}
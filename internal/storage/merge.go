@@ -0,0 +1,523 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Host returns the host name new rows are tagged with.
+func (s *SQLiteStore) Host() string {
+	return s.host
+}
+
+// SetHost renames this database's host. Rows already tagged with the old
+// name are retagged, so a host keeps one name across its history; rows
+// merged from other hosts are left alone.
+func (s *SQLiteStore) SetHost(ctx context.Context, name string) error {
+	if name == "" || name == s.host {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range hostTables {
+		if _, err := tx.ExecContext(ctx,
+			fmt.Sprintf("UPDATE %s SET host = ? WHERE host = ?", table), name, s.host); err != nil {
+			return fmt.Errorf("renaming host in %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO sync_state (key, value) VALUES ('host', ?)", name); err != nil {
+		return fmt.Errorf("storing host: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing host: %w", err)
+	}
+
+	s.host = name
+	return nil
+}
+
+// hostTables lists the tables whose rows are tagged with a host.
+var hostTables = []string{
+	"events",
+	"sessions",
+	"tool_stats",
+	"call_rollups_minute",
+	"call_rollups_hour",
+	"tool_latency_histograms",
+	"tool_latency_histograms_hour",
+	"tool_response_sizes",
+	"mcp_probes",
+	"mcp_tool_definitions",
+	"proxy_calls",
+	"turn_usage",
+	"tool_call_costs",
+}
+
+// MergeOptions configures a merge.
+type MergeOptions struct {
+	// Host names the source machine. It is required for databases created
+	// before rows were tagged with a host, and renames the source otherwise.
+	Host string
+}
+
+// MergeReport describes a completed merge.
+type MergeReport struct {
+	Source          string
+	Hosts           []string // Hosts present in the source
+	Events          int64    // Raw events imported
+	DuplicateEvents int64    // Source events already present
+	Sessions        int64    // Sessions imported or updated
+	AggregateRows   int64    // Rollup, tool stats, and histogram rows imported or updated
+	UsageRows       int64    // Model response and tool call cost rows imported
+}
+
+// Merge imports sessions, aggregates, and raw events from the database at
+// path. Every row keeps the host it was recorded on. Events already present
+// with the same session, type, tool, host, and timestamp are skipped, so
+// merging the same source twice imports nothing new; for aggregates the
+// row with the larger count wins. Times recorded in another time zone are
+// converted to this machine's.
+func (s *SQLiteStore) Merge(ctx context.Context, path string, opts MergeOptions) (*MergeReport, error) {
+	src, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	self, err := filepath.Abs(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if src == self {
+		return nil, fmt.Errorf("cannot merge %s into itself", path)
+	}
+
+	if _, err := VerifySnapshot(ctx, src); err != nil {
+		return nil, err
+	}
+
+	// Work on a migrated copy so the source is never written to
+	tmpDir, err := os.MkdirTemp(filepath.Dir(self), ".merge-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	staged := filepath.Join(tmpDir, "source.db")
+	if err := vacuumInto(ctx, src, staged); err != nil {
+		return nil, fmt.Errorf("copying %s: %w", path, err)
+	}
+	if err := prepareMergeSource(ctx, staged, opts); err != nil {
+		return nil, err
+	}
+
+	report := &MergeReport{Source: path}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS other", staged); err != nil {
+		return nil, fmt.Errorf("attaching %s: %w", path, err)
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE other")
+
+	if err := mergeAttached(ctx, conn, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// prepareMergeSource tags a staged source with its host and migrates it to
+// the current schema.
+func prepareMergeSource(ctx context.Context, path string, opts MergeOptions) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	var host string
+	err = db.QueryRowContext(ctx, "SELECT value FROM sync_state WHERE key = 'host'").Scan(&host)
+	if err == sql.ErrNoRows && opts.Host != "" {
+		// Older databases take the given name when they are migrated
+		_, err = db.ExecContext(ctx, "INSERT INTO sync_state (key, value) VALUES ('host', ?)", opts.Host)
+	}
+	db.Close()
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("source has no host name; name the machine it came from")
+	}
+	if err != nil {
+		return fmt.Errorf("reading source host: %w", err)
+	}
+
+	source, err := NewSQLiteStore(path)
+	if err != nil {
+		return fmt.Errorf("opening source: %w", err)
+	}
+	defer source.Close()
+	if err := source.SetHost(ctx, opts.Host); err != nil {
+		return err
+	}
+	return localizeMergeSource(ctx, source.db)
+}
+
+// mergeTimeColumns lists the timestamp columns of merged tables.
+var mergeTimeColumns = []struct{ table, column string }{
+	{"events", "created_at"},
+	{"sessions", "started_at"},
+	{"sessions", "ended_at"},
+	{"turn_usage", "created_at"},
+	{"tool_call_costs", "created_at"},
+}
+
+// localizeMergeSource rewrites the times in a staged source in this
+// machine's local time, as rows recorded here are stored, so merged rows
+// sort, filter and deduplicate against them. A source recorded in another
+// time zone, such as a devcontainer running in UTC, stores its own local
+// time. Timestamps carry their offset and convert exactly; minute and hour
+// rollup and latency histogram buckets are shifted by the offset of the
+// source's newest event.
+// Daily rows keep the source's day.
+func localizeMergeSource(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var newest sql.NullString
+	if err := tx.QueryRowContext(ctx,
+		"SELECT CAST(created_at AS TEXT) FROM events ORDER BY id DESC LIMIT 1").Scan(&newest); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("reading source offset: %w", err)
+	}
+	if newest.Valid {
+		if at := parseStoredTime(newest.String); !at.IsZero() {
+			_, offset := at.Zone()
+			for _, tier := range []Tier{TierMinute, TierHour} {
+				if err := rebucketRollups(ctx, tx, tier, time.FixedZone("", offset)); err != nil {
+					return err
+				}
+			}
+			if err := rebucketLatencies(ctx, tx, time.FixedZone("", offset)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range mergeTimeColumns {
+		if err := localizeColumn(ctx, tx, c.table, c.column); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing local times: %w", err)
+	}
+	return nil
+}
+
+// localizeColumn rewrites a timestamp column in local time.
+func localizeColumn(ctx context.Context, tx *sql.Tx, table, column string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		"SELECT rowid, CAST(%[2]s AS TEXT) FROM %[1]s WHERE %[2]s IS NOT NULL", table, column))
+	if err != nil {
+		return fmt.Errorf("reading %s.%s: %w", table, column, err)
+	}
+	times := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning %s.%s: %w", table, column, err)
+		}
+		if t := parseStoredTime(value); !t.IsZero() {
+			times[id] = t
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, t := range times {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s = ? WHERE rowid = ?", table, column), t.Local(), id); err != nil {
+			return fmt.Errorf("updating %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
+
+// rebucketRollups moves a rollup tier's buckets, recorded in the source's
+// local time at offset from, into this machine's local time. Buckets that
+// land together are summed.
+func rebucketRollups(ctx context.Context, tx *sql.Tx, tier Tier, from *time.Location) error {
+	rt := rollupTiers[tier]
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s, tool_name, server_name, host, call_count, error_count, total_latency_ms, COALESCE(last_seen_at, '')
+		FROM %s`, rt.bucket, rt.table))
+	if err != nil {
+		return fmt.Errorf("reading %s rollups: %w", tier, err)
+	}
+
+	type key struct{ bucket, tool, server, host string }
+	type sums struct {
+		calls, errors, latency int64
+		lastSeen               string
+	}
+	var order []key
+	moved := make(map[key]*sums)
+	for rows.Next() {
+		var k key
+		var v sums
+		if err := rows.Scan(&k.bucket, &k.tool, &k.server, &k.host, &v.calls, &v.errors, &v.latency, &v.lastSeen); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning %s rollup: %w", tier, err)
+		}
+		if t, err := time.ParseInLocation(rt.layout, k.bucket, from); err == nil {
+			k.bucket = t.Local().Format(rt.layout)
+		}
+		if m, ok := moved[k]; ok {
+			m.calls += v.calls
+			m.errors += v.errors
+			m.latency += v.latency
+			m.lastSeen = max(m.lastSeen, v.lastSeen)
+			continue
+		}
+		order = append(order, k)
+		moved[k] = &v
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+rt.table); err != nil {
+		return fmt.Errorf("clearing %s rollups: %w", tier, err)
+	}
+	for _, k := range order {
+		v := moved[k]
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s (%s, tool_name, server_name, host, call_count, error_count, total_latency_ms, last_seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))`, rt.table, rt.bucket),
+			k.bucket, k.tool, k.server, k.host, v.calls, v.errors, v.latency, v.lastSeen); err != nil {
+			return fmt.Errorf("rebucketing %s rollups: %w", tier, err)
+		}
+	}
+	return nil
+}
+
+// rebucketLatencies moves the hourly latency histograms, recorded in the
+// source's local time at offset from, into this machine's local time.
+// Buckets that land together are summed.
+func rebucketLatencies(ctx context.Context, tx *sql.Tx, from *time.Location) error {
+	lt := latencyTiers[TierHour]
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		"SELECT %s, tool_name, server_name, host, bucket, count FROM %s", lt.bucket, lt.table))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", lt.table, err)
+	}
+
+	type key struct {
+		hour, tool, server, host string
+		bucket                   int
+	}
+	var order []key
+	moved := make(map[key]int64)
+	for rows.Next() {
+		var k key
+		var count int64
+		if err := rows.Scan(&k.hour, &k.tool, &k.server, &k.host, &k.bucket, &count); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning %s: %w", lt.table, err)
+		}
+		if t, err := time.ParseInLocation(lt.layout, k.hour, from); err == nil {
+			k.hour = t.Local().Format(lt.layout)
+		}
+		if _, ok := moved[k]; !ok {
+			order = append(order, k)
+		}
+		moved[k] += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+lt.table); err != nil {
+		return fmt.Errorf("clearing %s: %w", lt.table, err)
+	}
+	for _, k := range order {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s (%s, tool_name, server_name, host, bucket, count)
+			VALUES (?, ?, ?, ?, ?, ?)`, lt.table, lt.bucket),
+			k.hour, k.tool, k.server, k.host, k.bucket, moved[k]); err != nil {
+			return fmt.Errorf("rebucketing %s: %w", lt.table, err)
+		}
+	}
+	return nil
+}
+
+// mergeAttached copies rows from the attached "other" database in one
+// transaction.
+func mergeAttached(ctx context.Context, conn *sql.Conn, report *MergeReport) error {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT host FROM other.events UNION SELECT host FROM other.sessions`)
+	if err != nil {
+		return fmt.Errorf("listing source hosts: %w", err)
+	}
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			rows.Close()
+			return err
+		}
+		report.Hosts = append(report.Hosts, host)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	sort.Strings(report.Hosts)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sourceEvents int64
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM other.events").Scan(&sourceEvents); err != nil {
+		return fmt.Errorf("counting source events: %w", err)
+	}
+
+	// New events are indexed for search by the insert trigger
+	report.Events, err = execCount(ctx, tx, `
+		INSERT INTO main.events (session_id, event_type, tool_name, mcp_server, success,
+			duration_ms, input_tokens, output_tokens, cost_usd, raw_payload,
+			tool_input, error, error_signature, response_bytes, response_tokens, cwd, host, created_at)
+		SELECT o.session_id, o.event_type, o.tool_name, o.mcp_server, o.success,
+			o.duration_ms, o.input_tokens, o.output_tokens, o.cost_usd, o.raw_payload,
+			o.tool_input, o.error, o.error_signature, o.response_bytes, o.response_tokens, o.cwd, o.host, o.created_at
+		FROM other.events o
+		WHERE NOT EXISTS (
+			SELECT 1 FROM main.events e
+			WHERE e.session_id = o.session_id
+				AND e.created_at = o.created_at
+				AND e.event_type = o.event_type
+				AND e.tool_name IS o.tool_name
+				AND e.host = o.host)
+		ORDER BY o.id`)
+	if err != nil {
+		return fmt.Errorf("merging events: %w", err)
+	}
+	report.DuplicateEvents = sourceEvents - report.Events
+
+	report.Sessions, err = execCount(ctx, tx, `
+		INSERT INTO main.sessions (id, cwd, host, started_at, ended_at, total_events, total_tokens, total_cost_usd)
+		SELECT id, cwd, host, started_at, ended_at, total_events, total_tokens, total_cost_usd
+		FROM other.sessions WHERE true
+		ON CONFLICT(id) DO UPDATE SET
+			cwd = COALESCE(NULLIF(cwd, ''), excluded.cwd),
+			ended_at = COALESCE(ended_at, excluded.ended_at),
+			total_events = MAX(total_events, excluded.total_events),
+			total_tokens = MAX(total_tokens, excluded.total_tokens),
+			total_cost_usd = MAX(total_cost_usd, excluded.total_cost_usd)`)
+	if err != nil {
+		return fmt.Errorf("merging sessions: %w", err)
+	}
+
+	// An aggregate row for the same bucket and host is the same count seen
+	// at different times, so the larger one is the more complete
+	for _, table := range []struct{ name, bucket string }{
+		{"tool_stats", "date"},
+		{"call_rollups_minute", "bucket"},
+		{"call_rollups_hour", "bucket"},
+	} {
+		n, err := execCount(ctx, tx, fmt.Sprintf(`
+			INSERT INTO main.%[1]s (%[2]s, tool_name, server_name, host, call_count, error_count, total_latency_ms, last_seen_at)
+			SELECT %[2]s, tool_name, server_name, host, call_count, error_count, total_latency_ms, last_seen_at
+			FROM other.%[1]s WHERE true
+			ON CONFLICT(%[2]s, tool_name, server_name, host) DO UPDATE SET
+				call_count = excluded.call_count,
+				error_count = excluded.error_count,
+				total_latency_ms = excluded.total_latency_ms,
+				last_seen_at = excluded.last_seen_at
+			WHERE excluded.call_count > call_count`, table.name, table.bucket))
+		if err != nil {
+			return fmt.Errorf("merging %s: %w", table.name, err)
+		}
+		report.AggregateRows += n
+	}
+
+	for _, tier := range []Tier{TierDay, TierHour} {
+		lt := latencyTiers[tier]
+		n, err := execCount(ctx, tx, fmt.Sprintf(`
+			INSERT INTO main.%[1]s (%[2]s, tool_name, server_name, host, bucket, count)
+			SELECT %[2]s, tool_name, server_name, host, bucket, count
+			FROM other.%[1]s WHERE true
+			ON CONFLICT(%[2]s, tool_name, server_name, host, bucket) DO UPDATE SET
+				count = excluded.count
+			WHERE excluded.count > count`, lt.table, lt.bucket))
+		if err != nil {
+			return fmt.Errorf("merging %s: %w", lt.table, err)
+		}
+		report.AggregateRows += n
+	}
+
+	n, err := execCount(ctx, tx, `
+		INSERT INTO main.tool_response_sizes (date, tool_name, server_name, host, bucket, count, total_bytes, total_tokens)
+		SELECT date, tool_name, server_name, host, bucket, count, total_bytes, total_tokens
+		FROM other.tool_response_sizes WHERE true
+		ON CONFLICT(date, tool_name, server_name, host, bucket) DO UPDATE SET
+			count = excluded.count,
+			total_bytes = excluded.total_bytes,
+			total_tokens = excluded.total_tokens
+		WHERE excluded.count > count`)
+	if err != nil {
+		return fmt.Errorf("merging response sizes: %w", err)
+	}
+	report.AggregateRows += n
+
+	// Usage rows are keyed by API message and tool_use IDs, so a row
+	// already present is the same response read from the same transcript
+	for _, table := range []struct{ name, columns string }{
+		{"turn_usage", `message_id, host, session_id, model, input_tokens, output_tokens,
+			cache_creation_tokens, cache_read_tokens, cost_usd, created_at`},
+		{"tool_call_costs", `tool_use_id, host, session_id, tool_name, server_name, model,
+			result_bytes, input_tokens, input_cost_usd, followup_cost_usd, created_at`},
+	} {
+		n, err := execCount(ctx, tx, fmt.Sprintf(`
+			INSERT OR IGNORE INTO main.%[1]s (%[2]s)
+			SELECT %[2]s FROM other.%[1]s`, table.name, table.columns))
+		if err != nil {
+			return fmt.Errorf("merging %s: %w", table.name, err)
+		}
+		report.UsageRows += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing merge: %w", err)
+	}
+	return nil
+}
+
+// execCount runs a statement and returns the number of rows it changed.
+func execCount(ctx context.Context, tx *sql.Tx, query string) (int64, error) {
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
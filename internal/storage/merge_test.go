@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createHostStore creates a store tagged with the given host holding one
+// session with two tool calls.
+func createHostStore(t *testing.T, host string, sessionID string, at time.Time) *SQLiteStore {
+	t.Helper()
+	ctx := context.Background()
+
+	store := createTestStore(t)
+	if err := store.SetHost(ctx, host); err != nil {
+		t.Fatalf("SetHost failed: %v", err)
+	}
+
+	events := []*Event{
+		{SessionID: sessionID, EventType: "SessionStart", Cwd: "/work/" + host, CreatedAt: at},
+		{SessionID: sessionID, EventType: "PostToolUse", ToolName: "search", MCPServer: "github",
+			Success: true, DurationMs: 100, ToolInput: `{"q":"` + host + `"}`, CreatedAt: at.Add(time.Minute)},
+		{SessionID: sessionID, EventType: "PostToolUse", ToolName: "read", MCPServer: "fs",
+			Success: false, DurationMs: 50, Error: "not found", CreatedAt: at.Add(2 * time.Minute)},
+	}
+	for _, e := range events {
+		if err := store.StoreEvent(ctx, e); err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+	return store
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Add(-time.Hour)
+
+	local := createHostStore(t, "laptop", "s-laptop", now)
+	defer local.Close()
+	remote := createHostStore(t, "desktop", "s-desktop", now)
+	remotePath := remote.path
+	remote.Close()
+
+	report, err := local.Merge(ctx, remotePath, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if report.Events != 3 || report.DuplicateEvents != 0 {
+		t.Errorf("events = %d new, %d duplicate, want 3, 0", report.Events, report.DuplicateEvents)
+	}
+	if report.Sessions != 1 {
+		t.Errorf("sessions = %d, want 1", report.Sessions)
+	}
+	if len(report.Hosts) != 1 || report.Hosts[0] != "desktop" {
+		t.Errorf("hosts = %v, want [desktop]", report.Hosts)
+	}
+
+	// Merging again imports nothing new
+	again, err := local.Merge(ctx, remotePath, MergeOptions{})
+	if err != nil {
+		t.Fatalf("second Merge failed: %v", err)
+	}
+	if again.Events != 0 || again.DuplicateEvents != 3 || again.AggregateRows != 0 {
+		t.Errorf("second merge = %d new, %d duplicate, %d aggregates, want 0, 3, 0",
+			again.Events, again.DuplicateEvents, again.AggregateRows)
+	}
+
+	tests := []struct {
+		host     string
+		events   int
+		calls    int64
+		sessions int
+	}{
+		{"", 6, 4, 2},
+		{"laptop", 3, 2, 1},
+		{"desktop", 3, 2, 1},
+		{"elsewhere", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run("host="+tt.host, func(t *testing.T) {
+			filter := TimeFilter{Host: tt.host}
+
+			events, err := local.GetEvents(ctx, EventFilter{TimeFilter: filter})
+			if err != nil {
+				t.Fatalf("GetEvents failed: %v", err)
+			}
+			if len(events) != tt.events {
+				t.Errorf("events = %d, want %d", len(events), tt.events)
+			}
+			for _, e := range events {
+				if tt.host != "" && e.Host != tt.host {
+					t.Errorf("event host = %q, want %q", e.Host, tt.host)
+				}
+			}
+
+			sessions, err := local.GetSessions(ctx, SessionFilter{TimeFilter: filter})
+			if err != nil {
+				t.Fatalf("GetSessions failed: %v", err)
+			}
+			if len(sessions) != tt.sessions {
+				t.Errorf("sessions = %d, want %d", len(sessions), tt.sessions)
+			}
+
+			// Raw events and rollups must agree
+			raw, err := local.GetToolStats(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetToolStats failed: %v", err)
+			}
+			rollup, err := local.toolStatsFromRollups(ctx, TierDay, filter)
+			if err != nil {
+				t.Fatalf("toolStatsFromRollups failed: %v", err)
+			}
+			for name, stats := range map[string][]ToolStats{"raw": raw, "rollup": rollup} {
+				var calls int64
+				for _, st := range stats {
+					calls += st.TotalCalls
+				}
+				if calls != tt.calls {
+					t.Errorf("%s calls = %d, want %d", name, calls, tt.calls)
+				}
+			}
+		})
+	}
+
+	results, err := local.SearchEvents(ctx, SearchQuery{Query: "desktop"})
+	if err != nil {
+		t.Fatalf("SearchEvents failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Host != "desktop" {
+		t.Errorf("search for merged event = %+v, want a desktop event", results)
+	}
+}
+
+func TestMerge_SourceInOtherTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+
+	ctx := context.Background()
+	at := time.Now().Add(-2 * time.Hour).Truncate(time.Hour).Add(10 * time.Minute)
+
+	// A devcontainer recording in UTC, merged into a laptop in Los Angeles
+	time.Local = time.UTC
+	remote := createHostStore(t, "devcontainer", "s-dev", at)
+	remotePath := remote.path
+	remote.Close()
+
+	time.Local = loc
+	local := createHostStore(t, "laptop", "s-laptop", at)
+	defer local.Close()
+
+	if _, err := local.Merge(ctx, remotePath, MergeOptions{}); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	filter := TimeFilter{From: at.Add(-time.Minute), To: at.Add(5 * time.Minute)}
+	events, err := local.GetEvents(ctx, EventFilter{TimeFilter: filter})
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != 6 {
+		t.Errorf("expected both hosts' events in range, got %d", len(events))
+	}
+
+	hourly, err := local.GetHourlyRollups(ctx, TimeFilter{From: at, To: at, Host: "devcontainer"})
+	if err != nil {
+		t.Fatalf("GetHourlyRollups failed: %v", err)
+	}
+	var calls int64
+	for _, r := range hourly {
+		calls += r.Calls
+		if !r.Hour.Equal(at.Truncate(time.Hour)) {
+			t.Errorf("expected the merged calls in the hour of %v, got %v", at, r.Hour)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 merged calls in the hour, got %+v", hourly)
+	}
+
+	// Merging again still finds every event already present
+	again, err := local.Merge(ctx, remotePath, MergeOptions{})
+	if err != nil {
+		t.Fatalf("second Merge failed: %v", err)
+	}
+	if again.Events != 0 {
+		t.Errorf("expected no new events on a second merge, got %d", again.Events)
+	}
+}
+
+func TestMerge_LegacySourceNeedsHost(t *testing.T) {
+	ctx := context.Background()
+
+	local := createTestStore(t)
+	defer local.Close()
+
+	legacyPath := filepath.Join(t.TempDir(), "legacy.db")
+	createLegacyDatabase(t, legacyPath)
+
+	if _, err := local.Merge(ctx, legacyPath, MergeOptions{}); err == nil || !strings.Contains(err.Error(), "no host name") {
+		t.Fatalf("Merge without host error = %v, want a missing host error", err)
+	}
+
+	report, err := local.Merge(ctx, legacyPath, MergeOptions{Host: "old-box"})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if report.Events != 1 || len(report.Hosts) != 1 || report.Hosts[0] != "old-box" {
+		t.Errorf("report = %+v, want 1 event from old-box", report)
+	}
+
+	sessions, err := local.GetSessions(ctx, SessionFilter{TimeFilter: TimeFilter{Host: "old-box"}})
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Cwd != "/home/user/legacy-project" {
+		t.Errorf("sessions = %+v, want the legacy session", sessions)
+	}
+}
+
+func TestMerge_RejectsSelf(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	if _, err := store.Merge(context.Background(), store.path, MergeOptions{}); err == nil {
+		t.Error("expected merging a database into itself to fail")
+	}
+}
+
+func TestSetHost(t *testing.T) {
+	ctx := context.Background()
+	store := createHostStore(t, "before", "s1", time.Now().Add(-time.Hour))
+	defer store.Close()
+
+	if err := store.SetHost(ctx, "after"); err != nil {
+		t.Fatalf("SetHost failed: %v", err)
+	}
+
+	events, err := store.GetEvents(ctx, EventFilter{TimeFilter: TimeFilter{Host: "after"}})
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("events tagged after rename = %d, want 3", len(events))
+	}
+
+	// The name survives reopening
+	path := store.path
+	store.Close()
+	reopened, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopening store: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Host() != "after" {
+		t.Errorf("Host() = %q, want %q", reopened.Host(), "after")
+	}
+}
@@ -0,0 +1,985 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/errsig"
+)
+
+// migration is a numbered, ordered schema change. Migrations are applied in
+// version order, each in its own transaction, and recorded in schema_version.
+// Never edit a migration once released; add a new one instead.
+type migration struct {
+	version     int
+	description string
+	up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations lists every schema change. Version 2 is the v2.0 baseline that
+// databases created before the migration framework already record.
+var migrations = []migration{
+	{
+		version:     2,
+		description: "baseline schema",
+		up:          execSQL(baselineSchema),
+	},
+	{
+		version:     3,
+		description: "latency histograms per (date, tool, server)",
+		up:          execSQL(latencyHistogramSchema),
+	},
+	{
+		version:     4,
+		description: "add server_name to tool_stats primary key",
+		up:          execSQL(toolStatsServerKey),
+	},
+	{
+		version:     5,
+		description: "retire daily_stats and mcp_servers",
+		up:          execSQL(retireLegacyAggregates),
+	},
+	{
+		version:     6,
+		description: "minute and hour call rollups",
+		up:          execSQL(callRollupsSchema),
+	},
+	{
+		version:     7,
+		description: "full-text search over tool inputs, errors, and cwd",
+		up:          execSQL(eventSearchSchema),
+	},
+	{
+		version:     8,
+		description: "tag rows with their source host",
+		up:          addHostColumns,
+	},
+	{
+		version:     9,
+		description: "error signatures for clustering failed calls",
+		up:          addErrorSignatures,
+	},
+	{
+		version:     10,
+		description: "results of probing configured MCP servers",
+		up:          execSQL(probeResultsSchema),
+	},
+	{
+		version:     11,
+		description: "tool definitions listed by MCP servers",
+		up:          execSQL(toolDefinitionsSchema),
+	},
+	{
+		version:     12,
+		description: "JSON-RPC calls recorded by the MCP proxy",
+		up:          execSQL(proxyCallsSchema),
+	},
+	{
+		version:     13,
+		description: "failure class and HTTP status of proxy calls",
+		up:          execSQL(proxyFailureColumns),
+	},
+	{
+		version:     14,
+		description: "tool response sizes",
+		up:          execSQL(responseSizeSchema),
+	},
+	{
+		version:     15,
+		description: "model usage and tool call costs from transcripts",
+		up:          execSQL(usageSchema),
+	},
+	{
+		version:     16,
+		description: "alert incidents for deduplication and escalation",
+		up:          execSQL(alertsSchema),
+	},
+	{
+		version:     17,
+		description: "alert rules and their pending and clearing state",
+		up:          execSQL(alertRulesSchema),
+	},
+	{
+		version:     18,
+		description: "latency histograms per hour",
+		up:          addHourlyLatencyHistograms,
+	},
+}
+
+// MigrationState describes one known migration and whether it is applied.
+type MigrationState struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// MigrationReport describes the migrations applied when a store was opened.
+type MigrationReport struct {
+	FromVersion int
+	ToVersion   int
+	Applied     []MigrationState
+	BackupPath  string // Empty when no backup was needed
+}
+
+// LatestSchemaVersion returns the schema version this build migrates to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// MigrationReport returns the migrations applied when the store was opened.
+func (s *SQLiteStore) MigrationReport() MigrationReport {
+	return s.migration
+}
+
+// SchemaVersion returns the highest applied schema version.
+func (s *SQLiteStore) SchemaVersion(ctx context.Context) (int, error) {
+	return currentVersion(ctx, s.db)
+}
+
+// migrate applies all pending migrations. Existing databases are backed up
+// with VACUUM INTO before the first pending migration runs.
+func (s *SQLiteStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, schemaVersionTable); err != nil {
+		return fmt.Errorf("creating schema_version: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, s.db)
+	if err != nil {
+		return err
+	}
+
+	from, err := currentVersion(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	report := MigrationReport{FromVersion: from, ToVersion: from}
+
+	var pending []migration
+	for _, m := range sortedMigrations() {
+		if _, ok := applied[m.version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	if len(pending) == 0 {
+		s.migration = report
+		return nil
+	}
+
+	// Only back up databases that already hold data
+	if from > 0 && s.path != "" {
+		backupPath, err := backupBeforeMigration(ctx, s.db, s.path, pending[len(pending)-1].version)
+		if err != nil {
+			return err
+		}
+		report.BackupPath = backupPath
+	}
+
+	for _, m := range pending {
+		ran, err := applyMigration(ctx, s.db, m)
+		if err != nil {
+			return err
+		}
+		if !ran {
+			// Another process opening the database applied it first
+			continue
+		}
+		report.Applied = append(report.Applied, MigrationState{
+			Version:     m.version,
+			Description: m.description,
+			Applied:     true,
+			AppliedAt:   time.Now(),
+		})
+		report.ToVersion = m.version
+	}
+
+	s.migration = report
+	return nil
+}
+
+// migrationBackupsKept is how many pre-migration backups are kept per
+// database; older ones are removed after each new backup.
+const migrationBackupsKept = 3
+
+// backupBeforeMigration copies the database at dbPath with VACUUM INTO and
+// prunes older pre-migration backups, returning the backup path. A backup
+// another process already wrote under the same name is reused.
+func backupBeforeMigration(ctx context.Context, db *sql.DB, dbPath string, version int) (string, error) {
+	backupPath := fmt.Sprintf("%s.pre-v%d-%s.bak", dbPath, version, time.Now().Format("20060102-150405"))
+	if _, err := os.Stat(backupPath); err == nil {
+		return backupPath, nil
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", backupPath); err != nil {
+		if _, statErr := os.Stat(backupPath); statErr == nil {
+			return backupPath, nil
+		}
+		return "", fmt.Errorf("backing up database before migration: %w", err)
+	}
+
+	backups, err := filepath.Glob(dbPath + ".pre-v*.bak")
+	if err != nil {
+		return backupPath, nil
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backupTime(backups[i]).After(backupTime(backups[j]))
+	})
+	for _, old := range backups[min(len(backups), migrationBackupsKept):] {
+		os.Remove(old)
+	}
+	return backupPath, nil
+}
+
+// backupTime returns the time a pre-migration backup was taken, from the
+// timestamp in its name.
+func backupTime(path string) time.Time {
+	name := strings.TrimSuffix(path, ".bak")
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		if j := strings.LastIndex(name[:i], "-"); j >= 0 {
+			t, _ := time.Parse("20060102-150405", name[j+1:])
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// applyMigration runs a single migration in a transaction, reporting whether
+// it ran. The transaction takes the write lock when it begins, and a
+// migration recorded by the time it holds it is skipped.
+func applyMigration(ctx context.Context, db *sql.DB, m migration) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("beginning migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM schema_version WHERE version = ?", m.version).Scan(&applied); err != nil {
+		return false, fmt.Errorf("checking migration %d: %w", m.version, err)
+	}
+	if applied > 0 {
+		return false, nil
+	}
+
+	if err := m.up(ctx, tx); err != nil {
+		return false, fmt.Errorf("applying migration %d (%s): %w", m.version, m.description, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_version (version) VALUES (?)", m.version); err != nil {
+		return false, fmt.Errorf("recording migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("committing migration %d: %w", m.version, err)
+	}
+	return true, nil
+}
+
+// ReadMigrationStatus reports which migrations are applied to the database
+// at dbPath without applying anything.
+func ReadMigrationStatus(ctx context.Context, dbPath string) ([]MigrationState, error) {
+	var applied map[int]time.Time
+
+	if _, err := os.Stat(dbPath); err == nil {
+		db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+		if err != nil {
+			return nil, fmt.Errorf("opening database: %w", err)
+		}
+		defer db.Close()
+
+		applied, err = appliedVersions(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var states []MigrationState
+	for _, m := range sortedMigrations() {
+		state := MigrationState{Version: m.version, Description: m.description}
+		if at, ok := applied[m.version]; ok {
+			state.Applied = true
+			state.AppliedAt = at
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// appliedVersions returns the recorded migrations and when they ran.
+func appliedVersions(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	var exists int
+	err := db.QueryRowContext(ctx,
+		"SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'").Scan(&exists)
+	if err == sql.ErrNoRows {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking schema_version: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("querying schema_version: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("scanning schema_version: %w", err)
+		}
+		applied[version] = appliedAt.Time
+	}
+	return applied, rows.Err()
+}
+
+// currentVersion returns the highest applied version, or 0 for a new database.
+func currentVersion(ctx context.Context, db *sql.DB) (int, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// sortedMigrations returns migrations in ascending version order.
+func sortedMigrations() []migration {
+	sorted := make([]migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].version < sorted[j].version
+	})
+	return sorted
+}
+
+// execSQL returns a migration step that executes a SQL script.
+func execSQL(script string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, script)
+		return err
+	}
+}
+
+const schemaVersionTable = `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+`
+
+const baselineSchema = `
+	-- Core events table (append-only) - kept for backward compatibility
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		tool_name TEXT,
+		mcp_server TEXT,
+		success INTEGER,
+		duration_ms INTEGER,
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
+		cost_usd REAL DEFAULT 0.0,
+		raw_payload BLOB,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_events_session ON events(session_id);
+	CREATE INDEX IF NOT EXISTS idx_events_created ON events(created_at);
+	CREATE INDEX IF NOT EXISTS idx_events_type ON events(event_type);
+	CREATE INDEX IF NOT EXISTS idx_events_mcp_server ON events(mcp_server);
+	CREATE INDEX IF NOT EXISTS idx_events_tool ON events(tool_name);
+
+	-- Sessions table
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		cwd TEXT,
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME,
+		total_events INTEGER DEFAULT 0,
+		total_tokens INTEGER DEFAULT 0,
+		total_cost_usd REAL DEFAULT 0.0
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_started ON sessions(started_at);
+
+	-- MCP servers table
+	CREATE TABLE IF NOT EXISTS mcp_servers (
+		name TEXT PRIMARY KEY,
+		first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		total_calls INTEGER DEFAULT 0,
+		total_errors INTEGER DEFAULT 0
+	);
+
+	-- Daily stats / tool_stats for aggregated queries
+	CREATE TABLE IF NOT EXISTS tool_stats (
+		date TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		call_count INTEGER DEFAULT 0,
+		error_count INTEGER DEFAULT 0,
+		total_latency_ms INTEGER DEFAULT 0,
+		PRIMARY KEY (date, tool_name)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tool_stats_date ON tool_stats(date);
+	CREATE INDEX IF NOT EXISTS idx_tool_stats_server ON tool_stats(server_name);
+
+	-- Daily stats for performance (legacy, kept for compatibility)
+	CREATE TABLE IF NOT EXISTS daily_stats (
+		date DATE NOT NULL,
+		mcp_server TEXT,
+		tool_name TEXT,
+		call_count INTEGER DEFAULT 0,
+		success_count INTEGER DEFAULT 0,
+		error_count INTEGER DEFAULT 0,
+		total_latency_ms INTEGER DEFAULT 0,
+		total_tokens INTEGER DEFAULT 0,
+		total_cost_usd REAL DEFAULT 0.0,
+		PRIMARY KEY (date, mcp_server, tool_name)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_daily_stats_date ON daily_stats(date);
+
+	-- Recent events circular buffer (for TUI display)
+	CREATE TABLE IF NOT EXISTS recent_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		timestamp TEXT NOT NULL,
+		session_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		tool_name TEXT,
+		server_name TEXT,
+		duration_ms INTEGER,
+		success INTEGER
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_recent_events_ts ON recent_events(timestamp DESC);
+
+	-- Sync state (track JSONL processing position)
+	CREATE TABLE IF NOT EXISTS sync_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	INSERT OR IGNORE INTO sync_state (key, value) VALUES ('position', '0');
+
+	-- Event fingerprints for deduplication
+	CREATE TABLE IF NOT EXISTS event_fingerprints (
+		fingerprint TEXT PRIMARY KEY,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_fingerprints_created ON event_fingerprints(created_at);
+`
+
+const latencyHistogramSchema = `
+	-- Log-bucketed latency histograms per (date, tool, server)
+	CREATE TABLE IF NOT EXISTS tool_latency_histograms (
+		date TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		bucket INTEGER NOT NULL,
+		count INTEGER DEFAULT 0,
+		PRIMARY KEY (date, tool_name, server_name, bucket)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_latency_histograms_date ON tool_latency_histograms(date);
+`
+
+// toolStatsServerKey rebuilds tool_stats so the same tool name reported by
+// two servers is aggregated separately.
+const toolStatsServerKey = `
+	CREATE TABLE tool_stats_new (
+		date TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		call_count INTEGER DEFAULT 0,
+		error_count INTEGER DEFAULT 0,
+		total_latency_ms INTEGER DEFAULT 0,
+		PRIMARY KEY (date, tool_name, server_name)
+	);
+
+	INSERT INTO tool_stats_new (date, tool_name, server_name, call_count, error_count, total_latency_ms)
+	SELECT date, tool_name, server_name, call_count, error_count, total_latency_ms FROM tool_stats;
+
+	DROP TABLE tool_stats;
+	ALTER TABLE tool_stats_new RENAME TO tool_stats;
+
+	CREATE INDEX IF NOT EXISTS idx_tool_stats_date ON tool_stats(date);
+	CREATE INDEX IF NOT EXISTS idx_tool_stats_server ON tool_stats(server_name);
+`
+
+// retireLegacyAggregates drops tables that no query reads. Server and tool
+// aggregates live in tool_stats, fed by both sync and StoreEvent.
+const retireLegacyAggregates = `
+	DROP INDEX IF EXISTS idx_daily_stats_date;
+	DROP TABLE IF EXISTS daily_stats;
+	DROP TABLE IF EXISTS mcp_servers;
+`
+
+// callRollupsSchema adds minute and hour rollup tiers alongside the daily
+// tool_stats tier, and backfills them from raw events still on disk.
+const callRollupsSchema = `
+	ALTER TABLE tool_stats ADD COLUMN last_seen_at TEXT;
+
+	CREATE TABLE IF NOT EXISTS call_rollups_minute (
+		bucket TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		call_count INTEGER DEFAULT 0,
+		error_count INTEGER DEFAULT 0,
+		total_latency_ms INTEGER DEFAULT 0,
+		last_seen_at TEXT,
+		PRIMARY KEY (bucket, tool_name, server_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS call_rollups_hour (
+		bucket TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		call_count INTEGER DEFAULT 0,
+		error_count INTEGER DEFAULT 0,
+		total_latency_ms INTEGER DEFAULT 0,
+		last_seen_at TEXT,
+		PRIMARY KEY (bucket, tool_name, server_name)
+	);
+
+	-- created_at is stored as "YYYY-MM-DD HH:MM:SS... <zone>" in local time
+	INSERT INTO call_rollups_minute (bucket, tool_name, server_name, call_count, error_count, total_latency_ms)
+	SELECT substr(created_at, 1, 16), tool_name, COALESCE(mcp_server, ''), COUNT(*),
+		SUM(CASE WHEN success = 0 THEN 1 ELSE 0 END), SUM(duration_ms)
+	FROM events
+	WHERE event_type = 'PostToolUse' AND tool_name != ''
+	GROUP BY 1, 2, 3;
+
+	INSERT INTO call_rollups_hour (bucket, tool_name, server_name, call_count, error_count, total_latency_ms)
+	SELECT substr(created_at, 1, 13) || ':00', tool_name, COALESCE(mcp_server, ''), COUNT(*),
+		SUM(CASE WHEN success = 0 THEN 1 ELSE 0 END), SUM(duration_ms)
+	FROM events
+	WHERE event_type = 'PostToolUse' AND tool_name != ''
+	GROUP BY 1, 2, 3;
+`
+
+// eventSearchSchema adds the redacted search columns to events and an
+// external-content FTS5 index over them, kept in sync by triggers.
+const eventSearchSchema = `
+	ALTER TABLE events ADD COLUMN tool_input TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN error TEXT NOT NULL DEFAULT '';
+	ALTER TABLE events ADD COLUMN cwd TEXT NOT NULL DEFAULT '';
+
+	-- Existing events get the session cwd so older rows are searchable by
+	-- directory. Runs before the triggers exist, so the rebuild below indexes it.
+	UPDATE events SET cwd = COALESCE((SELECT cwd FROM sessions WHERE sessions.id = events.session_id), '');
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(
+		tool_input, error, cwd,
+		content='events', content_rowid='id'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+		INSERT INTO events_fts(rowid, tool_input, error, cwd)
+		VALUES (new.id, new.tool_input, new.error, new.cwd);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+		INSERT INTO events_fts(events_fts, rowid, tool_input, error, cwd)
+		VALUES ('delete', old.id, old.tool_input, old.error, old.cwd);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE ON events BEGIN
+		INSERT INTO events_fts(events_fts, rowid, tool_input, error, cwd)
+		VALUES ('delete', old.id, old.tool_input, old.error, old.cwd);
+		INSERT INTO events_fts(rowid, tool_input, error, cwd)
+		VALUES (new.id, new.tool_input, new.error, new.cwd);
+	END;
+
+	INSERT INTO events_fts(events_fts) VALUES ('rebuild');
+`
+
+// probeResultsSchema stores the outcome of each health check of a configured
+// MCP server, keyed by the name its tools are recorded under.
+const probeResultsSchema = `
+	CREATE TABLE IF NOT EXISTS mcp_probes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		server_name TEXT NOT NULL,
+		transport TEXT NOT NULL DEFAULT '',
+		startup_ms INTEGER DEFAULT 0,
+		handshake_ms INTEGER DEFAULT 0,
+		info_name TEXT NOT NULL DEFAULT '',
+		info_version TEXT NOT NULL DEFAULT '',
+		protocol_version TEXT NOT NULL DEFAULT '',
+		tool_count INTEGER DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		host TEXT NOT NULL DEFAULT '',
+		probed_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_mcp_probes_server ON mcp_probes(server_name, host, probed_at);
+`
+
+// toolDefinitionsSchema keeps the latest tools/list snapshot of each server,
+// one row per tool.
+const toolDefinitionsSchema = `
+	CREATE TABLE IF NOT EXISTS mcp_tool_definitions (
+		server_name TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		input_schema TEXT NOT NULL DEFAULT '',
+		tokens INTEGER DEFAULT 0,
+		host TEXT NOT NULL DEFAULT '',
+		captured_at DATETIME NOT NULL,
+		PRIMARY KEY (server_name, tool_name, host)
+	);
+`
+
+// proxyCallsSchema stores the messages the MCP proxy sees between Claude Code
+// and a server, keyed by the server's name.
+const proxyCallsSchema = `
+	CREATE TABLE IF NOT EXISTS proxy_calls (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		server_name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		direction TEXT NOT NULL DEFAULT '',
+		method TEXT NOT NULL DEFAULT '',
+		tool_name TEXT NOT NULL DEFAULT '',
+		duration_us INTEGER DEFAULT 0,
+		request_bytes INTEGER DEFAULT 0,
+		response_bytes INTEGER DEFAULT 0,
+		error_code INTEGER DEFAULT 0,
+		tool_error INTEGER DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		host TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_proxy_calls_server ON proxy_calls(server_name, created_at);
+	CREATE INDEX IF NOT EXISTS idx_proxy_calls_created ON proxy_calls(created_at);
+`
+
+// proxyFailureColumns records why a proxied request failed, so upstream HTTP
+// statuses and connection failures are told apart from JSON-RPC errors.
+const proxyFailureColumns = `
+	ALTER TABLE proxy_calls ADD COLUMN http_status INTEGER DEFAULT 0;
+	ALTER TABLE proxy_calls ADD COLUMN failure TEXT NOT NULL DEFAULT '';
+`
+
+const responseSizeSchema = `
+	ALTER TABLE events ADD COLUMN response_bytes INTEGER DEFAULT 0;
+	ALTER TABLE events ADD COLUMN response_tokens INTEGER DEFAULT 0;
+
+	-- Log-bucketed histograms of estimated response tokens per (date, tool,
+	-- server, host), with the byte and token totals of each bucket
+	CREATE TABLE IF NOT EXISTS tool_response_sizes (
+		date TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		host TEXT NOT NULL DEFAULT '',
+		bucket INTEGER NOT NULL,
+		count INTEGER DEFAULT 0,
+		total_bytes INTEGER DEFAULT 0,
+		total_tokens INTEGER DEFAULT 0,
+		PRIMARY KEY (date, tool_name, server_name, host, bucket)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_response_sizes_date ON tool_response_sizes(date);
+`
+
+// usageSchema records the token usage of each model response read from
+// session transcripts, and the spend attributed to each tool call whose
+// result a response read. Rows are keyed by the API message and tool_use
+// IDs, so reading a transcript again replaces them.
+const usageSchema = `
+	CREATE TABLE IF NOT EXISTS turn_usage (
+		message_id TEXT NOT NULL,
+		host TEXT NOT NULL DEFAULT '',
+		session_id TEXT NOT NULL,
+		model TEXT NOT NULL DEFAULT '',
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
+		cache_creation_tokens INTEGER DEFAULT 0,
+		cache_read_tokens INTEGER DEFAULT 0,
+		cost_usd REAL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (message_id, host)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_turn_usage_created ON turn_usage(created_at);
+	CREATE INDEX IF NOT EXISTS idx_turn_usage_session ON turn_usage(session_id);
+
+	CREATE TABLE IF NOT EXISTS tool_call_costs (
+		tool_use_id TEXT NOT NULL,
+		host TEXT NOT NULL DEFAULT '',
+		session_id TEXT NOT NULL,
+		tool_name TEXT NOT NULL,
+		server_name TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		result_bytes INTEGER DEFAULT 0,
+		input_tokens INTEGER DEFAULT 0,
+		input_cost_usd REAL DEFAULT 0,
+		followup_cost_usd REAL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (tool_use_id, host)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tool_call_costs_created ON tool_call_costs(created_at);
+`
+
+// alertsSchema records alert incidents: one row per condition from when it
+// fired until it resolved, with when it was last notified. Alerts describe
+// this database as a whole, so rows are not tagged with a host and are not
+// merged.
+const alertsSchema = `
+	CREATE TABLE IF NOT EXISTS alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		alert_key TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL DEFAULT '',
+		severity TEXT NOT NULL,
+		message TEXT NOT NULL DEFAULT '',
+		value REAL DEFAULT 0,
+		threshold REAL DEFAULT 0,
+		fired_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		notified_at DATETIME,
+		notifications INTEGER DEFAULT 0,
+		resolved_at DATETIME
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_alerts_active ON alerts(resolved_at, alert_key);
+	CREATE INDEX IF NOT EXISTS idx_alerts_fired ON alerts(fired_at);
+`
+
+// alertRulesSchema records which rule raised an alert, and the state of
+// rule conditions between evaluations: since when a condition has held
+// without firing yet, and since when a firing one has stopped holding.
+const alertRulesSchema = `
+	ALTER TABLE alerts ADD COLUMN rule TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS alert_rule_states (
+		alert_key TEXT PRIMARY KEY,
+		rule TEXT NOT NULL,
+		holding_since DATETIME,
+		clearing_since DATETIME
+	);
+`
+
+// addHostColumns records this database's host in sync_state and tags every
+// existing row with it, so rows merged from other machines stay apart. The
+// aggregate tables are rebuilt because host joins their primary keys.
+func addHostColumns(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR IGNORE INTO sync_state (key, value) VALUES ('host', ?)", DefaultHost()); err != nil {
+		return err
+	}
+	var host string
+	if err := tx.QueryRowContext(ctx, "SELECT value FROM sync_state WHERE key = 'host'").Scan(&host); err != nil {
+		return err
+	}
+
+	statements := []string{
+		// Re-index only when searchable columns change, so tagging rows
+		// below does not rewrite the full-text index
+		"DROP TRIGGER IF EXISTS events_fts_update",
+		`CREATE TRIGGER events_fts_update AFTER UPDATE OF tool_input, error, cwd ON events BEGIN
+			INSERT INTO events_fts(events_fts, rowid, tool_input, error, cwd)
+			VALUES ('delete', old.id, old.tool_input, old.error, old.cwd);
+			INSERT INTO events_fts(rowid, tool_input, error, cwd)
+			VALUES (new.id, new.tool_input, new.error, new.cwd);
+		END`,
+
+		"ALTER TABLE events ADD COLUMN host TEXT NOT NULL DEFAULT ''",
+		"UPDATE events SET host = ?1",
+		"CREATE INDEX IF NOT EXISTS idx_events_host ON events(host)",
+		// Identity used to deduplicate merged events
+		"CREATE INDEX IF NOT EXISTS idx_events_identity ON events(session_id, created_at)",
+
+		"ALTER TABLE sessions ADD COLUMN host TEXT NOT NULL DEFAULT ''",
+		"UPDATE sessions SET host = ?1",
+		"CREATE INDEX IF NOT EXISTS idx_sessions_host ON sessions(host)",
+	}
+
+	for _, table := range []string{"tool_stats", "call_rollups_minute", "call_rollups_hour"} {
+		bucket := "bucket"
+		if table == "tool_stats" {
+			bucket = "date"
+		}
+		statements = append(statements,
+			fmt.Sprintf(`CREATE TABLE %[1]s_new (
+				%[2]s TEXT NOT NULL,
+				tool_name TEXT NOT NULL,
+				server_name TEXT NOT NULL DEFAULT '',
+				host TEXT NOT NULL DEFAULT '',
+				call_count INTEGER DEFAULT 0,
+				error_count INTEGER DEFAULT 0,
+				total_latency_ms INTEGER DEFAULT 0,
+				last_seen_at TEXT,
+				PRIMARY KEY (%[2]s, tool_name, server_name, host)
+			)`, table, bucket),
+			fmt.Sprintf(`INSERT INTO %[1]s_new (%[2]s, tool_name, server_name, host, call_count, error_count, total_latency_ms, last_seen_at)
+				SELECT %[2]s, tool_name, server_name, ?1, call_count, error_count, total_latency_ms, last_seen_at FROM %[1]s`, table, bucket),
+			fmt.Sprintf("DROP TABLE %s", table),
+			fmt.Sprintf("ALTER TABLE %[1]s_new RENAME TO %[1]s", table),
+		)
+	}
+
+	statements = append(statements,
+		"CREATE INDEX IF NOT EXISTS idx_tool_stats_date ON tool_stats(date)",
+		"CREATE INDEX IF NOT EXISTS idx_tool_stats_server ON tool_stats(server_name)",
+
+		`CREATE TABLE tool_latency_histograms_new (
+			date TEXT NOT NULL,
+			tool_name TEXT NOT NULL,
+			server_name TEXT NOT NULL DEFAULT '',
+			host TEXT NOT NULL DEFAULT '',
+			bucket INTEGER NOT NULL,
+			count INTEGER DEFAULT 0,
+			PRIMARY KEY (date, tool_name, server_name, host, bucket)
+		)`,
+		`INSERT INTO tool_latency_histograms_new (date, tool_name, server_name, host, bucket, count)
+			SELECT date, tool_name, server_name, ?1, bucket, count FROM tool_latency_histograms`,
+		"DROP TABLE tool_latency_histograms",
+		"ALTER TABLE tool_latency_histograms_new RENAME TO tool_latency_histograms",
+		"CREATE INDEX IF NOT EXISTS idx_latency_histograms_date ON tool_latency_histograms(date)",
+	)
+
+	for _, stmt := range statements {
+		var args []interface{}
+		if strings.Contains(stmt, "?1") {
+			args = append(args, host)
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultHost returns the host name new databases are tagged with.
+func DefaultHost() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "localhost"
+	}
+	return host
+}
+
+// addErrorSignatures adds the normalized error signature of failed tool calls
+// and computes it for the calls already stored.
+func addErrorSignatures(ctx context.Context, tx *sql.Tx) error {
+	for _, stmt := range []string{
+		"ALTER TABLE events ADD COLUMN error_signature TEXT NOT NULL DEFAULT ''",
+		"CREATE INDEX IF NOT EXISTS idx_events_error_signature ON events(mcp_server, error_signature)",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, error FROM events WHERE event_type = 'PostToolUse' AND success = 0")
+	if err != nil {
+		return err
+	}
+	signatures := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var message string
+		if err := rows.Scan(&id, &message); err != nil {
+			rows.Close()
+			return err
+		}
+		signatures[id] = errsig.Signature(message)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, signature := range signatures {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE events SET error_signature = ? WHERE id = ?", signature, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addHourlyLatencyHistograms adds latency histograms bucketed by local hour,
+// so percentiles cover the same range as the hourly rollups. Raw events
+// still kept are replayed into it; older days only have daily histograms.
+func addHourlyLatencyHistograms(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS tool_latency_histograms_hour (
+			hour TEXT NOT NULL,
+			tool_name TEXT NOT NULL,
+			server_name TEXT NOT NULL DEFAULT '',
+			host TEXT NOT NULL DEFAULT '',
+			bucket INTEGER NOT NULL,
+			count INTEGER DEFAULT 0,
+			PRIMARY KEY (hour, tool_name, server_name, host, bucket)
+		)`); err != nil {
+		return err
+	}
+
+	// created_at is stored as "YYYY-MM-DD HH:MM:SS... <zone>" in local time
+	rows, err := tx.QueryContext(ctx, `
+		SELECT substr(created_at, 1, 13) || ':00', tool_name, COALESCE(mcp_server, ''), host, duration_ms
+		FROM events
+		WHERE event_type = 'PostToolUse' AND tool_name != '' AND duration_ms > 0`)
+	if err != nil {
+		return err
+	}
+	type key struct {
+		hour, tool, server, host string
+		bucket                   int
+	}
+	var order []key
+	counts := make(map[key]int64)
+	for rows.Next() {
+		var k key
+		var ms int64
+		if err := rows.Scan(&k.hour, &k.tool, &k.server, &k.host, &ms); err != nil {
+			rows.Close()
+			return err
+		}
+		k.bucket = LatencyBucket(ms)
+		if _, ok := counts[k]; !ok {
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, k := range order {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tool_latency_histograms_hour (hour, tool_name, server_name, host, bucket, count)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			k.hour, k.tool, k.server, k.host, k.bucket, counts[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	version, err := store.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("failed to get schema version: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("expected version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	report := store.MigrationReport()
+	if report.FromVersion != 0 {
+		t.Errorf("expected fresh database to start at version 0, got %d", report.FromVersion)
+	}
+	if len(report.Applied) != len(migrations) {
+		t.Errorf("expected %d migrations applied, got %d", len(migrations), len(report.Applied))
+	}
+	if report.BackupPath != "" {
+		t.Errorf("expected no backup for a fresh database, got %s", report.BackupPath)
+	}
+}
+
+func TestMigrate_ReopenIsNoop(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	store.Close()
+
+	store, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	if applied := store.MigrationReport().Applied; len(applied) != 0 {
+		t.Errorf("expected no migrations on reopen, got %d", len(applied))
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	createLegacyDatabase(t, dbPath)
+
+	// Status before opening shows only the baseline applied
+	states, err := ReadMigrationStatus(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("failed to read migration status: %v", err)
+	}
+	for _, st := range states {
+		if st.Applied != (st.Version == 2) {
+			t.Errorf("version %d: expected applied=%v, got %v", st.Version, st.Version == 2, st.Applied)
+		}
+	}
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open legacy store: %v", err)
+	}
+	defer store.Close()
+
+	report := store.MigrationReport()
+	if report.FromVersion != 2 || report.ToVersion != LatestSchemaVersion() {
+		t.Errorf("expected migration 2 -> %d, got %d -> %d", LatestSchemaVersion(), report.FromVersion, report.ToVersion)
+	}
+	if report.BackupPath == "" {
+		t.Fatal("expected a pre-migration backup")
+	}
+	if _, err := os.Stat(report.BackupPath); err != nil {
+		t.Errorf("backup file missing: %v", err)
+	}
+
+	// Legacy aggregate tables are retired
+	var tables int
+	if err := store.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('daily_stats', 'mcp_servers')").Scan(&tables); err != nil {
+		t.Fatalf("failed to query tables: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("expected daily_stats and mcp_servers to be dropped, found %d", tables)
+	}
+
+	// Raw events are backfilled into the hourly rollups
+	var bucket string
+	if err := store.db.QueryRowContext(ctx,
+		"SELECT bucket FROM call_rollups_hour WHERE tool_name = 'search'").Scan(&bucket); err != nil {
+		t.Fatalf("expected backfilled hourly rollup: %v", err)
+	}
+	if bucket != "2026-01-10 10:00" {
+		t.Errorf("expected bucket 2026-01-10 10:00, got %s", bucket)
+	}
+
+	// Existing events are indexed under their session's cwd
+	results, err := store.SearchEvents(ctx, SearchQuery{Query: "legacy-project"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Cwd != "/home/user/legacy-project" {
+		t.Errorf("expected the legacy event to be searchable by cwd, got %+v", results)
+	}
+
+	// Existing aggregates survive the tool_stats rebuild
+	stats, err := store.GetMCPServerStatsAggregated(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 3 {
+		t.Fatalf("expected legacy github stats with 3 calls, got %+v", stats)
+	}
+
+	// The same tool name from another server is now a separate row
+	if err := store.UpsertToolStats(ctx, "2026-01-10", "search", "gitlab", 1, 0, 10); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	if err := store.UpsertToolStats(ctx, "2026-01-10", "search", "github", 1, 0, 10); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	stats, err = store.GetMCPServerStatsAggregated(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("expected github and gitlab stats, got %+v", stats)
+	}
+
+	states, err = ReadMigrationStatus(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("failed to read migration status: %v", err)
+	}
+	for _, st := range states {
+		if !st.Applied {
+			t.Errorf("expected version %d to be applied", st.Version)
+		}
+	}
+}
+
+func TestReadMigrationStatus_MissingDatabase(t *testing.T) {
+	states, err := ReadMigrationStatus(context.Background(), filepath.Join(t.TempDir(), "none.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(states) != len(migrations) {
+		t.Fatalf("expected %d states, got %d", len(migrations), len(states))
+	}
+	for _, st := range states {
+		if st.Applied {
+			t.Errorf("expected version %d to be pending", st.Version)
+		}
+	}
+}
+
+func TestMigrate_ConcurrentOpens(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	createLegacyDatabase(t, dbPath)
+
+	// Old backups beyond the kept count are pruned
+	for _, stamp := range []string{"20260101-000000", "20260102-000000", "20260103-000000"} {
+		if err := os.WriteFile(dbPath+".pre-v5-"+stamp+".bak", nil, 0644); err != nil {
+			t.Fatalf("failed to write old backup: %v", err)
+		}
+	}
+
+	// Proxies started together all open the database at once
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store, err := NewSQLiteStore(dbPath)
+			if err != nil {
+				errs <- err
+				return
+			}
+			store.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent open failed: %v", err)
+	}
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+	if version, err := store.SchemaVersion(context.Background()); err != nil || version != LatestSchemaVersion() {
+		t.Errorf("expected version %d, got %d (%v)", LatestSchemaVersion(), version, err)
+	}
+
+	backups, _ := filepath.Glob(dbPath + ".pre-v*.bak")
+	if len(backups) != migrationBackupsKept {
+		t.Errorf("expected %d backups kept, got %v", migrationBackupsKept, backups)
+	}
+	for _, b := range backups {
+		if strings.Contains(b, "20260101") {
+			t.Errorf("expected the oldest backup pruned, got %v", backups)
+		}
+	}
+}
+
+// createLegacyDatabase writes a database as created before the migration
+// framework: the baseline schema with version 2 recorded.
+func createLegacyDatabase(t *testing.T, dbPath string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open legacy db: %v", err)
+	}
+	defer db.Close()
+
+	legacy := baselineSchema + `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT OR IGNORE INTO schema_version (version) VALUES (2);
+	INSERT INTO tool_stats (date, tool_name, server_name, call_count, error_count, total_latency_ms)
+	VALUES ('2026-01-10', 'search', 'github', 3, 1, 300);
+	INSERT INTO events (session_id, event_type, tool_name, mcp_server, success, duration_ms, created_at)
+	VALUES ('s1', 'PostToolUse', 'search', 'github', 1, 100, '2026-01-10 10:15:00.5 +0000 UTC');
+	INSERT INTO sessions (id, cwd, started_at)
+	VALUES ('s1', '/home/user/legacy-project', '2026-01-10 10:00:00 +0000 UTC');
+	`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+}
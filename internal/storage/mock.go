@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,6 +14,13 @@ type MockStore struct {
 	events       []Event
 	sessions     map[string]*Session
 	fingerprints map[string]time.Time
+	probes       []ProbeResult
+	toolDefs     []ToolDefinition
+	proxyCalls   []ProxyCall
+	turns        []TurnUsage
+	callCosts    []ToolCallCost
+	alerts       []Alert
+	ruleStates   map[string]AlertRuleState
 	nextID       int64
 }
 
@@ -43,6 +52,7 @@ func (m *MockStore) StoreEvent(ctx context.Context, event *Event) error {
 	if _, exists := m.sessions[event.SessionID]; !exists {
 		m.sessions[event.SessionID] = &Session{
 			ID:           event.SessionID,
+			Host:         event.Host,
 			StartedAt:    event.CreatedAt,
 			TotalEvents:  0,
 			TotalTokens:  0,
@@ -78,6 +88,127 @@ func (m *MockStore) GetEvents(ctx context.Context, filter EventFilter) ([]Event,
 	return result, nil
 }
 
+// SearchEvents matches every search term as a case-insensitive substring of
+// the event's tool input, error, or cwd.
+func (m *MockStore) SearchEvents(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := splitTerms(strings.ToLower(q.Query))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var result []SearchResult
+	for i := len(m.events) - 1; i >= 0; i-- {
+		e := m.events[i]
+		if !m.matchesFilter(e, q.EventFilter) {
+			continue
+		}
+		text := strings.ToLower(e.ToolInput + "\n" + e.Error + "\n" + e.Cwd)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, strings.TrimRight(term, "*")) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result = append(result, SearchResult{Event: e})
+		}
+	}
+
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+// GetErrorClusters groups events with an error signature by server and
+// signature, largest first.
+func (m *MockStore) GetErrorClusters(ctx context.Context, filter ErrorClusterFilter) ([]ErrorCluster, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	examples := filter.Examples
+	if examples <= 0 {
+		examples = 3
+	}
+
+	index := make(map[string]int)
+	var result []ErrorCluster
+	for i := len(m.events) - 1; i >= 0; i-- {
+		e := m.events[i]
+		if e.ErrorSignature == "" || !m.matchesFilter(e, EventFilter{TimeFilter: filter.TimeFilter}) {
+			continue
+		}
+		if filter.MCPServer != "" && e.MCPServer != filter.MCPServer {
+			continue
+		}
+
+		key := e.MCPServer + "|" + e.ErrorSignature
+		j, ok := index[key]
+		if !ok {
+			j = len(result)
+			index[key] = j
+			result = append(result, ErrorCluster{
+				MCPServer: e.MCPServer,
+				Signature: e.ErrorSignature,
+				FirstSeen: e.CreatedAt,
+				LastSeen:  e.CreatedAt,
+			})
+		}
+		c := &result[j]
+		c.Count++
+		if e.CreatedAt.Before(c.FirstSeen) {
+			c.FirstSeen = e.CreatedAt
+		}
+		if e.CreatedAt.After(c.LastSeen) {
+			c.LastSeen = e.CreatedAt
+		}
+		if len(c.Examples) < examples {
+			c.Examples = append(c.Examples, e)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// GetErrorMessages counts events with an error signature by server and
+// error message.
+func (m *MockStore) GetErrorMessages(ctx context.Context, filter TimeFilter) ([]ErrorMessageCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	index := make(map[string]int)
+	var result []ErrorMessageCount
+	for _, e := range m.events {
+		if e.ErrorSignature == "" || !m.matchesFilter(e, EventFilter{TimeFilter: filter}) {
+			continue
+		}
+		key := e.MCPServer + "|" + e.Error
+		j, ok := index[key]
+		if !ok {
+			j = len(result)
+			index[key] = j
+			result = append(result, ErrorMessageCount{MCPServer: e.MCPServer, Error: e.Error})
+		}
+		result[j].Count++
+	}
+	return result, nil
+}
+
 func (m *MockStore) matchesFilter(e Event, f EventFilter) bool {
 	if f.SessionID != "" && e.SessionID != f.SessionID {
 		return false
@@ -130,6 +261,10 @@ func (m *MockStore) matchesFilter(e Event, f EventFilter) bool {
 		return false
 	}
 
+	if f.Host != "" && e.Host != f.Host {
+		return false
+	}
+
 	return true
 }
 
@@ -173,6 +308,9 @@ func (m *MockStore) matchesSessionFilter(s Session, f SessionFilter) bool {
 	if !f.To.IsZero() && s.StartedAt.After(f.To) {
 		return false
 	}
+	if f.Host != "" && s.Host != f.Host {
+		return false
+	}
 	return true
 }
 
@@ -182,6 +320,7 @@ func (m *MockStore) GetMCPServerStats(ctx context.Context, filter TimeFilter) ([
 	defer m.mu.RUnlock()
 
 	statsMap := make(map[string]*MCPServerStats)
+	histograms := make(map[string]*LatencyHistogram)
 
 	for _, e := range m.events {
 		if e.MCPServer == "" {
@@ -195,6 +334,9 @@ func (m *MockStore) GetMCPServerStats(ctx context.Context, filter TimeFilter) ([
 		if !filter.To.IsZero() && e.CreatedAt.After(filter.To) {
 			continue
 		}
+		if filter.Host != "" && e.Host != filter.Host {
+			continue
+		}
 
 		if _, exists := statsMap[e.MCPServer]; !exists {
 			statsMap[e.MCPServer] = &MCPServerStats{
@@ -211,10 +353,16 @@ func (m *MockStore) GetMCPServerStats(ctx context.Context, filter TimeFilter) ([
 		}
 		stats.AvgLatencyMs = (stats.AvgLatencyMs*float64(stats.TotalCalls-1) + float64(e.DurationMs)) / float64(stats.TotalCalls)
 		stats.LastUsedAt = e.CreatedAt
+		addMockLatency(histograms, e.MCPServer, e)
 	}
 
 	var result []MCPServerStats
-	for _, stats := range statsMap {
+	for name, stats := range statsMap {
+		if h, ok := histograms[name]; ok {
+			stats.P50LatencyMs = h.Percentile(0.50)
+			stats.P90LatencyMs = h.Percentile(0.90)
+			stats.P99LatencyMs = h.Percentile(0.99)
+		}
 		result = append(result, *stats)
 	}
 
@@ -227,6 +375,7 @@ func (m *MockStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]Tool
 	defer m.mu.RUnlock()
 
 	statsMap := make(map[string]*ToolStats)
+	histograms := make(map[string]*LatencyHistogram)
 
 	for _, e := range m.events {
 		if e.ToolName == "" {
@@ -240,6 +389,9 @@ func (m *MockStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]Tool
 		if !filter.To.IsZero() && e.CreatedAt.After(filter.To) {
 			continue
 		}
+		if filter.Host != "" && e.Host != filter.Host {
+			continue
+		}
 
 		key := e.ToolName + ":" + e.MCPServer
 		if _, exists := statsMap[key]; !exists {
@@ -257,29 +409,107 @@ func (m *MockStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]Tool
 			stats.ErrorCount++
 		}
 		stats.AvgLatencyMs = (stats.AvgLatencyMs*float64(stats.TotalCalls-1) + float64(e.DurationMs)) / float64(stats.TotalCalls)
+		addMockLatency(histograms, key, e)
 	}
 
 	var result []ToolStats
-	for _, stats := range statsMap {
+	for key, stats := range statsMap {
+		if h, ok := histograms[key]; ok {
+			stats.P50LatencyMs = h.Percentile(0.50)
+			stats.P90LatencyMs = h.Percentile(0.90)
+			stats.P99LatencyMs = h.Percentile(0.99)
+		}
 		result = append(result, *stats)
 	}
+	applyResponseSizes(m.responseSizes(filter), result)
 
 	return result, nil
 }
 
+// addMockLatency records an event's latency in the histogram for key.
+// Events without a known duration are not part of the distribution.
+// GetToolLatencies builds latency histograms per tool from stored events.
+func (m *MockStore) GetToolLatencies(ctx context.Context, filter TimeFilter) ([]ToolLatency, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	histograms := make(map[string]*LatencyHistogram)
+	var result []ToolLatency
+	for _, e := range m.events {
+		if e.ToolName == "" || !m.matchesFilter(e, EventFilter{TimeFilter: filter}) {
+			continue
+		}
+		key := e.ToolName + ":" + e.MCPServer
+		if _, ok := histograms[key]; !ok && e.DurationMs > 0 {
+			result = append(result, ToolLatency{ToolName: e.ToolName, ServerName: e.MCPServer})
+		}
+		addMockLatency(histograms, key, e)
+	}
+
+	for i := range result {
+		result[i].Histogram = histograms[result[i].ToolName+":"+result[i].ServerName]
+	}
+	return result, nil
+}
+
+// GetToolResponseSizes builds response size histograms per tool from stored
+// events.
+func (m *MockStore) GetToolResponseSizes(ctx context.Context, filter TimeFilter) ([]ToolResponseSize, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.responseSizes(filter), nil
+}
+
+// responseSizes summarizes the sizes of stored tool responses. Callers hold
+// the lock.
+func (m *MockStore) responseSizes(filter TimeFilter) []ToolResponseSize {
+	index := make(map[string]int)
+	var result []ToolResponseSize
+	for _, e := range m.events {
+		if e.ToolName == "" || e.ResponseBytes <= 0 || !m.matchesFilter(e, EventFilter{TimeFilter: filter}) {
+			continue
+		}
+		key := e.ToolName + ":" + e.MCPServer
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, ToolResponseSize{ToolName: e.ToolName, ServerName: e.MCPServer, Histogram: NewLatencyHistogram()})
+		}
+		result[i].Responses++
+		result[i].TotalBytes += e.ResponseBytes
+		result[i].TotalTokens += e.ResponseTokens
+		result[i].Histogram.Add(e.ResponseTokens)
+	}
+	sortResponseSizes(result)
+	return result
+}
+
+func addMockLatency(histograms map[string]*LatencyHistogram, key string, e Event) {
+	if e.DurationMs <= 0 {
+		return
+	}
+	h, ok := histograms[key]
+	if !ok {
+		h = NewLatencyHistogram()
+		histograms[key] = h
+	}
+	h.Add(e.DurationMs)
+}
+
 // GetCostSummary retrieves aggregated cost metrics.
 func (m *MockStore) GetCostSummary(ctx context.Context, filter TimeFilter) (*CostSummary, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	return m.costSummary(filter), nil
+}
 
+// costSummary sums event and transcript usage. Callers hold the lock.
+func (m *MockStore) costSummary(filter TimeFilter) *CostSummary {
 	summary := &CostSummary{}
 
 	for _, e := range m.events {
-		// Apply time filter
-		if !filter.From.IsZero() && e.CreatedAt.Before(filter.From) {
-			continue
-		}
-		if !filter.To.IsZero() && e.CreatedAt.After(filter.To) {
+		if !inTimeFilter(e.CreatedAt, e.Host, filter) {
 			continue
 		}
 
@@ -288,13 +518,204 @@ func (m *MockStore) GetCostSummary(ctx context.Context, filter TimeFilter) (*Cos
 		summary.TotalCostUSD += e.CostUSD
 	}
 
-	summary.TotalTokens = summary.InputTokens + summary.OutputTokens
-	return summary, nil
+	for _, t := range m.turns {
+		if !inTimeFilter(t.CreatedAt, t.Host, filter) {
+			continue
+		}
+		summary.InputTokens += t.InputTokens
+		summary.OutputTokens += t.OutputTokens
+		summary.CacheCreationTokens += t.CacheCreationTokens
+		summary.CacheReadTokens += t.CacheReadTokens
+		summary.TotalCostUSD += t.CostUSD
+	}
+
+	summary.TotalTokens = summary.InputTokens + summary.OutputTokens +
+		summary.CacheCreationTokens + summary.CacheReadTokens
+	return summary
 }
 
-// GetCostByModel retrieves cost breakdown by model.
+// GetDailyCosts returns event and transcript spend by local day.
+func (m *MockStore) GetDailyCosts(ctx context.Context, filter TimeFilter) ([]DailyCost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byDay := make(map[time.Time]float64)
+	add := func(t time.Time, host string, cost float64) {
+		if !inTimeFilter(t, host, filter) {
+			return
+		}
+		local := t.Local()
+		byDay[time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)] += cost
+	}
+	for _, e := range m.events {
+		add(e.CreatedAt, e.Host, e.CostUSD)
+	}
+	for _, t := range m.turns {
+		add(t.CreatedAt, t.Host, t.CostUSD)
+	}
+
+	costs := []DailyCost{}
+	for day, cost := range byDay {
+		if cost > 0 {
+			costs = append(costs, DailyCost{Date: day, CostUSD: cost})
+		}
+	}
+	sort.Slice(costs, func(i, j int) bool { return costs[i].Date.Before(costs[j].Date) })
+	return costs, nil
+}
+
+// GetCostByModel returns transcript usage grouped by model.
 func (m *MockStore) GetCostByModel(ctx context.Context, filter TimeFilter) ([]ModelCost, error) {
-	return []ModelCost{}, nil
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byModel := make(map[string]*ModelCost)
+	costs := []ModelCost{}
+	for _, t := range m.turns {
+		if !inTimeFilter(t.CreatedAt, t.Host, filter) {
+			continue
+		}
+		c, ok := byModel[t.Model]
+		if !ok {
+			c = &ModelCost{Model: t.Model}
+			byModel[t.Model] = c
+		}
+		c.InputTokens += t.InputTokens
+		c.OutputTokens += t.OutputTokens
+		c.CacheCreationTokens += t.CacheCreationTokens
+		c.CacheReadTokens += t.CacheReadTokens
+		c.TotalCostUSD += t.CostUSD
+	}
+	for _, c := range byModel {
+		costs = append(costs, *c)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].TotalCostUSD != costs[j].TotalCostUSD {
+			return costs[i].TotalCostUSD > costs[j].TotalCostUSD
+		}
+		return costs[i].Model < costs[j].Model
+	})
+	return costs, nil
+}
+
+// StoreUsage records transcript usage in memory, replacing rows with the
+// same message or tool_use ID.
+func (m *MockStore) StoreUsage(ctx context.Context, turns []TurnUsage, calls []ToolCallCost) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range turns {
+		replaced := false
+		for i := range m.turns {
+			if m.turns[i].MessageID == t.MessageID && m.turns[i].Host == t.Host {
+				m.turns[i], replaced = t, true
+			}
+		}
+		if !replaced {
+			m.turns = append(m.turns, t)
+		}
+	}
+	for _, c := range calls {
+		replaced := false
+		for i := range m.callCosts {
+			if m.callCosts[i].ToolUseID == c.ToolUseID && m.callCosts[i].Host == c.Host {
+				m.callCosts[i], replaced = c, true
+			}
+		}
+		if !replaced {
+			m.callCosts = append(m.callCosts, c)
+		}
+	}
+	return nil
+}
+
+// GetCostByServer returns attributed spend grouped by MCP server.
+func (m *MockStore) GetCostByServer(ctx context.Context, filter TimeFilter) ([]ServerCost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := m.costSummary(filter).TotalCostUSD
+	byServer := make(map[string]*ServerCost)
+	for _, c := range m.callCosts {
+		if c.ServerName == "" || !inTimeFilter(c.CreatedAt, c.Host, filter) {
+			continue
+		}
+		sc, ok := byServer[c.ServerName]
+		if !ok {
+			sc = &ServerCost{ServerName: c.ServerName}
+			byServer[c.ServerName] = sc
+		}
+		sc.Calls++
+		sc.InputTokens += c.InputTokens
+		sc.InputCostUSD += c.InputCostUSD
+		sc.FollowUpCostUSD += c.FollowUpCostUSD
+	}
+
+	costs := make([]ServerCost, 0, len(byServer))
+	for _, sc := range byServer {
+		sc.TotalCostUSD = sc.InputCostUSD + sc.FollowUpCostUSD
+		sc.SharePct = sharePct(sc.TotalCostUSD, total)
+		costs = append(costs, *sc)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].TotalCostUSD != costs[j].TotalCostUSD {
+			return costs[i].TotalCostUSD > costs[j].TotalCostUSD
+		}
+		return costs[i].ServerName < costs[j].ServerName
+	})
+	return costs, nil
+}
+
+// GetCostByTool returns attributed spend grouped by tool.
+func (m *MockStore) GetCostByTool(ctx context.Context, filter TimeFilter) ([]ToolCost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := m.costSummary(filter).TotalCostUSD
+	byTool := make(map[string]*ToolCost)
+	for _, c := range m.callCosts {
+		if !inTimeFilter(c.CreatedAt, c.Host, filter) {
+			continue
+		}
+		key := c.ToolName + "|" + c.ServerName
+		tc, ok := byTool[key]
+		if !ok {
+			tc = &ToolCost{ToolName: c.ToolName, ServerName: c.ServerName}
+			byTool[key] = tc
+		}
+		tc.Calls++
+		tc.InputTokens += c.InputTokens
+		tc.InputCostUSD += c.InputCostUSD
+		tc.FollowUpCostUSD += c.FollowUpCostUSD
+	}
+
+	costs := make([]ToolCost, 0, len(byTool))
+	for _, tc := range byTool {
+		tc.TotalCostUSD = tc.InputCostUSD + tc.FollowUpCostUSD
+		tc.SharePct = sharePct(tc.TotalCostUSD, total)
+		costs = append(costs, *tc)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].TotalCostUSD != costs[j].TotalCostUSD {
+			return costs[i].TotalCostUSD > costs[j].TotalCostUSD
+		}
+		if costs[i].ToolName != costs[j].ToolName {
+			return costs[i].ToolName < costs[j].ToolName
+		}
+		return costs[i].ServerName < costs[j].ServerName
+	})
+	return costs, nil
+}
+
+// inTimeFilter reports whether a row recorded at t on host is within filter.
+func inTimeFilter(t time.Time, host string, filter TimeFilter) bool {
+	if !filter.From.IsZero() && t.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && t.After(filter.To) {
+		return false
+	}
+	return filter.Host == "" || host == filter.Host
 }
 
 // Cleanup removes events older than the specified time.
@@ -362,6 +783,9 @@ func (m *MockStore) GetCallVolumeByHour(ctx context.Context, filter TimeFilter)
 		if !filter.To.IsZero() && e.CreatedAt.After(filter.To) {
 			continue
 		}
+		if filter.Host != "" && e.Host != filter.Host {
+			continue
+		}
 
 		hourKey := e.CreatedAt.Truncate(time.Hour).Format(time.RFC3339)
 		if _, exists := hourlyMap[hourKey]; !exists {
@@ -384,6 +808,316 @@ func (m *MockStore) GetCallVolumeByHour(ctx context.Context, filter TimeFilter)
 	return result, nil
 }
 
+// GetHourlyRollups groups PostToolUse events by local hour, tool, and server.
+func (m *MockStore) GetHourlyRollups(ctx context.Context, filter TimeFilter) ([]HourlyRollup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	index := make(map[string]int)
+	var result []HourlyRollup
+	for _, e := range m.events {
+		if e.EventType != "PostToolUse" || e.ToolName == "" || !m.matchesFilter(e, EventFilter{TimeFilter: filter}) {
+			continue
+		}
+
+		local := e.CreatedAt.Local()
+		hour := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, time.Local)
+		key := hour.Format(time.RFC3339) + "|" + e.ToolName + "|" + e.MCPServer
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, HourlyRollup{Hour: hour, ToolName: e.ToolName, MCPServer: e.MCPServer})
+		}
+		result[i].Calls++
+		if !e.Success {
+			result[i].Errors++
+		}
+		result[i].TotalLatencyMs += e.DurationMs
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Hour.Before(result[j].Hour)
+	})
+	return result, nil
+}
+
+// StoreProbeResult stores a probe result in memory.
+func (m *MockStore) StoreProbeResult(ctx context.Context, result *ProbeResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result.ID = int64(len(m.probes) + 1)
+	m.probes = append(m.probes, *result)
+	return nil
+}
+
+// GetLatestProbes returns the most recent probe of each server on each host.
+func (m *MockStore) GetLatestProbes(ctx context.Context, host string) ([]ProbeResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latest := make(map[string]ProbeResult)
+	for _, p := range m.probes {
+		if host == "" || p.Host == host {
+			latest[p.ServerName+"|"+p.Host] = p
+		}
+	}
+
+	result := make([]ProbeResult, 0, len(latest))
+	for _, p := range latest {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ServerName != result[j].ServerName {
+			return result[i].ServerName < result[j].ServerName
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result, nil
+}
+
+// ReplaceToolDefinitions replaces a server's tool definitions in memory.
+func (m *MockStore) ReplaceToolDefinitions(ctx context.Context, serverName string, tools []ToolDefinition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.toolDefs[:0]
+	for _, t := range m.toolDefs {
+		if t.ServerName != serverName {
+			kept = append(kept, t)
+		}
+	}
+	for _, t := range tools {
+		t.ServerName = serverName
+		kept = append(kept, t)
+	}
+	m.toolDefs = kept
+	return nil
+}
+
+// GetToolDefinitions returns stored tool definitions by server and tool name.
+func (m *MockStore) GetToolDefinitions(ctx context.Context, host string) ([]ToolDefinition, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []ToolDefinition
+	for _, t := range m.toolDefs {
+		if host == "" || t.Host == host {
+			result = append(result, t)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ServerName != result[j].ServerName {
+			return result[i].ServerName < result[j].ServerName
+		}
+		return result[i].ToolName < result[j].ToolName
+	})
+	return result, nil
+}
+
+// StoreProxyCalls stores proxy calls in memory.
+func (m *MockStore) StoreProxyCalls(ctx context.Context, calls []ProxyCall) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range calls {
+		calls[i].ID = int64(len(m.proxyCalls) + 1)
+		m.proxyCalls = append(m.proxyCalls, calls[i])
+	}
+	return nil
+}
+
+// GetProxyStats aggregates stored proxy calls, busiest first.
+func (m *MockStore) GetProxyStats(ctx context.Context, filter TimeFilter) ([]ProxyMethodStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type totals struct {
+		stats                              ProxyMethodStats
+		latencyUs, requestBytes, respBytes int64
+	}
+	index := make(map[[5]string]*totals)
+	var order [][5]string
+	for _, c := range m.proxyCalls {
+		if !filter.From.IsZero() && c.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && c.CreatedAt.After(filter.To) {
+			continue
+		}
+		if filter.Host != "" && c.Host != filter.Host {
+			continue
+		}
+
+		key := [5]string{c.ServerName, c.Kind, c.Direction, c.Method, c.ToolName}
+		t, ok := index[key]
+		if !ok {
+			t = &totals{stats: ProxyMethodStats{ServerName: c.ServerName, Kind: c.Kind,
+				Direction: c.Direction, Method: c.Method, ToolName: c.ToolName}}
+			index[key] = t
+			order = append(order, key)
+		}
+		t.stats.Calls++
+		if !c.OK() {
+			t.stats.Errors++
+		}
+		t.latencyUs += c.DurationUs
+		t.requestBytes += c.RequestBytes
+		t.respBytes += c.ResponseBytes
+		if ms := float64(c.DurationUs) / 1000; ms > t.stats.MaxLatencyMs {
+			t.stats.MaxLatencyMs = ms
+		}
+		if c.ResponseBytes > t.stats.MaxResponseBytes {
+			t.stats.MaxResponseBytes = c.ResponseBytes
+		}
+		if c.CreatedAt.After(t.stats.LastSeenAt) {
+			t.stats.LastSeenAt = c.CreatedAt
+		}
+	}
+
+	result := make([]ProxyMethodStats, 0, len(order))
+	for _, key := range order {
+		t := index[key]
+		n := float64(t.stats.Calls)
+		t.stats.AvgLatencyMs = float64(t.latencyUs) / 1000 / n
+		t.stats.AvgRequestBytes = float64(t.requestBytes) / n
+		t.stats.AvgResponseBytes = float64(t.respBytes) / n
+		result = append(result, t.stats)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Calls > result[j].Calls
+	})
+	return result, nil
+}
+
+// GetProxyFailures counts stored failed proxy requests, most frequent first.
+func (m *MockStore) GetProxyFailures(ctx context.Context, filter TimeFilter) ([]ProxyFailureCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type key struct {
+		server, failure string
+		status          int
+	}
+	index := make(map[key]int)
+	var result []ProxyFailureCount
+	for _, c := range m.proxyCalls {
+		if c.Kind != ProxyRequest || c.Failure == "" {
+			continue
+		}
+		if !filter.From.IsZero() && c.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && c.CreatedAt.After(filter.To) {
+			continue
+		}
+		if filter.Host != "" && c.Host != filter.Host {
+			continue
+		}
+
+		k := key{c.ServerName, c.Failure, c.HTTPStatus}
+		i, ok := index[k]
+		if !ok {
+			i = len(result)
+			index[k] = i
+			result = append(result, ProxyFailureCount{ServerName: c.ServerName, Failure: c.Failure, HTTPStatus: c.HTTPStatus})
+		}
+		result[i].Count++
+		if !c.CreatedAt.Before(result[i].LastSeenAt) {
+			result[i].LastSeenAt = c.CreatedAt
+			result[i].LastError = c.Error
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+	return result, nil
+}
+
+// SaveAlert records or updates an alert.
+func (m *MockStore) SaveAlert(ctx context.Context, alert *Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if alert.ID == 0 {
+		alert.ID = int64(len(m.alerts) + 1)
+		m.alerts = append(m.alerts, *alert)
+		return nil
+	}
+	for i := range m.alerts {
+		if m.alerts[i].ID == alert.ID {
+			m.alerts[i] = *alert
+			return nil
+		}
+	}
+	return nil
+}
+
+// GetActiveAlerts returns the alerts that have not resolved, oldest first.
+func (m *MockStore) GetActiveAlerts(ctx context.Context) ([]Alert, error) {
+	return m.alertsMatching(AlertFilter{ActiveOnly: true}), nil
+}
+
+// GetAlerts returns the alerts fired in the range, newest first.
+func (m *MockStore) GetAlerts(ctx context.Context, filter AlertFilter) ([]Alert, error) {
+	alerts := m.alertsMatching(filter)
+	for i, j := 0, len(alerts)-1; i < j; i, j = i+1, j-1 {
+		alerts[i], alerts[j] = alerts[j], alerts[i]
+	}
+	if filter.Limit > 0 && len(alerts) > filter.Limit {
+		alerts = alerts[:filter.Limit]
+	}
+	return alerts, nil
+}
+
+// GetAlertRuleStates returns the saved state of alert rule conditions.
+func (m *MockStore) GetAlertRuleStates(ctx context.Context) ([]AlertRuleState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	states := make([]AlertRuleState, 0, len(m.ruleStates))
+	for _, st := range m.ruleStates {
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Key < states[j].Key })
+	return states, nil
+}
+
+// SaveAlertRuleState records the state of an alert rule condition,
+// removing it once neither holding nor clearing.
+func (m *MockStore) SaveAlertRuleState(ctx context.Context, state *AlertRuleState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state.HoldingSince.IsZero() && state.ClearingSince.IsZero() {
+		delete(m.ruleStates, state.Key)
+		return nil
+	}
+	if m.ruleStates == nil {
+		m.ruleStates = make(map[string]AlertRuleState)
+	}
+	m.ruleStates[state.Key] = *state
+	return nil
+}
+
+// alertsMatching returns the alerts matching filter, oldest first,
+// ignoring its limit.
+func (m *MockStore) alertsMatching(filter AlertFilter) []Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := []Alert{}
+	for _, a := range m.alerts {
+		if filter.ActiveOnly && !a.Active() {
+			continue
+		}
+		if !inTimeFilter(a.FiredAt, "", TimeFilter{From: filter.From, To: filter.To}) {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts
+}
+
 // EventCount returns the number of stored events (for testing).
 func (m *MockStore) EventCount() int {
 	m.mu.RLock()
@@ -398,6 +1132,13 @@ func (m *MockStore) Reset() {
 	m.events = make([]Event, 0)
 	m.sessions = make(map[string]*Session)
 	m.fingerprints = make(map[string]time.Time)
+	m.probes = nil
+	m.toolDefs = nil
+	m.proxyCalls = nil
+	m.turns = nil
+	m.callCosts = nil
+	m.alerts = nil
+	m.ruleStates = nil
 	m.nextID = 1
 }
 
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// StoreProbeResult records the outcome of probing a server, tagged with this
+// database's host.
+func (s *SQLiteStore) StoreProbeResult(ctx context.Context, result *ProbeResult) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO mcp_probes (server_name, transport, startup_ms, handshake_ms, info_name,
+			info_version, protocol_version, tool_count, error, host, probed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.ServerName, result.Transport, result.StartupMs, result.HandshakeMs, result.InfoName,
+		result.InfoVersion, result.ProtocolVersion, result.ToolCount, result.Error, s.host, result.ProbedAt)
+	if err != nil {
+		return fmt.Errorf("inserting probe result: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("getting probe result id: %w", err)
+	}
+	result.ID = id
+	result.Host = s.host
+	return nil
+}
+
+// GetLatestProbes returns the most recent probe of each server on each host,
+// by server name, or only those from host unless it is empty.
+func (s *SQLiteStore) GetLatestProbes(ctx context.Context, host string) ([]ProbeResult, error) {
+	query := `
+		SELECT id, server_name, transport, startup_ms, handshake_ms, info_name, info_version,
+			protocol_version, tool_count, error, host, probed_at
+		FROM mcp_probes
+		WHERE id IN (SELECT MAX(id) FROM mcp_probes`
+	var args []interface{}
+	if host != "" {
+		query += " WHERE host = ?"
+		args = append(args, host)
+	}
+	query += " GROUP BY server_name, host) ORDER BY server_name, host"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying probe results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ProbeResult
+	for rows.Next() {
+		var p ProbeResult
+		var probedAt string
+		if err := rows.Scan(&p.ID, &p.ServerName, &p.Transport, &p.StartupMs, &p.HandshakeMs, &p.InfoName,
+			&p.InfoVersion, &p.ProtocolVersion, &p.ToolCount, &p.Error, &p.Host, &probedAt); err != nil {
+			return nil, fmt.Errorf("scanning probe result: %w", err)
+		}
+		p.ProbedAt = parseStoredTime(probedAt)
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
+// ReplaceToolDefinitions replaces the tool definitions recorded for a server
+// on this database's host with the tools it listed most recently.
+func (s *SQLiteStore) ReplaceToolDefinitions(ctx context.Context, serverName string, tools []ToolDefinition) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM mcp_tool_definitions WHERE server_name = ? AND host = ?", serverName, s.host); err != nil {
+		return fmt.Errorf("deleting tool definitions: %w", err)
+	}
+	for i := range tools {
+		t := &tools[i]
+		t.ServerName = serverName
+		t.Host = s.host
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO mcp_tool_definitions (server_name, tool_name, description,
+				input_schema, tokens, host, captured_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			serverName, t.ToolName, t.Description, t.InputSchema, t.Tokens, s.host, t.CapturedAt); err != nil {
+			return fmt.Errorf("inserting tool definition: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing tool definitions: %w", err)
+	}
+	return nil
+}
+
+// GetToolDefinitions returns the tool definitions recorded for each server
+// on each host, by server and tool name, or only those from host unless it
+// is empty.
+func (s *SQLiteStore) GetToolDefinitions(ctx context.Context, host string) ([]ToolDefinition, error) {
+	query := `
+		SELECT server_name, tool_name, description, input_schema, tokens, host, captured_at
+		FROM mcp_tool_definitions`
+	var args []interface{}
+	if host != "" {
+		query += " WHERE host = ?"
+		args = append(args, host)
+	}
+	query += " ORDER BY server_name, tool_name, host"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying tool definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var tools []ToolDefinition
+	for rows.Next() {
+		var t ToolDefinition
+		var capturedAt string
+		if err := rows.Scan(&t.ServerName, &t.ToolName, &t.Description, &t.InputSchema, &t.Tokens,
+			&t.Host, &capturedAt); err != nil {
+			return nil, fmt.Errorf("scanning tool definition: %w", err)
+		}
+		t.CapturedAt = parseStoredTime(capturedAt)
+		tools = append(tools, t)
+	}
+	return tools, rows.Err()
+}
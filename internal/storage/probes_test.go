@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProbeResults(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	results := []*ProbeResult{
+		{ServerName: "github", Transport: "stdio", StartupMs: 900, HandshakeMs: 40, InfoName: "github-mcp-server",
+			InfoVersion: "0.4.0", ProtocolVersion: "2025-06-18", ToolCount: 30, ProbedAt: now.Add(-time.Hour)},
+		{ServerName: "fs", Transport: "stdio", Error: "exec: \"fs-mcp\": executable file not found in $PATH", ProbedAt: now.Add(-time.Hour)},
+		{ServerName: "github", Transport: "stdio", StartupMs: 700, HandshakeMs: 35, ToolCount: 31, ProbedAt: now},
+	}
+	for _, r := range results {
+		if err := store.StoreProbeResult(ctx, r); err != nil {
+			t.Fatalf("StoreProbeResult failed: %v", err)
+		}
+		if r.ID == 0 || r.Host == "" {
+			t.Errorf("expected ID and host to be set, got %d %q", r.ID, r.Host)
+		}
+	}
+
+	latest, err := store.GetLatestProbes(ctx, "")
+	if err != nil {
+		t.Fatalf("GetLatestProbes failed: %v", err)
+	}
+	if len(latest) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(latest))
+	}
+	if latest[0].ServerName != "fs" || latest[0].OK() {
+		t.Errorf("expected failed fs probe first, got %+v", latest[0])
+	}
+	github := latest[1]
+	if github.StartupMs != 700 || github.ToolCount != 31 || !github.OK() {
+		t.Errorf("expected latest github probe, got %+v", github)
+	}
+	if !github.ProbedAt.Equal(now) {
+		t.Errorf("ProbedAt = %v, want %v", github.ProbedAt, now)
+	}
+
+	other, err := store.GetLatestProbes(ctx, "elsewhere")
+	if err != nil {
+		t.Fatalf("GetLatestProbes failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no probes from another host, got %d", len(other))
+	}
+}
+
+func TestToolDefinitions(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	first := []ToolDefinition{
+		{ToolName: "search_code", Description: "Search code", InputSchema: `{"type":"object"}`, Tokens: 120, CapturedAt: now.Add(-time.Hour)},
+		{ToolName: "create_issue", Tokens: 300, CapturedAt: now.Add(-time.Hour)},
+	}
+	if err := store.ReplaceToolDefinitions(ctx, "github", first); err != nil {
+		t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+	}
+	if err := store.ReplaceToolDefinitions(ctx, "fs", []ToolDefinition{{ToolName: "read", Tokens: 50, CapturedAt: now}}); err != nil {
+		t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+	}
+
+	// A later snapshot replaces the server's tools rather than adding to them
+	second := []ToolDefinition{{ToolName: "search_code", Description: "Search code", Tokens: 110, CapturedAt: now}}
+	if err := store.ReplaceToolDefinitions(ctx, "github", second); err != nil {
+		t.Fatalf("ReplaceToolDefinitions failed: %v", err)
+	}
+
+	tools, err := store.GetToolDefinitions(ctx, "")
+	if err != nil {
+		t.Fatalf("GetToolDefinitions failed: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d: %+v", len(tools), tools)
+	}
+	if tools[0].ServerName != "fs" || tools[1].ServerName != "github" {
+		t.Errorf("unexpected order: %s, %s", tools[0].ServerName, tools[1].ServerName)
+	}
+	search := tools[1]
+	if search.ToolName != "search_code" || search.Tokens != 110 || search.Host == "" || !search.CapturedAt.Equal(now) {
+		t.Errorf("unexpected tool definition: %+v", search)
+	}
+
+	other, err := store.GetToolDefinitions(ctx, "elsewhere")
+	if err != nil {
+		t.Fatalf("GetToolDefinitions failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no tools from another host, got %d", len(other))
+	}
+}
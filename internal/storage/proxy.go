@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// StoreProxyCalls records calls seen by the MCP proxy in one transaction,
+// tagged with this database's host.
+func (s *SQLiteStore) StoreProxyCalls(ctx context.Context, calls []ProxyCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO proxy_calls (server_name, kind, direction, method, tool_name, duration_us,
+			request_bytes, response_bytes, error_code, tool_error, http_status, failure, error, host, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparing proxy call insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range calls {
+		c := &calls[i]
+		toolError := 0
+		if c.ToolError {
+			toolError = 1
+		}
+		res, err := stmt.ExecContext(ctx, c.ServerName, c.Kind, c.Direction, c.Method, c.ToolName, c.DurationUs,
+			c.RequestBytes, c.ResponseBytes, c.ErrorCode, toolError, c.HTTPStatus, c.Failure, c.Error, s.host, c.CreatedAt.Local())
+		if err != nil {
+			return fmt.Errorf("inserting proxy call: %w", err)
+		}
+		if c.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("getting proxy call id: %w", err)
+		}
+		c.Host = s.host
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing proxy calls: %w", err)
+	}
+	return nil
+}
+
+// GetProxyStats aggregates proxy calls by server, kind, direction, method and
+// tool, busiest first.
+func (s *SQLiteStore) GetProxyStats(ctx context.Context, filter TimeFilter) ([]ProxyMethodStats, error) {
+	query := `
+		SELECT server_name, kind, direction, method, tool_name,
+			COUNT(*),
+			SUM(CASE WHEN failure != '' OR error_code != 0 OR tool_error = 1 OR error != '' THEN 1 ELSE 0 END),
+			AVG(duration_us) / 1000.0,
+			MAX(duration_us) / 1000.0,
+			AVG(request_bytes),
+			AVG(response_bytes),
+			MAX(response_bytes),
+			MAX(created_at)
+		FROM proxy_calls
+		WHERE 1=1`
+
+	var args []interface{}
+	query, args = appendTimeRange(query, args, "created_at", filter)
+	query, args = appendHostFilter(query, args, filter)
+	query += `
+		GROUP BY server_name, kind, direction, method, tool_name
+		ORDER BY COUNT(*) DESC, server_name, method, tool_name`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying proxy stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []ProxyMethodStats
+	for rows.Next() {
+		var st ProxyMethodStats
+		var avgLatency, maxLatency, avgRequest, avgResponse sql.NullFloat64
+		var lastSeen string
+		if err := rows.Scan(&st.ServerName, &st.Kind, &st.Direction, &st.Method, &st.ToolName,
+			&st.Calls, &st.Errors, &avgLatency, &maxLatency, &avgRequest, &avgResponse,
+			&st.MaxResponseBytes, &lastSeen); err != nil {
+			return nil, fmt.Errorf("scanning proxy stats: %w", err)
+		}
+		st.AvgLatencyMs = avgLatency.Float64
+		st.MaxLatencyMs = maxLatency.Float64
+		st.AvgRequestBytes = avgRequest.Float64
+		st.AvgResponseBytes = avgResponse.Float64
+		st.LastSeenAt = parseStoredTime(lastSeen)
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
+
+// GetProxyFailures counts failed proxy requests by server, failure class and
+// HTTP status, most frequent first.
+func (s *SQLiteStore) GetProxyFailures(ctx context.Context, filter TimeFilter) ([]ProxyFailureCount, error) {
+	// With MAX(), SQLite takes the bare error column from the latest row
+	query := `
+		SELECT server_name, failure, http_status, COUNT(*), MAX(created_at), error
+		FROM proxy_calls
+		WHERE kind = 'request' AND failure != ''`
+
+	var args []interface{}
+	query, args = appendTimeRange(query, args, "created_at", filter)
+	query, args = appendHostFilter(query, args, filter)
+	query += `
+		GROUP BY server_name, failure, http_status
+		ORDER BY COUNT(*) DESC, server_name, failure, http_status`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying proxy failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []ProxyFailureCount
+	for rows.Next() {
+		var f ProxyFailureCount
+		var lastSeen string
+		if err := rows.Scan(&f.ServerName, &f.Failure, &f.HTTPStatus, &f.Count, &lastSeen, &f.LastError); err != nil {
+			return nil, fmt.Errorf("scanning proxy failures: %w", err)
+		}
+		f.LastSeenAt = parseStoredTime(lastSeen)
+		failures = append(failures, f)
+	}
+	return failures, rows.Err()
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProxyCalls(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	calls := []ProxyCall{
+		{ServerName: "github", Kind: ProxyRequest, Direction: "client", Method: "tools/call", ToolName: "search",
+			DurationUs: 120000, RequestBytes: 200, ResponseBytes: 5000, CreatedAt: now.Add(-2 * time.Minute)},
+		{ServerName: "github", Kind: ProxyRequest, Direction: "client", Method: "tools/call", ToolName: "search",
+			DurationUs: 80000, RequestBytes: 100, ResponseBytes: 1000, ToolError: true, Failure: FailureTool, CreatedAt: now.Add(-time.Minute)},
+		{ServerName: "github", Kind: ProxyRequest, Direction: "client", Method: "tools/call", ToolName: "search",
+			DurationUs: 40000, RequestBytes: 300, ResponseBytes: 90, ErrorCode: -32602, Failure: FailureRPC, Error: "invalid params", CreatedAt: now},
+		{ServerName: "github", Kind: ProxyNotification, Direction: "server", Method: "notifications/progress", CreatedAt: now},
+		{ServerName: "github", Kind: ProxyStderr, Direction: "server", Error: "rate limited", CreatedAt: now},
+		{ServerName: "github", Kind: ProxyRequest, Direction: "client", Method: "tools/list", DurationUs: 5000, CreatedAt: now.Add(-48 * time.Hour)},
+	}
+	if err := store.StoreProxyCalls(ctx, calls); err != nil {
+		t.Fatalf("StoreProxyCalls failed: %v", err)
+	}
+	if calls[0].ID == 0 || calls[0].Host == "" {
+		t.Errorf("expected ID and host to be set, got %d %q", calls[0].ID, calls[0].Host)
+	}
+
+	stats, err := store.GetProxyStats(ctx, TimeFilter{From: now.Add(-time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("GetProxyStats failed: %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 groups in range, got %d: %+v", len(stats), stats)
+	}
+	search := stats[0]
+	if search.Method != "tools/call" || search.ToolName != "search" || search.Calls != 3 || search.Errors != 2 {
+		t.Errorf("unexpected tools/call stats: %+v", search)
+	}
+	if search.AvgLatencyMs != 80 || search.MaxLatencyMs != 120 {
+		t.Errorf("latency = avg %.1fms max %.1fms, want 80 and 120", search.AvgLatencyMs, search.MaxLatencyMs)
+	}
+	if search.AvgRequestBytes != 200 || search.MaxResponseBytes != 5000 {
+		t.Errorf("bytes = avg request %.0f max response %d, want 200 and 5000", search.AvgRequestBytes, search.MaxResponseBytes)
+	}
+	if !search.LastSeenAt.Equal(now) {
+		t.Errorf("LastSeenAt = %v, want %v", search.LastSeenAt, now)
+	}
+
+	// Proxy calls age out with events
+	if _, err := store.Cleanup(ctx, now.Add(-24*time.Hour)); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	all, err := store.GetProxyStats(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetProxyStats failed: %v", err)
+	}
+	for _, st := range all {
+		if st.Method == "tools/list" {
+			t.Error("expected the old tools/list call to be cleaned up")
+		}
+	}
+}
+
+func TestProxyFailures(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	call := func(failure string, status int, msg string, at time.Time) ProxyCall {
+		return ProxyCall{ServerName: "remote", Kind: ProxyRequest, Direction: "client", Method: "tools/call",
+			Failure: failure, HTTPStatus: status, Error: msg, CreatedAt: at}
+	}
+	calls := []ProxyCall{
+		call(FailureHTTPStatus, 401, "HTTP 401: token expired", now.Add(-3*time.Minute)),
+		call(FailureHTTPStatus, 401, "HTTP 401: bad token", now.Add(-time.Minute)),
+		call(FailureHTTPStatus, 503, "HTTP 503", now.Add(-2*time.Minute)),
+		call(FailureTLS, 0, "x509: certificate signed by unknown authority", now),
+		call("", 200, "", now),
+		call(FailureConnect, 0, "connection refused", now.Add(-48*time.Hour)),
+	}
+	if err := store.StoreProxyCalls(ctx, calls); err != nil {
+		t.Fatalf("StoreProxyCalls failed: %v", err)
+	}
+
+	failures, err := store.GetProxyFailures(ctx, TimeFilter{From: now.Add(-time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("GetProxyFailures failed: %v", err)
+	}
+	if len(failures) != 3 {
+		t.Fatalf("expected 3 failure groups in range, got %d: %+v", len(failures), failures)
+	}
+	first := failures[0]
+	if first.Failure != FailureHTTPStatus || first.HTTPStatus != 401 || first.Count != 2 {
+		t.Errorf("expected 2 HTTP 401 failures first, got %+v", first)
+	}
+	if first.LastError != "HTTP 401: bad token" || !first.LastSeenAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("expected the latest 401 error, got %q at %v", first.LastError, first.LastSeenAt)
+	}
+	for _, f := range failures[1:] {
+		if f.Count != 1 || (f.Failure != FailureTLS && f.HTTPStatus != 503) {
+			t.Errorf("unexpected failure group: %+v", f)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ToolResponseSize is the distribution of one tool's response sizes over a
+// time range. The histogram buckets estimated tokens per response using the
+// same log layout as latencies, so its percentiles are within ~5%.
+type ToolResponseSize struct {
+	ToolName    string
+	ServerName  string
+	Responses   int64 // Responses with a recorded size
+	TotalBytes  int64
+	TotalTokens int64
+	Histogram   *LatencyHistogram // Estimated tokens per response
+}
+
+// AvgBytes returns the mean response length in bytes.
+func (r ToolResponseSize) AvgBytes() float64 {
+	if r.Responses == 0 {
+		return 0
+	}
+	return float64(r.TotalBytes) / float64(r.Responses)
+}
+
+// AvgTokens returns the mean estimated tokens per response.
+func (r ToolResponseSize) AvgTokens() float64 {
+	if r.Responses == 0 {
+		return 0
+	}
+	return float64(r.TotalTokens) / float64(r.Responses)
+}
+
+// UpsertResponseSize records the size of one tool response in the histogram
+// for the given date, tool, and server.
+func (s *SQLiteStore) UpsertResponseSize(ctx context.Context, date string, toolName string, serverName string, bytes int64, tokens int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tool_response_sizes (date, tool_name, server_name, host, bucket, count, total_bytes, total_tokens)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT(date, tool_name, server_name, host, bucket) DO UPDATE SET
+			count = count + 1,
+			total_bytes = total_bytes + excluded.total_bytes,
+			total_tokens = total_tokens + excluded.total_tokens`,
+		date, toolName, serverName, s.host, LatencyBucket(tokens), bytes, tokens)
+	return err
+}
+
+// GetToolResponseSizes returns the response size distribution of each tool
+// with recorded sizes, merged across all days in the filter range, largest
+// total first.
+func (s *SQLiteStore) GetToolResponseSizes(ctx context.Context, filter TimeFilter) ([]ToolResponseSize, error) {
+	query := `
+		SELECT tool_name, server_name, bucket, SUM(count), SUM(total_bytes), SUM(total_tokens)
+		FROM tool_response_sizes
+		WHERE 1=1`
+
+	var args []interface{}
+
+	if !filter.From.IsZero() {
+		query += " AND date >= ?"
+		args = append(args, filter.From.Local().Format("2006-01-02"))
+	}
+
+	if !filter.To.IsZero() {
+		query += " AND date <= ?"
+		args = append(args, filter.To.Local().Format("2006-01-02"))
+	}
+
+	query, args = appendHostFilter(query, args, filter)
+
+	query += " GROUP BY tool_name, server_name, bucket ORDER BY tool_name, server_name"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying response sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var sizes []ToolResponseSize
+	index := make(map[string]int)
+	for rows.Next() {
+		var toolName, serverName string
+		var bucket int
+		var count, bytes, tokens int64
+		if err := rows.Scan(&toolName, &serverName, &bucket, &count, &bytes, &tokens); err != nil {
+			return nil, fmt.Errorf("scanning response sizes: %w", err)
+		}
+
+		key := toolName + "|" + serverName
+		i, ok := index[key]
+		if !ok {
+			i = len(sizes)
+			index[key] = i
+			sizes = append(sizes, ToolResponseSize{
+				ToolName:   toolName,
+				ServerName: serverName,
+				Histogram:  NewLatencyHistogram(),
+			})
+		}
+		sizes[i].Responses += count
+		sizes[i].TotalBytes += bytes
+		sizes[i].TotalTokens += tokens
+		sizes[i].Histogram.AddBucket(bucket, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortResponseSizes(sizes)
+	return sizes, nil
+}
+
+// fillToolResponseSizes sets the response size averages and percentiles on
+// tool stats from the stored response size histograms.
+func (s *SQLiteStore) fillToolResponseSizes(ctx context.Context, filter TimeFilter, stats []ToolStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	sizes, err := s.GetToolResponseSizes(ctx, filter)
+	if err != nil {
+		return err
+	}
+	applyResponseSizes(sizes, stats)
+	return nil
+}
+
+// applyResponseSizes copies each tool's response size summary onto its
+// stats.
+func applyResponseSizes(sizes []ToolResponseSize, stats []ToolStats) {
+	byTool := make(map[string]ToolResponseSize, len(sizes))
+	for _, rs := range sizes {
+		byTool[rs.ToolName+"|"+rs.ServerName] = rs
+	}
+	for i := range stats {
+		if rs, ok := byTool[stats[i].ToolName+"|"+stats[i].MCPServer]; ok {
+			stats[i].AvgResponseTokens = rs.AvgTokens()
+			stats[i].P50ResponseTokens = rs.Histogram.Percentile(0.50)
+			stats[i].P90ResponseTokens = rs.Histogram.Percentile(0.90)
+			stats[i].P99ResponseTokens = rs.Histogram.Percentile(0.99)
+		}
+	}
+}
+
+// sortResponseSizes orders tools by total response tokens, largest first.
+func sortResponseSizes(sizes []ToolResponseSize) {
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].TotalTokens != sizes[j].TotalTokens {
+			return sizes[i].TotalTokens > sizes[j].TotalTokens
+		}
+		if sizes[i].ToolName != sizes[j].ToolName {
+			return sizes[i].ToolName < sizes[j].ToolName
+		}
+		return sizes[i].ServerName < sizes[j].ServerName
+	})
+}
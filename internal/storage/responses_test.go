@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResponseSizes(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now()
+
+	// Nine small responses and one huge one from search; one from fetch; a
+	// call without a recorded size
+	for i := 0; i < 10; i++ {
+		tokens := int64(100)
+		if i == 9 {
+			tokens = 20000
+		}
+		if err := store.StoreEvent(ctx, &Event{
+			SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__search", MCPServer: "github",
+			Success: true, DurationMs: 10, ResponseBytes: tokens * 4, ResponseTokens: tokens, CreatedAt: now,
+		}); err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+	}
+	if err := store.StoreEvent(ctx, &Event{
+		SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__web__fetch", MCPServer: "web",
+		Success: true, ResponseBytes: 2000, ResponseTokens: 500, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+	if err := store.StoreEvent(ctx, &Event{
+		SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__web__fetch", MCPServer: "web",
+		Success: true, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+
+	sizes, err := store.GetToolResponseSizes(ctx, TimeFilter{From: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("GetToolResponseSizes failed: %v", err)
+	}
+	if len(sizes) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(sizes))
+	}
+	search := sizes[0]
+	if search.ToolName != "mcp__github__search" || search.Responses != 10 || search.TotalTokens != 20900 || search.TotalBytes != 83600 {
+		t.Errorf("expected search first with 10 responses, got %+v", search)
+	}
+	if p50 := search.Histogram.Percentile(0.50); math.Abs(p50-100)/100 > 0.06 {
+		t.Errorf("p50 = %.1f, want ~100", p50)
+	}
+	if p99 := search.Histogram.Percentile(0.99); math.Abs(p99-20000)/20000 > 0.06 {
+		t.Errorf("p99 = %.1f, want ~20000", p99)
+	}
+	if fetch := sizes[1]; fetch.Responses != 1 || fetch.AvgTokens() != 500 || fetch.AvgBytes() != 2000 {
+		t.Errorf("expected only the sized fetch response, got %+v", fetch)
+	}
+
+	stats, err := store.GetToolStats(ctx, TimeFilter{From: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("GetToolStats failed: %v", err)
+	}
+	for _, st := range stats {
+		if st.ToolName == "mcp__github__search" && (st.AvgResponseTokens != 2090 || st.P50ResponseTokens == 0 || st.P99ResponseTokens < st.P90ResponseTokens) {
+			t.Errorf("expected response sizes on tool stats, got %+v", st)
+		}
+	}
+
+	events, err := store.GetEvents(ctx, EventFilter{ToolNames: []string{"mcp__web__fetch"}})
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	var sized int
+	for _, e := range events {
+		if e.ResponseBytes == 2000 && e.ResponseTokens == 500 {
+			sized++
+		}
+	}
+	if sized != 1 {
+		t.Errorf("expected the response size on the stored event, got %+v", events)
+	}
+
+	other, err := store.GetToolResponseSizes(ctx, TimeFilter{Host: "elsewhere"})
+	if err != nil {
+		t.Fatalf("GetToolResponseSizes failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no sizes from another host, got %d", len(other))
+	}
+}
@@ -0,0 +1,382 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Tier identifies a level of aggregation for call statistics.
+type Tier int
+
+const (
+	// TierRaw reads individual rows from the events table.
+	TierRaw Tier = iota
+	// TierMinute reads per-minute rollups.
+	TierMinute
+	// TierHour reads per-hour rollups.
+	TierHour
+	// TierDay reads per-day rollups (tool_stats), which are kept forever.
+	TierDay
+)
+
+// String returns the tier name.
+func (t Tier) String() string {
+	switch t {
+	case TierRaw:
+		return "raw"
+	case TierMinute:
+		return "minute"
+	case TierHour:
+		return "hour"
+	case TierDay:
+		return "day"
+	default:
+		return "unknown"
+	}
+}
+
+// storedTimestampLayout is the fixed-width UTC layout used for last_seen_at,
+// so MAX() on the text column matches chronological order.
+const storedTimestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// rollupTier describes the table and bucket format of a rollup tier.
+type rollupTier struct {
+	table  string
+	bucket string // Bucket column name
+	layout string // Bucket key layout in local time
+}
+
+var rollupTiers = map[Tier]rollupTier{
+	TierMinute: {table: "call_rollups_minute", bucket: "bucket", layout: "2006-01-02 15:04"},
+	TierHour:   {table: "call_rollups_hour", bucket: "bucket", layout: "2006-01-02 15:00"},
+	TierDay:    {table: "tool_stats", bucket: "date", layout: "2006-01-02"},
+}
+
+// latencyTiers describes the latency histogram tables, bucketed like the
+// hourly and daily rollups.
+var latencyTiers = map[Tier]rollupTier{
+	TierHour: {table: "tool_latency_histograms_hour", bucket: "hour", layout: "2006-01-02 15:00"},
+	TierDay:  {table: "tool_latency_histograms", bucket: "date", layout: "2006-01-02"},
+}
+
+// RetentionPolicy defines how long each tier is kept. Zero keeps a tier
+// forever. Daily rollups are always kept forever.
+type RetentionPolicy struct {
+	RawDays    int
+	MinuteDays int
+	HourlyDays int
+}
+
+// DefaultRetentionPolicy returns the default tiered retention.
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		RawDays:    30,
+		MinuteDays: 90,
+		HourlyDays: 400,
+	}
+}
+
+// Days returns the retention in days for a tier, or 0 for forever.
+func (p RetentionPolicy) Days(tier Tier) int {
+	switch tier {
+	case TierRaw:
+		return p.RawDays
+	case TierMinute:
+		return p.MinuteDays
+	case TierHour:
+		return p.HourlyDays
+	default:
+		return 0
+	}
+}
+
+// UsageDays returns the retention in days for transcript usage and tool
+// call costs, or 0 for forever. They are kept as long as hourly rollups, and
+// never for less time than raw events.
+func (p RetentionPolicy) UsageDays() int {
+	if p.HourlyDays <= 0 || p.RawDays <= 0 {
+		return 0
+	}
+	if p.HourlyDays < p.RawDays {
+		return p.RawDays
+	}
+	return p.HourlyDays
+}
+
+// Covers reports whether a tier still holds data for the whole filter range.
+// An open-ended range (zero From) is only covered by tiers kept forever.
+func (p RetentionPolicy) Covers(tier Tier, filter TimeFilter, now time.Time) bool {
+	days := p.Days(tier)
+	if days <= 0 {
+		return true
+	}
+	if filter.From.IsZero() {
+		return false
+	}
+	return !filter.From.Before(now.AddDate(0, 0, -days))
+}
+
+// TierFor returns the finest tier that covers the filter range.
+func (p RetentionPolicy) TierFor(filter TimeFilter, now time.Time) Tier {
+	for _, tier := range []Tier{TierRaw, TierMinute, TierHour} {
+		if p.Covers(tier, filter, now) {
+			return tier
+		}
+	}
+	return TierDay
+}
+
+// tierFor returns the finest tier that covers the filter range under the
+// retention policy and holds the data for it. A tier that starts after the
+// daily rollups do, as raw events and the finer rollups do on an install
+// upgraded from before they existed, is skipped when the daily rollups hold
+// calls in the range before the tier's oldest row. Gaps are found to the day.
+func (s *SQLiteStore) tierFor(ctx context.Context, filter TimeFilter) (Tier, error) {
+	now := time.Now()
+	for _, tier := range []Tier{TierRaw, TierMinute, TierHour} {
+		if !s.retention.Covers(tier, filter, now) {
+			continue
+		}
+		missing, err := s.missingFromTier(ctx, tier, filter)
+		if err != nil {
+			return 0, err
+		}
+		if !missing {
+			return tier, nil
+		}
+	}
+	return TierDay, nil
+}
+
+// missingFromTier reports whether the daily rollups hold calls in the filter
+// range from before the oldest row of tier.
+func (s *SQLiteStore) missingFromTier(ctx context.Context, tier Tier, filter TimeFilter) (bool, error) {
+	if tier == TierRaw {
+		return s.missingBefore(ctx, rollupTier{table: "events", bucket: "created_at"}, filter)
+	}
+	return s.missingBefore(ctx, rollupTiers[tier], filter)
+}
+
+// missingBefore reports whether the daily rollups hold calls in the filter
+// range from before the oldest row of rt. An rt without a layout holds
+// stored timestamps rather than bucket keys.
+func (s *SQLiteStore) missingBefore(ctx context.Context, rt rollupTier, filter TimeFilter) (bool, error) {
+	query := fmt.Sprintf("SELECT MIN(%s) FROM %s WHERE 1=1", rt.bucket, rt.table)
+	var args []interface{}
+	query, args = appendHostFilter(query, args, filter)
+
+	var oldest sql.NullString
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&oldest); err != nil {
+		return false, fmt.Errorf("finding oldest %s row: %w", rt.table, err)
+	}
+
+	day := rollupTiers[TierDay]
+	query = "SELECT EXISTS(SELECT 1 FROM tool_stats WHERE 1=1"
+	args = nil
+	if !filter.From.IsZero() {
+		query += " AND date >= ?"
+		args = append(args, filter.From.Local().Format(day.layout))
+	}
+	if oldest.Valid {
+		start := parseStoredTime(oldest.String)
+		if rt.layout != "" {
+			start, _ = time.ParseInLocation(rt.layout, oldest.String, time.Local)
+		}
+		query += " AND date < ?"
+		args = append(args, start.Local().Format(day.layout))
+	}
+	query, args = appendHostFilter(query, args, filter)
+	query += ")"
+
+	var missing bool
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&missing); err != nil {
+		return false, fmt.Errorf("checking %s coverage: %w", rt.table, err)
+	}
+	return missing, nil
+}
+
+// SetRetentionPolicy sets the retention policy used to pick query tiers and
+// to prune old data.
+func (s *SQLiteStore) SetRetentionPolicy(policy RetentionPolicy) {
+	s.retention = policy
+}
+
+// RetentionPolicy returns the store's retention policy.
+func (s *SQLiteStore) RetentionPolicy() RetentionPolicy {
+	return s.retention
+}
+
+// UpsertCallRollups records tool calls in the minute, hour, and day tiers.
+func (s *SQLiteStore) UpsertCallRollups(ctx context.Context, timestamp time.Time, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
+	local := timestamp.Local()
+	lastSeen := timestamp.UTC().Format(storedTimestampLayout)
+
+	for _, tier := range []Tier{TierMinute, TierHour, TierDay} {
+		rt := rollupTiers[tier]
+		_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %[1]s (%[2]s, tool_name, server_name, host, call_count, error_count, total_latency_ms, last_seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(%[2]s, tool_name, server_name, host) DO UPDATE SET
+				call_count = call_count + excluded.call_count,
+				error_count = error_count + excluded.error_count,
+				total_latency_ms = total_latency_ms + excluded.total_latency_ms,
+				last_seen_at = MAX(COALESCE(last_seen_at, ''), excluded.last_seen_at)`, rt.table, rt.bucket),
+			local.Format(rt.layout), toolName, serverName, s.host, calls, errors, latencyMs, lastSeen)
+		if err != nil {
+			return fmt.Errorf("updating %s rollup: %w", tier, err)
+		}
+	}
+	return nil
+}
+
+// rollupRow is one grouped row read from a rollup tier.
+type rollupRow struct {
+	toolName   string
+	serverName string
+	calls      int64
+	errors     int64
+	latencyMs  int64
+	lastSeen   time.Time
+}
+
+// queryRollups sums a rollup tier over the filter range, grouped by server
+// and, if byTool is set, by tool.
+func (s *SQLiteStore) queryRollups(ctx context.Context, tier Tier, filter TimeFilter, byTool bool) ([]rollupRow, error) {
+	rt := rollupTiers[tier]
+
+	toolCol := "''"
+	groupBy := "server_name"
+	if byTool {
+		toolCol = "tool_name"
+		groupBy = "tool_name, server_name"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, server_name, SUM(call_count), SUM(error_count), SUM(total_latency_ms), MAX(last_seen_at)
+		FROM %s WHERE 1=1`, toolCol, rt.table)
+
+	if !byTool {
+		query += " AND server_name != ''"
+	}
+
+	var args []interface{}
+	query, args = rollupRange(query, args, rt, filter)
+	query += " GROUP BY " + groupBy + " ORDER BY SUM(call_count) DESC"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s rollups: %w", tier, err)
+	}
+	defer rows.Close()
+
+	var result []rollupRow
+	for rows.Next() {
+		var r rollupRow
+		var lastSeen sql.NullString
+		if err := rows.Scan(&r.toolName, &r.serverName, &r.calls, &r.errors, &r.latencyMs, &lastSeen); err != nil {
+			return nil, fmt.Errorf("scanning %s rollup: %w", tier, err)
+		}
+		if lastSeen.Valid {
+			r.lastSeen = parseStoredTime(lastSeen.String)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// rollupRange appends bucket range conditions for a filter. Buckets are
+// matched by their start, so a partially covered bucket is included.
+func rollupRange(query string, args []interface{}, rt rollupTier, filter TimeFilter) (string, []interface{}) {
+	if !filter.From.IsZero() {
+		query += fmt.Sprintf(" AND %s >= ?", rt.bucket)
+		args = append(args, filter.From.Local().Format(rt.layout))
+	}
+	if !filter.To.IsZero() {
+		query += fmt.Sprintf(" AND %s <= ?", rt.bucket)
+		args = append(args, filter.To.Local().Format(rt.layout))
+	}
+	return appendHostFilter(query, args, filter)
+}
+
+// GetHourlyRollups returns per-tool hourly rollups in the filter range,
+// oldest first, summed across hosts unless the filter names one.
+func (s *SQLiteStore) GetHourlyRollups(ctx context.Context, filter TimeFilter) ([]HourlyRollup, error) {
+	rt := rollupTiers[TierHour]
+
+	query := `
+		SELECT bucket, tool_name, server_name, SUM(call_count), SUM(error_count), SUM(total_latency_ms)
+		FROM call_rollups_hour
+		WHERE 1=1`
+
+	var args []interface{}
+	query, args = rollupRange(query, args, rt, filter)
+	query += " GROUP BY bucket, tool_name, server_name ORDER BY bucket"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying hourly rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var result []HourlyRollup
+	for rows.Next() {
+		var r HourlyRollup
+		var bucket string
+		if err := rows.Scan(&bucket, &r.ToolName, &r.MCPServer, &r.Calls, &r.Errors, &r.TotalLatencyMs); err != nil {
+			return nil, fmt.Errorf("scanning hourly rollup: %w", err)
+		}
+		r.Hour, _ = time.ParseInLocation(rt.layout, bucket, time.Local)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// avgLatency returns the mean latency of a rollup row.
+func (r rollupRow) avgLatency() float64 {
+	if r.calls == 0 {
+		return 0
+	}
+	return float64(r.latencyMs) / float64(r.calls)
+}
+
+// PruneRollups deletes rollups of a tier whose bucket starts before olderThan.
+// The daily tier is never pruned.
+func (s *SQLiteStore) PruneRollups(ctx context.Context, tier Tier, olderThan time.Time) (int64, error) {
+	if tier != TierMinute && tier != TierHour {
+		return 0, fmt.Errorf("pruning %s rollups is not supported", tier)
+	}
+
+	rt := rollupTiers[tier]
+	result, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE %s < ?", rt.table, rt.bucket),
+		olderThan.Local().Format(rt.layout))
+	if err != nil {
+		return 0, fmt.Errorf("pruning %s rollups: %w", tier, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
+// PruneLatencyHistograms deletes hourly latency histograms whose hour starts
+// before olderThan. Daily histograms are kept forever.
+func (s *SQLiteStore) PruneLatencyHistograms(ctx context.Context, olderThan time.Time) (int64, error) {
+	lt := latencyTiers[TierHour]
+	result, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE %s < ?", lt.table, lt.bucket),
+		olderThan.Local().Format(lt.layout))
+	if err != nil {
+		return 0, fmt.Errorf("pruning latency histograms: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return deleted, nil
+}
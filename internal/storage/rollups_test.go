@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy_TierFor(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+	policy := RetentionPolicy{RawDays: 3, MinuteDays: 7, HourlyDays: 90}
+
+	tests := []struct {
+		name     string
+		filter   TimeFilter
+		expected Tier
+	}{
+		{"last hour uses raw events", TimeFilter{From: now.Add(-time.Hour)}, TierRaw},
+		{"five days uses minutes", TimeFilter{From: now.AddDate(0, 0, -5)}, TierMinute},
+		{"thirty days uses hours", TimeFilter{From: now.AddDate(0, 0, -30)}, TierHour},
+		{"a year uses days", TimeFilter{From: now.AddDate(-1, 0, 0)}, TierDay},
+		{"all time uses days", TimeFilter{}, TierDay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.TierFor(tt.filter, now); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// Each default tier outlives the finer one, so every tier is read
+	defaults := DefaultRetentionPolicy()
+	if defaults.MinuteDays <= defaults.RawDays || defaults.HourlyDays <= defaults.MinuteDays {
+		t.Errorf("expected each tier kept longer than the finer one, got %+v", defaults)
+	}
+
+	// A tier kept forever covers any range
+	forever := RetentionPolicy{}
+	if got := forever.TierFor(TimeFilter{}, now); got != TierRaw {
+		t.Errorf("expected raw tier when raw events are kept forever, got %s", got)
+	}
+}
+
+func TestRollups_QueryPicksTier(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	store.SetRetentionPolicy(RetentionPolicy{RawDays: 1, MinuteDays: 2, HourlyDays: 60})
+
+	ctx := context.Background()
+	now := time.Now()
+	old := now.AddDate(0, 0, -20)
+
+	// A call 20 days ago exists only in rollups, as if raw events were pruned
+	if err := store.UpsertCallRollups(ctx, old, "mcp__github__get_issue", "github", 3, 1, 600); err != nil {
+		t.Fatalf("failed to upsert rollups: %v", err)
+	}
+
+	stats, err := store.GetMCPServerStats(ctx, TimeFilter{From: now.AddDate(0, 0, -30), To: now})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 server from hourly rollups, got %d", len(stats))
+	}
+	st := stats[0]
+	if st.TotalCalls != 3 || st.ErrorCount != 1 || st.AvgLatencyMs != 200 {
+		t.Errorf("unexpected rollup stats: %+v", st)
+	}
+	if st.LastUsedAt.Sub(old).Abs() > time.Millisecond {
+		t.Errorf("expected last used %v, got %v", old, st.LastUsedAt)
+	}
+
+	tools, err := store.GetToolStats(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get tool stats: %v", err)
+	}
+	if len(tools) != 1 || tools[0].TotalCalls != 3 {
+		t.Errorf("expected tool stats from daily rollups, got %+v", tools)
+	}
+
+	// The last hour is read from raw events, which hold nothing
+	stats, err = store.GetMCPServerStats(ctx, TimeFilter{From: now.Add(-time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 0 {
+		t.Errorf("expected no recent stats, got %+v", stats)
+	}
+}
+
+func TestRollups_HistoryBeforeRawEvents(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// An upgraded install: history only in tool_stats, raw events and the
+	// finer rollups starting today
+	old := now.AddDate(0, 0, -10).Format("2006-01-02")
+	if err := store.UpsertToolStats(ctx, old, "mcp__github__search", "github", 5, 1, 500); err != nil {
+		t.Fatalf("failed to upsert tool stats: %v", err)
+	}
+	if err := store.StoreEvent(ctx, &Event{
+		SessionID:  "s1",
+		EventType:  "PostToolUse",
+		ToolName:   "mcp__github__search",
+		MCPServer:  "github",
+		Success:    true,
+		DurationMs: 100,
+		CreatedAt:  now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+
+	week := TimeFilter{From: now.AddDate(0, 0, -14), To: now}
+	stats, err := store.GetMCPServerStats(ctx, week)
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 6 || stats[0].ErrorCount != 1 {
+		t.Errorf("expected the history from daily rollups, got %+v", stats)
+	}
+	tools, err := store.GetToolStats(ctx, week)
+	if err != nil {
+		t.Fatalf("failed to get tool stats: %v", err)
+	}
+	if len(tools) != 1 || tools[0].TotalCalls != 6 {
+		t.Errorf("expected the history from daily rollups, got %+v", tools)
+	}
+
+	// Ranges the raw events hold are still read from them
+	stats, err = store.GetMCPServerStats(ctx, TimeFilter{From: now.Add(-2 * time.Hour), To: now})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 1 {
+		t.Errorf("expected the last hours from raw events, got %+v", stats)
+	}
+}
+
+func TestRollups_CallVolumeByHour(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+
+	for i, calls := range []int64{2, 5, 1} {
+		ts := base.Add(time.Duration(i)*time.Hour + 10*time.Minute)
+		if err := store.UpsertCallRollups(ctx, ts, "Read", "", calls, 0, 0); err != nil {
+			t.Fatalf("failed to upsert rollups: %v", err)
+		}
+	}
+
+	volumes, err := store.GetCallVolumeByHour(ctx, TimeFilter{From: base, To: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to get call volume: %v", err)
+	}
+	if len(volumes) != 3 {
+		t.Fatalf("expected 3 hourly points, got %d", len(volumes))
+	}
+	for i, expected := range []int64{2, 5, 1} {
+		if volumes[i].TotalCalls != expected {
+			t.Errorf("hour %d: expected %d calls, got %d", i, expected, volumes[i].TotalCalls)
+		}
+		if !volumes[i].Hour.Equal(base.Add(time.Duration(i) * time.Hour)) {
+			t.Errorf("hour %d: expected %v, got %v", i, base.Add(time.Duration(i)*time.Hour), volumes[i].Hour)
+		}
+	}
+}
+
+func TestGetHourlyRollups(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+
+	upserts := []struct {
+		hour    int
+		tool    string
+		server  string
+		calls   int64
+		errors  int64
+		latency int64
+	}{
+		{0, "mcp__github__search", "github", 4, 1, 400},
+		{0, "mcp__github__search", "github", 2, 0, 100},
+		{0, "Read", "", 3, 0, 30},
+		{2, "mcp__github__search", "github", 1, 1, 900},
+	}
+	for _, u := range upserts {
+		ts := base.Add(time.Duration(u.hour)*time.Hour + 5*time.Minute)
+		if err := store.UpsertCallRollups(ctx, ts, u.tool, u.server, u.calls, u.errors, u.latency); err != nil {
+			t.Fatalf("failed to upsert rollups: %v", err)
+		}
+	}
+
+	rollups, err := store.GetHourlyRollups(ctx, TimeFilter{From: base, To: time.Now()})
+	if err != nil {
+		t.Fatalf("GetHourlyRollups failed: %v", err)
+	}
+	if len(rollups) != 3 {
+		t.Fatalf("expected 3 hourly rollups, got %d: %+v", len(rollups), rollups)
+	}
+
+	var github []HourlyRollup
+	for _, r := range rollups {
+		if r.MCPServer == "github" {
+			github = append(github, r)
+		}
+	}
+	if len(github) != 2 || !github[0].Hour.Equal(base) || !github[1].Hour.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("expected github rollups at hours 0 and 2, got %+v", github)
+	}
+	if r := github[0]; r.Calls != 6 || r.Errors != 1 || r.TotalLatencyMs != 500 {
+		t.Errorf("first github hour = %+v, want 6 calls, 1 error, 500ms", r)
+	}
+}
+
+func TestPruneRollups(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, age := range []int{1, 10, 100} {
+		ts := now.AddDate(0, 0, -age)
+		if err := store.UpsertCallRollups(ctx, ts, "mcp__github__get_issue", "github", 1, 0, 50); err != nil {
+			t.Fatalf("failed to upsert rollups: %v", err)
+		}
+	}
+
+	deleted, err := store.PruneRollups(ctx, TierMinute, now.AddDate(0, 0, -5))
+	if err != nil {
+		t.Fatalf("failed to prune minute rollups: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 minute rollups pruned, got %d", deleted)
+	}
+
+	deleted, err = store.PruneRollups(ctx, TierHour, now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("failed to prune hourly rollups: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 hourly rollup pruned, got %d", deleted)
+	}
+
+	if _, err := store.PruneRollups(ctx, TierDay, now); err == nil {
+		t.Error("expected pruning daily rollups to be rejected")
+	}
+
+	// Daily rollups keep the full history
+	stats, err := store.GetMCPServerStats(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalCalls != 3 {
+		t.Errorf("expected 3 calls from daily rollups, got %+v", stats)
+	}
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// appendEventFilter appends the conditions of an event filter to a query
+// over the events table.
+func appendEventFilter(query string, args []interface{}, filter EventFilter) (string, []interface{}) {
+	if filter.SessionID != "" {
+		query += " AND session_id = ?"
+		args = append(args, filter.SessionID)
+	}
+
+	if len(filter.EventTypes) > 0 {
+		placeholders := make([]string, len(filter.EventTypes))
+		for i, et := range filter.EventTypes {
+			placeholders[i] = "?"
+			args = append(args, et)
+		}
+		query += " AND event_type IN (" + strings.Join(placeholders, ",") + ")"
+	}
+
+	if len(filter.ToolNames) > 0 {
+		placeholders := make([]string, len(filter.ToolNames))
+		for i, tn := range filter.ToolNames {
+			placeholders[i] = "?"
+			args = append(args, tn)
+		}
+		query += " AND tool_name IN (" + strings.Join(placeholders, ",") + ")"
+	}
+
+	if len(filter.MCPServers) > 0 {
+		placeholders := make([]string, len(filter.MCPServers))
+		for i, ms := range filter.MCPServers {
+			placeholders[i] = "?"
+			args = append(args, ms)
+		}
+		query += " AND mcp_server IN (" + strings.Join(placeholders, ",") + ")"
+	}
+
+	query, args = appendTimeRange(query, args, "created_at", filter.TimeFilter)
+
+	return appendHostFilter(query, args, filter.TimeFilter)
+}
+
+// appendTimeRange restricts column to the filter range. Times are stored as
+// local time text and compared as strings, so the bounds are converted to
+// local time first.
+func appendTimeRange(query string, args []interface{}, column string, filter TimeFilter) (string, []interface{}) {
+	if !filter.From.IsZero() {
+		query += fmt.Sprintf(" AND %s >= ?", column)
+		args = append(args, filter.From.Local())
+	}
+	if !filter.To.IsZero() {
+		query += fmt.Sprintf(" AND %s <= ?", column)
+		args = append(args, filter.To.Local())
+	}
+	return query, args
+}
+
+// appendHostFilter restricts a query to rows from the filter's host, if set.
+func appendHostFilter(query string, args []interface{}, filter TimeFilter) (string, []interface{}) {
+	if filter.Host != "" {
+		query += " AND host = ?"
+		args = append(args, filter.Host)
+	}
+	return query, args
+}
+
+// SearchEvents runs a full-text search over redacted tool inputs, error text,
+// and working directories. Results are ranked by relevance.
+func (s *SQLiteStore) SearchEvents(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	match := ftsQuery(q.Query)
+	if match == "" {
+		return nil, nil
+	}
+
+	query := `SELECT id, session_id, event_type, tool_name, mcp_server, success, duration_ms,
+		events.tool_input, events.error, events.cwd, host, created_at,
+		snippet(events_fts, -1, '[', ']', '…', 16)
+		FROM events_fts JOIN events ON events.id = events_fts.rowid
+		WHERE events_fts MATCH ?`
+	args := []interface{}{match}
+
+	query, args = appendEventFilter(query, args, q.EventFilter)
+	query += " ORDER BY bm25(events_fts), created_at DESC"
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit)
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", q.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching events: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var success int
+		var toolName, mcpServer sql.NullString
+		var durationMs sql.NullInt64
+
+		err := rows.Scan(&r.ID, &r.SessionID, &r.EventType, &toolName, &mcpServer, &success,
+			&durationMs, &r.ToolInput, &r.Error, &r.Cwd, &r.Host, &r.CreatedAt, &r.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+
+		r.Success = success == 1
+		r.ToolName = toolName.String
+		r.MCPServer = mcpServer.String
+		r.DurationMs = durationMs.Int64
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// ftsQuery converts user input into an FTS5 query. Each word and each
+// "quoted phrase" becomes a quoted FTS5 string, so punctuation in paths and
+// commands is matched literally rather than parsed as query syntax. A
+// trailing * keeps prefix matching.
+func ftsQuery(input string) string {
+	var terms []string
+	for _, term := range splitTerms(input) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+	return strings.Join(terms, " ")
+}
+
+// splitTerms splits input on whitespace, keeping "quoted phrases" whole.
+func splitTerms(input string) []string {
+	var terms []string
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			terms = append(terms, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			flush()
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchEvents(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := store.UpsertSession(ctx, "s1", "/home/user/billing-service", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("failed to upsert session: %v", err)
+	}
+
+	events := []*Event{
+		{SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__postgres__query", MCPServer: "postgres",
+			ToolInput: `{"sql":"SELECT * FROM invoices"}`, Error: `relation "invoices" does not exist`, CreatedAt: now},
+		{SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__search_code", MCPServer: "github", Success: true,
+			ToolInput: `{"query":"invoice totals"}`, CreatedAt: now},
+		{SessionID: "s2", EventType: "PostToolUse", ToolName: "Bash", Success: true,
+			ToolInput: `{"command":"go test ./internal/storage/..."}`, Cwd: "/home/user/mcp-lens", CreatedAt: now},
+		{SessionID: "s3", EventType: "PostToolUse", ToolName: "mcp__postgres__query", MCPServer: "postgres",
+			ToolInput: `{"sql":"SELECT * FROM invoices"}`, CreatedAt: now.Add(-48 * time.Hour)},
+	}
+	for _, e := range events {
+		if err := store.InsertEvent(ctx, e); err != nil {
+			t.Fatalf("failed to insert event: %v", err)
+		}
+	}
+
+	recent := TimeFilter{From: now.Add(-24 * time.Hour), To: now.Add(time.Minute)}
+
+	tests := []struct {
+		name     string
+		query    SearchQuery
+		expected []string // Session IDs, sorted
+	}{
+		{"word in input", SearchQuery{Query: "invoices"}, []string{"s1", "s3"}},
+		{"time filter", SearchQuery{Query: "invoices", EventFilter: EventFilter{TimeFilter: recent}}, []string{"s1"}},
+		{"phrase in error", SearchQuery{Query: `"does not exist"`}, []string{"s1"}},
+		{"words out of order do not match a phrase", SearchQuery{Query: `"exist does"`}, nil},
+		{"all words must match", SearchQuery{Query: "invoices github"}, nil},
+		{"prefix", SearchQuery{Query: "invoice*", EventFilter: EventFilter{MCPServers: []string{"github"}}}, []string{"s1"}},
+		{"cwd inherited from session", SearchQuery{Query: "billing-service", EventFilter: EventFilter{MCPServers: []string{"github"}}}, []string{"s1"}},
+		{"path punctuation", SearchQuery{Query: "./internal/storage/..."}, []string{"s2"}},
+		{"session filter", SearchQuery{Query: "invoices", EventFilter: EventFilter{SessionID: "s3"}}, []string{"s3"}},
+		{"empty query", SearchQuery{Query: "  "}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.SearchEvents(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+
+			var got []string
+			for _, r := range results {
+				got = append(got, r.SessionID)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected sessions %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchEvents_SnippetAndIndexMaintenance(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	old := time.Now().AddDate(0, 0, -60)
+
+	if err := store.StoreEvent(ctx, &Event{
+		SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__fs__read_file", MCPServer: "fs",
+		ToolInput: `{"path":"/etc/hosts"}`, Error: "permission denied", CreatedAt: old,
+	}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+
+	results, err := store.SearchEvents(ctx, SearchQuery{Query: "permission"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Snippet != "[permission] denied" {
+		t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+	}
+	if results[0].ToolInput != `{"path":"/etc/hosts"}` {
+		t.Errorf("expected tool input to round-trip, got %q", results[0].ToolInput)
+	}
+
+	// Deleting events removes them from the index
+	if _, err := store.Cleanup(ctx, time.Now().AddDate(0, 0, -30)); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	results, err = store.SearchEvents(ctx, SearchQuery{Query: "permission"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected cleaned up events to leave the index, got %d", len(results))
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"timeout", `"timeout"`},
+		{"connection refused", `"connection" "refused"`},
+		{`"no such table" users`, `"no such table" "users"`},
+		{"migrat*", `"migrat"*`},
+		{`say"hi`, `"say" "hi"`},
+		{"AND OR NOT", `"AND" "OR" "NOT"`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ftsQuery(tt.input); got != tt.expected {
+			t.Errorf("ftsQuery(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SnapshotInfo describes a verified database snapshot.
+type SnapshotInfo struct {
+	Path          string
+	SchemaVersion int
+	Events        int64
+	Sessions      int64
+	SizeBytes     int64
+}
+
+// Snapshot writes a consistent copy of the live database to path with
+// VACUUM INTO. It is safe to run while other connections read and write.
+func (s *SQLiteStore) Snapshot(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("snapshot %s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// VerifySnapshot checks that the file at path is an intact MCP Lens database
+// this build can open. Snapshots from older schema versions are accepted and
+// migrated when opened; snapshots from newer versions are rejected.
+func VerifySnapshot(ctx context.Context, path string) (*SnapshotInfo, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return nil, fmt.Errorf("checking snapshot integrity: %w", err)
+	}
+	if result != "ok" {
+		return nil, fmt.Errorf("snapshot failed integrity check: %s", result)
+	}
+
+	version, err := currentVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%s is not an mcp-lens database", path)
+	}
+	if version > LatestSchemaVersion() {
+		return nil, fmt.Errorf("snapshot schema version %d is newer than supported version %d",
+			version, LatestSchemaVersion())
+	}
+
+	info := &SnapshotInfo{Path: path, SchemaVersion: version, SizeBytes: stat.Size()}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&info.Events); err != nil {
+		return nil, fmt.Errorf("counting snapshot events: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sessions").Scan(&info.Sessions); err != nil {
+		return nil, fmt.Errorf("counting snapshot sessions: %w", err)
+	}
+	return info, nil
+}
+
+// RestoreReport describes a completed restore.
+type RestoreReport struct {
+	Snapshot       *SnapshotInfo
+	PreviousBackup string // Copy of the replaced database, empty if there was none
+}
+
+// RestoreSnapshot verifies the snapshot at snapshotPath and atomically
+// replaces the database at dbPath with it. The replaced database is kept as
+// <dbPath>.pre-restore-<timestamp>.bak. The database must not be open in
+// another process while it is restored.
+func RestoreSnapshot(ctx context.Context, snapshotPath string, dbPath string) (*RestoreReport, error) {
+	info, err := VerifySnapshot(ctx, snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	report := &RestoreReport{Snapshot: info}
+
+	// Keep the current database, including anything still in its WAL
+	if _, err := os.Stat(dbPath); err == nil {
+		previous := fmt.Sprintf("%s.pre-restore-%s.bak", dbPath, time.Now().Format("20060102-150405"))
+		if err := vacuumInto(ctx, dbPath, previous); err != nil {
+			return nil, fmt.Errorf("backing up current database: %w", err)
+		}
+		report.PreviousBackup = previous
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Stage the copy beside the target so the final rename stays on one
+	// filesystem and is atomic
+	staged := dbPath + ".restore"
+	if err := copyFileSync(snapshotPath, staged); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("staging snapshot: %w", err)
+	}
+
+	// A leftover WAL would be replayed into the restored database
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(staged)
+			return nil, fmt.Errorf("removing %s: %w", dbPath+suffix, err)
+		}
+	}
+
+	if err := os.Rename(staged, dbPath); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("replacing database: %w", err)
+	}
+	return report, nil
+}
+
+// vacuumInto copies the database at src to dst with VACUUM INTO.
+func vacuumInto(ctx context.Context, src, dst string) error {
+	db, err := sql.Open("sqlite", src+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, "VACUUM INTO ?", dst)
+	return err
+}
+
+// copyFileSync copies src to dst and flushes dst to disk.
+func copyFileSync(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
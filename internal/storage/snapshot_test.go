@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "SessionStart", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+
+	snapshot := filepath.Join(dir, "backups", "snap.db")
+	if err := store.Snapshot(ctx, snapshot); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	if err := store.Snapshot(ctx, snapshot); err == nil {
+		t.Error("expected snapshot to refuse overwriting an existing file")
+	}
+
+	// Data written after the snapshot is not part of it
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s2", EventType: "SessionStart", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+	store.Close()
+
+	info, err := VerifySnapshot(ctx, snapshot)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if info.SchemaVersion != LatestSchemaVersion() || info.Events != 1 || info.Sessions != 1 {
+		t.Errorf("unexpected snapshot info: %+v", info)
+	}
+
+	report, err := RestoreSnapshot(ctx, snapshot, dbPath)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if !strings.Contains(report.PreviousBackup, ".pre-restore-") {
+		t.Errorf("expected the replaced database to be kept, got %q", report.PreviousBackup)
+	}
+	if _, err := os.Stat(dbPath + ".restore"); !os.IsNotExist(err) {
+		t.Error("expected staged copy to be renamed into place")
+	}
+
+	restored, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open restored store: %v", err)
+	}
+	defer restored.Close()
+
+	events, err := restored.GetEvents(ctx, EventFilter{})
+	if err != nil {
+		t.Fatalf("failed to get events: %v", err)
+	}
+	if len(events) != 1 || events[0].SessionID != "s1" {
+		t.Errorf("expected only the snapshotted event, got %+v", events)
+	}
+
+	previous, err := VerifySnapshot(ctx, report.PreviousBackup)
+	if err != nil {
+		t.Fatalf("previous database backup is not valid: %v", err)
+	}
+	if previous.Events != 2 {
+		t.Errorf("expected previous backup to hold both events, got %d", previous.Events)
+	}
+}
+
+func TestVerifySnapshot_Rejects(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := VerifySnapshot(ctx, garbage); err == nil {
+		t.Error("expected a non-database file to be rejected")
+	}
+
+	foreign := filepath.Join(dir, "foreign.db")
+	db, err := sql.Open("sqlite", foreign)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := db.Exec("CREATE TABLE notes (body TEXT)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	db.Close()
+	if _, err := VerifySnapshot(ctx, foreign); err == nil || !strings.Contains(err.Error(), "not an mcp-lens database") {
+		t.Errorf("expected a database without schema_version to be rejected, got %v", err)
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	store, err := NewSQLiteStore(newer)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err := store.db.Exec("INSERT INTO schema_version (version) VALUES (?)", LatestSchemaVersion()+1); err != nil {
+		t.Fatalf("failed to bump schema version: %v", err)
+	}
+	store.Close()
+	if _, err := VerifySnapshot(ctx, newer); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("expected a newer schema to be rejected, got %v", err)
+	}
+}
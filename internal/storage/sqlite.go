@@ -14,7 +14,12 @@ import (
 
 // SQLiteStore implements Store using SQLite.
 type SQLiteStore struct {
-	db *sql.DB
+	db        *sql.DB
+	path      string
+	migration MigrationReport
+	retention RetentionPolicy
+	recent    int    // Rows kept in the recent events buffer
+	host      string // Host new rows are tagged with
 }
 
 // NewSQLiteStore creates a new SQLite store.
@@ -25,140 +30,28 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("creating database directory: %w", err)
 	}
 
-	// Open database with WAL mode for better concurrency
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	// Open database with WAL mode for better concurrency. Transactions take
+	// the write lock when they begin, so processes opening the database
+	// together wait for each other rather than failing to upgrade a read lock.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate")
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
 
-	store := &SQLiteStore{db: db}
+	store := &SQLiteStore{db: db, path: dbPath, retention: DefaultRetentionPolicy(), recent: RecentEventsCapacity}
 
-	// Initialize schema
-	if err := store.initSchema(); err != nil {
+	// Bring the schema up to date
+	if err := store.migrate(context.Background()); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("initializing schema: %w", err)
+		return nil, fmt.Errorf("migrating schema: %w", err)
 	}
 
-	return store, nil
-}
+	if err := db.QueryRow("SELECT value FROM sync_state WHERE key = 'host'").Scan(&store.host); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("reading host: %w", err)
+	}
 
-func (s *SQLiteStore) initSchema() error {
-	schema := `
-	-- Core events table (append-only) - kept for backward compatibility
-	CREATE TABLE IF NOT EXISTS events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		session_id TEXT NOT NULL,
-		event_type TEXT NOT NULL,
-		tool_name TEXT,
-		mcp_server TEXT,
-		success INTEGER,
-		duration_ms INTEGER,
-		input_tokens INTEGER DEFAULT 0,
-		output_tokens INTEGER DEFAULT 0,
-		cost_usd REAL DEFAULT 0.0,
-		raw_payload BLOB,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_events_session ON events(session_id);
-	CREATE INDEX IF NOT EXISTS idx_events_created ON events(created_at);
-	CREATE INDEX IF NOT EXISTS idx_events_type ON events(event_type);
-	CREATE INDEX IF NOT EXISTS idx_events_mcp_server ON events(mcp_server);
-	CREATE INDEX IF NOT EXISTS idx_events_tool ON events(tool_name);
-
-	-- Sessions table
-	CREATE TABLE IF NOT EXISTS sessions (
-		id TEXT PRIMARY KEY,
-		cwd TEXT,
-		started_at DATETIME NOT NULL,
-		ended_at DATETIME,
-		total_events INTEGER DEFAULT 0,
-		total_tokens INTEGER DEFAULT 0,
-		total_cost_usd REAL DEFAULT 0.0
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_sessions_started ON sessions(started_at);
-
-	-- MCP servers table
-	CREATE TABLE IF NOT EXISTS mcp_servers (
-		name TEXT PRIMARY KEY,
-		first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		last_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		total_calls INTEGER DEFAULT 0,
-		total_errors INTEGER DEFAULT 0
-	);
-
-	-- Daily stats / tool_stats for aggregated queries
-	CREATE TABLE IF NOT EXISTS tool_stats (
-		date TEXT NOT NULL,
-		tool_name TEXT NOT NULL,
-		server_name TEXT NOT NULL DEFAULT '',
-		call_count INTEGER DEFAULT 0,
-		error_count INTEGER DEFAULT 0,
-		total_latency_ms INTEGER DEFAULT 0,
-		PRIMARY KEY (date, tool_name)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tool_stats_date ON tool_stats(date);
-	CREATE INDEX IF NOT EXISTS idx_tool_stats_server ON tool_stats(server_name);
-
-	-- Daily stats for performance (legacy, kept for compatibility)
-	CREATE TABLE IF NOT EXISTS daily_stats (
-		date DATE NOT NULL,
-		mcp_server TEXT,
-		tool_name TEXT,
-		call_count INTEGER DEFAULT 0,
-		success_count INTEGER DEFAULT 0,
-		error_count INTEGER DEFAULT 0,
-		total_latency_ms INTEGER DEFAULT 0,
-		total_tokens INTEGER DEFAULT 0,
-		total_cost_usd REAL DEFAULT 0.0,
-		PRIMARY KEY (date, mcp_server, tool_name)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_daily_stats_date ON daily_stats(date);
-
-	-- Recent events circular buffer (for TUI display)
-	CREATE TABLE IF NOT EXISTS recent_events (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		timestamp TEXT NOT NULL,
-		session_id TEXT NOT NULL,
-		event_type TEXT NOT NULL,
-		tool_name TEXT,
-		server_name TEXT,
-		duration_ms INTEGER,
-		success INTEGER
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_recent_events_ts ON recent_events(timestamp DESC);
-
-	-- Sync state (track JSONL processing position)
-	CREATE TABLE IF NOT EXISTS sync_state (
-		key TEXT PRIMARY KEY,
-		value TEXT NOT NULL
-	);
-
-	INSERT OR IGNORE INTO sync_state (key, value) VALUES ('position', '0');
-
-	-- Event fingerprints for deduplication
-	CREATE TABLE IF NOT EXISTS event_fingerprints (
-		fingerprint TEXT PRIMARY KEY,
-		created_at TEXT NOT NULL
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_fingerprints_created ON event_fingerprints(created_at);
-
-	-- Schema version
-	CREATE TABLE IF NOT EXISTS schema_version (
-		version INTEGER PRIMARY KEY,
-		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	INSERT OR IGNORE INTO schema_version (version) VALUES (2);
-	`
-
-	_, err := s.db.Exec(schema)
-	return err
+	return store, nil
 }
 
 // StoreEvent stores a hook event.
@@ -174,11 +67,13 @@ func (s *SQLiteStore) StoreEvent(ctx context.Context, event *Event) error {
 
 	result, err := s.db.ExecContext(ctx, `
 		INSERT INTO events (session_id, event_type, tool_name, mcp_server, success,
-			duration_ms, input_tokens, output_tokens, cost_usd, raw_payload, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			duration_ms, input_tokens, output_tokens, cost_usd, raw_payload,
+			tool_input, error, error_signature, response_bytes, response_tokens, cwd, host, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		event.SessionID, event.EventType, event.ToolName, event.MCPServer, successInt,
 		event.DurationMs, event.InputTokens, event.OutputTokens, event.CostUSD,
-		event.RawPayload, event.CreatedAt)
+		event.RawPayload, event.ToolInput, event.Error, event.ErrorSignature,
+		event.ResponseBytes, event.ResponseTokens, event.Cwd, s.host, event.CreatedAt.Local())
 	if err != nil {
 		return fmt.Errorf("inserting event: %w", err)
 	}
@@ -188,45 +83,57 @@ func (s *SQLiteStore) StoreEvent(ctx context.Context, event *Event) error {
 		return fmt.Errorf("getting last insert ID: %w", err)
 	}
 	event.ID = id
+	event.Host = s.host
 
 	// Update or create session
 	if event.EventType == "SessionStart" {
 		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO sessions (id, cwd, started_at, total_events)
-			VALUES (?, ?, ?, 1)
+			INSERT INTO sessions (id, cwd, host, started_at, total_events)
+			VALUES (?, ?, ?, ?, 1)
 			ON CONFLICT(id) DO UPDATE SET total_events = total_events + 1`,
-			event.SessionID, "", event.CreatedAt)
+			event.SessionID, event.Cwd, s.host, event.CreatedAt.Local())
 	} else {
 		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO sessions (id, cwd, started_at, total_events, total_tokens, total_cost_usd)
-			VALUES (?, '', ?, 1, ?, ?)
+			INSERT INTO sessions (id, cwd, host, started_at, total_events, total_tokens, total_cost_usd)
+			VALUES (?, '', ?, ?, 1, ?, ?)
 			ON CONFLICT(id) DO UPDATE SET
 				total_events = total_events + 1,
 				total_tokens = total_tokens + excluded.total_tokens,
 				total_cost_usd = total_cost_usd + excluded.total_cost_usd`,
-			event.SessionID, event.CreatedAt,
+			event.SessionID, s.host, event.CreatedAt.Local(),
 			event.InputTokens+event.OutputTokens, event.CostUSD)
 	}
 	if err != nil {
 		return fmt.Errorf("updating session: %w", err)
 	}
 
-	// Update MCP server stats if applicable
-	if event.MCPServer != "" {
-		errorInc := 0
+	// Tool calls feed the same aggregates as the sync engine so both ingest
+	// paths are visible to every query.
+	if event.EventType == "PostToolUse" && event.ToolName != "" {
+		date := event.CreatedAt.Local().Format("2006-01-02")
+		var errorInc int64
 		if !event.Success {
 			errorInc = 1
 		}
-		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO mcp_servers (name, first_seen_at, last_seen_at, total_calls, total_errors)
-			VALUES (?, ?, ?, 1, ?)
-			ON CONFLICT(name) DO UPDATE SET
-				last_seen_at = excluded.last_seen_at,
-				total_calls = total_calls + 1,
-				total_errors = total_errors + excluded.total_errors`,
-			event.MCPServer, event.CreatedAt, event.CreatedAt, errorInc)
-		if err != nil {
-			return fmt.Errorf("updating MCP server: %w", err)
+		if err := s.UpsertCallRollups(ctx, event.CreatedAt, event.ToolName, event.MCPServer, 1, errorInc, event.DurationMs); err != nil {
+			return fmt.Errorf("updating call rollups: %w", err)
+		}
+
+		// Events without a known duration are not part of the latency distribution
+		if event.DurationMs > 0 {
+			if err := s.UpsertLatencyHistogram(ctx, event.CreatedAt, event.ToolName, event.MCPServer, event.DurationMs); err != nil {
+				return fmt.Errorf("updating latency histogram: %w", err)
+			}
+		}
+		if event.ResponseBytes > 0 {
+			if err := s.UpsertResponseSize(ctx, date, event.ToolName, event.MCPServer, event.ResponseBytes, event.ResponseTokens); err != nil {
+				return fmt.Errorf("updating response sizes: %w", err)
+			}
+		}
+
+		if err := s.InsertRecentEvent(ctx, event.CreatedAt, event.SessionID, event.EventType,
+			event.ToolName, event.MCPServer, event.DurationMs, event.Success); err != nil {
+			return fmt.Errorf("inserting recent event: %w", err)
 		}
 	}
 
@@ -236,52 +143,13 @@ func (s *SQLiteStore) StoreEvent(ctx context.Context, event *Event) error {
 // GetEvents retrieves events matching the filter.
 func (s *SQLiteStore) GetEvents(ctx context.Context, filter EventFilter) ([]Event, error) {
 	query := `SELECT id, session_id, event_type, tool_name, mcp_server, success,
-		duration_ms, input_tokens, output_tokens, cost_usd, raw_payload, created_at
+		duration_ms, input_tokens, output_tokens, cost_usd, raw_payload,
+		tool_input, error, error_signature, response_bytes, response_tokens, cwd, host, created_at
 		FROM events WHERE 1=1`
 
 	var args []interface{}
 
-	if filter.SessionID != "" {
-		query += " AND session_id = ?"
-		args = append(args, filter.SessionID)
-	}
-
-	if len(filter.EventTypes) > 0 {
-		placeholders := make([]string, len(filter.EventTypes))
-		for i, et := range filter.EventTypes {
-			placeholders[i] = "?"
-			args = append(args, et)
-		}
-		query += " AND event_type IN (" + strings.Join(placeholders, ",") + ")"
-	}
-
-	if len(filter.ToolNames) > 0 {
-		placeholders := make([]string, len(filter.ToolNames))
-		for i, tn := range filter.ToolNames {
-			placeholders[i] = "?"
-			args = append(args, tn)
-		}
-		query += " AND tool_name IN (" + strings.Join(placeholders, ",") + ")"
-	}
-
-	if len(filter.MCPServers) > 0 {
-		placeholders := make([]string, len(filter.MCPServers))
-		for i, ms := range filter.MCPServers {
-			placeholders[i] = "?"
-			args = append(args, ms)
-		}
-		query += " AND mcp_server IN (" + strings.Join(placeholders, ",") + ")"
-	}
-
-	if !filter.From.IsZero() {
-		query += " AND created_at >= ?"
-		args = append(args, filter.From)
-	}
-
-	if !filter.To.IsZero() {
-		query += " AND created_at <= ?"
-		args = append(args, filter.To)
-	}
+	query, args = appendEventFilter(query, args, filter)
 
 	query += " ORDER BY created_at DESC"
 
@@ -308,7 +176,8 @@ func (s *SQLiteStore) GetEvents(ctx context.Context, filter EventFilter) ([]Even
 
 		err := rows.Scan(&e.ID, &e.SessionID, &e.EventType, &toolName, &mcpServer,
 			&success, &e.DurationMs, &e.InputTokens, &e.OutputTokens, &e.CostUSD,
-			&rawPayload, &e.CreatedAt)
+			&rawPayload, &e.ToolInput, &e.Error, &e.ErrorSignature, &e.ResponseBytes, &e.ResponseTokens,
+			&e.Cwd, &e.Host, &e.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scanning event: %w", err)
 		}
@@ -326,14 +195,14 @@ func (s *SQLiteStore) GetEvents(ctx context.Context, filter EventFilter) ([]Even
 // GetSession retrieves a session by ID.
 func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Session, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, cwd, started_at, ended_at, total_events, total_tokens, total_cost_usd
+		SELECT id, cwd, host, started_at, ended_at, total_events, total_tokens, total_cost_usd
 		FROM sessions WHERE id = ?`, sessionID)
 
 	var session Session
 	var cwd sql.NullString
 	var endedAt sql.NullTime
 
-	err := row.Scan(&session.ID, &cwd, &session.StartedAt, &endedAt,
+	err := row.Scan(&session.ID, &cwd, &session.Host, &session.StartedAt, &endedAt,
 		&session.TotalEvents, &session.TotalTokens, &session.TotalCostUSD)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -352,7 +221,7 @@ func (s *SQLiteStore) GetSession(ctx context.Context, sessionID string) (*Sessio
 
 // GetSessions retrieves sessions matching the filter.
 func (s *SQLiteStore) GetSessions(ctx context.Context, filter SessionFilter) ([]Session, error) {
-	query := `SELECT id, cwd, started_at, ended_at, total_events, total_tokens, total_cost_usd
+	query := `SELECT id, cwd, host, started_at, ended_at, total_events, total_tokens, total_cost_usd
 		FROM sessions WHERE 1=1`
 
 	var args []interface{}
@@ -362,15 +231,9 @@ func (s *SQLiteStore) GetSessions(ctx context.Context, filter SessionFilter) ([]
 		args = append(args, filter.Cwd)
 	}
 
-	if !filter.From.IsZero() {
-		query += " AND started_at >= ?"
-		args = append(args, filter.From)
-	}
+	query, args = appendTimeRange(query, args, "started_at", filter.TimeFilter)
 
-	if !filter.To.IsZero() {
-		query += " AND started_at <= ?"
-		args = append(args, filter.To)
-	}
+	query, args = appendHostFilter(query, args, filter.TimeFilter)
 
 	query += " ORDER BY started_at DESC"
 
@@ -394,7 +257,7 @@ func (s *SQLiteStore) GetSessions(ctx context.Context, filter SessionFilter) ([]
 		var cwd sql.NullString
 		var endedAt sql.NullTime
 
-		err := rows.Scan(&sess.ID, &cwd, &sess.StartedAt, &endedAt,
+		err := rows.Scan(&sess.ID, &cwd, &sess.Host, &sess.StartedAt, &endedAt,
 			&sess.TotalEvents, &sess.TotalTokens, &sess.TotalCostUSD)
 		if err != nil {
 			return nil, fmt.Errorf("scanning session: %w", err)
@@ -410,8 +273,18 @@ func (s *SQLiteStore) GetSessions(ctx context.Context, filter SessionFilter) ([]
 	return sessions, rows.Err()
 }
 
-// GetMCPServerStats retrieves aggregated stats for MCP servers.
+// GetMCPServerStats retrieves aggregated stats for MCP servers. Raw events
+// are used while they cover the range; older ranges, and ranges reaching back
+// before the first raw event, read the finest rollup tier that holds them.
 func (s *SQLiteStore) GetMCPServerStats(ctx context.Context, filter TimeFilter) ([]MCPServerStats, error) {
+	tier, err := s.tierFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if tier != TierRaw {
+		return s.mcpServerStatsFromRollups(ctx, tier, filter)
+	}
+
 	query := `
 		SELECT
 			mcp_server,
@@ -425,15 +298,9 @@ func (s *SQLiteStore) GetMCPServerStats(ctx context.Context, filter TimeFilter)
 
 	var args []interface{}
 
-	if !filter.From.IsZero() {
-		query += " AND created_at >= ?"
-		args = append(args, filter.From)
-	}
+	query, args = appendTimeRange(query, args, "created_at", filter)
 
-	if !filter.To.IsZero() {
-		query += " AND created_at <= ?"
-		args = append(args, filter.To)
-	}
+	query, args = appendHostFilter(query, args, filter)
 
 	query += " GROUP BY mcp_server ORDER BY total_calls DESC"
 
@@ -457,20 +324,56 @@ func (s *SQLiteStore) GetMCPServerStats(ctx context.Context, filter TimeFilter)
 
 		st.AvgLatencyMs = avgLatency.Float64
 		if lastUsed.Valid {
-			st.LastUsedAt, _ = time.Parse(time.RFC3339, lastUsed.String)
-			if st.LastUsedAt.IsZero() {
-				// Try alternate format
-				st.LastUsedAt, _ = time.Parse("2006-01-02 15:04:05.999999999-07:00", lastUsed.String)
-			}
+			st.LastUsedAt = parseStoredTime(lastUsed.String)
 		}
 		stats = append(stats, st)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillServerPercentiles(ctx, filter, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
 
-	return stats, rows.Err()
+// storedTimeLayouts are the text forms a DATETIME column can hold. Aggregates
+// such as MAX(created_at) return the stored text rather than a time.Time.
+var storedTimeLayouts = []string{
+	storedTimestampLayout,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
 }
 
-// GetToolStats retrieves aggregated stats for tools.
+// parseStoredTime parses a DATETIME value, returning the zero time if no
+// known layout matches.
+func parseStoredTime(value string) time.Time {
+	// Strip the monotonic clock reading that time.Time.String appends
+	if i := strings.Index(value, " m="); i >= 0 {
+		value = value[:i]
+	}
+	for _, layout := range storedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// GetToolStats retrieves aggregated stats for tools, choosing the data tier
+// like GetMCPServerStats.
 func (s *SQLiteStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]ToolStats, error) {
+	tier, err := s.tierFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if tier != TierRaw {
+		return s.toolStatsFromRollups(ctx, tier, filter)
+	}
+
 	query := `
 		SELECT
 			tool_name,
@@ -484,15 +387,9 @@ func (s *SQLiteStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]To
 
 	var args []interface{}
 
-	if !filter.From.IsZero() {
-		query += " AND created_at >= ?"
-		args = append(args, filter.From)
-	}
+	query, args = appendTimeRange(query, args, "created_at", filter)
 
-	if !filter.To.IsZero() {
-		query += " AND created_at <= ?"
-		args = append(args, filter.To)
-	}
+	query, args = appendHostFilter(query, args, filter)
 
 	query += " GROUP BY tool_name, mcp_server ORDER BY total_calls DESC"
 
@@ -518,30 +415,33 @@ func (s *SQLiteStore) GetToolStats(ctx context.Context, filter TimeFilter) ([]To
 		st.AvgLatencyMs = avgLatency.Float64
 		stats = append(stats, st)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return stats, rows.Err()
+	if err := s.fillToolPercentiles(ctx, filter, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
 }
 
-// GetCostSummary retrieves aggregated cost metrics.
+// GetCostSummary retrieves aggregated cost metrics: the usage read from
+// session transcripts, plus any usage recorded on events.
 func (s *SQLiteStore) GetCostSummary(ctx context.Context, filter TimeFilter) (*CostSummary, error) {
+	where := " WHERE 1=1"
+	var args []interface{}
+
+	where, args = appendTimeRange(where, args, "created_at", filter)
+
+	where, args = appendHostFilter(where, args, filter)
+
 	query := `
 		SELECT
 			COALESCE(SUM(input_tokens), 0) as input_tokens,
 			COALESCE(SUM(output_tokens), 0) as output_tokens,
 			COALESCE(SUM(cost_usd), 0) as total_cost
-		FROM events WHERE 1=1`
-
-	var args []interface{}
-
-	if !filter.From.IsZero() {
-		query += " AND created_at >= ?"
-		args = append(args, filter.From)
-	}
-
-	if !filter.To.IsZero() {
-		query += " AND created_at <= ?"
-		args = append(args, filter.To)
-	}
+		FROM events` + where
 
 	row := s.db.QueryRowContext(ctx, query, args...)
 
@@ -551,21 +451,117 @@ func (s *SQLiteStore) GetCostSummary(ctx context.Context, filter TimeFilter) (*C
 		return nil, fmt.Errorf("scanning cost summary: %w", err)
 	}
 
-	summary.TotalTokens = summary.InputTokens + summary.OutputTokens
+	query = `
+		SELECT
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_creation_tokens), 0),
+			COALESCE(SUM(cache_read_tokens), 0),
+			COALESCE(SUM(cost_usd), 0)
+		FROM turn_usage` + where
+
+	var input, output int64
+	var cost float64
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&input, &output,
+		&summary.CacheCreationTokens, &summary.CacheReadTokens, &cost)
+	if err != nil {
+		return nil, fmt.Errorf("scanning turn usage: %w", err)
+	}
+	summary.InputTokens += input
+	summary.OutputTokens += output
+	summary.TotalCostUSD += cost
+
+	summary.TotalTokens = summary.InputTokens + summary.OutputTokens +
+		summary.CacheCreationTokens + summary.CacheReadTokens
 	return &summary, nil
 }
 
-// GetCostByModel retrieves cost breakdown by model.
+// GetDailyCosts returns the spend on each local day in the range with any,
+// oldest first.
+func (s *SQLiteStore) GetDailyCosts(ctx context.Context, filter TimeFilter) ([]DailyCost, error) {
+	where := " WHERE 1=1"
+	var args []interface{}
+	where, args = appendTimeRange(where, args, "created_at", filter)
+	where, args = appendHostFilter(where, args, filter)
+
+	// created_at is stored in local time, so its date is the local day
+	query := `
+		SELECT day, SUM(cost) FROM (
+			SELECT substr(created_at, 1, 10) AS day, cost_usd AS cost FROM events` + where + `
+			UNION ALL
+			SELECT substr(created_at, 1, 10), cost_usd FROM turn_usage` + where + `
+		)
+		GROUP BY day
+		HAVING SUM(cost) > 0
+		ORDER BY day`
+	args = append(args, args...)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying daily costs: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []DailyCost
+	for rows.Next() {
+		var day string
+		var c DailyCost
+		if err := rows.Scan(&day, &c.CostUSD); err != nil {
+			return nil, fmt.Errorf("scanning daily costs: %w", err)
+		}
+		c.Date, err = time.ParseInLocation("2006-01-02", day, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("parsing day %q: %w", day, err)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
+}
+
+// GetCostByModel retrieves cost breakdown by model, most expensive first,
+// from the usage read from session transcripts.
 func (s *SQLiteStore) GetCostByModel(ctx context.Context, filter TimeFilter) ([]ModelCost, error) {
-	// Note: Model information would need to be stored in events
-	// For now, return empty - this would be implemented when we track model info
-	return []ModelCost{}, nil
+	query := `
+		SELECT model, SUM(input_tokens), SUM(output_tokens), SUM(cache_creation_tokens),
+			SUM(cache_read_tokens), SUM(cost_usd)
+		FROM turn_usage
+		WHERE 1=1`
+
+	var args []interface{}
+
+	query, args = appendTimeRange(query, args, "created_at", filter)
+
+	query, args = appendHostFilter(query, args, filter)
+
+	query += " GROUP BY model ORDER BY SUM(cost_usd) DESC, model"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying cost by model: %w", err)
+	}
+	defer rows.Close()
+
+	costs := []ModelCost{}
+	for rows.Next() {
+		var c ModelCost
+		if err := rows.Scan(&c.Model, &c.InputTokens, &c.OutputTokens, &c.CacheCreationTokens,
+			&c.CacheReadTokens, &c.TotalCostUSD); err != nil {
+			return nil, fmt.Errorf("scanning cost by model: %w", err)
+		}
+		costs = append(costs, c)
+	}
+	return costs, rows.Err()
 }
 
-// Cleanup removes events older than the specified time.
+// Cleanup removes events older than the specified time, returning how many
+// were deleted. Proxy calls, the same raw tier, are removed with them.
+// Transcript usage is kept longer; see CleanupUsage.
 func (s *SQLiteStore) Cleanup(ctx context.Context, olderThan time.Time) (int64, error) {
+	// created_at is stored as local time text, so the cutoff must be too
+	cutoff := olderThan.Local()
+
 	result, err := s.db.ExecContext(ctx,
-		"DELETE FROM events WHERE created_at < ?", olderThan)
+		"DELETE FROM events WHERE created_at < ?", cutoff)
 	if err != nil {
 		return 0, fmt.Errorf("deleting old events: %w", err)
 	}
@@ -575,6 +571,15 @@ func (s *SQLiteStore) Cleanup(ctx context.Context, olderThan time.Time) (int64,
 		return 0, fmt.Errorf("getting rows affected: %w", err)
 	}
 
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM proxy_calls WHERE created_at < ?", cutoff); err != nil {
+		return deleted, fmt.Errorf("deleting old proxy calls: %w", err)
+	}
+
+	if err := s.deleteResolvedAlerts(ctx, cutoff); err != nil {
+		return deleted, err
+	}
+
 	return deleted, nil
 }
 
@@ -609,27 +614,52 @@ func (s *SQLiteStore) SetSyncPosition(ctx context.Context, pos int64) error {
 	return err
 }
 
-// UpsertToolStats updates aggregated tool statistics.
+// InsertEvent records a raw event from the JSONL log in the events table.
+// Unlike StoreEvent it does not touch sessions or aggregates, which the sync
+// engine maintains itself. Events without a cwd inherit their session's.
+func (s *SQLiteStore) InsertEvent(ctx context.Context, event *Event) error {
+	successInt := 0
+	if event.Success {
+		successInt = 1
+	}
+
+	// Stored in local time like StoreEvent so created_at compares consistently
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO events (session_id, event_type, tool_name, mcp_server, success, duration_ms,
+			tool_input, error, error_signature, response_bytes, response_tokens, cwd, host, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+			COALESCE(NULLIF(?, ''), (SELECT cwd FROM sessions WHERE id = ?), ''), ?, ?)`,
+		event.SessionID, event.EventType, event.ToolName, event.MCPServer, successInt, event.DurationMs,
+		event.ToolInput, event.Error, event.ErrorSignature, event.ResponseBytes, event.ResponseTokens,
+		event.Cwd, event.SessionID, s.host, event.CreatedAt.Local())
+	if err != nil {
+		return fmt.Errorf("inserting event: %w", err)
+	}
+	return nil
+}
+
+// UpsertToolStats updates the daily rollup for a tool. Ingest paths use
+// UpsertCallRollups, which also maintains the minute and hour tiers.
 func (s *SQLiteStore) UpsertToolStats(ctx context.Context, date string, toolName string, serverName string, calls int64, errors int64, latencyMs int64) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO tool_stats (date, tool_name, server_name, call_count, error_count, total_latency_ms)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(date, tool_name) DO UPDATE SET
+		INSERT INTO tool_stats (date, tool_name, server_name, host, call_count, error_count, total_latency_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(date, tool_name, server_name, host) DO UPDATE SET
 			call_count = call_count + excluded.call_count,
 			error_count = error_count + excluded.error_count,
 			total_latency_ms = total_latency_ms + excluded.total_latency_ms`,
-		date, toolName, serverName, calls, errors, latencyMs)
+		date, toolName, serverName, s.host, calls, errors, latencyMs)
 	return err
 }
 
 // UpsertSession creates or updates a session.
 func (s *SQLiteStore) UpsertSession(ctx context.Context, id string, cwd string, startedAt time.Time) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO sessions (id, cwd, started_at, total_events)
-		VALUES (?, ?, ?, 0)
+		INSERT INTO sessions (id, cwd, host, started_at, total_events)
+		VALUES (?, ?, ?, ?, 0)
 		ON CONFLICT(id) DO UPDATE SET
 			cwd = COALESCE(NULLIF(excluded.cwd, ''), cwd)`,
-		id, cwd, startedAt)
+		id, cwd, s.host, startedAt.Local())
 	return err
 }
 
@@ -637,7 +667,7 @@ func (s *SQLiteStore) UpsertSession(ctx context.Context, id string, cwd string,
 func (s *SQLiteStore) UpdateSessionEnd(ctx context.Context, id string, endedAt time.Time) error {
 	_, err := s.db.ExecContext(ctx,
 		"UPDATE sessions SET ended_at = ? WHERE id = ?",
-		endedAt, id)
+		endedAt.Local(), id)
 	return err
 }
 
@@ -651,7 +681,21 @@ func (s *SQLiteStore) IncrementSessionStats(ctx context.Context, id string, tool
 	return err
 }
 
-// InsertRecentEvent adds an event to the recent events buffer.
+// RecentEventsCapacity is the default number of rows kept in the recent
+// events buffer.
+const RecentEventsCapacity = 100
+
+// SetRecentEventsCapacity sets the number of rows kept in the recent events
+// buffer. Zero or less restores the default.
+func (s *SQLiteStore) SetRecentEventsCapacity(n int) {
+	if n <= 0 {
+		n = RecentEventsCapacity
+	}
+	s.recent = n
+}
+
+// InsertRecentEvent adds an event to the recent events buffer and trims it
+// to the configured capacity.
 func (s *SQLiteStore) InsertRecentEvent(ctx context.Context, timestamp time.Time, sessionID string, eventType string, toolName string, serverName string, durationMs int64, success bool) error {
 	successInt := 0
 	if success {
@@ -661,16 +705,16 @@ func (s *SQLiteStore) InsertRecentEvent(ctx context.Context, timestamp time.Time
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO recent_events (timestamp, session_id, event_type, tool_name, server_name, duration_ms, success)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		timestamp.Format(time.RFC3339), sessionID, eventType, toolName, serverName, durationMs, successInt)
+		timestamp.UTC().Format(time.RFC3339), sessionID, eventType, toolName, serverName, durationMs, successInt)
 	if err != nil {
 		return err
 	}
 
-	// Trim to keep only last 100 events
+	// Trim to keep only the most recent events
 	_, err = s.db.ExecContext(ctx, `
 		DELETE FROM recent_events WHERE id <= (
-			SELECT id FROM recent_events ORDER BY id DESC LIMIT 1 OFFSET 100
-		)`)
+			SELECT id FROM recent_events ORDER BY id DESC LIMIT 1 OFFSET ?
+		)`, s.recent)
 	return err
 }
 
@@ -710,74 +754,215 @@ func (s *SQLiteStore) GetRecentEvents(ctx context.Context, limit int) ([]RecentE
 	return events, rows.Err()
 }
 
-// GetMCPServerStatsAggregated retrieves MCP server stats from tool_stats table.
+// GetMCPServerStatsAggregated retrieves MCP server stats from the daily
+// rollups (tool_stats), regardless of the retention policy.
 func (s *SQLiteStore) GetMCPServerStatsAggregated(ctx context.Context, filter TimeFilter) ([]MCPServerStats, error) {
-	query := `
-		SELECT
-			server_name,
-			SUM(call_count) as total_calls,
-			SUM(call_count) - SUM(error_count) as success_count,
-			SUM(error_count) as error_count,
-			CASE WHEN SUM(call_count) > 0
-				 THEN SUM(total_latency_ms) * 1.0 / SUM(call_count)
-				 ELSE 0
-			END as avg_latency_ms
-		FROM tool_stats
-		WHERE server_name != ''`
+	return s.mcpServerStatsFromRollups(ctx, TierDay, filter)
+}
 
-	var args []interface{}
+// mcpServerStatsFromRollups builds server stats from a rollup tier.
+func (s *SQLiteStore) mcpServerStatsFromRollups(ctx context.Context, tier Tier, filter TimeFilter) ([]MCPServerStats, error) {
+	rows, err := s.queryRollups(ctx, tier, filter, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []MCPServerStats
+	for _, r := range rows {
+		stats = append(stats, MCPServerStats{
+			ServerName:   r.serverName,
+			TotalCalls:   r.calls,
+			SuccessCount: r.calls - r.errors,
+			ErrorCount:   r.errors,
+			AvgLatencyMs: r.avgLatency(),
+			LastUsedAt:   r.lastSeen,
+		})
+	}
+
+	if err := s.fillServerPercentiles(ctx, filter, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// toolStatsFromRollups builds tool stats from a rollup tier.
+func (s *SQLiteStore) toolStatsFromRollups(ctx context.Context, tier Tier, filter TimeFilter) ([]ToolStats, error) {
+	rows, err := s.queryRollups(ctx, tier, filter, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []ToolStats
+	for _, r := range rows {
+		stats = append(stats, ToolStats{
+			ToolName:     r.toolName,
+			MCPServer:    r.serverName,
+			TotalCalls:   r.calls,
+			SuccessCount: r.calls - r.errors,
+			ErrorCount:   r.errors,
+			AvgLatencyMs: r.avgLatency(),
+		})
+	}
+
+	if err := s.fillToolPercentiles(ctx, filter, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
 
-	if !filter.From.IsZero() {
-		query += " AND date >= ?"
-		args = append(args, filter.From.Format("2006-01-02"))
+// UpsertLatencyHistogram records one latency observation in the daily and
+// hourly histograms for the given tool and server.
+func (s *SQLiteStore) UpsertLatencyHistogram(ctx context.Context, timestamp time.Time, toolName string, serverName string, durationMs int64) error {
+	local := timestamp.Local()
+	for _, tier := range []Tier{TierHour, TierDay} {
+		lt := latencyTiers[tier]
+		_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %[1]s (%[2]s, tool_name, server_name, host, bucket, count)
+			VALUES (?, ?, ?, ?, ?, 1)
+			ON CONFLICT(%[2]s, tool_name, server_name, host, bucket) DO UPDATE SET
+				count = count + 1`, lt.table, lt.bucket),
+			local.Format(lt.layout), toolName, serverName, s.host, LatencyBucket(durationMs))
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if !filter.To.IsZero() {
-		query += " AND date <= ?"
-		args = append(args, filter.To.Format("2006-01-02"))
+// GetToolLatencies returns latency histograms per tool, merged across the
+// filter range. Hourly histograms are used whenever the call stats come from
+// a finer tier than the daily one, so percentiles and averages cover the same
+// hours; past the hourly retention both are daily.
+func (s *SQLiteStore) GetToolLatencies(ctx context.Context, filter TimeFilter) ([]ToolLatency, error) {
+	tier, err := s.tierFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	lt := latencyTiers[TierDay]
+	if tier != TierDay {
+		// Hourly histograms start later than the hourly rollups on an
+		// install upgraded from before they existed
+		missing, err := s.missingBefore(ctx, latencyTiers[TierHour], filter)
+		if err != nil {
+			return nil, err
+		}
+		if !missing {
+			lt = latencyTiers[TierHour]
+		}
 	}
 
-	query += " GROUP BY server_name ORDER BY total_calls DESC"
+	query := fmt.Sprintf(`
+		SELECT tool_name, server_name, bucket, SUM(count)
+		FROM %s
+		WHERE 1=1`, lt.table)
+
+	var args []interface{}
+	query, args = rollupRange(query, args, lt, filter)
+
+	query += " GROUP BY tool_name, server_name, bucket ORDER BY tool_name, server_name"
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("querying MCP stats: %w", err)
+		return nil, fmt.Errorf("querying latency histograms: %w", err)
 	}
 	defer rows.Close()
 
-	var stats []MCPServerStats
+	var latencies []ToolLatency
+	index := make(map[string]int)
 	for rows.Next() {
-		var st MCPServerStats
-		err := rows.Scan(&st.ServerName, &st.TotalCalls, &st.SuccessCount, &st.ErrorCount, &st.AvgLatencyMs)
-		if err != nil {
-			return nil, fmt.Errorf("scanning MCP stats: %w", err)
+		var toolName, serverName string
+		var bucket int
+		var count int64
+		if err := rows.Scan(&toolName, &serverName, &bucket, &count); err != nil {
+			return nil, fmt.Errorf("scanning latency histogram: %w", err)
 		}
-		stats = append(stats, st)
+
+		key := toolName + "|" + serverName
+		i, ok := index[key]
+		if !ok {
+			i = len(latencies)
+			index[key] = i
+			latencies = append(latencies, ToolLatency{
+				ToolName:   toolName,
+				ServerName: serverName,
+				Histogram:  NewLatencyHistogram(),
+			})
+		}
+		latencies[i].Histogram.AddBucket(bucket, count)
 	}
 
-	return stats, rows.Err()
+	return latencies, rows.Err()
 }
 
-// GetCallVolumeByHour retrieves hourly call counts for sparkline display.
-func (s *SQLiteStore) GetCallVolumeByHour(ctx context.Context, filter TimeFilter) ([]HourlyCallVolume, error) {
-	query := `
-		SELECT date, SUM(call_count) as calls
-		FROM tool_stats
-		WHERE 1=1`
+// fillServerPercentiles sets P50/P90/P99 on server stats from the stored
+// latency histograms.
+func (s *SQLiteStore) fillServerPercentiles(ctx context.Context, filter TimeFilter, stats []MCPServerStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
 
-	var args []interface{}
+	latencies, err := s.GetToolLatencies(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	servers := mergeByServer(latencies)
+	for i := range stats {
+		if h, ok := servers[stats[i].ServerName]; ok {
+			stats[i].P50LatencyMs = h.Percentile(0.50)
+			stats[i].P90LatencyMs = h.Percentile(0.90)
+			stats[i].P99LatencyMs = h.Percentile(0.99)
+		}
+	}
+	return nil
+}
 
-	if !filter.From.IsZero() {
-		query += " AND date >= ?"
-		args = append(args, filter.From.Format("2006-01-02"))
+// fillToolPercentiles sets P50/P90/P99 on tool stats from the stored
+// latency histograms, and response sizes from the response histograms.
+func (s *SQLiteStore) fillToolPercentiles(ctx context.Context, filter TimeFilter, stats []ToolStats) error {
+	if len(stats) == 0 {
+		return nil
 	}
 
-	if !filter.To.IsZero() {
-		query += " AND date <= ?"
-		args = append(args, filter.To.Format("2006-01-02"))
+	latencies, err := s.GetToolLatencies(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	byTool := make(map[string]*LatencyHistogram, len(latencies))
+	for _, tl := range latencies {
+		byTool[tl.ToolName+"|"+tl.ServerName] = tl.Histogram
+	}
+	for i := range stats {
+		if h, ok := byTool[stats[i].ToolName+"|"+stats[i].MCPServer]; ok {
+			stats[i].P50LatencyMs = h.Percentile(0.50)
+			stats[i].P90LatencyMs = h.Percentile(0.90)
+			stats[i].P99LatencyMs = h.Percentile(0.99)
+		}
 	}
+	return s.fillToolResponseSizes(ctx, filter, stats)
+}
+
+// GetCallVolumeByHour retrieves call counts for sparkline display. It uses
+// hourly rollups while they cover the range and daily rollups beyond that,
+// so each point is an hour or a day respectively.
+func (s *SQLiteStore) GetCallVolumeByHour(ctx context.Context, filter TimeFilter) ([]HourlyCallVolume, error) {
+	tier := TierDay
+	if s.retention.Covers(TierHour, filter, time.Now()) {
+		tier = TierHour
+	}
+	rt := rollupTiers[tier]
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s, SUM(call_count) as calls, SUM(error_count) as errors
+		FROM %[2]s
+		WHERE 1=1`, rt.bucket, rt.table)
 
-	query += " GROUP BY date ORDER BY date"
+	var args []interface{}
+	query, args = rollupRange(query, args, rt, filter)
+	query += fmt.Sprintf(" GROUP BY %[1]s ORDER BY %[1]s", rt.bucket)
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -788,12 +973,12 @@ func (s *SQLiteStore) GetCallVolumeByHour(ctx context.Context, filter TimeFilter
 	var volumes []HourlyCallVolume
 	for rows.Next() {
 		var v HourlyCallVolume
-		var date string
-		err := rows.Scan(&date, &v.TotalCalls)
+		var bucket string
+		err := rows.Scan(&bucket, &v.TotalCalls, &v.Errors)
 		if err != nil {
 			return nil, fmt.Errorf("scanning call volume: %w", err)
 		}
-		v.Hour, _ = time.Parse("2006-01-02", date)
+		v.Hour, _ = time.ParseInLocation(rt.layout, bucket, time.Local)
 		volumes = append(volumes, v)
 	}
 
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -216,6 +217,97 @@ func TestGetMCPServerStats(t *testing.T) {
 	}
 }
 
+func TestLatencyPercentilesAcrossDays(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// Day 1: fast calls, day 2: a few slow calls
+	for i := 0; i < 90; i++ {
+		if err := store.UpsertToolStats(ctx, "2026-01-10", "mcp__github__get_issue", "github", 1, 0, 100); err != nil {
+			t.Fatalf("failed to upsert tool stats: %v", err)
+		}
+		if err := store.UpsertLatencyHistogram(ctx, time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local), "mcp__github__get_issue", "github", 100); err != nil {
+			t.Fatalf("failed to upsert histogram: %v", err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if err := store.UpsertToolStats(ctx, "2026-01-11", "mcp__github__get_issue", "github", 1, 0, 4000); err != nil {
+			t.Fatalf("failed to upsert tool stats: %v", err)
+		}
+		if err := store.UpsertLatencyHistogram(ctx, time.Date(2026, 1, 11, 12, 0, 0, 0, time.Local), "mcp__github__get_issue", "github", 4000); err != nil {
+			t.Fatalf("failed to upsert histogram: %v", err)
+		}
+	}
+
+	day1 := time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 1, 11, 23, 0, 0, 0, time.Local)
+
+	stats, err := store.GetMCPServerStatsAggregated(ctx, TimeFilter{From: day1, To: day2})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 server, got %d", len(stats))
+	}
+
+	st := stats[0]
+	if st.P50LatencyMs < 95 || st.P50LatencyMs > 105 {
+		t.Errorf("expected p50 ~100ms, got %.1f", st.P50LatencyMs)
+	}
+	if st.P99LatencyMs < 3800 || st.P99LatencyMs > 4200 {
+		t.Errorf("expected p99 ~4000ms, got %.1f", st.P99LatencyMs)
+	}
+
+	// Restricting to day 1 excludes the slow calls entirely
+	stats, err = store.GetMCPServerStatsAggregated(ctx, TimeFilter{From: day1, To: day1})
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].P99LatencyMs > 105 {
+		t.Errorf("expected day 1 p99 ~100ms, got %+v", stats)
+	}
+}
+
+func TestLatencyPercentilesWithinDay(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	// Fast calls in the morning, slow calls in the afternoon of the same day
+	y, m, d := time.Now().AddDate(0, 0, -1).Date()
+	morning := time.Date(y, m, d, 10, 0, 0, 0, time.Local)
+	afternoon := time.Date(y, m, d, 14, 0, 0, 0, time.Local)
+	for i := 0; i < 10; i++ {
+		for _, e := range []*Event{
+			{SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__get_issue", MCPServer: "github",
+				Success: true, DurationMs: 100, CreatedAt: morning.Add(time.Duration(i) * time.Minute)},
+			{SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__get_issue", MCPServer: "github",
+				Success: true, DurationMs: 4000, CreatedAt: afternoon.Add(time.Duration(i) * time.Minute)},
+		} {
+			if err := store.StoreEvent(ctx, e); err != nil {
+				t.Fatalf("failed to store event: %v", err)
+			}
+		}
+	}
+
+	// The morning alone holds none of the slow calls, in the average or the
+	// percentiles
+	filter := TimeFilter{From: morning.Add(-time.Hour), To: morning.Add(time.Hour)}
+	stats, err := store.GetToolStats(ctx, filter)
+	if err != nil {
+		t.Fatalf("failed to get tool stats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(stats))
+	}
+	if st := stats[0]; st.AvgLatencyMs != 100 || st.P99LatencyMs > 105 {
+		t.Errorf("expected avg and p99 ~100ms, got avg %.1f p99 %.1f", st.AvgLatencyMs, st.P99LatencyMs)
+	}
+}
+
 func TestGetToolStats(t *testing.T) {
 	store := createTestStore(t)
 	defer store.Close()
@@ -244,6 +336,64 @@ func TestGetToolStats(t *testing.T) {
 	}
 }
 
+func TestIngestPathsShareReadModel(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// Hook processor path
+	if err := store.StoreEvent(ctx, &Event{
+		SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__get_issue",
+		MCPServer: "github", Success: true, DurationMs: 100, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to store event: %v", err)
+	}
+
+	// Sync engine path
+	if err := store.InsertEvent(ctx, &Event{
+		SessionID: "s2", EventType: "PostToolUse", ToolName: "mcp__github__get_issue",
+		MCPServer: "github", DurationMs: 300, CreatedAt: now.UTC(),
+	}); err != nil {
+		t.Fatalf("failed to insert event: %v", err)
+	}
+	if err := store.UpsertCallRollups(ctx, now.UTC(), "mcp__github__get_issue", "github", 1, 1, 300); err != nil {
+		t.Fatalf("failed to upsert call rollups: %v", err)
+	}
+
+	filter := TimeFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour)}
+
+	raw, err := store.GetMCPServerStats(ctx, filter)
+	if err != nil {
+		t.Fatalf("failed to get MCP stats: %v", err)
+	}
+	agg, err := store.GetMCPServerStatsAggregated(ctx, filter)
+	if err != nil {
+		t.Fatalf("failed to get aggregated MCP stats: %v", err)
+	}
+	if len(raw) != 1 || len(agg) != 1 {
+		t.Fatalf("expected one server from both queries, got %d and %d", len(raw), len(agg))
+	}
+	if raw[0].TotalCalls != 2 || raw[0].ErrorCount != 1 {
+		t.Errorf("expected 2 calls and 1 error from events, got %+v", raw[0])
+	}
+	if agg[0].TotalCalls != raw[0].TotalCalls || agg[0].ErrorCount != raw[0].ErrorCount {
+		t.Errorf("tool_stats and events disagree: %+v vs %+v", agg[0], raw[0])
+	}
+	if raw[0].LastUsedAt.IsZero() {
+		t.Error("expected last used time from events")
+	}
+
+	recent, err := store.GetRecentEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("failed to get recent events: %v", err)
+	}
+	if len(recent) != 1 || recent[0].SessionID != "s1" {
+		t.Errorf("expected StoreEvent to feed recent events, got %+v", recent)
+	}
+}
+
 func TestCleanup(t *testing.T) {
 	store := createTestStore(t)
 	defer store.Close()
@@ -282,6 +432,92 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestCleanup_UTCCutoff(t *testing.T) {
+	// Stored times are local, so a UTC cutoff only works once normalized
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	for i, at := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour)} {
+		id := fmt.Sprintf("%d", i)
+		if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "PostToolUse", ToolName: "Read", CreatedAt: at}); err != nil {
+			t.Fatalf("StoreEvent failed: %v", err)
+		}
+		if err := store.StoreProxyCalls(ctx, []ProxyCall{{ServerName: "github", Kind: ProxyRequest, CreatedAt: at}}); err != nil {
+			t.Fatalf("StoreProxyCalls failed: %v", err)
+		}
+		err := store.StoreUsage(ctx,
+			[]TurnUsage{{MessageID: "msg_" + id, SessionID: "s1", CostUSD: 1, CreatedAt: at}},
+			[]ToolCallCost{{ToolUseID: "toolu_" + id, SessionID: "s1", ToolName: "Read", CreatedAt: at}})
+		if err != nil {
+			t.Fatalf("StoreUsage failed: %v", err)
+		}
+	}
+
+	if _, err := store.Cleanup(ctx, now.UTC()); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if _, err := store.CleanupUsage(ctx, now.UTC()); err != nil {
+		t.Fatalf("CleanupUsage failed: %v", err)
+	}
+
+	for _, table := range []string{"events", "proxy_calls", "turn_usage", "tool_call_costs"} {
+		var count int
+		if err := store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			t.Fatalf("counting %s: %v", table, err)
+		}
+		if count != 1 {
+			t.Errorf("expected only the row after the cutoff left in %s, got %d", table, count)
+		}
+	}
+}
+
+func TestTimeFilter_UTCBounds(t *testing.T) {
+	// Stored times are local, so UTC bounds only match once normalized
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = loc
+
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "PostToolUse", ToolName: "mcp__github__search",
+		MCPServer: "github", Success: true, DurationMs: 100, CreatedAt: now.Add(-30 * time.Minute)}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+	if err := store.StoreUsage(ctx, []TurnUsage{{MessageID: "msg_1", SessionID: "s1", CostUSD: 2,
+		CreatedAt: now.Add(-30 * time.Minute)}}, nil); err != nil {
+		t.Fatalf("StoreUsage failed: %v", err)
+	}
+
+	filter := TimeFilter{From: now.Add(-time.Hour).UTC(), To: now.UTC()}
+	stats, err := store.GetMCPServerStats(ctx, filter)
+	if err != nil || len(stats) != 1 {
+		t.Errorf("expected 1 server in a UTC range, got %+v (%v)", stats, err)
+	}
+	events, err := store.GetEvents(ctx, EventFilter{TimeFilter: filter})
+	if err != nil || len(events) != 1 {
+		t.Errorf("expected 1 event in a UTC range, got %d (%v)", len(events), err)
+	}
+	sessions, err := store.GetSessions(ctx, SessionFilter{TimeFilter: filter})
+	if err != nil || len(sessions) != 1 {
+		t.Errorf("expected 1 session in a UTC range, got %d (%v)", len(sessions), err)
+	}
+	summary, err := store.GetCostSummary(ctx, filter)
+	if err != nil || summary.TotalCostUSD != 2 {
+		t.Errorf("expected $2 in a UTC range, got %+v (%v)", summary, err)
+	}
+}
+
 func TestGetCostSummary(t *testing.T) {
 	store := createTestStore(t)
 	defer store.Close()
@@ -11,6 +11,7 @@ type Store interface {
 	// Event operations
 	StoreEvent(ctx context.Context, event *Event) error
 	GetEvents(ctx context.Context, filter EventFilter) ([]Event, error)
+	SearchEvents(ctx context.Context, query SearchQuery) ([]SearchResult, error)
 
 	// Session operations
 	GetSession(ctx context.Context, sessionID string) (*Session, error)
@@ -19,10 +20,39 @@ type Store interface {
 	// MCP metrics operations
 	GetMCPServerStats(ctx context.Context, filter TimeFilter) ([]MCPServerStats, error)
 	GetToolStats(ctx context.Context, filter TimeFilter) ([]ToolStats, error)
+	GetHourlyRollups(ctx context.Context, filter TimeFilter) ([]HourlyRollup, error)
+	GetToolLatencies(ctx context.Context, filter TimeFilter) ([]ToolLatency, error)
+	GetToolResponseSizes(ctx context.Context, filter TimeFilter) ([]ToolResponseSize, error)
 
 	// Cost operations
 	GetCostSummary(ctx context.Context, filter TimeFilter) (*CostSummary, error)
 	GetCostByModel(ctx context.Context, filter TimeFilter) ([]ModelCost, error)
+	GetDailyCosts(ctx context.Context, filter TimeFilter) ([]DailyCost, error)
+	GetCostByServer(ctx context.Context, filter TimeFilter) ([]ServerCost, error)
+	GetCostByTool(ctx context.Context, filter TimeFilter) ([]ToolCost, error)
+	StoreUsage(ctx context.Context, turns []TurnUsage, calls []ToolCallCost) error
+
+	// Error operations
+	GetErrorClusters(ctx context.Context, filter ErrorClusterFilter) ([]ErrorCluster, error)
+	GetErrorMessages(ctx context.Context, filter TimeFilter) ([]ErrorMessageCount, error)
+
+	// Probe operations
+	StoreProbeResult(ctx context.Context, result *ProbeResult) error
+	GetLatestProbes(ctx context.Context, host string) ([]ProbeResult, error)
+	ReplaceToolDefinitions(ctx context.Context, serverName string, tools []ToolDefinition) error
+	GetToolDefinitions(ctx context.Context, host string) ([]ToolDefinition, error)
+
+	// Proxy operations
+	StoreProxyCalls(ctx context.Context, calls []ProxyCall) error
+	GetProxyStats(ctx context.Context, filter TimeFilter) ([]ProxyMethodStats, error)
+	GetProxyFailures(ctx context.Context, filter TimeFilter) ([]ProxyFailureCount, error)
+
+	// Alert operations
+	SaveAlert(ctx context.Context, alert *Alert) error
+	GetActiveAlerts(ctx context.Context) ([]Alert, error)
+	GetAlerts(ctx context.Context, filter AlertFilter) ([]Alert, error)
+	GetAlertRuleStates(ctx context.Context) ([]AlertRuleState, error)
+	SaveAlertRuleState(ctx context.Context, state *AlertRuleState) error
 
 	// TUI-specific operations (v2.0)
 	GetRecentEvents(ctx context.Context, limit int) ([]RecentEvent, error)
@@ -35,24 +65,32 @@ type Store interface {
 
 // Event represents a stored hook event.
 type Event struct {
-	ID           int64
-	SessionID    string
-	EventType    string
-	ToolName     string
-	MCPServer    string
-	Success      bool
-	DurationMs   int64
-	InputTokens  int64
-	OutputTokens int64
-	CostUSD      float64
-	RawPayload   []byte
-	CreatedAt    time.Time
+	ID             int64
+	SessionID      string
+	EventType      string
+	ToolName       string
+	MCPServer      string
+	Success        bool
+	DurationMs     int64
+	InputTokens    int64
+	OutputTokens   int64
+	CostUSD        float64
+	RawPayload     []byte
+	ToolInput      string // Redacted tool input, indexed for search
+	Error          string // Redacted error text, indexed for search
+	ErrorSignature string // Normalized error text of a failed tool call
+	ResponseBytes  int64  // Length of the tool response; 0 when not recorded
+	ResponseTokens int64  // Estimated tokens of the tool response
+	Cwd            string
+	Host           string // Machine the event was recorded on
+	CreatedAt      time.Time
 }
 
 // Session represents a Claude Code session.
 type Session struct {
 	ID           string
 	Cwd          string
+	Host         string
 	StartedAt    time.Time
 	EndedAt      *time.Time
 	TotalEvents  int
@@ -81,28 +119,144 @@ type ToolStats struct {
 	SuccessCount int64
 	ErrorCount   int64
 	AvgLatencyMs float64
+	P50LatencyMs float64
+	P90LatencyMs float64
+	P99LatencyMs float64
+
+	// Estimated tokens of the tool's responses, where recorded
+	AvgResponseTokens float64
+	P50ResponseTokens float64
+	P90ResponseTokens float64
+	P99ResponseTokens float64
 }
 
 // CostSummary holds aggregated cost metrics.
 type CostSummary struct {
-	TotalTokens  int64
-	InputTokens  int64
-	OutputTokens int64
-	TotalCostUSD float64
+	TotalTokens         int64
+	InputTokens         int64 // Uncached input tokens
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	TotalCostUSD        float64
 }
 
 // ModelCost holds cost breakdown by model.
 type ModelCost struct {
-	Model        string
-	InputTokens  int64
-	OutputTokens int64
-	TotalCostUSD float64
+	Model               string
+	InputTokens         int64 // Uncached input tokens
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	TotalCostUSD        float64
+}
+
+// DailyCost is the spend on one local calendar day.
+type DailyCost struct {
+	Date    time.Time // Local midnight
+	CostUSD float64
+}
+
+// Alert is one incident of an alert condition, from when it fired until it
+// resolved. Key identifies the condition, such as "budget:daily" or
+// "error_rate:github"; at most one incident per key is active.
+type Alert struct {
+	ID            int64
+	Key           string
+	Kind          string // "budget", "error_rate", "latency", "rule"
+	Subject       string // Budget period or server name
+	Rule          string // Name of the rule that raised it, for rule alerts
+	Severity      string // "warning" or "critical"
+	Message       string
+	Value         float64
+	Threshold     float64
+	FiredAt       time.Time
+	UpdatedAt     time.Time // Last evaluated
+	NotifiedAt    time.Time // Zero if never notified
+	Notifications int
+	ResolvedAt    time.Time // Zero while active
+}
+
+// Active reports whether the alert has not resolved.
+func (a *Alert) Active() bool {
+	return a.ResolvedAt.IsZero()
+}
+
+// AlertRuleState is the state of an alert rule's condition between
+// evaluations, under the key its alert would have.
+type AlertRuleState struct {
+	Key           string
+	Rule          string
+	HoldingSince  time.Time // Held since, while not yet firing; zero otherwise
+	ClearingSince time.Time // Stopped holding since, while still firing; zero otherwise
+}
+
+// AlertFilter selects alerts fired in a range, newest first.
+type AlertFilter struct {
+	TimeFilter
+	ActiveOnly bool
+	Limit      int
+}
+
+// TurnUsage is the token usage and cost of one model response, read from a
+// session transcript.
+type TurnUsage struct {
+	MessageID           string
+	SessionID           string
+	Model               string
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	CostUSD             float64
+	Host                string
+	CreatedAt           time.Time
+}
+
+// ToolCallCost is the spend attributed to one tool call: the input tokens
+// its result added to the next model response, and its share of that
+// response's output.
+type ToolCallCost struct {
+	ToolUseID       string
+	SessionID       string
+	ToolName        string
+	ServerName      string // Empty for built-in tools
+	Model           string // Model of the response that read the result
+	ResultBytes     int64
+	InputTokens     int64
+	InputCostUSD    float64
+	FollowUpCostUSD float64
+	Host            string
+	CreatedAt       time.Time
+}
+
+// ServerCost is the spend attributed to an MCP server's tool calls.
+type ServerCost struct {
+	ServerName      string
+	Calls           int64
+	InputTokens     int64   // Tokens of context added by tool results
+	InputCostUSD    float64 // Those tokens at input prices
+	FollowUpCostUSD float64 // Share of the output of the responses that read them
+	TotalCostUSD    float64
+	SharePct        float64 // Percentage of all spend in the range
+}
+
+// ToolCost is the spend attributed to one tool's calls.
+type ToolCost struct {
+	ToolName        string
+	ServerName      string // Empty for built-in tools
+	Calls           int64
+	InputTokens     int64
+	InputCostUSD    float64
+	FollowUpCostUSD float64
+	TotalCostUSD    float64
+	SharePct        float64 // Percentage of all spend in the range
 }
 
 // TimeFilter specifies a time range for queries.
 type TimeFilter struct {
 	From time.Time
 	To   time.Time
+	Host string // Only rows recorded on this host; empty for all hosts
 }
 
 // EventFilter specifies criteria for event queries.
@@ -116,6 +270,19 @@ type EventFilter struct {
 	Offset     int
 }
 
+// SearchQuery specifies a full-text search over event tool inputs, errors,
+// and working directories, narrowed by the usual event filters.
+type SearchQuery struct {
+	EventFilter
+	Query string // Words and "quoted phrases", all of which must match
+}
+
+// SearchResult is an event matching a search, best match first.
+type SearchResult struct {
+	Event
+	Snippet string // Matching text with hits wrapped in [ ]
+}
+
 // SessionFilter specifies criteria for session queries.
 type SessionFilter struct {
 	TimeFilter
@@ -124,6 +291,144 @@ type SessionFilter struct {
 	Offset int
 }
 
+// ErrorClusterFilter specifies criteria for error cluster queries.
+type ErrorClusterFilter struct {
+	TimeFilter
+	MCPServer string // Only clusters for this server; empty for all
+	Limit     int    // Clusters returned, largest first; default 10
+	Examples  int    // Example calls per cluster, newest first; default 3
+}
+
+// ErrorCluster groups failed tool calls to one server whose error messages
+// normalize to the same signature.
+type ErrorCluster struct {
+	MCPServer string
+	Signature string
+	Count     int64
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Examples  []Event
+}
+
+// ErrorMessageCount is the number of failed calls to one server that
+// reported the same error message.
+type ErrorMessageCount struct {
+	MCPServer string
+	Error     string
+	Count     int64
+}
+
+// ProbeResult is the outcome of health-checking a configured MCP server by
+// launching it, or connecting to it, and performing the MCP handshake.
+type ProbeResult struct {
+	ID              int64
+	ServerName      string // Name the server's tools are recorded under
+	Transport       string // stdio or http
+	StartupMs       int64  // Launch or first request until initialize was answered
+	HandshakeMs     int64  // tools/list round trip once initialized
+	InfoName        string // Name the server reported
+	InfoVersion     string // Version the server reported
+	ProtocolVersion string
+	ToolCount       int
+	Tools           []ToolDefinition // Tools the server listed; not stored with the result
+	Error           string           // Empty when the probe succeeded
+	Host            string
+	ProbedAt        time.Time
+}
+
+// OK reports whether the probe succeeded.
+func (p ProbeResult) OK() bool {
+	return p.Error == ""
+}
+
+// ToolDefinition is a tool as a server lists it in tools/list, with an
+// estimate of the context tokens its definition takes up in every session
+// the server is loaded into.
+type ToolDefinition struct {
+	ServerName  string
+	ToolName    string // Name as the server lists it, without the mcp__ prefix
+	Description string
+	InputSchema string // JSON schema of the tool's arguments
+	Tokens      int    // Estimated tokens of the whole definition
+	Host        string
+	CapturedAt  time.Time
+}
+
+// Proxy call kinds.
+const (
+	ProxyRequest      = "request"      // A request and its response
+	ProxyNotification = "notification" // A notification, which has no response
+	ProxyStderr       = "stderr"       // A line the server wrote to stderr
+)
+
+// Proxy call failure classes, from the JSON-RPC layer down to the transport.
+const (
+	FailureRPC        = "rpc_error"   // A JSON-RPC error response
+	FailureTool       = "tool_error"  // A tools/call result with isError set
+	FailureCancelled  = "cancelled"   // The sender cancelled the request
+	FailureNoResponse = "no_response" // The connection ended before the response
+	FailureHTTPStatus = "http_status" // The upstream answered with an error status
+	FailureTLS        = "tls"         // The TLS handshake or certificate check failed
+	FailureConnect    = "connect"     // The upstream could not be reached or dropped the connection
+	FailureTimeout    = "timeout"     // The upstream did not answer in time
+)
+
+// ProxyCall is a message exchange with an MCP server seen by the mcp-lens
+// proxy: a JSON-RPC request and its response, a notification, or a line of
+// the server's stderr.
+type ProxyCall struct {
+	ID            int64
+	ServerName    string
+	Kind          string // ProxyRequest, ProxyNotification or ProxyStderr
+	Direction     string // "client" when sent by Claude Code, "server" when sent by the server
+	Method        string
+	ToolName      string // Tool called by tools/call, as the server names it
+	DurationUs    int64  // Request read from its sender until its response was read
+	RequestBytes  int64
+	ResponseBytes int64
+	ErrorCode     int    // JSON-RPC error code; 0 when none
+	ToolError     bool   // A tools/call result with isError set
+	HTTPStatus    int    // Upstream HTTP status of an http proxy; 0 for stdio
+	Failure       string // Failure class of a request that failed; empty otherwise
+	Error         string // Error message, or the stderr line
+	Host          string
+	CreatedAt     time.Time // When the request or notification was sent
+}
+
+// OK reports whether a request succeeded.
+func (c ProxyCall) OK() bool {
+	return c.Failure == "" && c.ErrorCode == 0 && !c.ToolError && c.Error == ""
+}
+
+// ProxyMethodStats aggregates proxy calls by server, kind, direction, method
+// and tool.
+type ProxyMethodStats struct {
+	ServerName       string
+	Kind             string
+	Direction        string
+	Method           string
+	ToolName         string
+	Calls            int64
+	Errors           int64
+	AvgLatencyMs     float64
+	MaxLatencyMs     float64
+	AvgRequestBytes  float64
+	AvgResponseBytes float64
+	MaxResponseBytes int64
+	LastSeenAt       time.Time
+}
+
+// ProxyFailureCount counts a server's failed proxy calls of one class and
+// HTTP status.
+type ProxyFailureCount struct {
+	ServerName string
+	Failure    string
+	HTTPStatus int
+	Count      int64
+	LastError  string
+	LastSeenAt time.Time
+}
+
 // RecentEvent represents an event in the recent events circular buffer.
 type RecentEvent struct {
 	ID         int64
@@ -143,6 +448,16 @@ type HourlyCallVolume struct {
 	Errors     int64
 }
 
+// HourlyRollup holds the calls to one tool in one hour.
+type HourlyRollup struct {
+	Hour           time.Time // Start of the hour, local time
+	ToolName       string
+	MCPServer      string // Empty for built-in tools
+	Calls          int64
+	Errors         int64
+	TotalLatencyMs int64
+}
+
 // AggregatedMCPStats holds aggregated stats for MCP servers from tool_stats table.
 type AggregatedMCPStats struct {
 	ServerName     string
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StoreUsage records the usage of model responses and the spend attributed
+// to tool calls, replacing rows already recorded for the same message or
+// tool_use ID. The token and cost totals of each session involved are
+// recomputed from its responses.
+func (s *SQLiteStore) StoreUsage(ctx context.Context, turns []TurnUsage, calls []ToolCallCost) error {
+	if len(turns) == 0 && len(calls) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sessions := make(map[string]bool)
+	for i := range turns {
+		t := &turns[i]
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO turn_usage (message_id, host, session_id, model, input_tokens, output_tokens,
+				cache_creation_tokens, cache_read_tokens, cost_usd, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(message_id, host) DO UPDATE SET
+				session_id = excluded.session_id,
+				model = excluded.model,
+				input_tokens = excluded.input_tokens,
+				output_tokens = excluded.output_tokens,
+				cache_creation_tokens = excluded.cache_creation_tokens,
+				cache_read_tokens = excluded.cache_read_tokens,
+				cost_usd = excluded.cost_usd,
+				created_at = excluded.created_at`,
+			t.MessageID, s.host, t.SessionID, t.Model, t.InputTokens, t.OutputTokens,
+			t.CacheCreationTokens, t.CacheReadTokens, t.CostUSD, t.CreatedAt.Local()); err != nil {
+			return fmt.Errorf("inserting turn usage: %w", err)
+		}
+		t.Host = s.host
+		sessions[t.SessionID] = true
+	}
+
+	for i := range calls {
+		c := &calls[i]
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tool_call_costs (tool_use_id, host, session_id, tool_name, server_name, model,
+				result_bytes, input_tokens, input_cost_usd, followup_cost_usd, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(tool_use_id, host) DO UPDATE SET
+				session_id = excluded.session_id,
+				tool_name = excluded.tool_name,
+				server_name = excluded.server_name,
+				model = excluded.model,
+				result_bytes = excluded.result_bytes,
+				input_tokens = excluded.input_tokens,
+				input_cost_usd = excluded.input_cost_usd,
+				followup_cost_usd = excluded.followup_cost_usd,
+				created_at = excluded.created_at`,
+			c.ToolUseID, s.host, c.SessionID, c.ToolName, c.ServerName, c.Model,
+			c.ResultBytes, c.InputTokens, c.InputCostUSD, c.FollowUpCostUSD, c.CreatedAt.Local()); err != nil {
+			return fmt.Errorf("inserting tool call cost: %w", err)
+		}
+		c.Host = s.host
+	}
+
+	for id := range sessions {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE sessions SET
+				total_tokens = (
+					SELECT COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0)
+					FROM turn_usage WHERE session_id = sessions.id),
+				total_cost_usd = (
+					SELECT COALESCE(SUM(cost_usd), 0) FROM turn_usage WHERE session_id = sessions.id)
+			WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("updating session totals: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing usage: %w", err)
+	}
+	return nil
+}
+
+// GetCostByServer returns the spend attributed to each MCP server's tool
+// calls, most expensive first. Built-in tools are left out.
+func (s *SQLiteStore) GetCostByServer(ctx context.Context, filter TimeFilter) ([]ServerCost, error) {
+	rows, total, err := s.attributedCosts(ctx, filter, "server_name", " AND server_name != ''")
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make([]ServerCost, 0, len(rows))
+	for _, r := range rows {
+		costs = append(costs, ServerCost{
+			ServerName:      r.serverName,
+			Calls:           r.calls,
+			InputTokens:     r.inputTokens,
+			InputCostUSD:    r.inputCost,
+			FollowUpCostUSD: r.followUpCost,
+			TotalCostUSD:    r.inputCost + r.followUpCost,
+			SharePct:        sharePct(r.inputCost+r.followUpCost, total),
+		})
+	}
+	return costs, nil
+}
+
+// GetCostByTool returns the spend attributed to each tool's calls, built-in
+// tools included, most expensive first.
+func (s *SQLiteStore) GetCostByTool(ctx context.Context, filter TimeFilter) ([]ToolCost, error) {
+	rows, total, err := s.attributedCosts(ctx, filter, "tool_name, server_name", "")
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make([]ToolCost, 0, len(rows))
+	for _, r := range rows {
+		costs = append(costs, ToolCost{
+			ToolName:        r.toolName,
+			ServerName:      r.serverName,
+			Calls:           r.calls,
+			InputTokens:     r.inputTokens,
+			InputCostUSD:    r.inputCost,
+			FollowUpCostUSD: r.followUpCost,
+			TotalCostUSD:    r.inputCost + r.followUpCost,
+			SharePct:        sharePct(r.inputCost+r.followUpCost, total),
+		})
+	}
+	return costs, nil
+}
+
+// attributedCost is one group of tool call costs.
+type attributedCost struct {
+	toolName     string
+	serverName   string
+	calls        int64
+	inputTokens  int64
+	inputCost    float64
+	followUpCost float64
+}
+
+// attributedCosts sums tool call costs grouped by groupBy, most expensive
+// first, and returns them with the total spend in the range.
+func (s *SQLiteStore) attributedCosts(ctx context.Context, filter TimeFilter, groupBy string, where string) ([]attributedCost, float64, error) {
+	query := `
+		SELECT MAX(tool_name), server_name, COUNT(*), COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(input_cost_usd), 0), COALESCE(SUM(followup_cost_usd), 0)
+		FROM tool_call_costs
+		WHERE 1=1` + where
+
+	var args []interface{}
+	query, args = appendTimeRange(query, args, "created_at", filter)
+	query, args = appendHostFilter(query, args, filter)
+	query += " GROUP BY " + groupBy + `
+		ORDER BY SUM(input_cost_usd) + SUM(followup_cost_usd) DESC, SUM(input_tokens) DESC, ` + groupBy
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying tool call costs: %w", err)
+	}
+	defer rows.Close()
+
+	var result []attributedCost
+	for rows.Next() {
+		var r attributedCost
+		if err := rows.Scan(&r.toolName, &r.serverName, &r.calls, &r.inputTokens, &r.inputCost, &r.followUpCost); err != nil {
+			return nil, 0, fmt.Errorf("scanning tool call costs: %w", err)
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	summary, err := s.GetCostSummary(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, summary.TotalCostUSD, nil
+}
+
+// CleanupUsage removes transcript usage and tool call costs older than the
+// specified time, returning how many rows were deleted. Costs have no
+// rollup, so they are kept past the raw events for month-to-date spend,
+// budgets and forecasts; see RetentionPolicy.UsageDays.
+func (s *SQLiteStore) CleanupUsage(ctx context.Context, olderThan time.Time) (int64, error) {
+	// created_at is stored as local time text, so the cutoff must be too
+	cutoff := olderThan.Local()
+
+	var deleted int64
+	for _, table := range []string{"turn_usage", "tool_call_costs"} {
+		result, err := s.db.ExecContext(ctx,
+			fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", table), cutoff)
+		if err != nil {
+			return deleted, fmt.Errorf("deleting old %s: %w", table, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("getting rows affected: %w", err)
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// sharePct returns part as a percentage of total, or 0 when total is 0.
+func sharePct(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return part / total * 100
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "SessionStart", CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+
+	turns := []TurnUsage{
+		{MessageID: "msg_1", SessionID: "s1", Model: "claude-sonnet-4-5", InputTokens: 10, OutputTokens: 100,
+			CacheCreationTokens: 1000, CacheReadTokens: 5000, CostUSD: 6, CreatedAt: now.Add(-time.Hour)},
+		{MessageID: "msg_2", SessionID: "s1", Model: "claude-sonnet-4-5", InputTokens: 10, OutputTokens: 100,
+			CostUSD: 3, CreatedAt: now.Add(-50 * time.Minute)},
+		{MessageID: "msg_3", SessionID: "s1", Model: "claude-haiku-4-5", OutputTokens: 10,
+			CostUSD: 1, CreatedAt: now.Add(-40 * time.Minute)},
+		// Outside the range
+		{MessageID: "msg_old", SessionID: "s0", Model: "claude-opus-4-1", OutputTokens: 10,
+			CostUSD: 100, CreatedAt: now.Add(-72 * time.Hour)},
+	}
+	calls := []ToolCallCost{
+		{ToolUseID: "toolu_1", SessionID: "s1", ToolName: "mcp__github__search", ServerName: "github",
+			InputTokens: 800, InputCostUSD: 1, FollowUpCostUSD: 1, CreatedAt: now.Add(-55 * time.Minute)},
+		{ToolUseID: "toolu_2", SessionID: "s1", ToolName: "mcp__github__get_issue", ServerName: "github",
+			InputTokens: 200, InputCostUSD: 0.5, FollowUpCostUSD: 0.5, CreatedAt: now.Add(-45 * time.Minute)},
+		{ToolUseID: "toolu_3", SessionID: "s1", ToolName: "mcp__web__fetch", ServerName: "web",
+			InputTokens: 3000, InputCostUSD: 2.5, FollowUpCostUSD: 0.5, CreatedAt: now.Add(-45 * time.Minute)},
+		{ToolUseID: "toolu_4", SessionID: "s1", ToolName: "Read",
+			InputTokens: 100, InputCostUSD: 0.1, FollowUpCostUSD: 0.1, CreatedAt: now.Add(-45 * time.Minute)},
+	}
+	if err := store.StoreUsage(ctx, turns, calls); err != nil {
+		t.Fatalf("StoreUsage failed: %v", err)
+	}
+	// Reading the same transcript again replaces rows rather than adding them
+	if err := store.StoreUsage(ctx, turns[:1], calls[:1]); err != nil {
+		t.Fatalf("StoreUsage failed: %v", err)
+	}
+
+	filter := TimeFilter{From: now.Add(-24 * time.Hour)}
+	summary, err := store.GetCostSummary(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCostSummary failed: %v", err)
+	}
+	if summary.TotalCostUSD != 10 || summary.InputTokens != 20 || summary.OutputTokens != 210 ||
+		summary.CacheCreationTokens != 1000 || summary.CacheReadTokens != 5000 || summary.TotalTokens != 6230 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	byModel, err := store.GetCostByModel(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCostByModel failed: %v", err)
+	}
+	if len(byModel) != 2 || byModel[0].Model != "claude-sonnet-4-5" || byModel[0].TotalCostUSD != 9 || byModel[0].CacheReadTokens != 5000 {
+		t.Errorf("unexpected cost by model %+v", byModel)
+	}
+
+	byServer, err := store.GetCostByServer(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCostByServer failed: %v", err)
+	}
+	wantServers := []ServerCost{
+		{ServerName: "web", Calls: 1, InputTokens: 3000, InputCostUSD: 2.5, FollowUpCostUSD: 0.5, TotalCostUSD: 3, SharePct: 30},
+		{ServerName: "github", Calls: 2, InputTokens: 1000, InputCostUSD: 1.5, FollowUpCostUSD: 1.5, TotalCostUSD: 3, SharePct: 30},
+	}
+	if len(byServer) != len(wantServers) {
+		t.Fatalf("expected %d servers, got %+v", len(wantServers), byServer)
+	}
+	for i, w := range wantServers {
+		got := byServer[i]
+		if got.ServerName != w.ServerName || got.Calls != w.Calls || got.InputTokens != w.InputTokens ||
+			got.TotalCostUSD != w.TotalCostUSD || math.Abs(got.SharePct-w.SharePct) > 1e-9 {
+			t.Errorf("byServer[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	byTool, err := store.GetCostByTool(ctx, filter)
+	if err != nil {
+		t.Fatalf("GetCostByTool failed: %v", err)
+	}
+	if len(byTool) != 4 || byTool[0].ToolName != "mcp__web__fetch" || byTool[3].ToolName != "Read" || byTool[3].ServerName != "" {
+		t.Errorf("unexpected cost by tool %+v", byTool)
+	}
+
+	session, err := store.GetSession(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if session.TotalCostUSD != 10 || session.TotalTokens != 6230 {
+		t.Errorf("expected session totals from its usage, got %d tokens $%.2f", session.TotalTokens, session.TotalCostUSD)
+	}
+
+	// Usage outlives the raw events until its own cleanup
+	if _, err := store.Cleanup(ctx, now.Add(-24*time.Hour)); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	all, err := store.GetCostSummary(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetCostSummary failed: %v", err)
+	}
+	if all.TotalCostUSD != 110 {
+		t.Errorf("expected usage kept by Cleanup, got $%.2f", all.TotalCostUSD)
+	}
+	deleted, err := store.CleanupUsage(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("CleanupUsage failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 usage row removed, got %d", deleted)
+	}
+	all, err = store.GetCostSummary(ctx, TimeFilter{})
+	if err != nil {
+		t.Fatalf("GetCostSummary failed: %v", err)
+	}
+	if all.TotalCostUSD != 10 {
+		t.Errorf("expected usage older than the cutoff to be removed, got $%.2f", all.TotalCostUSD)
+	}
+
+	other, err := store.GetCostByServer(ctx, TimeFilter{Host: "elsewhere"})
+	if err != nil {
+		t.Fatalf("GetCostByServer failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no costs from another host, got %+v", other)
+	}
+}
+
+func TestGetDailyCosts(t *testing.T) {
+	store := createTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	if err := store.StoreEvent(ctx, &Event{SessionID: "s1", EventType: "Stop", CostUSD: 0.5, CreatedAt: day.Add(23 * time.Hour)}); err != nil {
+		t.Fatalf("StoreEvent failed: %v", err)
+	}
+	turns := []TurnUsage{
+		{MessageID: "msg_1", SessionID: "s1", CostUSD: 2, CreatedAt: day.Add(time.Hour)},
+		{MessageID: "msg_2", SessionID: "s1", CostUSD: 3, CreatedAt: day.Add(23*time.Hour + 30*time.Minute)},
+		{MessageID: "msg_3", SessionID: "s1", CostUSD: 4, CreatedAt: day.AddDate(0, 0, 2).Add(time.Hour)},
+		{MessageID: "msg_4", SessionID: "s1", CostUSD: 0, CreatedAt: day.AddDate(0, 0, 3).Add(time.Hour)},
+	}
+	if err := store.StoreUsage(ctx, turns, nil); err != nil {
+		t.Fatalf("StoreUsage failed: %v", err)
+	}
+
+	costs, err := store.GetDailyCosts(ctx, TimeFilter{From: day.AddDate(0, 0, -1)})
+	if err != nil {
+		t.Fatalf("GetDailyCosts failed: %v", err)
+	}
+	// Days without spend are left out
+	want := []DailyCost{{Date: day, CostUSD: 5.5}, {Date: day.AddDate(0, 0, 2), CostUSD: 4}}
+	if len(costs) != len(want) {
+		t.Fatalf("expected %d days, got %+v", len(want), costs)
+	}
+	for i, w := range want {
+		if !costs[i].Date.Equal(w.Date) || math.Abs(costs[i].CostUSD-w.CostUSD) > 1e-9 {
+			t.Errorf("day %d = %+v, want %+v", i, costs[i], w)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package tokens estimates how many tokens text takes up in a model's
+// context window without a tokenizer. Estimates are meant for comparing and
+// ranking, within roughly 20% of a real BPE tokenizer on English prose and
+// JSON.
+package tokens
+
+import (
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BytesPerToken is the average length of a token in tool output, for
+// estimating text known only by its length.
+const BytesPerToken = 4
+
+// ToolOverhead is the tokens a tool definition costs beyond its name,
+// description and schema, for the framing around each tool in the prompt.
+const ToolOverhead = 8
+
+// Estimate approximates the token count of text. Runs of ASCII letters cost
+// one token per five characters and runs of digits one per three, since
+// tokenizers split long numbers; runs of punctuation cost one token per two
+// characters, as common pairs like `":` and `},` are single tokens; any other
+// character costs a token of its own. Whitespace is absorbed into the
+// following token.
+func Estimate(text string) int {
+	total := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case r < utf8.RuneSelf && isASCIILetter(byte(r)):
+			n := runLength(text[i:], isASCIILetter)
+			total += (n + 4) / 5
+			i += n
+		case r < utf8.RuneSelf && isDigit(byte(r)):
+			n := runLength(text[i:], isDigit)
+			total += (n + 2) / 3
+			i += n
+		case unicode.IsSpace(r):
+			i += size
+		case r < utf8.RuneSelf:
+			n := runLength(text[i:], isASCIIPunct)
+			total += (n + 1) / 2
+			i += n
+		default:
+			total++
+			i += size
+		}
+	}
+	return total
+}
+
+// EstimateTool approximates the tokens a tool definition takes up: its name,
+// description and JSON input schema, plus ToolOverhead.
+func EstimateTool(name, description, inputSchema string) int {
+	return Estimate(name) + Estimate(description) + Estimate(inputSchema) + ToolOverhead
+}
+
+// EstimateBytes approximates the token count of text from its length in
+// bytes alone.
+func EstimateBytes(n int64) int64 {
+	return (n + BytesPerToken - 1) / BytesPerToken
+}
+
+// Response measures a tool response as JSON appears in a hook payload,
+// returning its length in bytes and estimated tokens. A JSON string is
+// measured as the text it holds; anything else as its JSON.
+func Response(raw json.RawMessage) (bytes int64, estimate int64) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, 0
+	}
+	text := string(raw)
+	if raw[0] == '"' {
+		if json.Unmarshal(raw, &text) != nil {
+			text = string(raw)
+		}
+	}
+	return int64(len(text)), int64(Estimate(text))
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isASCIIPunct(b byte) bool {
+	return b < utf8.RuneSelf && !isASCIILetter(b) && !isDigit(b) && b != ' ' && b != '\t' && b != '\n' && b != '\r'
+}
+
+// runLength returns how many leading bytes of s satisfy in.
+func runLength(s string, in func(byte) bool) int {
+	n := 0
+	for n < len(s) && in(s[n]) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package tokens
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"   \n\t", 0},
+		{"hello", 1},
+		{"repositories", 3},
+		{"the quick brown fox", 4},
+		{"1234567", 3},
+		{`{"a":1}`, 5},
+		{"naïve", 3},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := Estimate(tt.text); got != tt.want {
+			t.Errorf("Estimate(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate_ScalesWithLength(t *testing.T) {
+	// English prose runs at about four characters per token
+	prose := "Search for code across all repositories the user can access, returning matching files with line numbers."
+	if got := Estimate(prose); got < len(prose)/6 || got > len(prose)/3 {
+		t.Errorf("Estimate of %d characters of prose = %d, want about %d", len(prose), got, len(prose)/4)
+	}
+}
+
+func TestEstimateTool(t *testing.T) {
+	schema := `{"type":"object","properties":{"query":{"type":"string","description":"Search query"}},"required":["query"]}`
+	got := EstimateTool("search_code", "Search code", schema)
+	want := Estimate("search_code") + Estimate("Search code") + Estimate(schema) + ToolOverhead
+	if got != want {
+		t.Errorf("EstimateTool = %d, want %d", got, want)
+	}
+	if got < 30 || got > 60 {
+		t.Errorf("EstimateTool = %d, expected a schema like this to cost 30-60 tokens", got)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		raw        string
+		wantBytes  int64
+		wantTokens int64
+	}{
+		{``, 0, 0},
+		{`null`, 0, 0},
+		{`"hello world"`, 11, 2},
+		{`"line\nbreak"`, 10, 2},
+		{`[{"type":"text","text":"hi"}]`, 29, int64(Estimate(`[{"type":"text","text":"hi"}]`))},
+	}
+	for _, tt := range tests {
+		bytes, tokens := Response([]byte(tt.raw))
+		if bytes != tt.wantBytes || tokens != tt.wantTokens {
+			t.Errorf("Response(%s) = %d bytes %d tokens, want %d and %d", tt.raw, bytes, tokens, tt.wantBytes, tt.wantTokens)
+		}
+	}
+}
+
+func TestEstimateBytes(t *testing.T) {
+	if got := EstimateBytes(0); got != 0 {
+		t.Errorf("EstimateBytes(0) = %d, want 0", got)
+	}
+	if got := EstimateBytes(4001); got != 1001 {
+		t.Errorf("EstimateBytes(4001) = %d, want 1001", got)
+	}
+}
@@ -0,0 +1,278 @@
+// Package transcript reads token usage from Claude Code session transcripts
+// and attributes it to the tool calls whose results drove it.
+//
+// A transcript is a JSONL file with one line per message. Assistant lines
+// carry the model and the API usage of the response they belong to; a
+// response with several content blocks is split across lines sharing one
+// message ID. User lines carry tool results, each naming the tool_use block
+// it answers.
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/anthropics/mcp-lens/internal/tokens"
+)
+
+// Usage is the token usage of one model response.
+type Usage struct {
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+// ContextTokens returns the tokens of context the response was given,
+// cached or not.
+func (u Usage) ContextTokens() int64 {
+	return u.InputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+// Pricer returns the cost in USD of usage on a model at the prices in
+// effect at a time.
+type Pricer func(model string, at time.Time, usage Usage) float64
+
+// Turn is one model response and what it cost.
+type Turn struct {
+	MessageID string
+	Model     string
+	Timestamp time.Time
+	Usage     Usage
+	CostUSD   float64
+}
+
+// ToolCall is the spend attributed to one tool call: the context its result
+// added to the next turn, and its share of that turn's output.
+type ToolCall struct {
+	ToolUseID       string
+	ToolName        string
+	Model           string    // Model of the follow-up turn
+	Timestamp       time.Time // When the result arrived
+	ResultBytes     int64
+	InputTokens     int64   // Tokens of context the result added
+	InputCostUSD    float64 // InputTokens at the follow-up turn's input price
+	FollowUpCostUSD float64 // Share of the follow-up turn's output cost
+}
+
+// CostUSD returns the total spend attributed to the call.
+func (c ToolCall) CostUSD() float64 {
+	return c.InputCostUSD + c.FollowUpCostUSD
+}
+
+// Transcript is the usage recorded in one session transcript.
+type Transcript struct {
+	SessionID string
+	Turns     []Turn
+	ToolCalls []ToolCall // Calls whose result was followed by a turn
+}
+
+// line is the part of a transcript line that carries usage and tool calls.
+type line struct {
+	Type        string    `json:"type"`
+	SessionID   string    `json:"sessionId"`
+	IsSidechain bool      `json:"isSidechain"`
+	Timestamp   time.Time `json:"timestamp"`
+	Message     struct {
+		ID      string          `json:"id"`
+		Model   string          `json:"model"`
+		Usage   *Usage          `json:"usage"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// block is a content block of a message.
+type block struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`          // tool_use
+	Name      string          `json:"name"`        // tool_use
+	ToolUseID string          `json:"tool_use_id"` // tool_result
+	Content   json.RawMessage `json:"content"`     // tool_result
+}
+
+// ReadFile reads the transcript at path, pricing usage with price.
+func ReadFile(path string, price Pricer) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, price)
+}
+
+// Read reads a transcript, pricing usage with price.
+//
+// The context a tool result added is measured from how much the follow-up
+// turn's context grew over the previous turn's context and output, split
+// across the results it answered in proportion to their length. When the
+// growth is implausible, as after the conversation is compacted, each
+// result's length is used as an estimate instead. Subagent turns count
+// toward spend but not toward attribution, since they follow a separate
+// conversation.
+func Read(r io.Reader, price Pricer) (*Transcript, error) {
+	t := &Transcript{}
+	a := attributor{t: t, price: price, names: make(map[string]string), turns: make(map[string]int), prev: -1, cur: -1}
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			var l line
+			// Lines that are not messages, or cut off mid-write, are skipped
+			if json.Unmarshal(data, &l) == nil {
+				if t.SessionID == "" {
+					t.SessionID = l.SessionID
+				}
+				a.add(&l)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading transcript: %w", err)
+		}
+	}
+	a.attribute()
+	return t, nil
+}
+
+// pendingResult is a tool result awaiting the turn that reads it.
+type pendingResult struct {
+	call     ToolCall
+	estimate int64
+}
+
+// attributor follows the main conversation, pairing tool results with the
+// turn that follows them. Turns are referred to by index in t.Turns.
+type attributor struct {
+	t     *Transcript
+	price Pricer
+	names map[string]string // Tool name by tool_use ID
+	turns map[string]int    // Turn by message ID
+
+	cur      int             // Latest main conversation turn, or -1
+	prev     int             // The main conversation turn before it, or -1
+	answered []pendingResult // Results read by cur
+	pending  []pendingResult // Results awaiting the next turn
+}
+
+func (a *attributor) add(l *line) {
+	var blocks []block
+	// Plain text content is a string, which has no blocks
+	_ = json.Unmarshal(l.Message.Content, &blocks)
+
+	switch l.Type {
+	case "assistant":
+		for _, b := range blocks {
+			if b.Type == "tool_use" && b.ID != "" {
+				a.names[b.ID] = b.Name
+			}
+		}
+		if l.Message.Usage == nil || l.Message.ID == "" {
+			return
+		}
+		if i, ok := a.turns[l.Message.ID]; ok {
+			// A later line of the same response; output tokens are final on
+			// the last one
+			turn := &a.t.Turns[i]
+			if l.Message.Usage.OutputTokens > turn.Usage.OutputTokens {
+				turn.Usage.OutputTokens = l.Message.Usage.OutputTokens
+				turn.CostUSD = a.cost(turn.Model, turn.Timestamp, turn.Usage)
+			}
+			return
+		}
+
+		turn := Turn{
+			MessageID: l.Message.ID,
+			Model:     l.Message.Model,
+			Timestamp: l.Timestamp,
+			Usage:     *l.Message.Usage,
+		}
+		turn.CostUSD = a.cost(turn.Model, turn.Timestamp, turn.Usage)
+		a.turns[turn.MessageID] = len(a.t.Turns)
+		a.t.Turns = append(a.t.Turns, turn)
+
+		if l.IsSidechain {
+			return
+		}
+		// Results are attributed once the turn reading them is final, when
+		// the next one starts or the transcript ends
+		a.attribute()
+		a.prev, a.cur = a.cur, len(a.t.Turns)-1
+		a.answered, a.pending = a.pending, nil
+
+	case "user":
+		if l.IsSidechain {
+			return
+		}
+		for _, b := range blocks {
+			if b.Type != "tool_result" {
+				continue
+			}
+			name, ok := a.names[b.ToolUseID]
+			if !ok {
+				continue
+			}
+			bytes, estimate := tokens.Response(b.Content)
+			a.pending = append(a.pending, pendingResult{
+				call: ToolCall{
+					ToolUseID:   b.ToolUseID,
+					ToolName:    name,
+					Timestamp:   l.Timestamp,
+					ResultBytes: bytes,
+				},
+				estimate: estimate,
+			})
+		}
+	}
+}
+
+// attribute assigns the results read by the current turn their share of it.
+func (a *attributor) attribute() {
+	answered := a.answered
+	a.answered = nil
+	if len(answered) == 0 {
+		return
+	}
+
+	turn := a.t.Turns[a.cur]
+	var estimated int64
+	for _, p := range answered {
+		estimated += p.estimate
+	}
+
+	added := estimated
+	if a.prev >= 0 {
+		prev := a.t.Turns[a.prev].Usage
+		growth := turn.Usage.ContextTokens() - prev.ContextTokens() - prev.OutputTokens
+		if growth > 0 && growth <= 2*estimated {
+			added = growth
+		}
+	}
+
+	followUp := a.cost(turn.Model, turn.Timestamp, Usage{OutputTokens: turn.Usage.OutputTokens})
+	for _, p := range answered {
+		share := 1 / float64(len(answered))
+		if estimated > 0 {
+			share = float64(p.estimate) / float64(estimated)
+		}
+		call := p.call
+		call.Model = turn.Model
+		call.InputTokens = int64(float64(added)*share + 0.5)
+		call.InputCostUSD = a.cost(turn.Model, turn.Timestamp, Usage{InputTokens: call.InputTokens})
+		call.FollowUpCostUSD = followUp * share
+		a.t.ToolCalls = append(a.t.ToolCalls, call)
+	}
+}
+
+func (a *attributor) cost(model string, at time.Time, usage Usage) float64 {
+	if a.price == nil {
+		return 0
+	}
+	return a.price(model, at, usage)
+}
@@ -0,0 +1,109 @@
+package transcript
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// price charges $1 per input token and $10 per output token, with cache
+// writes at 1.25x and reads at 0.1x, so costs are easy to check.
+func price(model string, at time.Time, u Usage) float64 {
+	return float64(u.InputTokens) + float64(u.CacheCreationTokens)*1.25 + float64(u.CacheReadTokens)*0.1 + float64(u.OutputTokens)*10
+}
+
+func assistant(id, content string, in, cacheWrite, cacheRead, out int64) string {
+	return fmt.Sprintf(`{"type":"assistant","sessionId":"s1","timestamp":"2026-10-01T12:00:00Z","message":{"id":%q,"model":"claude-sonnet-4-5","content":[%s],"usage":{"input_tokens":%d,"cache_creation_input_tokens":%d,"cache_read_input_tokens":%d,"output_tokens":%d}}}`,
+		id, content, in, cacheWrite, cacheRead, out)
+}
+
+func toolUse(id, name string) string {
+	return fmt.Sprintf(`{"type":"tool_use","id":%q,"name":%q,"input":{}}`, id, name)
+}
+
+func toolResult(id string, bytes int) string {
+	return fmt.Sprintf(`{"type":"user","sessionId":"s1","timestamp":"2026-10-01T12:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":%q,"content":%q}]}}`,
+		id, strings.Repeat("x", bytes))
+}
+
+func TestRead(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"Earlier work"}`,
+		`{"type":"user","sessionId":"s1","message":{"role":"user","content":"Find the open issues"}}`,
+		// One response split across two lines; output is final on the last
+		assistant("msg_1", `{"type":"text","text":"Searching"}`, 10, 1000, 0, 10),
+		assistant("msg_1", toolUse("toolu_1", "mcp__github__search"), 10, 1000, 0, 50),
+		toolResult("toolu_1", 4000),
+		// Context grew by 2115 - (1010 + 50) = 1055 tokens
+		assistant("msg_2", toolUse("toolu_2", "mcp__web__fetch")+","+toolUse("toolu_3", "Read"), 5, 1100, 1010, 200),
+		// A subagent's turns count toward spend only
+		`{"type":"assistant","isSidechain":true,"sessionId":"s1","message":{"id":"msg_side","model":"claude-haiku-4-5","content":[],"usage":{"input_tokens":100,"output_tokens":10}}}`,
+		toolResult("toolu_2", 400),
+		toolResult("toolu_3", 1200),
+		`not json`,
+		// After compaction the context shrank, so results are estimated
+		assistant("msg_3", `{"type":"text","text":"Done"}`, 5, 200, 0, 100),
+		toolResult("toolu_unknown", 100),
+	}
+
+	tr, err := Read(strings.NewReader(strings.Join(lines, "\n")), price)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if tr.SessionID != "s1" {
+		t.Errorf("expected session s1, got %q", tr.SessionID)
+	}
+	if len(tr.Turns) != 4 {
+		t.Fatalf("expected 4 turns, got %d", len(tr.Turns))
+	}
+	if first := tr.Turns[0]; first.Usage.OutputTokens != 50 || first.CostUSD != 10+1250+500 {
+		t.Errorf("expected the first turn's final usage, got %+v", first)
+	}
+
+	want := []struct {
+		id, tool string
+		input    int64
+		inCost   float64
+		followUp float64
+	}{
+		{"toolu_1", "mcp__github__search", 1055, 1055, 2000},
+		{"toolu_2", "mcp__web__fetch", 80, 80, 250},
+		{"toolu_3", "Read", 240, 240, 750},
+	}
+	if len(tr.ToolCalls) != len(want) {
+		t.Fatalf("expected %d attributed calls, got %+v", len(want), tr.ToolCalls)
+	}
+	for i, w := range want {
+		got := tr.ToolCalls[i]
+		if got.ToolUseID != w.id || got.ToolName != w.tool || got.InputTokens != w.input ||
+			math.Abs(got.InputCostUSD-w.inCost) > 1e-9 || math.Abs(got.FollowUpCostUSD-w.followUp) > 1e-9 {
+			t.Errorf("call %d = %s %s %d tokens $%.2f + $%.2f, want %s %s %d tokens $%.2f + $%.2f",
+				i, got.ToolUseID, got.ToolName, got.InputTokens, got.InputCostUSD, got.FollowUpCostUSD,
+				w.id, w.tool, w.input, w.inCost, w.followUp)
+		}
+		if got.Model != "claude-sonnet-4-5" {
+			t.Errorf("call %d: expected the follow-up turn's model, got %q", i, got.Model)
+		}
+	}
+	if c := tr.ToolCalls[0]; c.ResultBytes != 4000 || c.CostUSD() != 3055 {
+		t.Errorf("expected 4000 result bytes and $3055 in total, got %d and $%.2f", c.ResultBytes, c.CostUSD())
+	}
+}
+
+func TestRead_NoPricer(t *testing.T) {
+	lines := []string{
+		assistant("msg_1", toolUse("toolu_1", "mcp__github__search"), 10, 0, 0, 50),
+		toolResult("toolu_1", 400),
+		assistant("msg_2", "", 120, 0, 0, 20),
+	}
+	tr, err := Read(strings.NewReader(strings.Join(lines, "\n")+"\n"), nil)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(tr.ToolCalls) != 1 || tr.ToolCalls[0].InputTokens != 60 || tr.ToolCalls[0].CostUSD() != 0 {
+		t.Errorf("expected 60 measured tokens at no cost, got %+v", tr.ToolCalls)
+	}
+}
@@ -8,27 +8,39 @@ import (
 
 	ui "github.com/gizak/termui/v3"
 
+	"github.com/anthropics/mcp-lens/internal/alerts"
 	"github.com/anthropics/mcp-lens/internal/analytics"
 	"github.com/anthropics/mcp-lens/internal/collector"
+	"github.com/anthropics/mcp-lens/internal/errclass"
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
 // App represents the TUI application.
 type App struct {
-	config            AppConfig
-	store             storage.Store
-	sync              *collector.SyncEngine
-	dashboard         *Dashboard
-	stopChan          chan struct{}
+	config              AppConfig
+	store               storage.Store
+	sync                *collector.SyncEngine
+	dashboard           *Dashboard
+	stopChan            chan struct{}
 	utilizationAnalyzer *analytics.UtilizationAnalyzer
 	errorAnalyzer       *analytics.ErrorAnalyzer
+	anomalyDetector     *analytics.AnomalyDetector
+	sloEngine           *analytics.SLOEngine
+	consumerAnalyzer    *analytics.ContextConsumerAnalyzer
+	activeAlerts        []storage.Alert
 }
 
 // AppConfig configures the TUI application.
 type AppConfig struct {
 	RefreshInterval time.Duration
 	TimeRange       string // 1h, 24h, 7d, 30d
+	Host            string // Only show data from this host; empty for all
 	NoColor         bool
+	Classifier      *errclass.Classifier // Error classifier; nil for the built-in rules
+	SLOs            []analytics.SLO
+	Inventory       *mcpconfig.Inventory // Configured MCP servers; nil when unknown
+	Alerts          *alerts.Engine       // Run after each sync; nil when alerts are off
 }
 
 // DefaultAppConfig returns default TUI configuration.
@@ -51,7 +63,14 @@ func NewApp(config AppConfig, store storage.Store, sync *collector.SyncEngine) *
 
 	// Initialize analytics analyzers
 	app.utilizationAnalyzer = analytics.NewUtilizationAnalyzer(store, analytics.DefaultUtilizationConfig())
+	app.utilizationAnalyzer.SetInventory(config.Inventory)
 	app.errorAnalyzer = analytics.NewErrorAnalyzer(store, analytics.DefaultErrorAnalyzerConfig())
+	app.errorAnalyzer.SetClassifier(config.Classifier)
+	app.anomalyDetector = analytics.NewAnomalyDetector(store, analytics.DefaultAnomalyConfig())
+	app.consumerAnalyzer = analytics.NewContextConsumerAnalyzer(store)
+	if len(config.SLOs) > 0 {
+		app.sloEngine = analytics.NewSLOEngine(store, analytics.DefaultSLOConfig(), config.SLOs)
+	}
 
 	return app
 }
@@ -149,6 +168,14 @@ func (a *App) refresh(ctx context.Context) error {
 		a.sync.Sync(ctx)
 	}
 
+	// Evaluate alerts against the synced data; delivery failures are
+	// retried on the next refresh
+	if a.config.Alerts != nil {
+		if result, err := a.config.Alerts.Run(ctx, time.Now()); err == nil {
+			a.activeAlerts = result.Active
+		}
+	}
+
 	// Fetch data
 	data, err := a.fetchDashboardData(ctx)
 	if err != nil {
@@ -167,6 +194,7 @@ func (a *App) fetchDashboardData(ctx context.Context) (*DashboardData, error) {
 	data := &DashboardData{
 		TimeRange: a.config.TimeRange,
 		UpdatedAt: time.Now(),
+		Alerts:    a.activeAlerts,
 	}
 
 	// Parse time range
@@ -197,12 +225,39 @@ func (a *App) fetchDashboardData(ctx context.Context) (*DashboardData, error) {
 		if util, err := a.utilizationAnalyzer.AnalyzeUtilization(ctx, filter); err == nil {
 			data.Utilization = util
 		}
+		if a.config.Inventory != nil {
+			if configured, err := a.utilizationAnalyzer.AnalyzeInventory(ctx, a.config.Inventory, filter); err == nil {
+				data.Configured = configured
+			}
+		}
 	}
 
-	// Get analytics data - error totals
+	// Get analytics data - error summaries and totals
 	if a.errorAnalyzer != nil {
-		if totals, err := a.errorAnalyzer.GetErrorTotals(ctx, filter); err == nil {
-			data.ErrorTotals = totals
+		if summaries, err := a.errorAnalyzer.AnalyzeErrors(ctx, filter); err == nil {
+			data.ErrorSummaries = summaries
+			data.ErrorTotals = analytics.SummarizeTotals(summaries)
+		}
+	}
+
+	// Get analytics data - anomalies
+	if a.anomalyDetector != nil {
+		if anomalies, err := a.anomalyDetector.Detect(ctx, filter); err == nil {
+			data.Anomalies = anomalies
+		}
+	}
+
+	// Get analytics data - SLOs
+	if a.sloEngine != nil {
+		if results, err := a.sloEngine.Evaluate(ctx, time.Now(), a.config.Host); err == nil {
+			data.SLOs = results
+		}
+	}
+
+	// Get analytics data - biggest context consumers
+	if a.consumerAnalyzer != nil {
+		if consumers, err := a.consumerAnalyzer.Analyze(ctx, filter, 4); err == nil {
+			data.ContextConsumers = consumers
 		}
 	}
 
@@ -230,6 +285,7 @@ func (a *App) parseTimeFilter() storage.TimeFilter {
 	return storage.TimeFilter{
 		From: from,
 		To:   now,
+		Host: a.config.Host,
 	}
 }
 
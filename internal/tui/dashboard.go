@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	ui "github.com/gizak/termui/v3"
@@ -21,8 +22,15 @@ type DashboardData struct {
 	UpdatedAt    time.Time
 
 	// Analytics data
-	Utilization []analytics.ServerUtilization
-	ErrorTotals *analytics.ErrorTotals
+	Utilization    []analytics.ServerUtilization
+	ErrorTotals    *analytics.ErrorTotals
+	ErrorSummaries []analytics.ErrorSummary
+	Anomalies      []analytics.Anomaly
+	SLOs           []analytics.SLOResult
+	Configured     []analytics.ConfiguredServer // Configured MCP servers with their usage
+
+	ContextConsumers []analytics.ContextConsumer // Tools whose responses take up the most context
+	Alerts           []storage.Alert             // Active alerts
 }
 
 // Dashboard holds all TUI widgets.
@@ -31,6 +39,7 @@ type Dashboard struct {
 	mcpTable       *widgets.Table
 	sparkline      *widgets.Sparkline
 	sparklineGroup *widgets.SparklineGroup
+	consumersList  *widgets.List
 	eventsList     *widgets.List
 	footer         *widgets.Paragraph
 
@@ -68,6 +77,13 @@ func NewDashboard(timeRange string) *Dashboard {
 	d.sparklineGroup.BorderStyle = ui.NewStyle(ui.ColorMagenta)
 	d.sparklineGroup.TitleStyle = ui.NewStyle(ui.ColorMagenta, ui.ColorClear, ui.ModifierBold)
 
+	// Biggest responses list
+	d.consumersList = widgets.NewList()
+	d.consumersList.Title = " Biggest Responses "
+	d.consumersList.TextStyle = ui.NewStyle(ui.ColorWhite)
+	d.consumersList.BorderStyle = ui.NewStyle(ui.ColorMagenta)
+	d.consumersList.TitleStyle = ui.NewStyle(ui.ColorMagenta, ui.ColorClear, ui.ModifierBold)
+
 	// Events list
 	d.eventsList = widgets.NewList()
 	d.eventsList.Title = " Recent Events "
@@ -95,6 +111,7 @@ func (d *Dashboard) Update(data *DashboardData) {
 	d.updateHeader()
 	d.updateMCPTable()
 	d.updateSparkline()
+	d.updateConsumersList()
 	d.updateEventsList()
 }
 
@@ -129,6 +146,22 @@ func (d *Dashboard) updateHeader() {
 			healthSummary = "  |  [✓ healthy](fg:green)"
 		}
 	}
+	if slos := formatSLOSummary(d.data.SLOs); slos != "" {
+		healthSummary += "  |  SLO " + slos
+	}
+	if n := len(d.data.Anomalies); n > 0 {
+		a := d.data.Anomalies[0]
+		healthSummary += fmt.Sprintf("  |  [⚠ %d anomalies](fg:magenta): %s %s", n, a.Subject(), a.Describe())
+	}
+
+	if n := len(d.data.Alerts); n > 0 {
+		a := d.data.Alerts[n-1]
+		color := "yellow"
+		if a.Severity == "critical" {
+			color = "red"
+		}
+		healthSummary += fmt.Sprintf("  |  [🔔 %d alerts](fg:%s): %s", n, color, a.Message)
+	}
 
 	d.header.Title = fmt.Sprintf(" MCP Lens - %s ", d.data.UpdatedAt.Format("15:04:05"))
 	d.header.Text = fmt.Sprintf(
@@ -155,17 +188,33 @@ func (d *Dashboard) updateMCPTable() {
 	}
 
 	rows := [][]string{
-		{"Server", "Calls", "Util %", "Errors", "Avg Latency", "Health"},
+		{"Server", "Calls", "Util %", "Errors", "Avg Latency", "p50/p90/p99", "Health"},
+	}
+
+	// Build lookup map of each server's largest server-wide anomaly
+	anomalyMap := make(map[string]analytics.Anomaly)
+	for _, a := range d.data.Anomalies {
+		if _, seen := anomalyMap[a.MCPServer]; !seen && a.ToolName == "" {
+			anomalyMap[a.MCPServer] = a
+		}
+	}
+
+	// Build error summary lookup map
+	errorMap := make(map[string]analytics.ErrorSummary)
+	for _, e := range d.data.ErrorSummaries {
+		errorMap[e.ServerName] = e
 	}
 
 	for _, s := range d.data.MCPServers {
 		// Get utilization data if available
 		util, hasUtil := utilMap[s.ServerName]
 
-		// Utilization percentage
+		// Utilization percentage and call volume trend
 		utilPct := "-"
+		calls := fmt.Sprintf("%d", s.TotalCalls)
 		if hasUtil {
 			utilPct = fmt.Sprintf("%.1f%%", util.UtilizationPct)
+			calls = formatCalls(s.TotalCalls, util)
 		}
 
 		// Health status with color
@@ -173,21 +222,37 @@ func (d *Dashboard) updateMCPTable() {
 		if hasUtil {
 			health = healthStatusIndicator(util.HealthStatus)
 		}
+		if a, ok := anomalyMap[s.ServerName]; ok {
+			health = anomalyIndicator(a)
+		}
 
 		latency := formatLatency(s.AvgLatencyMs)
+		percentiles := formatPercentiles(s.P50LatencyMs, s.P90LatencyMs, s.P99LatencyMs)
 
 		rows = append(rows, []string{
 			s.ServerName,
-			fmt.Sprintf("%d", s.TotalCalls),
+			calls,
 			utilPct,
-			fmt.Sprintf("%d", s.ErrorCount),
+			formatErrors(s.ErrorCount, errorMap[s.ServerName]),
 			latency,
+			percentiles,
 			health,
 		})
 	}
 
+	// Configured servers without calls in the range
+	listed := make(map[string]bool, len(d.data.MCPServers))
+	for _, s := range d.data.MCPServers {
+		listed[s.ServerName] = true
+	}
+	for _, c := range d.data.Configured {
+		if c.Calls == 0 && !listed[c.ServerName] {
+			rows = append(rows, []string{c.ServerName, "0", "-", "-", "-", "-", usageIndicator(c)})
+		}
+	}
+
 	if len(rows) == 1 {
-		rows = append(rows, []string{"(no MCP servers)", "-", "-", "-", "-", "-"})
+		rows = append(rows, []string{"(no MCP servers)", "-", "-", "-", "-", "-", "-"})
 	}
 
 	d.mcpTable.Rows = rows
@@ -208,6 +273,38 @@ func (d *Dashboard) updateSparkline() {
 	d.sparkline.Data = data
 }
 
+// updateConsumersList updates the list of tools whose responses take up the
+// most context.
+func (d *Dashboard) updateConsumersList() {
+	if d.data == nil {
+		return
+	}
+
+	rows := make([]string, 0, len(d.data.ContextConsumers))
+	for _, c := range d.data.ContextConsumers {
+		toolName := c.ToolName
+		if len(toolName) > 28 {
+			toolName = toolName[:25] + "..."
+		}
+
+		color := "green"
+		if c.LargeResponses > 0 {
+			color = "red"
+		} else if c.P90Tokens > analytics.LargeResponseTokens/2 {
+			color = "yellow"
+		}
+
+		rows = append(rows, fmt.Sprintf(" %-28s %4.0f%%  p90 [%s](fg:%s)",
+			toolName, c.SharePct, formatTokens(c.P90Tokens), color))
+	}
+
+	if len(rows) == 0 {
+		rows = []string{" (no response sizes)"}
+	}
+
+	d.consumersList.Rows = rows
+}
+
 // updateEventsList updates the recent events list.
 func (d *Dashboard) updateEventsList() {
 	if d.data == nil {
@@ -273,8 +370,10 @@ func (d *Dashboard) Render() {
 	d.mcpTable.SetRect(0, y, termWidth, y+tableHeight)
 	y += tableHeight
 
-	// Sparkline
-	d.sparklineGroup.SetRect(0, y, termWidth, y+sparklineHeight)
+	// Sparkline, with the biggest responses beside it
+	consumersWidth := min(56, termWidth*2/5)
+	d.sparklineGroup.SetRect(0, y, termWidth-consumersWidth, y+sparklineHeight)
+	d.consumersList.SetRect(termWidth-consumersWidth, y, termWidth, y+sparklineHeight)
 	y += sparklineHeight
 
 	// Events list
@@ -284,7 +383,7 @@ func (d *Dashboard) Render() {
 	// Footer
 	d.footer.SetRect(0, y, termWidth, termHeight)
 
-	ui.Render(d.header, d.mcpTable, d.sparklineGroup, d.eventsList, d.footer)
+	ui.Render(d.header, d.mcpTable, d.sparklineGroup, d.consumersList, d.eventsList, d.footer)
 }
 
 // ScrollDown scrolls the events list down.
@@ -331,6 +430,81 @@ func healthStatusIndicator(status analytics.HealthStatus) string {
 	}
 }
 
+// usageIndicator marks a configured server that is not being called.
+func usageIndicator(c analytics.ConfiguredServer) string {
+	switch {
+	case c.Disabled():
+		return "[○](fg:white) disabled"
+	case c.Usage == analytics.UsageNeverUsed:
+		return "[○](fg:yellow) never used"
+	default:
+		return fmt.Sprintf("[○](fg:white) unused %dd", c.DaysSinceUse)
+	}
+}
+
+// formatSLOSummary names the SLOs that are breached or at risk, or reports
+// that all are met.
+func formatSLOSummary(results []analytics.SLOResult) string {
+	var breached, atRisk []string
+	met := 0
+	for _, r := range results {
+		switch r.Status {
+		case analytics.SLOBreached:
+			breached = append(breached, r.SLO.Name)
+		case analytics.SLOAtRisk:
+			atRisk = append(atRisk, r.SLO.Name)
+		case analytics.SLOMet:
+			met++
+		}
+	}
+
+	var parts []string
+	if len(breached) > 0 {
+		parts = append(parts, fmt.Sprintf("[✗ %s](fg:red)", strings.Join(breached, ", ")))
+	}
+	if len(atRisk) > 0 {
+		parts = append(parts, fmt.Sprintf("[! %s](fg:yellow)", strings.Join(atRisk, ", ")))
+	}
+	if len(parts) == 0 && met > 0 {
+		parts = append(parts, fmt.Sprintf("[✓ %d met](fg:green)", met))
+	}
+	return strings.Join(parts, " ")
+}
+
+// anomalyIndicator marks a server that is unusually slow or failing.
+func anomalyIndicator(a analytics.Anomaly) string {
+	if a.Metric == analytics.MetricLatency {
+		return fmt.Sprintf("[▲](fg:magenta) slow %.1fσ", a.ZScore)
+	}
+	return fmt.Sprintf("[▲](fg:magenta) failing %.1fσ", a.ZScore)
+}
+
+// formatCalls renders a call count with its trend, such as "120 ↑35%".
+func formatCalls(count int64, util analytics.ServerUtilization) string {
+	if util.Trend != analytics.TrendUp && util.Trend != analytics.TrendDown {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%d %s", count, analytics.FormatTrend(util.Trend, util.TrendPct))
+}
+
+// formatErrors renders an error count with the server's main kind of
+// failure and error rate trend, such as "12 (80% auth) ↑40%". A rising
+// error rate is red and a falling one green.
+func formatErrors(count int64, summary analytics.ErrorSummary) string {
+	result := fmt.Sprintf("%d", count)
+	if c, ok := summary.TopCategory(); count > 0 && ok {
+		result += fmt.Sprintf(" (%.0f%% %s)", c.Share, c.Category.Label())
+	}
+
+	switch summary.ErrorTrend {
+	case analytics.TrendUp:
+		result += fmt.Sprintf(" [%s](fg:red)", analytics.FormatTrend(summary.ErrorTrend, summary.ErrorTrendPct))
+	case analytics.TrendDown:
+		result += fmt.Sprintf(" [%s](fg:green)", analytics.FormatTrend(summary.ErrorTrend, summary.ErrorTrendPct))
+	}
+	return result
+}
+
 func formatLatency(ms float64) string {
 	if ms == 0 {
 		return "-"
@@ -346,6 +520,30 @@ func formatLatency(ms float64) string {
 	return fmt.Sprintf("[%dms](fg:%s)", int(ms), color)
 }
 
+// formatPercentiles renders p50/p90/p99 latencies, colored by p90.
+func formatPercentiles(p50, p90, p99 float64) string {
+	if p50 == 0 && p90 == 0 && p99 == 0 {
+		return "-"
+	}
+
+	color := "green"
+	if p90 > 500 {
+		color = "red"
+	} else if p90 > 100 {
+		color = "yellow"
+	}
+
+	return fmt.Sprintf("[%d/%d/%dms](fg:%s)", int(p50), int(p90), int(p99), color)
+}
+
+// formatTokens renders a token count compactly, such as "12.5K".
+func formatTokens(tokens float64) string {
+	if tokens >= 1000 {
+		return fmt.Sprintf("%.1fK", tokens/1000)
+	}
+	return fmt.Sprintf("%.0f", tokens)
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -1,9 +1,13 @@
 package tui
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/errclass"
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
@@ -90,6 +94,113 @@ func TestStatusIndicator(t *testing.T) {
 	}
 }
 
+func TestFormatErrors(t *testing.T) {
+	authHeavy := analytics.ErrorSummary{Categories: []analytics.CategoryCount{
+		{Category: errclass.Auth, Count: 8, Share: 80},
+		{Category: errclass.Timeout, Count: 2, Share: 20},
+	}}
+	unrecognized := analytics.ErrorSummary{Categories: []analytics.CategoryCount{
+		{Category: errclass.Other, Count: 3, Share: 100},
+	}}
+
+	tests := []struct {
+		count    int64
+		summary  analytics.ErrorSummary
+		expected string
+	}{
+		{0, analytics.ErrorSummary{}, "0"},
+		{10, authHeavy, "10 (80% auth)"},
+		{3, unrecognized, "3"},
+		{4, analytics.ErrorSummary{ErrorTrend: analytics.TrendUp, ErrorTrendPct: 50}, "4 [↑50%](fg:red)"},
+		{1, analytics.ErrorSummary{ErrorTrend: analytics.TrendDown, ErrorTrendPct: -75}, "1 [↓75%](fg:green)"},
+	}
+
+	for _, tt := range tests {
+		result := formatErrors(tt.count, tt.summary)
+		if result != tt.expected {
+			t.Errorf("formatErrors(%d) = %s, want %s", tt.count, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatSLOSummary(t *testing.T) {
+	result := func(name string, status analytics.SLOStatus) analytics.SLOResult {
+		return analytics.SLOResult{SLO: analytics.SLO{Name: name}, Status: status}
+	}
+
+	tests := []struct {
+		results  []analytics.SLOResult
+		expected string
+	}{
+		{nil, ""},
+		{[]analytics.SLOResult{result("github", analytics.SLOMet), result("fs", analytics.SLOMet)}, "[✓ 2 met](fg:green)"},
+		{[]analytics.SLOResult{result("github", analytics.SLOBreached), result("fs", analytics.SLOAtRisk), result("slack", analytics.SLOMet)},
+			"[✗ github](fg:red) [! fs](fg:yellow)"},
+		{[]analytics.SLOResult{result("slack", analytics.SLONoData)}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatSLOSummary(tt.results); got != tt.expected {
+			t.Errorf("formatSLOSummary() = %q, want %q", got, tt.expected)
+		}
+	}
+}
+
+func TestAnomalyIndicator(t *testing.T) {
+	slow := anomalyIndicator(analytics.Anomaly{Metric: analytics.MetricLatency, ZScore: 4.25})
+	if slow != "[▲](fg:magenta) slow 4.2σ" {
+		t.Errorf("latency anomaly indicator = %q", slow)
+	}
+	failing := anomalyIndicator(analytics.Anomaly{Metric: analytics.MetricErrorRate, ZScore: 12})
+	if failing != "[▲](fg:magenta) failing 12.0σ" {
+		t.Errorf("error rate anomaly indicator = %q", failing)
+	}
+}
+
+func TestUsageIndicator(t *testing.T) {
+	tests := []struct {
+		server   analytics.ConfiguredServer
+		expected string
+	}{
+		{
+			analytics.ConfiguredServer{Usage: analytics.UsageNeverUsed, Locations: []mcpconfig.Server{{Scope: mcpconfig.ScopeUser}}},
+			"[○](fg:yellow) never used",
+		},
+		{
+			analytics.ConfiguredServer{Usage: analytics.UsageInactive, DaysSinceUse: 12},
+			"[○](fg:white) unused 12d",
+		},
+		{
+			analytics.ConfiguredServer{Usage: analytics.UsageNeverUsed, Locations: []mcpconfig.Server{{Disabled: true}}},
+			"[○](fg:white) disabled",
+		},
+	}
+	for _, tt := range tests {
+		if got := usageIndicator(tt.server); got != tt.expected {
+			t.Errorf("usageIndicator() = %q, want %q", got, tt.expected)
+		}
+	}
+}
+
+func TestFormatCalls(t *testing.T) {
+	tests := []struct {
+		util     analytics.ServerUtilization
+		expected string
+	}{
+		{analytics.ServerUtilization{Trend: analytics.TrendStable, TrendPct: 5}, "120"},
+		{analytics.ServerUtilization{Trend: analytics.TrendUp, TrendPct: 35}, "120 ↑35%"},
+		{analytics.ServerUtilization{Trend: analytics.TrendDown, TrendPct: -40}, "120 ↓40%"},
+		{analytics.ServerUtilization{Trend: analytics.TrendUp, TrendPct: math.Inf(1)}, "120 ↑new"},
+	}
+
+	for _, tt := range tests {
+		result := formatCalls(120, tt.util)
+		if result != tt.expected {
+			t.Errorf("formatCalls(120, %s %v) = %s, want %s", tt.util.Trend, tt.util.TrendPct, result, tt.expected)
+		}
+	}
+}
+
 func TestFormatLatency(t *testing.T) {
 	tests := []struct {
 		ms       float64
@@ -109,6 +220,25 @@ func TestFormatLatency(t *testing.T) {
 	}
 }
 
+func TestFormatPercentiles(t *testing.T) {
+	tests := []struct {
+		p50, p90, p99 float64
+		expected      string
+	}{
+		{0, 0, 0, "-"},
+		{20, 80, 150, "[20/80/150ms](fg:green)"},
+		{50, 300, 900, "[50/300/900ms](fg:yellow)"},
+		{200, 1200, 4000, "[200/1200/4000ms](fg:red)"},
+	}
+
+	for _, tt := range tests {
+		result := formatPercentiles(tt.p50, tt.p90, tt.p99)
+		if result != tt.expected {
+			t.Errorf("formatPercentiles(%v, %v, %v) = %s, want %s", tt.p50, tt.p90, tt.p99, result, tt.expected)
+		}
+	}
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		input    string
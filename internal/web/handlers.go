@@ -6,6 +6,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/anthropics/mcp-lens/internal/analytics"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
 
@@ -16,6 +17,8 @@ type dashboardData struct {
 	Summary         interface{}
 	MCPUtilization  interface{}
 	RecentEvents    []storage.Event
+	Anomalies       []analytics.Anomaly
+	SLOs            []analytics.SLOResult
 }
 
 type mcpData struct {
@@ -23,12 +26,19 @@ type mcpData struct {
 	RefreshInterval int
 	Servers         interface{}
 	TotalCalls      int64
+	Errors          map[string]analytics.ErrorSummary // By server name
+	Configured      []analytics.ConfiguredServer      // Configured servers not in active use
+	Probes          []storage.ProbeResult             // Latest probe of each server
+	ContextCosts    []analytics.ContextCost           // Context taken by each server's tool definitions
+	ProxyStats      []storage.ProxyMethodStats        // Requests recorded by the MCP proxy
+	ProxyFailures   []storage.ProxyFailureCount       // Failed proxied requests by cause
 }
 
 type toolsData struct {
-	Title           string
-	RefreshInterval int
-	Tools           interface{}
+	Title            string
+	RefreshInterval  int
+	Tools            interface{}
+	ContextConsumers []analytics.ContextConsumer // Tools whose responses take up the most context
 }
 
 type costsData struct {
@@ -37,6 +47,8 @@ type costsData struct {
 	Summary         interface{}
 	Forecast        interface{}
 	ByModel         []storage.ModelCost
+	ByServer        []storage.ServerCost // Spend attributed to each MCP server's tool calls
+	ByTool          []storage.ToolCost   // Spend attributed to each tool, built-in tools included
 }
 
 type sessionsData struct {
@@ -47,6 +59,15 @@ type sessionsData struct {
 	HasMore         bool
 }
 
+type searchData struct {
+	Title   string
+	Query   string
+	Server  string
+	Session string
+	Range   string
+	Results []storage.SearchResult
+}
+
 type sessionDetailData struct {
 	Title   string
 	Session *storage.Session
@@ -70,12 +91,24 @@ func (s *Server) handleDashboard(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	anomalies, err := s.anomalies.Detect(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	slos, err := s.slos.Evaluate(ctx, time.Now(), filter.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	return c.Render(http.StatusOK, "dashboard.html", dashboardData{
 		Title:           "MCP Lens Dashboard",
 		RefreshInterval: s.config.RefreshInterval,
 		Summary:         summary,
 		MCPUtilization:  mcpUtil,
 		RecentEvents:    summary.RecentEvents,
+		Anomalies:       anomalies,
+		SLOs:            slos,
 	})
 }
 
@@ -94,11 +127,64 @@ func (s *Server) handleMCPServers(c echo.Context) error {
 		totalCalls += srv.CallCount
 	}
 
+	summaries, err := s.errors.AnalyzeErrors(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	errorsByServer := make(map[string]analytics.ErrorSummary, len(summaries))
+	for _, summary := range summaries {
+		errorsByServer[summary.ServerName] = summary
+	}
+
+	var configured []analytics.ConfiguredServer
+	if s.config.Inventory != nil {
+		all, err := s.utilization.AnalyzeInventory(ctx, s.config.Inventory, filter)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		for _, srv := range all {
+			if srv.Usage != analytics.UsageActive {
+				configured = append(configured, srv)
+			}
+		}
+	}
+
+	probes, err := s.store.GetLatestProbes(ctx, filter.Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	contextCosts, err := s.context.Analyze(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	proxyStats, err := s.store.GetProxyStats(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	proxyFailures, err := s.store.GetProxyFailures(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	var requests []storage.ProxyMethodStats
+	for _, st := range proxyStats {
+		if st.Kind == storage.ProxyRequest {
+			requests = append(requests, st)
+		}
+	}
+
 	return c.Render(http.StatusOK, "mcp.html", mcpData{
 		Title:           "MCP Servers",
 		RefreshInterval: s.config.RefreshInterval,
 		Servers:         servers,
 		TotalCalls:      totalCalls,
+		Errors:          errorsByServer,
+		Configured:      configured,
+		Probes:          probes,
+		ContextCosts:    contextCosts,
+		ProxyStats:      requests,
+		ProxyFailures:   proxyFailures,
 	})
 }
 
@@ -112,10 +198,16 @@ func (s *Server) handleTools(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	consumers, err := s.consumers.Analyze(ctx, filter, 20)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
 	return c.Render(http.StatusOK, "tools.html", toolsData{
-		Title:           "Tool Analytics",
-		RefreshInterval: s.config.RefreshInterval,
-		Tools:           tools,
+		Title:            "Tool Analytics",
+		RefreshInterval:  s.config.RefreshInterval,
+		Tools:            tools,
+		ContextConsumers: consumers,
 	})
 }
 
@@ -129,7 +221,7 @@ func (s *Server) handleCosts(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	forecast, err := s.calculator.GetCostForecast(ctx, 14)
+	forecast, err := s.calculator.GetCostForecast(ctx, 28, s.config.BudgetMonthly)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -139,12 +231,27 @@ func (s *Server) handleCosts(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	byServer, err := s.store.GetCostByServer(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	byTool, err := s.store.GetCostByTool(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if len(byTool) > 20 {
+		byTool = byTool[:20]
+	}
+
 	return c.Render(http.StatusOK, "costs.html", costsData{
 		Title:           "Cost Analytics",
 		RefreshInterval: s.config.RefreshInterval,
 		Summary:         summary,
 		Forecast:        forecast,
 		ByModel:         byModel,
+		ByServer:        byServer,
+		ByTool:          byTool,
 	})
 }
 
@@ -227,7 +334,8 @@ func (s *Server) handleRecentEventsPartial(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	events, err := s.store.GetEvents(ctx, storage.EventFilter{
-		Limit: 10,
+		TimeFilter: storage.TimeFilter{Host: c.QueryParam("host")},
+		Limit:      10,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -236,12 +344,51 @@ func (s *Server) handleRecentEventsPartial(c echo.Context) error {
 	return c.Render(http.StatusOK, "partials/recent-events.html", events)
 }
 
+// Search handler
+func (s *Server) handleSearch(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	data := searchData{
+		Title:   "Search",
+		Query:   c.QueryParam("q"),
+		Server:  c.QueryParam("server"),
+		Session: c.QueryParam("session"),
+		Range:   c.QueryParam("range"),
+	}
+
+	if data.Query != "" {
+		query := storage.SearchQuery{
+			EventFilter: storage.EventFilter{
+				TimeFilter: s.parseTimeFilter(c),
+				SessionID:  data.Session,
+				Limit:      50,
+			},
+			Query: data.Query,
+		}
+		if data.Server != "" {
+			query.MCPServers = []string{data.Server}
+		}
+
+		results, err := s.store.SearchEvents(ctx, query)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		data.Results = results
+	}
+
+	return c.Render(http.StatusOK, "search.html", data)
+}
+
 // Helper to parse time filter from query params
 func (s *Server) parseTimeFilter(c echo.Context) storage.TimeFilter {
-	filter := storage.TimeFilter{}
+	now := time.Now()
 
-	// Default to last 24 hours
-	filter.From = time.Now().Add(-24 * time.Hour)
+	// Default to last 24 hours. Bounding the range at now gives trends a
+	// baseline window to compare against.
+	filter := storage.TimeFilter{
+		From: now.Add(-24 * time.Hour),
+		To:   now,
+	}
 
 	// Override with query params if provided
 	if from := c.QueryParam("from"); from != "" {
@@ -259,16 +406,19 @@ func (s *Server) parseTimeFilter(c echo.Context) storage.TimeFilter {
 	// Handle preset ranges
 	switch c.QueryParam("range") {
 	case "1h":
-		filter.From = time.Now().Add(-1 * time.Hour)
+		filter.From = now.Add(-1 * time.Hour)
 	case "6h":
-		filter.From = time.Now().Add(-6 * time.Hour)
+		filter.From = now.Add(-6 * time.Hour)
 	case "24h":
-		filter.From = time.Now().Add(-24 * time.Hour)
+		filter.From = now.Add(-24 * time.Hour)
 	case "7d":
-		filter.From = time.Now().AddDate(0, 0, -7)
+		filter.From = now.AddDate(0, 0, -7)
 	case "30d":
-		filter.From = time.Now().AddDate(0, 0, -30)
+		filter.From = now.AddDate(0, 0, -30)
 	}
 
+	// Restrict to one machine's data in merged databases
+	filter.Host = c.QueryParam("host")
+
 	return filter
 }
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/anthropics/mcp-lens/internal/metrics"
+	"github.com/anthropics/mcp-lens/internal/storage"
+)
+
+// dataRecorder records the data handlers render instead of executing
+// templates.
+type dataRecorder struct {
+	data interface{}
+}
+
+func (r *dataRecorder) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
+	r.data = data
+	return nil
+}
+
+func TestHandleMCPServers_Trend(t *testing.T) {
+	store := storage.NewMockStore()
+	now := time.Now()
+
+	// 10 calls in the previous day, 30 in the last one
+	for i, at := range []time.Time{now.Add(-30 * time.Hour), now.Add(-time.Hour)} {
+		for j := 0; j < 10+20*i; j++ {
+			err := store.StoreEvent(context.Background(), &storage.Event{
+				SessionID:  "s1",
+				EventType:  "PostToolUse",
+				ToolName:   "mcp__github__search",
+				MCPServer:  "github",
+				Success:    true,
+				DurationMs: 100,
+				CreatedAt:  at,
+			})
+			if err != nil {
+				t.Fatalf("StoreEvent failed: %v", err)
+			}
+		}
+	}
+
+	s, err := NewServer(DefaultServerConfig(), store)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+
+	rendered := &dataRecorder{}
+	s.echo.Renderer = rendered
+
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, ok := rendered.data.(mcpData)
+	if !ok {
+		t.Fatalf("expected MCP page data, got %T", rendered.data)
+	}
+	servers, _ := data.Servers.([]metrics.MCPUtilization)
+	if len(servers) != 1 {
+		t.Fatalf("expected one server rendered, got %+v", data.Servers)
+	}
+	srv := servers[0]
+	if got := formatTrend(srv.TrendDirection, srv.TrendPct); got != "↑200%" {
+		t.Errorf("expected calls trending up 200%%, got %q", got)
+	}
+}
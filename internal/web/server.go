@@ -14,6 +14,9 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/anthropics/mcp-lens/internal/analytics"
+	"github.com/anthropics/mcp-lens/internal/errclass"
+	"github.com/anthropics/mcp-lens/internal/mcpconfig"
 	"github.com/anthropics/mcp-lens/internal/metrics"
 	"github.com/anthropics/mcp-lens/internal/storage"
 )
@@ -27,7 +30,11 @@ type ServerConfig struct {
 	BindAddress     string
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
-	RefreshInterval int // seconds
+	RefreshInterval int                  // seconds
+	Classifier      *errclass.Classifier // Error classifier; nil for the built-in rules
+	SLOs            []analytics.SLO
+	Inventory       *mcpconfig.Inventory // Configured MCP servers; nil when unknown
+	BudgetMonthly   float64              // Monthly budget forecasts are projected against; 0 for none
 }
 
 // DefaultServerConfig returns default server configuration.
@@ -43,11 +50,17 @@ func DefaultServerConfig() ServerConfig {
 
 // Server represents the web dashboard server.
 type Server struct {
-	config     ServerConfig
-	echo       *echo.Echo
-	store      storage.Store
-	calculator *metrics.Calculator
-	templates  *template.Template
+	config      ServerConfig
+	echo        *echo.Echo
+	store       storage.Store
+	calculator  *metrics.Calculator
+	errors      *analytics.ErrorAnalyzer
+	utilization *analytics.UtilizationAnalyzer
+	anomalies   *analytics.AnomalyDetector
+	slos        *analytics.SLOEngine
+	context     *analytics.ContextCostAnalyzer
+	consumers   *analytics.ContextConsumerAnalyzer
+	templates   *template.Template
 }
 
 // NewServer creates a new web dashboard server.
@@ -63,10 +76,17 @@ func NewServer(config ServerConfig, store storage.Store) (*Server, error) {
 	}
 
 	s := &Server{
-		config:     config,
-		store:      store,
-		calculator: metrics.NewCalculator(store),
+		config:      config,
+		store:       store,
+		calculator:  metrics.NewCalculator(store),
+		errors:      analytics.NewErrorAnalyzer(store, analytics.DefaultErrorAnalyzerConfig()),
+		utilization: analytics.NewUtilizationAnalyzer(store, analytics.DefaultUtilizationConfig()),
+		anomalies:   analytics.NewAnomalyDetector(store, analytics.DefaultAnomalyConfig()),
+		slos:        analytics.NewSLOEngine(store, analytics.DefaultSLOConfig(), config.SLOs),
+		context:     analytics.NewContextCostAnalyzer(store),
+		consumers:   analytics.NewContextConsumerAnalyzer(store),
 	}
+	s.errors.SetClassifier(config.Classifier)
 
 	// Parse templates
 	templates, err := s.loadTemplates()
@@ -131,7 +151,11 @@ func (s *Server) loadTemplates() (*template.Template, error) {
 		"formatCost":     formatCost,
 		"formatPercent":  formatPercent,
 		"formatNumber":   formatNumber,
+		"formatBytes":    formatBytes,
 		"formatTime":     formatTime,
+		"formatDate":     formatDate,
+		"formatTrend":    formatTrend,
+		"formatWindow":   analytics.FormatWindow,
 		"sub":            func(a, b int) int { return a - b },
 		"add":            func(a, b int) int { return a + b },
 	}
@@ -176,6 +200,7 @@ func (s *Server) setupRoutes(e *echo.Echo) {
 	e.GET("/costs", s.handleCosts)
 	e.GET("/sessions", s.handleSessions)
 	e.GET("/sessions/:id", s.handleSessionDetail)
+	e.GET("/search", s.handleSearch)
 
 	// HTMX partials
 	e.GET("/partials/metrics", s.handleMetricsPartial)
@@ -235,9 +260,42 @@ func formatNumber(n int64) string {
 	return fmt.Sprintf("%.1fM", float64(n)/1000000)
 }
 
+// formatBytes renders a byte count, whole or averaged, such as "512B",
+// "4.2KB" or "1.3MB".
+func formatBytes(count interface{}) string {
+	var n float64
+	switch v := count.(type) {
+	case int64:
+		n = float64(v)
+	case float64:
+		n = v
+	}
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%.0fB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1fKB", n/1024)
+	default:
+		return fmt.Sprintf("%.1fMB", n/(1024*1024))
+	}
+}
+
 func formatTime(t time.Time) string {
 	if t.IsZero() {
 		return "-"
 	}
 	return t.Format("Jan 02 15:04")
 }
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("Mon Jan 02")
+}
+
+// formatTrend renders a trend direction ("up", "down" or "stable") with its
+// percentage change, such as "↑35%".
+func formatTrend(direction interface{}, changePct float64) string {
+	return analytics.FormatTrend(analytics.Trend(fmt.Sprint(direction)), changePct)
+}